@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+// Command sd-activate is a test harness for socket activation, similar to
+// [systemd-socket-activate(1)].
+//
+// It binds to the requested addresses, passes the sockets to the target
+// program using `LISTEN_FDS`, `LISTEN_FDNAMES` and `LISTEN_PID`, then executes
+// it. Optionally, a notify socket and watchdog can be emulated, allowing code
+// using both sdlisten and sdnotify to be exercised without systemd.
+//
+// Usage:
+//
+//	sd-activate [flags] -l ADDRESS [-l ADDRESS...] PROGRAM [ARGS...]
+//
+// [systemd-socket-activate(1)]: https://www.freedesktop.org/software/systemd/man/latest/systemd-socket-activate.html
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/sddaemon"
+)
+
+// stringsFlag is a [flag.Value] that may be specified multiple times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "sd-activate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		listen   stringsFlag
+		env      stringsFlag
+		fdnames  string
+		datagram bool
+		notify   bool
+		watchdog time.Duration
+	)
+	flag.Var(&listen, "l", "address to listen on, may be specified multiple times")
+	flag.Var(&env, "E", "environment variable (`VAR[=VALUE]`) to pass to the program, may be specified multiple times")
+	flag.StringVar(&fdnames, "fdname", "", "colon-separated list of names for the listeners")
+	flag.BoolVar(&datagram, "d", false, "listen on datagram sockets instead of stream sockets")
+	flag.BoolVar(&notify, "notify", false, "emulate a notify socket and print the messages sent to it")
+	flag.DurationVar(&watchdog, "watchdog", 0, "emulate the watchdog with the given interval, requires -notify")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] -l ADDRESS [-l ADDRESS...] PROGRAM [ARGS...]\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		flag.Usage()
+		return errors.New("missing program to execute")
+	}
+	if watchdog > 0 && !notify {
+		return errors.New("-watchdog requires -notify")
+	}
+
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return err
+	}
+
+	names := strings.Split(fdnames, ":")
+	files := make([]*os.File, len(listen))
+	for i, addr := range listen {
+		name := "LISTEN_FD_" + strconv.Itoa(i+3)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		f, err := listenFile(addr, name, datagram)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "sd-activate: listening on %s (fd %d, %s)\n", addr, i+3, name)
+		files[i] = f
+	}
+
+	a := &sddaemon.Activation{
+		Files:    files,
+		Watchdog: watchdog,
+	}
+
+	environ := os.Environ()
+	for _, kv := range env {
+		if !strings.Contains(kv, "=") {
+			kv += "=" + os.Getenv(kv)
+		}
+		environ = append(environ, kv)
+	}
+
+	if !notify {
+		// Without a notify socket there is nothing for us to do once the program
+		// is started, so replace ourselves with it.
+		return a.Exec(path, args, environ)
+	}
+
+	dir, err := os.MkdirTemp("", "sd-activate")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	a.NotifySocket = filepath.Join(dir, "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: a.NotifySocket, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Args[0] = args[0]
+	cmd.Env = environ
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := a.Apply(cmd); err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	for _, f := range files {
+		_ = f.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go forwardSignals(ctx, cmd.Process)
+	go readNotify(ctx, conn, cmd.Process, watchdog)
+
+	err = cmd.Wait()
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	cancel()
+	// Mirror the exit status of the program, using the shell convention of
+	// 128+n for programs killed by a signal.
+	if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		os.Exit(128 + int(ws.Signal()))
+	}
+	os.Exit(exitErr.ExitCode())
+	return nil
+}
+
+// listenFile binds to addr and returns the underlying socket as an [*os.File]
+// with the given name.
+//
+// Addresses starting with `/` or `@` are unix sockets (`@` being an abstract
+// socket), an address consisting of only a port number listens on all
+// interfaces, anything else is treated as a `host:port` pair.
+func listenFile(addr, name string, datagram bool) (*os.File, error) {
+	network := "tcp"
+	switch {
+	case strings.HasPrefix(addr, "/"), strings.HasPrefix(addr, "@"):
+		network = "unix"
+	case isPort(addr):
+		addr = ":" + addr
+	}
+	if datagram {
+		network = map[string]string{"tcp": "udp", "unix": "unixgram"}[network]
+	}
+
+	var (
+		f   *os.File
+		err error
+	)
+	switch network {
+	case "udp", "unixgram":
+		var pc net.PacketConn
+		pc, err = net.ListenPacket(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		defer pc.Close()
+		f, err = pc.(interface{ File() (*os.File, error) }).File()
+	default:
+		var l net.Listener
+		l, err = net.Listen(network, addr)
+		if err != nil {
+			return nil, err
+		}
+		if ul, ok := l.(*net.UnixListener); ok {
+			ul.SetUnlinkOnClose(false)
+		}
+		defer l.Close()
+		f, err = l.(interface{ File() (*os.File, error) }).File()
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Duplicate the file descriptor in order to give it the requested name.
+	fd, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_DUPFD_CLOEXEC, 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	return os.NewFile(fd, name), nil
+}
+
+// isPort reports whether v only consists of digits.
+func isPort(v string) bool {
+	if v == "" {
+		return false
+	}
+	for _, c := range v {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// forwardSignals forwards termination signals to p until ctx is canceled.
+func forwardSignals(ctx context.Context, p *os.Process) {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT)
+	defer signal.Stop(c)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case s := <-c:
+			_ = p.Signal(s)
+		}
+	}
+}
+
+// readNotify prints messages received on the notify socket until ctx is
+// canceled. If watchdog is greater than zero, p will be sent `SIGABRT` if it
+// fails to send `WATCHDOG=1` within the interval, or sends `WATCHDOG=trigger`.
+func readNotify(ctx context.Context, conn *net.UnixConn, p *os.Process, watchdog time.Duration) {
+	context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+
+	var timer *time.Timer
+	if watchdog > 0 {
+		timer = time.AfterFunc(watchdog, func() {
+			fmt.Fprintln(os.Stderr, "sd-activate: watchdog timeout, sending SIGABRT")
+			_ = p.Signal(syscall.SIGABRT)
+		})
+		defer timer.Stop()
+	}
+
+	buf := make([]byte, 16<<10)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "sd-activate: notify: %v\n", err)
+			}
+			return
+		}
+		for line := range bytes.SplitSeq(buf[:n], []byte{'\n'}) {
+			if len(line) == 0 {
+				continue
+			}
+			fmt.Fprintf(os.Stderr, "sd-activate: notify: %s\n", line)
+			if timer == nil {
+				continue
+			}
+			switch string(line) {
+			case "WATCHDOG=1":
+				timer.Reset(watchdog)
+			case "WATCHDOG=trigger":
+				timer.Reset(0)
+			}
+		}
+	}
+}
@@ -0,0 +1,734 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package dbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// busName is the well-known name of the message bus.
+	busName = "org.freedesktop.DBus"
+
+	// busPath is the object path of the message bus.
+	busPath ObjectPath = "/org/freedesktop/DBus"
+
+	// propertiesInterface is the standard interface used to access
+	// properties.
+	propertiesInterface = "org.freedesktop.DBus.Properties"
+
+	// defaultSystemBusAddress is the address of the system bus when
+	// `DBUS_SYSTEM_BUS_ADDRESS` is unset.
+	defaultSystemBusAddress = "unix:path=/run/dbus/system_bus_socket"
+
+	// authTimeout is the maximum time to wait for authentication when the
+	// context has no deadline.
+	authTimeout = 30 * time.Second
+)
+
+// ErrClosed is returned when using a connection that has been closed.
+var ErrClosed = errors.New("dbus: connection closed")
+
+// Conn is a connection to a D-Bus message bus or peer.
+type Conn struct {
+	conn   *net.UnixConn
+	name   string
+	unixFD bool
+
+	serial  atomic.Uint32
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	pending  map[uint32]chan *Message
+	handlers map[uint64]func(*Message)
+	methods  MethodHandler
+	nextID   uint64
+	err      error
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// SystemBusAddress returns the address of the system bus.
+func SystemBusAddress() string {
+	if v := os.Getenv("DBUS_SYSTEM_BUS_ADDRESS"); v != "" {
+		return v
+	}
+	return defaultSystemBusAddress
+}
+
+// SessionBusAddress returns the address of the session bus.
+func SessionBusAddress() (string, error) {
+	if v := os.Getenv("DBUS_SESSION_BUS_ADDRESS"); v != "" {
+		return v, nil
+	}
+	if v := os.Getenv("XDG_RUNTIME_DIR"); v != "" {
+		return "unix:path=" + escapeAddress(filepath.Join(v, "bus")), nil
+	}
+	return "", errors.New("dbus: unable to determine session bus address")
+}
+
+// SystemBus connects to the system bus.
+func SystemBus(ctx context.Context) (*Conn, error) {
+	return connectBus(ctx, SystemBusAddress())
+}
+
+// SessionBus connects to the session bus of the current user.
+func SessionBus(ctx context.Context) (*Conn, error) {
+	addr, err := SessionBusAddress()
+	if err != nil {
+		return nil, err
+	}
+	return connectBus(ctx, addr)
+}
+
+// connectBus connects to a message bus and registers with it.
+func connectBus(ctx context.Context, address string) (*Conn, error) {
+	c, err := Dial(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.Hello(ctx); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Dial connects and authenticates to the D-Bus server at address. The address
+// may contain multiple `;`-separated entries, which are tried in order.
+//
+// Dial does not register with the message bus, call [Conn.Hello] when
+// connecting to a bus rather than directly to a peer.
+func Dial(ctx context.Context, address string) (*Conn, error) {
+	var errs []error
+	for _, entry := range strings.Split(address, ";") {
+		if entry == "" {
+			continue
+		}
+		network, addr, err := parseAddress(entry)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		var d net.Dialer
+		nc, err := d.DialContext(ctx, network, addr)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		c, err := NewConn(ctx, nc.(*net.UnixConn))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		return c, nil
+	}
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("dbus: invalid address %q", address)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// parseAddress parses a single D-Bus server address, returning the network and
+// address to dial.
+//
+// ref; https://dbus.freedesktop.org/doc/dbus-specification.html#addresses
+func parseAddress(entry string) (string, string, error) {
+	transport, params, ok := strings.Cut(entry, ":")
+	if !ok {
+		return "", "", fmt.Errorf("dbus: invalid address %q", entry)
+	}
+	if transport != "unix" {
+		return "", "", fmt.Errorf("dbus: unsupported transport %q", transport)
+	}
+	for _, kv := range strings.Split(params, ",") {
+		k, v, _ := strings.Cut(kv, "=")
+		v, err := url.PathUnescape(v)
+		if err != nil {
+			return "", "", fmt.Errorf("dbus: invalid address %q: %w", entry, err)
+		}
+		switch k {
+		case "path":
+			return "unix", v, nil
+		case "abstract":
+			return "unix", "@" + v, nil
+		}
+	}
+	return "", "", fmt.Errorf("dbus: unsupported address %q", entry)
+}
+
+// escapeAddress escapes a value for use in a D-Bus address.
+func escapeAddress(v string) string {
+	var b strings.Builder
+	for i := range len(v) {
+		c := v[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '/', c == '\\', c == '.', c == '*':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// NewConn authenticates over an established connection and starts processing
+// incoming messages. The connection is owned by the returned [Conn].
+func NewConn(ctx context.Context, nc *net.UnixConn) (*Conn, error) {
+	c := newConn(nc)
+	if err := c.auth(ctx); err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// newConn returns a new, unauthenticated connection.
+func newConn(nc *net.UnixConn) *Conn {
+	return &Conn{
+		conn:     nc,
+		pending:  make(map[uint32]chan *Message),
+		handlers: make(map[uint64]func(*Message)),
+		done:     make(chan struct{}),
+	}
+}
+
+// Accept authenticates a client over an established connection, acting as
+// the server side of a peer-to-peer connection. Only the `EXTERNAL` mechanism
+// is supported and the credentials of the peer are not verified.
+func Accept(ctx context.Context, nc *net.UnixConn) (*Conn, error) {
+	c := newConn(nc)
+	if err := c.serverAuth(ctx); err != nil {
+		_ = nc.Close()
+		return nil, err
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+// serverAuth performs the server side of the authentication protocol.
+func (c *Conn) serverAuth(ctx context.Context) error {
+	stop, err := c.authDeadline(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	r := bufio.NewReader(&byteReader{c.conn})
+	if b, err := r.ReadByte(); err != nil || b != 0 {
+		return errors.New("dbus: invalid authentication request")
+	}
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("dbus: unable to authenticate: %w", err)
+		}
+		cmd, _, _ := strings.Cut(strings.TrimSpace(line), " ")
+		var resp string
+		switch cmd {
+		case "AUTH":
+			if !strings.HasPrefix(line, "AUTH EXTERNAL ") {
+				resp = "REJECTED EXTERNAL"
+				break
+			}
+			resp = "OK " + hex.EncodeToString(make([]byte, 16))
+		case "NEGOTIATE_UNIX_FD":
+			c.unixFD = true
+			resp = "AGREE_UNIX_FD"
+		case "BEGIN":
+			return c.conn.SetDeadline(time.Time{})
+		default:
+			resp = "ERROR"
+		}
+		if _, err := c.conn.Write([]byte(resp + "\r\n")); err != nil {
+			return fmt.Errorf("dbus: unable to authenticate: %w", err)
+		}
+	}
+}
+
+// authDeadline applies the deadline of ctx to the connection for the duration
+// of authentication.
+func (c *Conn) authDeadline(ctx context.Context) (stop func() bool, err error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(authTimeout)
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	return context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Now()) }), nil
+}
+
+// auth authenticates using the `EXTERNAL` mechanism, negotiating support for
+// passing file descriptors.
+//
+// ref; https://dbus.freedesktop.org/doc/dbus-specification.html#auth-protocol
+func (c *Conn) auth(ctx context.Context) error {
+	stop, err := c.authDeadline(ctx)
+	if err != nil {
+		return err
+	}
+	defer stop()
+
+	// The reader is only used during authentication, the server will not send
+	// anything after the final response until we begin sending messages.
+	r := bufio.NewReader(&byteReader{c.conn})
+	uid := hex.EncodeToString([]byte(strconv.Itoa(os.Getuid())))
+	if _, err := c.conn.Write([]byte("\x00AUTH EXTERNAL " + uid + "\r\n")); err != nil {
+		return fmt.Errorf("dbus: unable to authenticate: %w", err)
+	}
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("dbus: unable to authenticate: %w", err)
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return fmt.Errorf("dbus: authentication rejected: %s", strings.TrimSpace(line))
+	}
+
+	if _, err := c.conn.Write([]byte("NEGOTIATE_UNIX_FD\r\n")); err != nil {
+		return fmt.Errorf("dbus: unable to authenticate: %w", err)
+	}
+	line, err = r.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("dbus: unable to authenticate: %w", err)
+	}
+	c.unixFD = strings.HasPrefix(line, "AGREE_UNIX_FD")
+
+	if _, err := c.conn.Write([]byte("BEGIN\r\n")); err != nil {
+		return fmt.Errorf("dbus: unable to authenticate: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return c.conn.SetDeadline(time.Time{})
+}
+
+// byteReader reads a single byte at a time, ensuring no data past the end of
+// the authentication exchange is consumed.
+type byteReader struct {
+	conn *net.UnixConn
+}
+
+func (r *byteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.conn.Read(p)
+}
+
+// Hello registers the connection with the message bus, this must be the first
+// call made on a connection to a bus.
+func (c *Conn) Hello(ctx context.Context) error {
+	body, err := c.Call(ctx, busName, busPath, busName, "Hello", "")
+	if err != nil {
+		return err
+	}
+	if len(body) != 1 {
+		return errors.New("dbus: invalid reply to Hello")
+	}
+	name, _ := body[0].(string)
+	c.mu.Lock()
+	c.name = name
+	c.mu.Unlock()
+	return nil
+}
+
+// UniqueName returns the unique name assigned by the message bus, if any.
+func (c *Conn) UniqueName() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.name
+}
+
+// SupportsUnixFDs reports whether file descriptors may be passed over the
+// connection.
+func (c *Conn) SupportsUnixFDs() bool {
+	return c.unixFD
+}
+
+// Done returns a channel that is closed when the connection is closed.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the error that caused the connection to close, if any.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	err := c.conn.Close()
+	c.fail(ErrClosed)
+	return err
+}
+
+// fail marks the connection as failed, waking all pending calls.
+func (c *Conn) fail(err error) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.err = err
+		c.pending = nil
+		c.mu.Unlock()
+		_ = c.conn.Close()
+		close(c.done)
+	})
+}
+
+// Call calls a method and waits for its reply, returning the body of the
+// reply. If the method returns an error, it will be of type [*Error].
+func (c *Conn) Call(ctx context.Context, dest string, path ObjectPath, iface, method string, sig Signature, args ...any) ([]any, error) {
+	return c.CallWithFlags(ctx, 0, dest, path, iface, method, sig, args...)
+}
+
+// CallWithFlags is like [Conn.Call], but allows flags to be set on the
+// message.
+func (c *Conn) CallWithFlags(ctx context.Context, flags Flags, dest string, path ObjectPath, iface, method string, sig Signature, args ...any) ([]any, error) {
+	reply, err := c.Send(ctx, &Message{
+		Type:        TypeMethodCall,
+		Flags:       flags,
+		Path:        path,
+		Interface:   iface,
+		Member:      method,
+		Destination: dest,
+		Signature:   sig,
+		Body:        args,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reply.Body, nil
+}
+
+// Send sends a message. If the message is a method call that expects a reply,
+// Send waits for the reply and returns it, otherwise it returns a nil message.
+func (c *Conn) Send(ctx context.Context, m *Message) (*Message, error) {
+	m.Serial = c.serial.Add(1)
+	expectReply := m.Type == TypeMethodCall && m.Flags&FlagNoReplyExpected == 0
+
+	var ch chan *Message
+	if expectReply {
+		ch = make(chan *Message, 1)
+		c.mu.Lock()
+		if c.pending == nil {
+			err := c.err
+			c.mu.Unlock()
+			return nil, err
+		}
+		c.pending[m.Serial] = ch
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.pending, m.Serial)
+			c.mu.Unlock()
+		}()
+	}
+
+	if err := c.write(m); err != nil {
+		return nil, err
+	}
+	if !expectReply {
+		return nil, nil
+	}
+
+	select {
+	case reply := <-ch:
+		if reply.Type == TypeError {
+			return nil, &Error{Name: reply.ErrorName, Body: reply.Body}
+		}
+		return reply, nil
+	case <-c.done:
+		return nil, c.Err()
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// write encodes and writes a message to the connection.
+func (c *Conn) write(m *Message) error {
+	b, files, err := m.marshal()
+	if err != nil {
+		return err
+	}
+	var oob []byte
+	if len(files) > 0 {
+		if !c.unixFD {
+			return errors.New("dbus: connection does not support passing file descriptors")
+		}
+		fds := make([]int, len(files))
+		for i, f := range files {
+			fds[i] = int(f.Fd())
+		}
+		oob = syscall.UnixRights(fds...)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, _, err := c.conn.WriteMsgUnix(b, oob, nil); err != nil {
+		c.fail(err)
+		return fmt.Errorf("dbus: unable to write message: %w", err)
+	}
+	return nil
+}
+
+// readLoop reads and dispatches messages until the connection is closed.
+func (c *Conn) readLoop() {
+	var (
+		buf   []byte
+		files []*os.File
+		chunk = make([]byte, 64*1024)
+		oob   = make([]byte, syscall.CmsgSpace(253*4))
+	)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+
+	for {
+		for len(buf) >= headerLen {
+			n, err := messageLen(buf)
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			if len(buf) < n {
+				break
+			}
+			nfds, err := unixFDs(buf[:n])
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			if nfds > len(files) {
+				c.fail(fmt.Errorf("%w: missing file descriptors", errInvalidMessage))
+				return
+			}
+			m, err := parseMessage(buf[:n], files[:nfds:nfds])
+			if err != nil {
+				c.fail(err)
+				return
+			}
+			files = files[nfds:]
+			buf = append(buf[:0], buf[n:]...)
+			c.dispatch(m)
+		}
+
+		n, oobn, _, _, err := c.conn.ReadMsgUnix(chunk, oob)
+		if oobn > 0 {
+			files = append(files, parseRights(oob[:oobn])...)
+		}
+		if err != nil {
+			c.fail(err)
+			return
+		}
+		if n == 0 {
+			c.fail(ErrClosed)
+			return
+		}
+		buf = append(buf, chunk[:n]...)
+	}
+}
+
+// parseRights returns the files passed in `SCM_RIGHTS` control messages.
+func parseRights(oob []byte) []*os.File {
+	msgs, err := syscall.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil
+	}
+	var files []*os.File
+	for _, msg := range msgs {
+		fds, err := syscall.ParseUnixRights(&msg)
+		if err != nil {
+			continue
+		}
+		for _, fd := range fds {
+			syscall.CloseOnExec(fd)
+			files = append(files, os.NewFile(uintptr(fd), "dbus"))
+		}
+	}
+	return files
+}
+
+// dispatch routes a received message to its waiting caller or signal
+// handlers.
+func (c *Conn) dispatch(m *Message) {
+	switch m.Type {
+	case TypeMethodReturn, TypeError:
+		c.mu.Lock()
+		ch, ok := c.pending[m.ReplySerial]
+		c.mu.Unlock()
+		if ok {
+			ch <- m
+		}
+	case TypeSignal:
+		c.mu.Lock()
+		handlers := make([]func(*Message), 0, len(c.handlers))
+		for _, h := range c.handlers {
+			handlers = append(handlers, h)
+		}
+		c.mu.Unlock()
+		for _, h := range handlers {
+			h(m)
+		}
+	case TypeMethodCall:
+		c.reply(m)
+	}
+}
+
+// MethodHandler handles an incoming method call, returning the signature and
+// body of the reply. If err is an [*Error], its name is used as the name of the
+// error reply.
+type MethodHandler func(m *Message) (sig Signature, body []any, err error)
+
+// HandleMethodCalls sets the handler for incoming method calls, replacing any
+// previous handler. Without a handler, all calls except those to
+// `org.freedesktop.DBus.Peer` are rejected.
+func (c *Conn) HandleMethodCalls(h MethodHandler) {
+	c.mu.Lock()
+	c.methods = h
+	c.mu.Unlock()
+}
+
+// reply responds to an incoming method call.
+func (c *Conn) reply(m *Message) {
+	c.mu.Lock()
+	h := c.methods
+	c.mu.Unlock()
+
+	go func() {
+		var (
+			sig  Signature
+			body []any
+			err  error
+		)
+		switch {
+		case m.Interface == "org.freedesktop.DBus.Peer" && m.Member == "Ping":
+		case h != nil:
+			sig, body, err = h(m)
+		default:
+			err = &Error{
+				Name: "org.freedesktop.DBus.Error.UnknownMethod",
+				Body: []any{fmt.Sprintf("Unknown method %s.%s", m.Interface, m.Member)},
+			}
+		}
+		if m.Flags&FlagNoReplyExpected != 0 {
+			return
+		}
+
+		r := &Message{
+			Type:        TypeMethodReturn,
+			Flags:       FlagNoReplyExpected,
+			Serial:      c.serial.Add(1),
+			ReplySerial: m.Serial,
+			Destination: m.Sender,
+			Signature:   sig,
+			Body:        body,
+		}
+		if err != nil {
+			var e *Error
+			if !errors.As(err, &e) {
+				e = &Error{Name: "org.freedesktop.DBus.Error.Failed", Body: []any{err.Error()}}
+			}
+			r.Type = TypeError
+			r.ErrorName = e.Name
+			r.Signature, r.Body = "", nil
+			if msg := e.Message(); msg != "" {
+				r.Signature, r.Body = "s", []any{msg}
+			}
+		}
+		_ = c.write(r)
+	}()
+}
+
+// AddSignalHandler registers fn to be called for every signal received on the
+// connection, returning a function that removes the handler.
+//
+// Handlers are called from the goroutine reading from the connection and must
+// not block. Signals are only delivered by a message bus for match rules added
+// using [Conn.AddMatch].
+func (c *Conn) AddSignalHandler(fn func(*Message)) (remove func()) {
+	c.mu.Lock()
+	id := c.nextID
+	c.nextID++
+	c.handlers[id] = fn
+	c.mu.Unlock()
+	return func() {
+		c.mu.Lock()
+		delete(c.handlers, id)
+		c.mu.Unlock()
+	}
+}
+
+// AddMatch adds a match rule to the message bus, such as
+// `type='signal',interface='org.freedesktop.systemd1.Manager'`.
+func (c *Conn) AddMatch(ctx context.Context, rule string) error {
+	_, err := c.Call(ctx, busName, busPath, busName, "AddMatch", "s", rule)
+	return err
+}
+
+// RemoveMatch removes a match rule previously added with [Conn.AddMatch].
+func (c *Conn) RemoveMatch(ctx context.Context, rule string) error {
+	_, err := c.Call(ctx, busName, busPath, busName, "RemoveMatch", "s", rule)
+	return err
+}
+
+// GetProperty returns the value of a property.
+func (c *Conn) GetProperty(ctx context.Context, dest string, path ObjectPath, iface, name string) (Variant, error) {
+	body, err := c.Call(ctx, dest, path, propertiesInterface, "Get", "ss", iface, name)
+	if err != nil {
+		return Variant{}, err
+	}
+	if len(body) != 1 {
+		return Variant{}, errors.New("dbus: invalid reply to Get")
+	}
+	v, ok := body[0].(Variant)
+	if !ok {
+		return Variant{}, errors.New("dbus: invalid reply to Get")
+	}
+	return v, nil
+}
+
+// GetAllProperties returns the values of all properties of an interface.
+func (c *Conn) GetAllProperties(ctx context.Context, dest string, path ObjectPath, iface string) (map[string]Variant, error) {
+	body, err := c.Call(ctx, dest, path, propertiesInterface, "GetAll", "s", iface)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) != 1 {
+		return nil, errors.New("dbus: invalid reply to GetAll")
+	}
+	props, ok := VariantMap(body[0])
+	if !ok {
+		return nil, errors.New("dbus: invalid reply to GetAll")
+	}
+	return props, nil
+}
+
+// SetProperty sets the value of a property.
+func (c *Conn) SetProperty(ctx context.Context, dest string, path ObjectPath, iface, name string, value Variant) error {
+	_, err := c.Call(ctx, dest, path, propertiesInterface, "Set", "ssv", iface, name, value)
+	return err
+}
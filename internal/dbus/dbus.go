@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package dbus provides a minimal D-Bus client, implementing just enough of the
+// [D-Bus specification] to call methods, read properties, and receive signals
+// from systemd and its related services.
+//
+// Values are mapped to Go types as follows when decoding:
+//
+//	y → byte          b → bool          n → int16         q → uint16
+//	i → int32         u → uint32        x → int64         t → uint64
+//	d → float64       s → string        o → ObjectPath    g → Signature
+//	h → *os.File      v → Variant       ay → []byte       a{..} → map[any]any
+//	a.. → []any       (..) → Struct
+//
+// When encoding, the same types are accepted, in addition to any slice, map or
+// integer type that is compatible with the signature.
+//
+// [D-Bus specification]: https://dbus.freedesktop.org/doc/dbus-specification.html
+package dbus
+
+import (
+	"strings"
+)
+
+// ObjectPath is a D-Bus object path.
+type ObjectPath string
+
+// Signature is a D-Bus type signature.
+type Signature string
+
+// Struct is a D-Bus struct, a fixed-length sequence of values.
+type Struct []any
+
+// Variant is a D-Bus variant, a value along with its signature.
+type Variant struct {
+	Sig   Signature
+	Value any
+}
+
+// MakeVariant returns a [Variant] for v, inferring the signature from the
+// Go type of v. It panics if the signature cannot be inferred, use a [Variant]
+// literal for those types.
+func MakeVariant(v any) Variant {
+	sig, ok := signatureOf(v)
+	if !ok {
+		panic("dbus: unable to infer signature for variant")
+	}
+	return Variant{Sig: sig, Value: v}
+}
+
+// signatureOf infers the signature of basic Go types.
+func signatureOf(v any) (Signature, bool) {
+	switch v := v.(type) {
+	case byte:
+		return "y", true
+	case bool:
+		return "b", true
+	case int16:
+		return "n", true
+	case uint16:
+		return "q", true
+	case int32:
+		return "i", true
+	case uint32:
+		return "u", true
+	case int64:
+		return "x", true
+	case uint64:
+		return "t", true
+	case float64:
+		return "d", true
+	case string:
+		return "s", true
+	case ObjectPath:
+		return "o", true
+	case Signature:
+		return "g", true
+	case Variant:
+		return "v", true
+	case []byte:
+		return "ay", true
+	case []string:
+		return "as", true
+	case []ObjectPath:
+		return "ao", true
+	case []uint32:
+		return "au", true
+	case []int32:
+		return "ai", true
+	case []uint64:
+		return "at", true
+	case map[string]Variant:
+		return "a{sv}", true
+	case Struct:
+		var b strings.Builder
+		b.WriteByte('(')
+		for _, f := range v {
+			s, ok := signatureOf(f)
+			if !ok {
+				return "", false
+			}
+			b.WriteString(string(s))
+		}
+		b.WriteByte(')')
+		return Signature(b.String()), true
+	default:
+		return "", false
+	}
+}
+
+// Error is an error returned by a remote D-Bus method call.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.systemd1.NoSuchUnit`.
+	Name string
+
+	// Body is the body of the error message, usually a single string with a
+	// human-readable message.
+	Body []any
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if len(e.Body) > 0 {
+		if msg, ok := e.Body[0].(string); ok && msg != "" {
+			return e.Name + ": " + msg
+		}
+	}
+	return e.Name
+}
+
+// Message returns the human-readable message of the error, if any.
+func (e *Error) Message() string {
+	if len(e.Body) > 0 {
+		if msg, ok := e.Body[0].(string); ok {
+			return msg
+		}
+	}
+	return ""
+}
+
+// VariantMap converts a decoded `a{sv}` value to a map.
+func VariantMap(v any) (map[string]Variant, bool) {
+	m, ok := v.(map[any]any)
+	if !ok {
+		return nil, false
+	}
+	out := make(map[string]Variant, len(m))
+	for k, v := range m {
+		ks, ok := k.(string)
+		if !ok {
+			return nil, false
+		}
+		vv, ok := v.(Variant)
+		if !ok {
+			return nil, false
+		}
+		out[ks] = vv
+	}
+	return out, true
+}
@@ -0,0 +1,285 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package dbus
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		sig      Signature
+		in, want any
+	}{
+		{"y", byte(7), byte(7)},
+		{"b", true, true},
+		{"n", int16(-2), int16(-2)},
+		{"q", 3, uint16(3)},
+		{"i", -4, int32(-4)},
+		{"u", uint32(5), uint32(5)},
+		{"x", int64(-6), int64(-6)},
+		{"t", uint64(7), uint64(7)},
+		{"d", 1.5, 1.5},
+		{"s", "hello", "hello"},
+		{"o", ObjectPath("/org/freedesktop/systemd1"), ObjectPath("/org/freedesktop/systemd1")},
+		{"g", Signature("a{sv}"), Signature("a{sv}")},
+		{"ay", []byte("abc"), []byte("abc")},
+		{"as", []string{"a", "b"}, []any{"a", "b"}},
+		{"as", nil, []any{}},
+		{"v", "x", Variant{Sig: "s", Value: "x"}},
+		{"(sv)", Struct{"k", MakeVariant(uint32(1))}, Struct{"k", Variant{Sig: "u", Value: uint32(1)}}},
+		{"a{su}", map[string]uint32{"a": 1}, map[any]any{"a": uint32(1)}},
+		{"a(st)", [][]any{{"a", uint64(1)}, {"b", uint64(2)}}, []any{Struct{"a", uint64(1)}, Struct{"b", uint64(2)}}},
+	} {
+		for _, offset := range []int{0, 1} {
+			e := encoder{offset: offset}
+			if err := e.encodeAll(tc.sig, []any{tc.in}); err != nil {
+				t.Errorf("%q: %v", tc.sig, err)
+				continue
+			}
+			d := decoder{buf: e.buf, order: binary.LittleEndian, offset: offset}
+			got, err := d.decodeAll(tc.sig)
+			if err != nil {
+				t.Errorf("%q: %v", tc.sig, err)
+				continue
+			}
+			if !reflect.DeepEqual(got, []any{tc.want}) {
+				t.Errorf("%q: expected %#v, but got %#v", tc.sig, tc.want, got[0])
+			}
+			if d.pos != len(e.buf) {
+				t.Errorf("%q: decoded %d of %d bytes", tc.sig, d.pos, len(e.buf))
+			}
+		}
+	}
+}
+
+func TestEncodeMismatch(t *testing.T) {
+	for _, tc := range []struct {
+		sig Signature
+		v   any
+	}{
+		{"y", 256},
+		{"u", -1},
+		{"s", 1},
+		{"b", 1},
+		{"(su)", Struct{"a"}},
+		{"a{sv}", []string{"a"}},
+	} {
+		e := encoder{}
+		if err := e.encodeAll(tc.sig, []any{tc.v}); err == nil {
+			t.Errorf("%q: expected an error encoding %#v", tc.sig, tc.v)
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	m := &Message{
+		Type:        TypeMethodCall,
+		Serial:      42,
+		Path:        "/org/freedesktop/systemd1",
+		Interface:   "org.freedesktop.systemd1.Manager",
+		Member:      "StartUnit",
+		Destination: "org.freedesktop.systemd1",
+		Signature:   "ss",
+		Body:        []any{"example.service", "replace"},
+	}
+	b, files, err := m.marshal()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(files) != 0 {
+		t.Errorf("expected no files, but got %d", len(files))
+	}
+	n, err := messageLen(b[:headerLen])
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if n != len(b) {
+		t.Errorf("expected length %d, but got %d", len(b), n)
+	}
+	got, err := parseMessage(b, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !reflect.DeepEqual(m, got) {
+		t.Errorf("expected %#v, but got %#v", m, got)
+	}
+
+	if _, err := parseMessage(b[:len(b)-1], nil); err == nil {
+		t.Error("expected an error parsing a truncated message")
+	}
+}
+
+func TestParseAddress(t *testing.T) {
+	for _, tc := range []struct {
+		in, network, addr string
+	}{
+		{"unix:path=/run/dbus/system_bus_socket", "unix", "/run/dbus/system_bus_socket"},
+		{"unix:path=/tmp/a%20b,guid=abc", "unix", "/tmp/a b"},
+		{"unix:abstract=/tmp/dbus-x", "unix", "@/tmp/dbus-x"},
+	} {
+		network, addr, err := parseAddress(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if network != tc.network || addr != tc.addr {
+			t.Errorf("%q: expected \"%s\" \"%s\", but got \"%s\" \"%s\"", tc.in, tc.network, tc.addr, network, addr)
+		}
+	}
+	for _, v := range []string{"tcp:host=localhost", "unix:guid=abc", "invalid"} {
+		if _, _, err := parseAddress(v); err == nil {
+			t.Errorf("%q: expected an error", v)
+		}
+	}
+	if expected, got := "/tmp/a%20b", escapeAddress("/tmp/a b"); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+// pipe returns a connected client and server.
+func pipe(t *testing.T) (client, server *Conn) {
+	t.Helper()
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns := make([]*net.UnixConn, 2)
+	for i, fd := range fds {
+		f := os.NewFile(uintptr(fd), "")
+		c, err := net.FileConn(f)
+		_ = f.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns[i] = c.(*net.UnixConn)
+	}
+
+	ctx := context.Background()
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		server, err = Accept(ctx, conns[1])
+		errc <- err
+	}()
+	client, err = NewConn(ctx, conns[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+	return client, server
+}
+
+func TestConn(t *testing.T) {
+	client, server := pipe(t)
+	if !client.SupportsUnixFDs() {
+		t.Error("expected unix fd passing to be negotiated")
+	}
+
+	server.HandleMethodCalls(func(m *Message) (Signature, []any, error) {
+		switch m.Member {
+		case "Echo":
+			return m.Signature, m.Body, nil
+		case "Stat":
+			f := m.Body[0].(*os.File)
+			defer f.Close()
+			fi, err := f.Stat()
+			if err != nil {
+				return "", nil, err
+			}
+			return "b", []any{fi.Mode()&os.ModeCharDevice != 0}, nil
+		default:
+			return "", nil, &Error{Name: "com.example.Error", Body: []any{"nope"}}
+		}
+	})
+
+	ctx := context.Background()
+	body, err := client.Call(ctx, "", "/", "com.example", "Echo", "sa{sv}", "a", map[string]Variant{"k": MakeVariant(true)})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	want := []any{"a", map[any]any{"k": Variant{Sig: "b", Value: true}}}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("expected %#v, but got %#v", want, body)
+	}
+
+	f, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer f.Close()
+	body, err = client.Call(ctx, "", "/", "com.example", "Stat", "h", f)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if body[0] != true {
+		t.Errorf("expected the received file to be %s", os.DevNull)
+	}
+
+	_, err = client.Call(ctx, "", "/", "com.example", "Fail", "")
+	var e *Error
+	if !errors.As(err, &e) || e.Name != "com.example.Error" || e.Message() != "nope" {
+		t.Errorf("expected com.example.Error, but got %v", err)
+	}
+
+	if _, err := client.Call(ctx, "", "/", "org.freedesktop.DBus.Peer", "Ping", ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSignals(t *testing.T) {
+	client, server := pipe(t)
+
+	ch := make(chan *Message, 1)
+	remove := client.AddSignalHandler(func(m *Message) { ch <- m })
+	defer remove()
+
+	_, err := server.Send(context.Background(), &Message{
+		Type:      TypeSignal,
+		Path:      "/org/freedesktop/systemd1",
+		Interface: "org.freedesktop.systemd1.Manager",
+		Member:    "Reloading",
+		Signature: "b",
+		Body:      []any{true},
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	m := <-ch
+	if m.Member != "Reloading" || !reflect.DeepEqual(m.Body, []any{true}) {
+		t.Errorf("unexpected signal %#v", m)
+	}
+}
+
+func TestClose(t *testing.T) {
+	client, server := pipe(t)
+	_ = server.Close()
+	<-client.Done()
+	if _, err := client.Call(context.Background(), "", "/", "com.example", "Echo", ""); err == nil {
+		t.Error("expected an error calling on a closed connection")
+	}
+}
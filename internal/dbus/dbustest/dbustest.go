@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+// Package dbustest provides a fake D-Bus message bus for use in tests.
+package dbustest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// UniqueName is the unique name assigned to the client connection.
+const UniqueName = ":1.1"
+
+// Bus is a fake message bus with a single client connection. Method calls made
+// by the client are routed to handlers registered using [Bus.Handle].
+type Bus struct {
+	conn *dbus.Conn
+	// peer is set if the client is connected directly to the server rather
+	// than through a message bus.
+	peer bool
+
+	mu       sync.Mutex
+	handlers map[string]dbus.MethodHandler
+	calls    []*dbus.Message
+}
+
+// New returns a new [Bus] along with the client connected to it. Both are
+// closed when the test completes.
+func New(t testing.TB) (*Bus, *dbus.Conn) {
+	t.Helper()
+	return newBus(t, false)
+}
+
+// NewPeer is like [New], but the client is connected directly to the server
+// without a message bus, such as the private socket of the service manager.
+// The client is not registered and methods of the bus are not available.
+func NewPeer(t testing.TB) (*Bus, *dbus.Conn) {
+	t.Helper()
+	return newBus(t, true)
+}
+
+func newBus(t testing.TB, peer bool) (*Bus, *dbus.Conn) {
+	t.Helper()
+
+	fds, err := syscall.Socketpair(syscall.AF_UNIX, syscall.SOCK_STREAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, client := unixConn(t, fds[0]), unixConn(t, fds[1])
+
+	b := &Bus{peer: peer, handlers: make(map[string]dbus.MethodHandler)}
+	if !peer {
+		b.Handle("org.freedesktop.DBus", "Hello", func(*dbus.Message) (dbus.Signature, []any, error) {
+			return "s", []any{UniqueName}, nil
+		})
+		b.Handle("org.freedesktop.DBus", "AddMatch", empty)
+		b.Handle("org.freedesktop.DBus", "RemoveMatch", empty)
+	}
+
+	ctx := context.Background()
+	errc := make(chan error, 1)
+	go func() {
+		var err error
+		b.conn, err = dbus.Accept(ctx, server)
+		errc <- err
+	}()
+	c, err := dbus.NewConn(ctx, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+	}
+	b.conn.HandleMethodCalls(b.handle)
+	if !peer {
+		if err := c.Hello(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Cleanup(func() {
+		_ = c.Close()
+		_ = b.conn.Close()
+	})
+	return b, c
+}
+
+// unixConn converts a socket file descriptor to a [*net.UnixConn].
+func unixConn(t testing.TB, fd int) *net.UnixConn {
+	f := os.NewFile(uintptr(fd), "dbustest")
+	defer f.Close()
+	c, err := net.FileConn(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.(*net.UnixConn)
+}
+
+// empty is a [dbus.MethodHandler] that returns an empty reply.
+func empty(*dbus.Message) (dbus.Signature, []any, error) {
+	return "", nil, nil
+}
+
+// Handle registers a handler for calls to member on iface, replacing any
+// previous handler.
+func (b *Bus) Handle(iface, member string, h dbus.MethodHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[iface+"."+member] = h
+}
+
+// Calls returns the method calls received by the bus, excluding those made to
+// the bus itself.
+func (b *Bus) Calls() []*dbus.Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*dbus.Message(nil), b.calls...)
+}
+
+func (b *Bus) handle(m *dbus.Message) (dbus.Signature, []any, error) {
+	b.mu.Lock()
+	h, ok := b.handlers[m.Interface+"."+m.Member]
+	if m.Destination != "org.freedesktop.DBus" {
+		b.calls = append(b.calls, m)
+	}
+	b.mu.Unlock()
+	if !ok {
+		return "", nil, &dbus.Error{
+			Name: "org.freedesktop.DBus.Error.UnknownMethod",
+			Body: []any{fmt.Sprintf("Unknown method %s.%s", m.Interface, m.Member)},
+		}
+	}
+	return h(m)
+}
+
+// Emit sends a signal to the client.
+func (b *Bus) Emit(path dbus.ObjectPath, iface, member string, sig dbus.Signature, body ...any) error {
+	msg := &dbus.Message{
+		Type:      dbus.TypeSignal,
+		Path:      path,
+		Interface: iface,
+		Member:    member,
+		Signature: sig,
+		Body:      body,
+	}
+	if !b.peer {
+		msg.Destination = UniqueName
+	}
+	_, err := b.conn.Send(context.Background(), msg)
+	return err
+}
+
+// Close closes the connection to the client, simulating the bus going away.
+func (b *Bus) Close() error {
+	return b.conn.Close()
+}
@@ -0,0 +1,281 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+)
+
+// errTruncated is returned when decoding runs past the end of the data.
+var errTruncated = errors.New("dbus: message is truncated")
+
+// maxArrayLen is the maximum length of an array in bytes, as defined by the
+// specification.
+const maxArrayLen = 1 << 26
+
+// decoder decodes values in the D-Bus wire format.
+type decoder struct {
+	buf    []byte
+	pos    int
+	order  binary.ByteOrder
+	offset int
+
+	// files are the files received alongside the message, referenced by `h`
+	// values.
+	files []*os.File
+}
+
+// align skips padding up to the given alignment.
+func (d *decoder) align(n int) error {
+	for (d.offset+d.pos)%n != 0 {
+		if d.pos >= len(d.buf) {
+			return errTruncated
+		}
+		d.pos++
+	}
+	return nil
+}
+
+func (d *decoder) read(n int) ([]byte, error) {
+	if n < 0 || d.pos+n > len(d.buf) {
+		return nil, errTruncated
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint8() (uint8, error) {
+	b, err := d.read(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	if err := d.align(2); err != nil {
+		return 0, err
+	}
+	b, err := d.read(2)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if err := d.align(4); err != nil {
+		return 0, err
+	}
+	b, err := d.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint32(b), nil
+}
+
+func (d *decoder) uint64() (uint64, error) {
+	if err := d.align(8); err != nil {
+		return 0, err
+	}
+	b, err := d.read(8)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint64(b), nil
+}
+
+func (d *decoder) string() (string, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n) + 1)
+	if err != nil {
+		return "", err
+	}
+	return string(b[:n]), nil
+}
+
+func (d *decoder) signature() (Signature, error) {
+	n, err := d.uint8()
+	if err != nil {
+		return "", err
+	}
+	b, err := d.read(int(n) + 1)
+	if err != nil {
+		return "", err
+	}
+	return Signature(b[:n]), nil
+}
+
+// decodeAll decodes all the values described by sig.
+func (d *decoder) decodeAll(sig Signature) ([]any, error) {
+	types, err := splitTypes(sig)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(types))
+	for i, t := range types {
+		values[i], err = d.decode(t, 0)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// decode decodes a single value of the complete type sig.
+func (d *decoder) decode(sig Signature, depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, errInvalidSignature
+	}
+	switch sig[0] {
+	case 'y':
+		return d.uint8()
+	case 'b':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if n > 1 {
+			return nil, fmt.Errorf("dbus: invalid boolean value %d", n)
+		}
+		return n == 1, nil
+	case 'n':
+		n, err := d.uint16()
+		return int16(n), err
+	case 'q':
+		return d.uint16()
+	case 'i':
+		n, err := d.uint32()
+		return int32(n), err
+	case 'u':
+		return d.uint32()
+	case 'x':
+		n, err := d.uint64()
+		return int64(n), err
+	case 't':
+		return d.uint64()
+	case 'd':
+		n, err := d.uint64()
+		return math.Float64frombits(n), err
+	case 's':
+		return d.string()
+	case 'o':
+		s, err := d.string()
+		return ObjectPath(s), err
+	case 'g':
+		return d.signature()
+	case 'h':
+		n, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if int(n) >= len(d.files) {
+			return nil, fmt.Errorf("dbus: unix fd index %d out of range", n)
+		}
+		return d.files[n], nil
+	case 'v':
+		s, err := d.signature()
+		if err != nil {
+			return nil, err
+		}
+		if _, rest, err := nextType(s); err != nil || rest != "" {
+			return nil, fmt.Errorf("dbus: invalid variant signature %q", s)
+		}
+		v, err := d.decode(s, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		return Variant{Sig: s, Value: v}, nil
+	case '(':
+		if err := d.align(8); err != nil {
+			return nil, err
+		}
+		types, err := splitTypes(sig[1 : len(sig)-1])
+		if err != nil {
+			return nil, err
+		}
+		s := make(Struct, len(types))
+		for i, t := range types {
+			s[i], err = d.decode(t, depth+1)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	case 'a':
+		return d.decodeArray(sig, depth)
+	default:
+		return nil, errInvalidSignature
+	}
+}
+
+// decodeArray decodes an array or dict of the complete type sig.
+func (d *decoder) decodeArray(sig Signature, depth int) (any, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return nil, err
+	}
+	if n > maxArrayLen {
+		return nil, fmt.Errorf("dbus: array length %d exceeds maximum", n)
+	}
+	elem := sig[1:]
+	if err := d.align(alignment(elem[0])); err != nil {
+		return nil, err
+	}
+	end := d.pos + int(n)
+	if end > len(d.buf) {
+		return nil, errTruncated
+	}
+
+	switch elem[0] {
+	case 'y':
+		b, err := d.read(int(n))
+		if err != nil {
+			return nil, err
+		}
+		return append([]byte(nil), b...), nil
+	case '{':
+		kt, vt, err := nextType(elem[1 : len(elem)-1])
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[any]any)
+		for d.pos < end {
+			if err := d.align(8); err != nil {
+				return nil, err
+			}
+			k, err := d.decode(kt, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			v, err := d.decode(vt, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = v
+		}
+		return m, nil
+	default:
+		var s []any
+		for d.pos < end {
+			v, err := d.decode(elem, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		if s == nil {
+			s = []any{}
+		}
+		return s, nil
+	}
+}
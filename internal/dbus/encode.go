@@ -0,0 +1,293 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package dbus
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+)
+
+// encoder encodes values in the D-Bus wire format, always using little-endian
+// byte order.
+type encoder struct {
+	buf []byte
+
+	// offset is added to the length of buf when calculating alignment, used
+	// when the encoded data will be placed after other data.
+	offset int
+
+	// files are the files referenced by `h` values, sent alongside the
+	// message using `SCM_RIGHTS`.
+	files []*os.File
+}
+
+// align pads the buffer with zeros to the given alignment.
+func (e *encoder) align(n int) {
+	for (e.offset+len(e.buf))%n != 0 {
+		e.buf = append(e.buf, 0)
+	}
+}
+
+func (e *encoder) uint8(v uint8) { e.buf = append(e.buf, v) }
+
+func (e *encoder) uint16(v uint16) {
+	e.align(2)
+	e.buf = binary.LittleEndian.AppendUint16(e.buf, v)
+}
+
+func (e *encoder) uint32(v uint32) {
+	e.align(4)
+	e.buf = binary.LittleEndian.AppendUint32(e.buf, v)
+}
+
+func (e *encoder) uint64(v uint64) {
+	e.align(8)
+	e.buf = binary.LittleEndian.AppendUint64(e.buf, v)
+}
+
+func (e *encoder) string(v string) {
+	e.uint32(uint32(len(v)))
+	e.buf = append(e.buf, v...)
+	e.buf = append(e.buf, 0)
+}
+
+func (e *encoder) signature(v Signature) {
+	e.uint8(uint8(len(v)))
+	e.buf = append(e.buf, v...)
+	e.buf = append(e.buf, 0)
+}
+
+// encodeAll encodes values using the signature sig, which must contain exactly
+// one complete type per value.
+func (e *encoder) encodeAll(sig Signature, values []any) error {
+	types, err := splitTypes(sig)
+	if err != nil {
+		return err
+	}
+	if len(types) != len(values) {
+		return fmt.Errorf("dbus: signature %q requires %d values, got %d", sig, len(types), len(values))
+	}
+	for i, t := range types {
+		if err := e.encode(t, values[i], 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode encodes a single value with the complete type sig.
+func (e *encoder) encode(sig Signature, v any, depth int) error {
+	if depth > maxDepth {
+		return errInvalidSignature
+	}
+	rv := reflect.ValueOf(v)
+	mismatch := func() error {
+		return fmt.Errorf("dbus: cannot encode %T as %q", v, sig)
+	}
+
+	switch sig[0] {
+	case 'y':
+		n, ok := toUint(rv, math.MaxUint8)
+		if !ok {
+			return mismatch()
+		}
+		e.uint8(uint8(n))
+	case 'b':
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		var n uint32
+		if b {
+			n = 1
+		}
+		e.uint32(n)
+	case 'n':
+		n, ok := toInt(rv, math.MinInt16, math.MaxInt16)
+		if !ok {
+			return mismatch()
+		}
+		e.uint16(uint16(n))
+	case 'q':
+		n, ok := toUint(rv, math.MaxUint16)
+		if !ok {
+			return mismatch()
+		}
+		e.uint16(uint16(n))
+	case 'i':
+		n, ok := toInt(rv, math.MinInt32, math.MaxInt32)
+		if !ok {
+			return mismatch()
+		}
+		e.uint32(uint32(n))
+	case 'u':
+		n, ok := toUint(rv, math.MaxUint32)
+		if !ok {
+			return mismatch()
+		}
+		e.uint32(uint32(n))
+	case 'x':
+		n, ok := toInt(rv, math.MinInt64, math.MaxInt64)
+		if !ok {
+			return mismatch()
+		}
+		e.uint64(uint64(n))
+	case 't':
+		n, ok := toUint(rv, math.MaxUint64)
+		if !ok {
+			return mismatch()
+		}
+		e.uint64(n)
+	case 'd':
+		if rv.Kind() != reflect.Float64 && rv.Kind() != reflect.Float32 {
+			return mismatch()
+		}
+		e.uint64(math.Float64bits(rv.Float()))
+	case 's', 'o':
+		if rv.Kind() != reflect.String {
+			return mismatch()
+		}
+		e.string(rv.String())
+	case 'g':
+		if rv.Kind() != reflect.String {
+			return mismatch()
+		}
+		e.signature(Signature(rv.String()))
+	case 'h':
+		f, ok := v.(*os.File)
+		if !ok {
+			return mismatch()
+		}
+		e.uint32(uint32(len(e.files)))
+		e.files = append(e.files, f)
+	case 'v':
+		vv, ok := v.(Variant)
+		if !ok {
+			var err error
+			vv, err = VariantOf(v)
+			if err != nil {
+				return err
+			}
+		}
+		if _, rest, err := nextType(vv.Sig); err != nil || rest != "" {
+			return fmt.Errorf("dbus: invalid variant signature %q", vv.Sig)
+		}
+		e.signature(vv.Sig)
+		return e.encode(vv.Sig, vv.Value, depth+1)
+	case '(':
+		s, ok := v.(Struct)
+		if !ok {
+			if rv.Kind() != reflect.Slice {
+				return mismatch()
+			}
+			s = make(Struct, rv.Len())
+			for i := range s {
+				s[i] = rv.Index(i).Interface()
+			}
+		}
+		e.align(8)
+		types, err := splitTypes(sig[1 : len(sig)-1])
+		if err != nil {
+			return err
+		}
+		if len(types) != len(s) {
+			return mismatch()
+		}
+		for i, t := range types {
+			if err := e.encode(t, s[i], depth+1); err != nil {
+				return err
+			}
+		}
+	case 'a':
+		return e.encodeArray(sig, rv, depth)
+	default:
+		return errInvalidSignature
+	}
+	return nil
+}
+
+// encodeArray encodes an array or dict with the complete type sig.
+func (e *encoder) encodeArray(sig Signature, rv reflect.Value, depth int) error {
+	elem := sig[1:]
+	e.uint32(0)
+	lenAt := len(e.buf) - 4
+	e.align(alignment(elem[0]))
+	start := len(e.buf)
+
+	switch {
+	case elem[0] == '{':
+		if rv.Kind() != reflect.Map {
+			return fmt.Errorf("dbus: cannot encode %s as %q", rv.Type(), sig)
+		}
+		kt, vt, err := nextType(elem[1 : len(elem)-1])
+		if err != nil {
+			return err
+		}
+		iter := rv.MapRange()
+		for iter.Next() {
+			e.align(8)
+			if err := e.encode(kt, iter.Key().Interface(), depth+1); err != nil {
+				return err
+			}
+			if err := e.encode(vt, iter.Value().Interface(), depth+1); err != nil {
+				return err
+			}
+		}
+	case rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array:
+		for i := range rv.Len() {
+			if err := e.encode(elem, rv.Index(i).Interface(), depth+1); err != nil {
+				return err
+			}
+		}
+	case !rv.IsValid():
+		// A nil value is encoded as an empty array.
+	default:
+		return fmt.Errorf("dbus: cannot encode %s as %q", rv.Type(), sig)
+	}
+
+	binary.LittleEndian.PutUint32(e.buf[lenAt:], uint32(len(e.buf)-start))
+	return nil
+}
+
+// VariantOf returns a [Variant] for v, inferring the signature from the Go type
+// of v. Unlike [MakeVariant], an error is returned if the signature cannot be
+// inferred.
+func VariantOf(v any) (Variant, error) {
+	sig, ok := signatureOf(v)
+	if !ok {
+		return Variant{}, fmt.Errorf("dbus: unable to infer variant signature for %T", v)
+	}
+	return Variant{Sig: sig, Value: v}, nil
+}
+
+// toInt converts an integer value to an int64, ensuring it is within range.
+func toInt(rv reflect.Value, lo, hi int64) (int64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		return n, n >= lo && n <= hi
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		return int64(n), n <= uint64(hi)
+	default:
+		return 0, false
+	}
+}
+
+// toUint converts an integer value to a uint64, ensuring it is within range.
+func toUint(rv reflect.Value, hi uint64) (uint64, bool) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := rv.Int()
+		return uint64(n), n >= 0 && uint64(n) <= hi
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n := rv.Uint()
+		return n, n <= hi
+	default:
+		return 0, false
+	}
+}
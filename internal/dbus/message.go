@@ -0,0 +1,292 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package dbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// MessageType is the type of a D-Bus message.
+type MessageType uint8
+
+const (
+	TypeMethodCall   MessageType = 1
+	TypeMethodReturn MessageType = 2
+	TypeError        MessageType = 3
+	TypeSignal       MessageType = 4
+)
+
+// Flags are the flags of a D-Bus message.
+type Flags uint8
+
+const (
+	// FlagNoReplyExpected indicates the sender does not expect a reply.
+	FlagNoReplyExpected Flags = 0x1
+
+	// FlagNoAutoStart indicates the bus should not launch an owner for the
+	// destination name.
+	FlagNoAutoStart Flags = 0x2
+
+	// FlagAllowInteractiveAuthorization indicates the caller is prepared to
+	// wait for interactive authorization, such as a polkit prompt.
+	FlagAllowInteractiveAuthorization Flags = 0x4
+)
+
+// Header field codes.
+const (
+	fieldPath        = 1
+	fieldInterface   = 2
+	fieldMember      = 3
+	fieldErrorName   = 4
+	fieldReplySerial = 5
+	fieldDestination = 6
+	fieldSender      = 7
+	fieldSignature   = 8
+	fieldUnixFDs     = 9
+)
+
+const (
+	// protocolVersion is the major version of the D-Bus protocol.
+	protocolVersion = 1
+
+	// headerLen is the length of the fixed part of the message header,
+	// including the length of the header fields array.
+	headerLen = 16
+
+	// maxMessageLen is the maximum length of a message, as defined by the
+	// specification.
+	maxMessageLen = 1 << 27
+)
+
+// errInvalidMessage is returned when a message is malformed.
+var errInvalidMessage = errors.New("dbus: invalid message")
+
+// Message is a D-Bus message.
+type Message struct {
+	Type   MessageType
+	Flags  Flags
+	Serial uint32
+
+	Path        ObjectPath
+	Interface   string
+	Member      string
+	ErrorName   string
+	ReplySerial uint32
+	Destination string
+	Sender      string
+	Signature   Signature
+
+	Body []any
+
+	// Files are the files received alongside the message, referenced by `h`
+	// values in the body.
+	Files []*os.File
+}
+
+// marshal encodes the message, returning the encoded bytes and the files to
+// send alongside it.
+func (m *Message) marshal() ([]byte, []*os.File, error) {
+	body := encoder{}
+	if err := body.encodeAll(m.Signature, m.Body); err != nil {
+		return nil, nil, err
+	}
+
+	var fields []Struct
+	if m.Path != "" {
+		fields = append(fields, Struct{byte(fieldPath), Variant{"o", m.Path}})
+	}
+	if m.Interface != "" {
+		fields = append(fields, Struct{byte(fieldInterface), Variant{"s", m.Interface}})
+	}
+	if m.Member != "" {
+		fields = append(fields, Struct{byte(fieldMember), Variant{"s", m.Member}})
+	}
+	if m.ErrorName != "" {
+		fields = append(fields, Struct{byte(fieldErrorName), Variant{"s", m.ErrorName}})
+	}
+	if m.ReplySerial != 0 {
+		fields = append(fields, Struct{byte(fieldReplySerial), Variant{"u", m.ReplySerial}})
+	}
+	if m.Destination != "" {
+		fields = append(fields, Struct{byte(fieldDestination), Variant{"s", m.Destination}})
+	}
+	if m.Sender != "" {
+		fields = append(fields, Struct{byte(fieldSender), Variant{"s", m.Sender}})
+	}
+	if m.Signature != "" {
+		fields = append(fields, Struct{byte(fieldSignature), Variant{"g", m.Signature}})
+	}
+	if len(body.files) > 0 {
+		fields = append(fields, Struct{byte(fieldUnixFDs), Variant{"u", uint32(len(body.files))}})
+	}
+
+	e := encoder{}
+	e.uint8('l')
+	e.uint8(uint8(m.Type))
+	e.uint8(uint8(m.Flags))
+	e.uint8(protocolVersion)
+	e.uint32(uint32(len(body.buf)))
+	e.uint32(m.Serial)
+	if err := e.encode("a(yv)", fields, 0); err != nil {
+		return nil, nil, err
+	}
+	e.align(8)
+	e.buf = append(e.buf, body.buf...)
+	if len(e.buf) > maxMessageLen {
+		return nil, nil, errors.New("dbus: message exceeds maximum length")
+	}
+	return e.buf, body.files, nil
+}
+
+// byteOrder returns the byte order of a message from its first byte.
+func byteOrder(b byte) (binary.ByteOrder, error) {
+	switch b {
+	case 'l':
+		return binary.LittleEndian, nil
+	case 'B':
+		return binary.BigEndian, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown byte order %q", errInvalidMessage, b)
+	}
+}
+
+// messageLen returns the total length of a message from the fixed part of its
+// header.
+func messageLen(h []byte) (int, error) {
+	order, err := byteOrder(h[0])
+	if err != nil {
+		return 0, err
+	}
+	if h[3] != protocolVersion {
+		return 0, fmt.Errorf("%w: unsupported protocol version %d", errInvalidMessage, h[3])
+	}
+	bodyLen := int(order.Uint32(h[4:8]))
+	fieldsLen := int(order.Uint32(h[12:16]))
+	if bodyLen > maxMessageLen || fieldsLen > maxMessageLen {
+		return 0, fmt.Errorf("%w: message exceeds maximum length", errInvalidMessage)
+	}
+	n := headerLen + fieldsLen
+	n += (8 - n%8) % 8
+	n += bodyLen
+	if n > maxMessageLen {
+		return 0, fmt.Errorf("%w: message exceeds maximum length", errInvalidMessage)
+	}
+	return n, nil
+}
+
+// unixFDs returns the number of file descriptors that accompany a message by
+// decoding only its header.
+func unixFDs(b []byte) (int, error) {
+	order, err := byteOrder(b[0])
+	if err != nil {
+		return 0, err
+	}
+	d := decoder{buf: b[12:], order: order, offset: 12}
+	v, err := d.decode("a(yv)", 0)
+	if err != nil {
+		return 0, err
+	}
+	for _, f := range v.([]any) {
+		f := f.(Struct)
+		if f[0].(byte) == fieldUnixFDs {
+			if n, ok := f[1].(Variant).Value.(uint32); ok {
+				return int(n), nil
+			}
+		}
+	}
+	return 0, nil
+}
+
+// parseMessage decodes a complete message along with the files that were
+// received alongside it.
+func parseMessage(b []byte, files []*os.File) (*Message, error) {
+	if len(b) < headerLen {
+		return nil, errTruncated
+	}
+	order, err := byteOrder(b[0])
+	if err != nil {
+		return nil, err
+	}
+	m := &Message{
+		Type:   MessageType(b[1]),
+		Flags:  Flags(b[2]),
+		Serial: order.Uint32(b[8:12]),
+		Files:  files,
+	}
+	bodyLen := int(order.Uint32(b[4:8]))
+
+	d := decoder{buf: b[12:], order: order, offset: 12}
+	v, err := d.decode("a(yv)", 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range v.([]any) {
+		f := f.(Struct)
+		code := f[0].(byte)
+		val := f[1].(Variant).Value
+		var ok bool
+		switch code {
+		case fieldPath:
+			m.Path, ok = val.(ObjectPath)
+		case fieldInterface:
+			m.Interface, ok = val.(string)
+		case fieldMember:
+			m.Member, ok = val.(string)
+		case fieldErrorName:
+			m.ErrorName, ok = val.(string)
+		case fieldReplySerial:
+			m.ReplySerial, ok = val.(uint32)
+		case fieldDestination:
+			m.Destination, ok = val.(string)
+		case fieldSender:
+			m.Sender, ok = val.(string)
+		case fieldSignature:
+			m.Signature, ok = val.(Signature)
+		default:
+			// Unknown header fields must be ignored.
+			ok = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid type for header field %d", errInvalidMessage, code)
+		}
+	}
+	if err := d.align(8); err != nil {
+		return nil, err
+	}
+
+	body := b[12+d.pos:]
+	if len(body) != bodyLen {
+		return nil, fmt.Errorf("%w: body length mismatch", errInvalidMessage)
+	}
+	bd := decoder{buf: body, order: order, files: files}
+	if m.Body, err = bd.decodeAll(m.Signature); err != nil {
+		return nil, err
+	}
+	if bd.pos != len(body) {
+		return nil, fmt.Errorf("%w: trailing data after body", errInvalidMessage)
+	}
+
+	switch m.Type {
+	case TypeMethodCall:
+		if m.Path == "" || m.Member == "" {
+			return nil, fmt.Errorf("%w: method call is missing a path or member", errInvalidMessage)
+		}
+	case TypeMethodReturn:
+		if m.ReplySerial == 0 {
+			return nil, fmt.Errorf("%w: method return is missing a reply serial", errInvalidMessage)
+		}
+	case TypeError:
+		if m.ReplySerial == 0 || m.ErrorName == "" {
+			return nil, fmt.Errorf("%w: error is missing a reply serial or name", errInvalidMessage)
+		}
+	case TypeSignal:
+		if m.Path == "" || m.Interface == "" || m.Member == "" {
+			return nil, fmt.Errorf("%w: signal is missing a path, interface or member", errInvalidMessage)
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package dbus
+
+import (
+	"errors"
+	"fmt"
+)
+
+// errInvalidSignature is returned when a signature is malformed.
+var errInvalidSignature = errors.New("dbus: invalid signature")
+
+// maxDepth is the maximum nesting depth of containers in a signature.
+const maxDepth = 64
+
+// nextType splits the first complete type from sig, returning it and the
+// remainder of the signature.
+func nextType(sig Signature) (Signature, Signature, error) {
+	n, err := typeLen(string(sig), 0)
+	if err != nil {
+		return "", "", err
+	}
+	return sig[:n], sig[n:], nil
+}
+
+// typeLen returns the length of the first complete type in sig.
+func typeLen(sig string, depth int) (int, error) {
+	if sig == "" || depth > maxDepth {
+		return 0, errInvalidSignature
+	}
+	switch sig[0] {
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'v', 'h':
+		return 1, nil
+	case 'a':
+		n, err := typeLen(sig[1:], depth+1)
+		if err != nil {
+			return 0, err
+		}
+		return 1 + n, nil
+	case '(':
+		i := 1
+		for i < len(sig) && sig[i] != ')' {
+			n, err := typeLen(sig[i:], depth+1)
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+		if i >= len(sig) || i == 1 {
+			return 0, errInvalidSignature
+		}
+		return i + 1, nil
+	case '{':
+		// Dict entries must contain a basic key type and a single value type.
+		if len(sig) < 4 || !isBasic(sig[1]) {
+			return 0, errInvalidSignature
+		}
+		n, err := typeLen(sig[2:], depth+1)
+		if err != nil {
+			return 0, err
+		}
+		if 2+n >= len(sig) || sig[2+n] != '}' {
+			return 0, errInvalidSignature
+		}
+		return 3 + n, nil
+	default:
+		return 0, fmt.Errorf("%w: unknown type %q", errInvalidSignature, sig[0])
+	}
+}
+
+// isBasic reports whether c is a basic type code, which may be used as the key
+// of a dict entry.
+func isBasic(c byte) bool {
+	switch c {
+	case 'y', 'b', 'n', 'q', 'i', 'u', 'x', 't', 'd', 's', 'o', 'g', 'h':
+		return true
+	default:
+		return false
+	}
+}
+
+// alignment returns the alignment of a type code.
+func alignment(c byte) int {
+	switch c {
+	case 'y', 'g', 'v':
+		return 1
+	case 'n', 'q':
+		return 2
+	case 'b', 'i', 'u', 's', 'o', 'a', 'h':
+		return 4
+	case 'x', 't', 'd', '(', '{':
+		return 8
+	default:
+		return 1
+	}
+}
+
+// splitTypes splits a signature into its complete types.
+func splitTypes(sig Signature) ([]Signature, error) {
+	var types []Signature
+	for sig != "" {
+		t, rest, err := nextType(sig)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+		sig = rest
+	}
+	return types, nil
+}
@@ -0,0 +1,25 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package metrics holds the counters of the module exposed by sdmetrics, kept
+// internal so packages can update them without depending on sdmetrics.
+package metrics
+
+import "sync/atomic"
+
+var (
+	// NotifySent and NotifyFailed count the messages sent to the `sd_notify`
+	// socket and the messages that could not be sent.
+	NotifySent   atomic.Uint64
+	NotifyFailed atomic.Uint64
+
+	// WatchdogPings counts the keep-alives sent to the watchdog, and
+	// WatchdogMisses the keep-alives that were skipped or failed.
+	WatchdogPings  atomic.Uint64
+	WatchdogMisses atomic.Uint64
+
+	// ListenerAccepts and ListenerErrors count the connections accepted by
+	// listeners served by sd.Server and the errors accepting them.
+	ListenerAccepts atomic.Uint64
+	ListenerErrors  atomic.Uint64
+)
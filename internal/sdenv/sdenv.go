@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdenv provides parsing for environment variables set by systemd that
+// are shared between multiple packages.
+package sdenv
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+)
+
+// RemoteAddr parses `REMOTE_ADDR` and `REMOTE_PORT` into a [*net.TCPAddr].
+//
+// systemd sets these environment variables for services spawned by a socket
+// unit with `Accept=yes`, they contain the IP address and port of the remote
+// peer of the connection.
+//
+// If `REMOTE_ADDR` is unset, a nil value and an error of `nil` will be returned.
+func RemoteAddr() (*net.TCPAddr, error) {
+	v := os.Getenv("REMOTE_ADDR")
+	if v == "" {
+		return nil, nil
+	}
+	ip, err := netip.ParseAddr(v)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse REMOTE_ADDR: %w", err)
+	}
+
+	var port int
+	if v := os.Getenv("REMOTE_PORT"); v != "" {
+		port, err = strconv.Atoi(v)
+		if err != nil || port < 0 || port > 65535 {
+			return nil, fmt.Errorf("invalid REMOTE_PORT (%s)", v)
+		}
+	}
+
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip.Unmap(), uint16(port))), nil
+}
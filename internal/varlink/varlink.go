@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package varlink provides a minimal [Varlink] client, implementing just enough
+// of the protocol to call the `io.systemd.*` interfaces exposed by systemd.
+//
+// [Varlink]: https://varlink.org/
+package varlink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned when calling a method on a closed connection.
+var ErrClosed = errors.New("varlink: connection closed")
+
+// Error is an error reply to a method call.
+type Error struct {
+	// Name is the fully-qualified name of the error, such as
+	// `io.systemd.Unit.NoSuchUnit`.
+	Name string
+	// Parameters are the parameters of the error, if any.
+	Parameters json.RawMessage
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if len(e.Parameters) == 0 || bytes.Equal(e.Parameters, []byte("{}")) {
+		return "varlink: " + e.Name
+	}
+	return "varlink: " + e.Name + ": " + string(e.Parameters)
+}
+
+// request is a method call.
+//
+// ref; https://varlink.org/Method-Call
+type request struct {
+	Method     string `json:"method"`
+	Parameters any    `json:"parameters,omitempty"`
+	More       bool   `json:"more,omitempty"`
+}
+
+// reply is a reply to a method call.
+type reply struct {
+	Parameters json.RawMessage `json:"parameters"`
+	Continues  bool            `json:"continues"`
+	Error      string          `json:"error"`
+}
+
+// Conn is a connection to a Varlink service. Method calls are serialized, a
+// [Conn] may be used by multiple goroutines.
+type Conn struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	r      *bufio.Reader
+	closed bool
+}
+
+// Dial connects to the Varlink service listening on the unix socket at path.
+func Dial(ctx context.Context, path string) (*Conn, error) {
+	var d net.Dialer
+	nc, err := d.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("varlink: unable to connect to %s: %w", path, err)
+	}
+	return NewConn(nc), nil
+}
+
+// NewConn returns a [Conn] using an established connection, which is owned by
+// the returned [Conn].
+func NewConn(nc net.Conn) *Conn {
+	return &Conn{conn: nc, r: bufio.NewReader(nc)}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	return c.conn.Close()
+}
+
+// Call calls method with params, decoding the parameters of the reply into
+// out if it is not nil. If the service replies with an error, an [*Error] is
+// returned.
+func (c *Conn) Call(ctx context.Context, method string, params, out any) error {
+	return c.call(ctx, request{Method: method, Parameters: params}, func(p json.RawMessage) error {
+		if out == nil || len(p) == 0 {
+			return nil
+		}
+		return json.Unmarshal(p, out)
+	})
+}
+
+// CallMore calls method with params, requesting multiple replies. fn is called
+// with the parameters of each reply until the service indicates there are no
+// more replies, or fn returns an error.
+func (c *Conn) CallMore(ctx context.Context, method string, params any, fn func(json.RawMessage) error) error {
+	return c.call(ctx, request{Method: method, Parameters: params, More: true}, fn)
+}
+
+// call sends req and reads replies until the final one.
+func (c *Conn) call(ctx context.Context, req request, fn func(json.RawMessage) error) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("varlink: unable to encode call to %s: %w", req.Method, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.conn.SetDeadline(deadline)
+		defer c.conn.SetDeadline(time.Time{})
+	}
+	stop := context.AfterFunc(ctx, func() { _ = c.conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	fnErr, err := c.roundTrip(req.Method, append(b, 0), fn)
+	if err != nil {
+		// The connection is out of sync with the service if a reply was not
+		// read in full, so it cannot be used again.
+		c.closed = true
+		_ = c.conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// The deadline of the connection may pass before the context notices
+		// its own deadline.
+		if deadline, ok := ctx.Deadline(); ok && errors.Is(err, os.ErrDeadlineExceeded) && !time.Now().Before(deadline) {
+			return context.DeadlineExceeded
+		}
+		return err
+	}
+	return fnErr
+}
+
+// roundTrip writes a request and reads its replies, returning the first error
+// returned by fn or by the service separately from errors that leave the
+// connection unusable.
+func (c *Conn) roundTrip(method string, b []byte, fn func(json.RawMessage) error) (error, error) {
+	if _, err := c.conn.Write(b); err != nil {
+		return nil, fmt.Errorf("varlink: unable to call %s: %w", method, err)
+	}
+	var fnErr error
+	for {
+		line, err := c.r.ReadBytes(0)
+		if err != nil {
+			return nil, fmt.Errorf("varlink: unable to read reply to %s: %w", method, err)
+		}
+		var r reply
+		if err := json.Unmarshal(line[:len(line)-1], &r); err != nil {
+			return nil, fmt.Errorf("varlink: invalid reply to %s: %w", method, err)
+		}
+		if r.Error != "" {
+			return &Error{Name: r.Error, Parameters: r.Parameters}, nil
+		}
+		// Keep reading after fn fails, so the connection remains usable.
+		if fnErr == nil {
+			if err := fn(r.Parameters); err != nil {
+				fnErr = fmt.Errorf("varlink: invalid reply to %s: %w", method, err)
+			}
+		}
+		if !r.Continues {
+			return fnErr, nil
+		}
+	}
+}
@@ -0,0 +1,113 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package varlink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// serve answers calls on nc using fn, which returns the replies to send.
+func serve(nc net.Conn, fn func(req request) []string) {
+	r := bufio.NewReader(nc)
+	for {
+		line, err := r.ReadBytes(0)
+		if err != nil {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(line[:len(line)-1], &req); err != nil {
+			return
+		}
+		for _, reply := range fn(req) {
+			if _, err := nc.Write(append([]byte(reply), 0)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	client, server := net.Pipe()
+	go serve(server, func(req request) []string {
+		switch req.Method {
+		case "io.systemd.Test.Echo":
+			b, _ := json.Marshal(req.Parameters)
+			return []string{`{"parameters":` + string(b) + `}`}
+		case "io.systemd.Test.List":
+			if !req.More {
+				return []string{`{"error":"org.varlink.service.ExpectedMore"}`}
+			}
+			return []string{
+				`{"parameters":{"n":1},"continues":true}`,
+				`{"parameters":{"n":2},"continues":true}`,
+				`{"parameters":{"n":3}}`,
+			}
+		default:
+			return []string{`{"error":"org.varlink.service.MethodNotFound","parameters":{"method":"` + req.Method + `"}}`}
+		}
+	})
+	c := NewConn(client)
+	defer c.Close()
+	ctx := context.Background()
+
+	var out struct{ Name string }
+	if err := c.Call(ctx, "io.systemd.Test.Echo", map[string]string{"Name": "example"}, &out); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "example", out.Name; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	var sum int
+	err := c.CallMore(ctx, "io.systemd.Test.List", nil, func(p json.RawMessage) error {
+		var v struct{ N int }
+		if err := json.Unmarshal(p, &v); err != nil {
+			return err
+		}
+		sum += v.N
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := 6, sum; expected != got {
+		t.Errorf("expected %d, but got %d", expected, got)
+	}
+
+	// An error reply leaves the connection usable.
+	var e *Error
+	if err := c.Call(ctx, "io.systemd.Test.Missing", nil, nil); !errors.As(err, &e) || e.Name != "org.varlink.service.MethodNotFound" {
+		t.Errorf("expected a MethodNotFound error, but got %v", err)
+	}
+	if err := c.Call(ctx, "io.systemd.Test.List", nil, nil); !errors.As(err, &e) || e.Name != "org.varlink.service.ExpectedMore" {
+		t.Errorf("expected an ExpectedMore error, but got %v", err)
+	}
+	if err := c.Call(ctx, "io.systemd.Test.Echo", nil, nil); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+}
+
+func TestCallCanceled(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	go serve(server, func(request) []string { return nil })
+	c := NewConn(client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := c.Call(ctx, "io.systemd.Test.Hang", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, but got %v", context.DeadlineExceeded, err)
+	}
+	if err := c.Call(context.Background(), "io.systemd.Test.Hang", nil, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, but got %v", ErrClosed, err)
+	}
+}
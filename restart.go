@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/matthewpi/sd/sdlisten"
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// upgradeEnv is the environment variable with the file descriptor a process
+// started by [Upgrade] writes to once it is ready.
+const upgradeEnv = "SD_UPGRADE_FD"
+
+// upgradeSignals are the signals that make [Run] upgrade the application.
+var upgradeSignals = []os.Signal{syscall.SIGUSR2}
+
+// filer is implemented by listeners that can be passed to other processes,
+// such as [net.TCPListener] and [net.UnixListener].
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Upgrade hands over the listeners of the application to a new process of its
+// executable, such as after the binary was replaced, without closing them so
+// no connections are refused. The new process is started with the same
+// arguments, and receives the listeners the same as if they were passed by
+// systemd, so the new process must be run with [Run].
+//
+// The listeners are first stored in the file descriptor store of the service,
+// if it sets `FileDescriptorStoreMax=`, so they are kept if both processes
+// fail. Once the new process has started, systemd is notified that it is the
+// new main process of the service and its pid is returned. The caller must
+// then drain and exit, such as by returning from [Run].
+//
+// If the new process fails to start, or the context is canceled before it is
+// ready, the new process is killed and the application keeps running.
+// Upgrades need systemd to allow the main process to change, which is the case
+// unless the service uses `Type=forking` with `PIDFile=`.
+func Upgrade(ctx context.Context, listeners map[string][]sdlisten.Listener) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("sd: unable to find executable: %w", err)
+	}
+
+	var (
+		files []*os.File
+		names []string
+	)
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, name := range slices.Sorted(maps.Keys(listeners)) {
+		var stored []*os.File
+		for _, l := range listeners[name] {
+			lf, ok := l.Listener.(filer)
+			if !ok {
+				return 0, fmt.Errorf("sd: unable to hand over listener (%s): %T has no file", name, l.Listener)
+			}
+			f, err := lf.File()
+			if err != nil {
+				return 0, fmt.Errorf("sd: unable to hand over listener (%s): %w", name, err)
+			}
+			files = append(files, f)
+			stored = append(stored, f)
+			names = append(names, name)
+		}
+		if err := sdnotify.FDStore(name, stored...); err != nil {
+			return 0, fmt.Errorf("sd: unable to store listener (%s): %w", name, err)
+		}
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return 0, fmt.Errorf("sd: unable to create pipe: %w", err)
+	}
+	defer r.Close()
+
+	env := slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		k, _, _ := strings.Cut(kv, "=")
+		return k == "LISTEN_PID" || k == "LISTEN_FDS" || k == "LISTEN_FDNAMES" || k == upgradeEnv
+	})
+	env = append(env,
+		"LISTEN_FDS="+strconv.Itoa(len(files)),
+		"LISTEN_FDNAMES="+strings.Join(names, ":"),
+		// Extra files start at 3, right after the listeners.
+		upgradeEnv+"="+strconv.Itoa(3+len(files)),
+	)
+	cmd := &exec.Cmd{
+		Path:       exe,
+		Args:       os.Args,
+		Env:        env,
+		Stdin:      os.Stdin,
+		Stdout:     os.Stdout,
+		Stderr:     os.Stderr,
+		ExtraFiles: append(slices.Clone(files), w),
+	}
+	err = cmd.Start()
+	_ = w.Close()
+	if err != nil {
+		return 0, fmt.Errorf("sd: unable to start new process: %w", err)
+	}
+
+	ready := make(chan error, 1)
+	go func() {
+		_, err := r.Read(make([]byte, 1))
+		ready <- err
+	}()
+	select {
+	case err = <-ready:
+		if errors.Is(err, io.EOF) {
+			err = errors.New("exited before it was ready")
+		}
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err == nil {
+		err = sdnotify.MainPID(cmd.Process.Pid)
+	}
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return 0, fmt.Errorf("sd: unable to upgrade to new process: %w", err)
+	}
+	pid := cmd.Process.Pid
+	_ = cmd.Process.Release()
+	return pid, nil
+}
+
+// prepareUpgrade lets a process started by [Upgrade] use the listeners passed
+// to it, which it cannot know the pid of in advance.
+func prepareUpgrade() {
+	if os.Getenv(upgradeEnv) != "" && os.Getenv("LISTEN_FDS") != "" {
+		os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	}
+}
+
+// upgradeReady tells the process that started this process with [Upgrade]
+// that it is ready, reporting whether this process was started by [Upgrade].
+func upgradeReady() (bool, error) {
+	v := os.Getenv(upgradeEnv)
+	if v == "" {
+		return false, nil
+	}
+	os.Unsetenv(upgradeEnv)
+	fd, err := strconv.Atoi(v)
+	if err != nil || fd < 3 {
+		return true, fmt.Errorf("sd: invalid %s (%s)", upgradeEnv, v)
+	}
+	f := os.NewFile(uintptr(fd), "upgrade")
+	defer f.Close()
+	if _, err := f.Write([]byte{1}); err != nil {
+		return true, fmt.Errorf("sd: unable to notify previous process: %w", err)
+	}
+	return true, nil
+}
+
+// keepListeners keeps unix sockets from being removed when their listeners
+// are closed, as they are still used by the new process after an upgrade.
+func keepListeners(listeners map[string][]sdlisten.Listener) {
+	for _, ls := range listeners {
+		for _, l := range ls {
+			if ul, ok := l.Listener.(*net.UnixListener); ok {
+				ul.SetUnlinkOnClose(false)
+			}
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sd
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/matthewpi/sd/sdlisten"
+)
+
+var upgradeSignals []os.Signal
+
+func Upgrade(context.Context, map[string][]sdlisten.Listener) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func prepareUpgrade() {}
+
+func upgradeReady() (bool, error) { return false, nil }
+
+func keepListeners(map[string][]sdlisten.Listener) {}
@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sd runs an application as a systemd service, tying together the
+// other packages of this module.
+//
+// [Run] takes care of what every service otherwise copies from the examples of
+// sdlisten and sdnotify: acquiring the sockets passed with socket activation,
+// notifying systemd once the application has started, sending keep-alives to
+// the watchdog, reloading on `SIGHUP`, and stopping on `SIGTERM`.
+//
+// Like sdnotify and sdlisten, [Run] works on other operating systems, it only
+// listens on the sockets of the application itself and does not notify
+// anything.
+package sd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/internal/metrics"
+	"github.com/matthewpi/sd/sdlisten"
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// App is an application run as a systemd service by [Run].
+//
+// The unit of the service should use `Type=notify`, or `Type=notify-reload`
+// if the application supports reloading.
+type App struct {
+	// Listeners are the sockets the application listens on. Sockets passed by
+	// systemd are matched by their name, the [FileDescriptorName=] of the
+	// `.socket` unit, any sockets that were not passed are listened on by
+	// [Run] instead, such as when the application is run outside of systemd.
+	// Only stream sockets, such as `tcp` and `unix`, are supported.
+	//
+	// Sockets passed by systemd are given to [App.OnStart] even if they are
+	// not in Listeners.
+	//
+	// [FileDescriptorName=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.socket.html#FileDescriptorName=
+	Listeners []sdlisten.Socket
+
+	// OnStart starts the application with its listeners, grouped by name. It
+	// must not block once the application has started, such as by serving
+	// the listeners in new goroutines, systemd is notified that the
+	// application is ready once it returns.
+	//
+	// The context is canceled once the application starts stopping.
+	OnStart func(ctx context.Context, listeners map[string][]sdlisten.Listener) error
+
+	// OnReload reloads the configuration of the application when the process
+	// receives `SIGHUP`, or nil if the application cannot be reloaded. An
+	// error is reported to systemd, the application keeps running.
+	OnReload func(ctx context.Context) error
+
+	// OnStop drains and stops the application when the process receives
+	// `SIGTERM` or `SIGINT`, or the context passed to [Run] is canceled.
+	//
+	// The context is canceled after [App.StopTimeout], or when the process
+	// receives a second signal to stop.
+	OnStop func(ctx context.Context) error
+
+	// Health checks whether the application is healthy before each keep-alive
+	// is sent to the watchdog, if `WatchdogSec=` is configured. Keep-alives are
+	// not sent while the application is unhealthy, so systemd restarts it
+	// once the watchdog timeout is reached.
+	Health func(ctx context.Context) error
+
+	// StopTimeout limits how long [App.OnStop] may take, zero leaves it to
+	// `TimeoutStopSec=` of the service.
+	StopTimeout time.Duration
+
+	// UpgradeTimeout limits how long the new process started by [Upgrade] may
+	// take to become ready when the process receives `SIGUSR2`, defaulting to
+	// 90 seconds.
+	UpgradeTimeout time.Duration
+
+	// OnTransition is called when the application changes state, after
+	// systemd has been notified, such as to record the transitions as events
+	// of the span in the context with OpenTelemetry.
+	OnTransition func(ctx context.Context, t Transition)
+
+	// Logger logs errors that do not stop the application, such as failed
+	// reloads. [slog.Default] is used if nil.
+	Logger *slog.Logger
+}
+
+// Run runs the application until it is stopped, returning the error of
+// [App.OnStart] or [App.OnStop], if any.
+//
+// Run must only be called once, as it takes the sockets passed by systemd and
+// handles the `SIGHUP`, `SIGTERM`, and `SIGINT` signals of the process.
+//
+// On Linux, `SIGUSR2` upgrades the application to a new process of its
+// executable using [Upgrade], such as with `systemctl kill -s USR2`, then
+// stops this process once the new process is ready.
+func Run(ctx context.Context, app App) error {
+	logger := app.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGHUP, syscall.SIGTERM, os.Interrupt}, upgradeSignals...)...)
+	defer signal.Stop(signals)
+
+	listeners, err := acquireListeners(app.Listeners)
+	if err != nil {
+		_ = sdnotify.Error(err, errno(err))
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if app.OnStart != nil {
+		if err := app.OnStart(runCtx, listeners); err != nil {
+			closeListeners(listeners)
+			err = fmt.Errorf("sd: unable to start: %w", err)
+			_ = sdnotify.Error(err, errno(err))
+			return err
+		}
+	}
+	// A process started by Upgrade becomes ready once the previous process
+	// has made it the main process of the service.
+	if upgraded, err := upgradeReady(); err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "sd: unable to complete upgrade", slog.Any("err", err))
+	} else if !upgraded {
+		_ = sdnotify.Ready()
+	}
+	app.transition(runCtx, TransitionReady)
+
+	if i, err := sdnotify.WatchdogInterval(); err != nil {
+		logger.LogAttrs(ctx, slog.LevelError, "sd: unable to get watchdog interval", slog.Any("err", err))
+	} else if i > 0 {
+		go watchdog(runCtx, logger, i, app.Health)
+	}
+
+	var upgraded bool
+run:
+	for {
+		select {
+		case <-ctx.Done():
+			break run
+		case s := <-signals:
+			if slices.Contains(upgradeSignals, s) {
+				timeout := app.UpgradeTimeout
+				if timeout <= 0 {
+					timeout = 90 * time.Second
+				}
+				uctx, ucancel := context.WithTimeout(runCtx, timeout)
+				pid, err := Upgrade(uctx, listeners)
+				ucancel()
+				if err != nil {
+					logger.LogAttrs(ctx, slog.LevelError, "sd: unable to upgrade", slog.Any("err", err))
+					continue
+				}
+				logger.LogAttrs(ctx, slog.LevelInfo, "sd: upgraded to new process", slog.Int("pid", pid))
+				keepListeners(listeners)
+				upgraded = true
+				break run
+			}
+			if s != syscall.SIGHUP {
+				break run
+			}
+			if app.OnReload == nil {
+				continue
+			}
+			_ = sdnotify.Reloading()
+			app.transition(runCtx, TransitionReloading)
+			if err := app.OnReload(runCtx); err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "sd: unable to reload", slog.Any("err", err))
+				_ = sdnotify.Error(err, errno(err))
+				app.transition(runCtx, TransitionReloadFailed)
+				continue
+			}
+			_ = sdnotify.Ready()
+			app.transition(runCtx, TransitionReady)
+		}
+	}
+
+	// The new process is the main process of the service after an upgrade,
+	// the service is not stopping.
+	if !upgraded {
+		_ = sdnotify.Stopping()
+	}
+	app.transition(ctx, TransitionStopping)
+	cancel()
+
+	// Stopping is not limited by the context passed to Run, it was likely
+	// canceled to stop the application in the first place.
+	stopCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	defer stop()
+	if app.StopTimeout > 0 {
+		stopCtx, stop = context.WithTimeout(stopCtx, app.StopTimeout)
+		defer stop()
+	}
+	go func() {
+		select {
+		case <-stopCtx.Done():
+		case <-signals:
+			stop()
+		}
+	}()
+
+	if app.OnStop != nil {
+		err = app.OnStop(stopCtx)
+	}
+	closeListeners(listeners)
+	if err != nil {
+		return fmt.Errorf("sd: unable to stop: %w", err)
+	}
+	return nil
+}
+
+// acquireListeners returns the listeners passed by systemd along with new
+// listeners for the sockets that were not passed.
+func acquireListeners(sockets []sdlisten.Socket) (map[string][]sdlisten.Listener, error) {
+	prepareUpgrade()
+	listeners, err := sdlisten.ListenersByName()
+	if err != nil {
+		closeListeners(listeners)
+		return nil, fmt.Errorf("sd: unable to acquire listeners: %w", err)
+	}
+	if listeners == nil {
+		listeners = make(map[string][]sdlisten.Listener, len(sockets))
+	}
+
+	var errs error
+	for _, s := range sockets {
+		if _, ok := listeners[s.Name]; ok && s.Name != "" {
+			continue
+		}
+		l, err := net.Listen(s.Network, s.Address)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("sd: unable to listen (%s): %w", s.Name, err))
+			continue
+		}
+		listeners[s.Name] = append(listeners[s.Name], sdlisten.Listener{Listener: l, Name: s.Name})
+	}
+	if errs != nil {
+		closeListeners(listeners)
+		return nil, errs
+	}
+	return listeners, nil
+}
+
+func closeListeners(listeners map[string][]sdlisten.Listener) {
+	for _, ls := range listeners {
+		for _, l := range ls {
+			_ = l.Close()
+		}
+	}
+}
+
+// watchdog sends keep-alives to the watchdog at half of its interval, as
+// recommended by systemd, while the application is healthy.
+func watchdog(ctx context.Context, logger *slog.Logger, interval time.Duration, health func(context.Context) error) {
+	t := time.NewTicker(interval / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		if health != nil {
+			hctx, cancel := context.WithTimeout(ctx, interval/2)
+			err := health(hctx)
+			cancel()
+			if err != nil {
+				logger.LogAttrs(ctx, slog.LevelError, "sd: application is unhealthy", slog.Any("err", err))
+				metrics.WatchdogMisses.Add(1)
+				continue
+			}
+		}
+		if err := sdnotify.Watchdog(); err != nil {
+			logger.LogAttrs(ctx, slog.LevelError, "sd: unable to send keep-alive to watchdog", slog.Any("err", err))
+		}
+	}
+}
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/sdlisten"
+)
+
+func TestRun(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "20000")
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()))
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	started := make(chan string, 1)
+	reloaded := make(chan struct{}, 1)
+	healthy := make(chan struct{}, 1)
+	var (
+		stopped     bool
+		mu          sync.Mutex
+		transitions []Transition
+	)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- Run(ctx, App{
+			Listeners: []sdlisten.Socket{{Name: "http", Network: "tcp", Address: "127.0.0.1:0"}},
+			OnStart: func(_ context.Context, listeners map[string][]sdlisten.Listener) error {
+				if len(listeners["http"]) != 1 {
+					return errors.New("expected one http listener")
+				}
+				started <- listeners["http"][0].Addr().String()
+				return nil
+			},
+			OnReload: func(context.Context) error {
+				reloaded <- struct{}{}
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				stopped = true
+				return nil
+			},
+			OnTransition: func(_ context.Context, tr Transition) {
+				mu.Lock()
+				transitions = append(transitions, tr)
+				mu.Unlock()
+			},
+			Health: func(context.Context) error {
+				select {
+				case healthy <- struct{}{}:
+				default:
+				}
+				return nil
+			},
+		})
+	}()
+
+	var addr string
+	select {
+	case addr = <-started:
+	case err := <-errc:
+		t.Fatal(err)
+		return
+	}
+	select {
+	case <-healthy:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the health of the application to be checked")
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+		return
+	}
+	select {
+	case <-reloaded:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the application to be reloaded")
+	}
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !stopped {
+		t.Error("expected the application to be stopped")
+	}
+	if expected := []Transition{TransitionReady, TransitionReloading, TransitionReady, TransitionStopping}; !slices.Equal(transitions, expected) {
+		t.Errorf("expected %v, but got %v", expected, transitions)
+	}
+	// The listeners are closed once the application has stopped.
+	if c, err := net.Dial("tcp", addr); err == nil {
+		_ = c.Close()
+		t.Errorf("expected the listener on %s to be closed", addr)
+	}
+
+	errStart := errors.New("no database")
+	err := Run(t.Context(), App{OnStart: func(context.Context, map[string][]sdlisten.Listener) error { return errStart }})
+	if !errors.Is(err, errStart) {
+		t.Errorf("expected %v, but got %v", errStart, err)
+	}
+}
+
+// failingHandler is a [Handler] that fails as soon as it is served.
+type failingHandler struct{ err error }
+
+func (h failingHandler) Serve(net.Listener) error       { return h.err }
+func (h failingHandler) Shutdown(context.Context) error { return nil }
+
+func TestServer(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	sock := t.TempDir() + "/http.sock"
+	s := &Server{
+		Handlers: map[string]Handler{"http": &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("ok"))
+		})}},
+		Listeners: []sdlisten.Socket{{Name: "http", Network: "unix", Address: sock}},
+	}
+	errc := make(chan error, 1)
+	go func() { errc <- s.Serve(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", sock)
+		},
+	}}
+	var (
+		res *http.Response
+		err error
+	)
+	for range 100 {
+		if res, err = client.Get("http://app/"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("expected %d, but got %d", http.StatusOK, res.StatusCode)
+	}
+	client.CloseIdleConnections()
+
+	cancel()
+	if err := <-errc; err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	// A failing handler shuts down the other handlers.
+	errServe := syscall.EADDRINUSE
+	s = &Server{
+		Handlers: map[string]Handler{"http": &http.Server{}, "admin": failingHandler{err: errServe}},
+		Listeners: []sdlisten.Socket{
+			{Name: "http", Network: "tcp", Address: "127.0.0.1:0"},
+			{Name: "admin", Network: "tcp", Address: "127.0.0.1:0"},
+		},
+	}
+	if err := s.Serve(t.Context()); !errors.Is(err, errServe) || errno(err) != int(syscall.EADDRINUSE) {
+		t.Errorf("expected %v, but got %v", errServe, err)
+	}
+
+	s = &Server{Handlers: map[string]Handler{"http": &http.Server{}}}
+	if err := s.Serve(t.Context()); err == nil {
+		t.Error("expected an error for a handler without a listener")
+	}
+}
+
+func TestResourceAttributes(t *testing.T) {
+	t.Setenv("INVOCATION_ID", "6ad9cbf3a1a84fbe9f3f3fae1a4ba5b2")
+	attrs := ResourceAttributes()
+	if v := attrs["service.instance.id"]; v != "6ad9cbf3-a1a8-4fbe-9f3f-3fae1a4ba5b2" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "6ad9cbf3-a1a8-4fbe-9f3f-3fae1a4ba5b2", v)
+	}
+	if v := attrs["systemd.invocation_id"]; v != "6ad9cbf3a1a84fbe9f3f3fae1a4ba5b2" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "6ad9cbf3a1a84fbe9f3f3fae1a4ba5b2", v)
+	}
+
+	t.Setenv("INVOCATION_ID", "")
+	if _, ok := ResourceAttributes()["service.instance.id"]; ok {
+		t.Error("expected no invocation ID")
+	}
+}
+
+func TestUpgrade(t *testing.T) {
+	// The new process started by Upgrade runs this test again.
+	if os.Getenv(upgradeEnv) != "" {
+		listeners, err := acquireListeners(nil)
+		if err != nil || len(listeners["http"]) != 1 {
+			os.Exit(2)
+		}
+		if ok, err := upgradeReady(); !ok || err != nil {
+			os.Exit(3)
+		}
+		os.Exit(0)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer l.Close()
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+	pid, err := Upgrade(ctx, map[string][]sdlisten.Listener{"http": {{Listener: l, Name: "http"}}})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	state, err := p.Wait()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !state.Success() {
+		t.Errorf("expected the new process to succeed, but got %s", state)
+	}
+}
@@ -0,0 +1,370 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdcgroup
+
+import (
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNoUnified is returned when the unified hierarchy of cgroup v2 is not
+// mounted at `/sys/fs/cgroup`.
+var ErrNoUnified = errors.New("sdcgroup: cgroup v2 is not mounted")
+
+// ErrNotDelegated is returned when the control group of the process has not
+// been delegated to it, `Delegate=`.
+var ErrNotDelegated = errors.New("sdcgroup: control group not delegated")
+
+// Unlimited is the value of a limit that is not set, written as `max` by the
+// kernel.
+const Unlimited uint64 = math.MaxUint64
+
+// Limits are the resource limits of a control group.
+type Limits struct {
+	// Path is the path of the control group, such as
+	// `/system.slice/app.service`.
+	Path string
+	// Controllers are the controllers available in the control group, such as
+	// `cpu`, `memory`, and `pids`.
+	Controllers []string
+
+	// MemoryMin and MemoryLow are the amounts of memory in bytes protected from
+	// reclaim, `MemoryMin=` and `MemoryLow=`.
+	MemoryMin uint64
+	MemoryLow uint64
+	// MemoryHigh is the amount of memory in bytes above which the control
+	// group is throttled and reclaimed, `MemoryHigh=`.
+	MemoryHigh uint64
+	// MemoryMax is the amount of memory in bytes above which the control group
+	// is killed by the OOM killer, `MemoryMax=`.
+	MemoryMax uint64
+	// SwapMax is the amount of swap in bytes the control group may use,
+	// `MemorySwapMax=`.
+	SwapMax uint64
+
+	// CPUQuota is the CPU time the control group may use every
+	// [Limits.CPUPeriod], zero if unlimited, `CPUQuota=`.
+	CPUQuota time.Duration
+	// CPUPeriod is the period of [Limits.CPUQuota], `CPUQuotaPeriodSec=`.
+	CPUPeriod time.Duration
+	// CPUWeight is the relative share of CPU time of the control group,
+	// `CPUWeight=`.
+	CPUWeight uint64
+
+	// PidsMax is the number of tasks the control group may have, `TasksMax=`.
+	PidsMax uint64
+
+	// IO are the limits of the control group on each block device, such as
+	// `IOReadBandwidthMax=`.
+	IO []IOLimit
+}
+
+// CPUs returns the number of CPUs the control group may use as set by its CPU
+// quota, such as 1.5 for `CPUQuota=150%`, or zero if unlimited.
+func (l *Limits) CPUs() float64 {
+	if l.CPUQuota <= 0 || l.CPUPeriod <= 0 {
+		return 0
+	}
+	return float64(l.CPUQuota) / float64(l.CPUPeriod)
+}
+
+// HasController reports whether a controller is available in the control
+// group.
+func (l *Limits) HasController(name string) bool {
+	return slices.Contains(l.Controllers, name)
+}
+
+// IOLimit are the limits of a control group on a block device, each of which
+// is [Unlimited] if not set.
+type IOLimit struct {
+	// Major and Minor are the device number of the block device.
+	Major uint32
+	Minor uint32
+	// ReadBPS and WriteBPS are the bytes per second that may be read and
+	// written.
+	ReadBPS  uint64
+	WriteBPS uint64
+	// ReadIOPS and WriteIOPS are the read and write operations per second.
+	ReadIOPS  uint64
+	WriteIOPS uint64
+}
+
+// TuneOptions are the options of [AutoTune].
+type TuneOptions struct {
+	// Headroom is the fraction of the memory limit reserved for memory not
+	// managed by the Go runtime, such as memory allocated by cgo or mapped
+	// files, 0.1 if zero. The Go runtime is allowed to use the rest of the
+	// memory limit before collecting garbage more aggressively.
+	Headroom float64
+	// Interval is how often the limits are re-evaluated, one minute if zero.
+	// If negative, the limits are only evaluated once.
+	Interval time.Duration
+	// OnChange, if set, is called each time the settings of the runtime are
+	// changed.
+	OnChange func(Tuning)
+}
+
+// Tuning are the settings applied to the Go runtime by [AutoTune].
+type Tuning struct {
+	// MemoryLimit is the soft memory limit of the runtime in bytes, see
+	// [runtime/debug.SetMemoryLimit]. It is [math.MaxInt64] if there is no
+	// memory limit.
+	MemoryLimit int64
+	// MaxProcs is the number of CPUs that may execute Go code simultaneously,
+	// see [runtime.GOMAXPROCS].
+	MaxProcs int
+}
+
+// Usage is a snapshot of the resource usage of a control group.
+type Usage struct {
+	// Time is when the snapshot was taken.
+	Time time.Time
+
+	// MemoryCurrent is the memory used by the control group in bytes,
+	// including the page cache.
+	MemoryCurrent uint64
+	// MemoryPeak is the highest memory usage of the control group in bytes,
+	// zero if not supported by the kernel.
+	MemoryPeak uint64
+	// SwapCurrent is the swap used by the control group in bytes.
+	SwapCurrent uint64
+	// MemoryStat are the entries of `memory.stat`, such as `anon` and `file`.
+	MemoryStat map[string]uint64
+
+	// CPUUsage is the total CPU time used by the control group, which is the
+	// sum of [Usage.CPUUser] and [Usage.CPUSystem].
+	CPUUsage  time.Duration
+	CPUUser   time.Duration
+	CPUSystem time.Duration
+	// CPUThrottled is the total time the control group was throttled by its
+	// CPU quota, in [Usage.CPUThrottledPeriods] periods.
+	CPUThrottled        time.Duration
+	CPUThrottledPeriods uint64
+
+	// PidsCurrent is the number of tasks in the control group.
+	PidsCurrent uint64
+
+	// IO are the IO statistics of the control group for each block device.
+	IO []IOStat
+}
+
+// WorkingSet returns the memory of the control group that cannot be reclaimed
+// easily, which is [Usage.MemoryCurrent] excluding inactive page cache.
+func (u *Usage) WorkingSet() uint64 {
+	inactive := u.MemoryStat["inactive_file"]
+	if inactive > u.MemoryCurrent {
+		return 0
+	}
+	return u.MemoryCurrent - inactive
+}
+
+// Sub returns the change in resource usage since prev.
+func (u *Usage) Sub(prev *Usage) UsageDelta {
+	d := UsageDelta{
+		Interval:     u.Time.Sub(prev.Time),
+		CPUUsage:     u.CPUUsage - prev.CPUUsage,
+		CPUUser:      u.CPUUser - prev.CPUUser,
+		CPUSystem:    u.CPUSystem - prev.CPUSystem,
+		CPUThrottled: u.CPUThrottled - prev.CPUThrottled,
+	}
+	read, written, reads, writes := u.ioTotals()
+	pread, pwritten, preads, pwrites := prev.ioTotals()
+	d.ReadBytes, d.WriteBytes = read-pread, written-pwritten
+	d.ReadIOs, d.WriteIOs = reads-preads, writes-pwrites
+	return d
+}
+
+// ioTotals returns the IO statistics of all block devices.
+func (u *Usage) ioTotals() (read, written, reads, writes uint64) {
+	for _, s := range u.IO {
+		read += s.ReadBytes
+		written += s.WriteBytes
+		reads += s.ReadIOs
+		writes += s.WriteIOs
+	}
+	return read, written, reads, writes
+}
+
+// IOStat are the IO statistics of a control group for a block device.
+type IOStat struct {
+	// Major and Minor are the device number of the block device.
+	Major uint32
+	Minor uint32
+
+	ReadBytes    uint64
+	WriteBytes   uint64
+	ReadIOs      uint64
+	WriteIOs     uint64
+	DiscardBytes uint64
+	DiscardIOs   uint64
+}
+
+// UsageDelta is the change in resource usage of a control group between two
+// snapshots, see [Usage.Sub].
+type UsageDelta struct {
+	// Interval is the time between the snapshots.
+	Interval time.Duration
+
+	CPUUsage     time.Duration
+	CPUUser      time.Duration
+	CPUSystem    time.Duration
+	CPUThrottled time.Duration
+
+	// ReadBytes, WriteBytes, ReadIOs, and WriteIOs are totals of all block
+	// devices.
+	ReadBytes  uint64
+	WriteBytes uint64
+	ReadIOs    uint64
+	WriteIOs   uint64
+}
+
+// CPUs returns the average number of CPUs used during the interval, such as
+// 0.5 for 50% of a CPU as shown by `systemd-cgtop`.
+func (d UsageDelta) CPUs() float64 {
+	if d.Interval <= 0 {
+		return 0
+	}
+	return float64(d.CPUUsage) / float64(d.Interval)
+}
+
+// Sample is a snapshot of resource usage delivered by [WatchUsage], along with
+// the change since the previous snapshot.
+type Sample struct {
+	Usage *Usage
+	// Delta is the change in usage since the previous sample, which is zero
+	// for the first sample.
+	Delta UsageDelta
+}
+
+// OOMEvent is an event of processes in a control group being killed by the
+// OOM killer, delivered by [WatchOOM].
+type OOMEvent struct {
+	// Time is when the kill was detected.
+	Time time.Time
+	// Cgroup is the path of the control group the processes were killed in,
+	// which is either the watched control group or one of its children, such
+	// as `/system.slice/app.service/worker-1`.
+	Cgroup string
+	// Kills is the number of processes killed.
+	Kills uint64
+	// PIDs are the processes of the control group that exited since the
+	// previous event, which include the killed processes. The kernel does not
+	// report which processes were killed, so other processes that exited in
+	// the meantime are included as well.
+	PIDs []int
+}
+
+// String returns a description of the event, such as
+// `1 process of /app.service/worker-1 killed by the OOM killer (PID 1234)`.
+func (e OOMEvent) String() string {
+	var b strings.Builder
+	b.WriteString(strconv.FormatUint(e.Kills, 10))
+	if e.Kills == 1 {
+		b.WriteString(" process of ")
+	} else {
+		b.WriteString(" processes of ")
+	}
+	b.WriteString(e.Cgroup)
+	b.WriteString(" killed by the OOM killer")
+	if len(e.PIDs) > 0 {
+		if len(e.PIDs) == 1 {
+			b.WriteString(" (PID ")
+		} else {
+			b.WriteString(" (PIDs ")
+		}
+		for i, pid := range e.PIDs {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(strconv.Itoa(pid))
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// OOMOptions are the options of [WatchOOM].
+type OOMOptions struct {
+	// NotifyStatus, if true, reports each event to the service manager as the
+	// status of the service, as shown by `systemctl status`.
+	NotifyStatus bool
+	// Log, if set, is written a line describing each event, prefixed with the
+	// error priority understood by journald, such as [os.Stderr] for a
+	// service logging to the journal.
+	Log io.Writer
+}
+
+// ReportOptions are the options of [Report].
+type ReportOptions struct {
+	// Interval is how often the status is reported, ten seconds if zero.
+	Interval time.Duration
+	// Format, if set, formats the status reported, [Summary.String] if nil.
+	Format func(Summary) string
+}
+
+// Summary is the resource usage of a control group compared to its limits,
+// reported by [Report].
+type Summary struct {
+	Limits *Limits
+	Usage  *Usage
+	// Delta is the change in usage since the previous summary.
+	Delta UsageDelta
+	// Pressure is the `some avg10` memory pressure as a percentage, or
+	// negative if the kernel does not support pressure stall information.
+	Pressure float64
+}
+
+// String returns the summary as shown in the status of the service, such as
+// `mem 780M/1G, cpu 65% of quota, psi some=2.1`.
+func (s Summary) String() string {
+	var b strings.Builder
+	b.WriteString("mem ")
+	b.WriteString(formatBytes(s.Usage.MemoryCurrent))
+	if limit := min(s.Limits.MemoryHigh, s.Limits.MemoryMax); limit != Unlimited {
+		b.WriteByte('/')
+		b.WriteString(formatBytes(limit))
+	}
+	if s.Delta.Interval > 0 {
+		b.WriteString(", cpu ")
+		if cpus := s.Limits.CPUs(); cpus > 0 {
+			b.WriteString(strconv.FormatFloat(s.Delta.CPUs()/cpus*100, 'f', 0, 64))
+			b.WriteString("% of quota")
+		} else {
+			b.WriteString(strconv.FormatFloat(s.Delta.CPUs()*100, 'f', 0, 64))
+			b.WriteByte('%')
+		}
+	}
+	if s.Limits.PidsMax != Unlimited {
+		b.WriteString(", tasks ")
+		b.WriteString(strconv.FormatUint(s.Usage.PidsCurrent, 10))
+		b.WriteByte('/')
+		b.WriteString(strconv.FormatUint(s.Limits.PidsMax, 10))
+	}
+	if s.Pressure >= 0 {
+		b.WriteString(", psi some=")
+		b.WriteString(strconv.FormatFloat(s.Pressure, 'f', 1, 64))
+	}
+	return b.String()
+}
+
+// formatBytes formats a size in bytes with a binary suffix like systemd, such
+// as `780M` or `1.5G`.
+func formatBytes(v uint64) string {
+	const suffixes = "KMGTPE"
+	if v < 1024 {
+		return strconv.FormatUint(v, 10) + "B"
+	}
+	f, i := float64(v)/1024, 0
+	for f >= 1024 && i < len(suffixes)-1 {
+		f /= 1024
+		i++
+	}
+	s := strconv.FormatFloat(f, 'f', 1, 64)
+	return strings.TrimSuffix(s, ".0") + suffixes[i:i+1]
+}
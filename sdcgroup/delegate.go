@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Delegation is a control group delegated to the process by the service
+// manager with `Delegate=yes`, in which the process may create control groups
+// of its own, such as to limit the resources of worker processes.
+//
+// Processes may only be placed in the leaves of the tree of control groups
+// once controllers are enabled, so [Delegation.Init] must be called to move
+// the processes of the delegated control group into a leaf before creating
+// groups with [Delegation.Create].
+//
+// ref; https://systemd.io/CGROUP_DELEGATION/
+type Delegation struct {
+	path string
+}
+
+// Delegate returns the control group delegated to the current process, which
+// is the closest control group marked as delegated by the service manager
+// containing the process, or the control group of the process if it is
+// writable.
+//
+// If the control group has not been delegated, [ErrNotDelegated] is returned.
+func Delegate() (*Delegation, error) {
+	cgroup, err := Own()
+	if err != nil {
+		return nil, err
+	}
+	for p := cgroup; ; p = path.Dir(p) {
+		if delegated(p) {
+			return &Delegation{path: p}, nil
+		}
+		if p == "/" {
+			break
+		}
+	}
+	// User managers older than systemd 251 do not mark delegated control
+	// groups.
+	if syscall.Access(cgroupDir(cgroup, "cgroup.procs"), 2) == nil && syscall.Access(cgroupDir(cgroup), 2) == nil {
+		return &Delegation{path: cgroup}, nil
+	}
+	return nil, ErrNotDelegated
+}
+
+// delegated reports whether the service manager marked a control group as
+// delegated.
+func delegated(cgroup string) bool {
+	buf := make([]byte, 8)
+	for _, name := range []string{"trusted.delegate", "user.delegate"} {
+		if n, err := syscall.Getxattr(cgroupDir(cgroup), name, buf); err == nil && strings.TrimRight(string(buf[:n]), "\x00") == "1" {
+			return true
+		}
+	}
+	return false
+}
+
+// cgroupDir returns the path of a file of a control group.
+func cgroupDir(cgroup string, name ...string) string {
+	return filepath.Join(append([]string{rootDir, "sys/fs/cgroup", cgroup}, name...)...)
+}
+
+// writeFile writes a value to a file of a control group.
+func writeFile(cgroup, name, value string) error {
+	if err := os.WriteFile(cgroupDir(cgroup, name), []byte(value), 0o644); err != nil {
+		return fmt.Errorf("sdcgroup: unable to write %s of %s: %w", name, cgroup, err)
+	}
+	return nil
+}
+
+// Path returns the path of the delegated control group.
+func (d *Delegation) Path() string {
+	return d.path
+}
+
+// Init moves all processes of the delegated control group into a child named
+// leaf, such as `main`, and enables controllers for its children, such as
+// `memory` and `cpu`, so limits may be set on the groups created by
+// [Delegation.Create].
+func (d *Delegation) Init(leaf string, controllers ...string) error {
+	g, err := d.Create(leaf)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(cgroupDir(d.path, "cgroup.procs"))
+	if err != nil {
+		return fmt.Errorf("sdcgroup: unable to read processes of %s: %w", d.path, err)
+	}
+	for _, v := range strings.Fields(string(b)) {
+		pid, err := strconv.Atoi(v)
+		if err != nil {
+			continue
+		}
+		if err := g.AddProcess(pid); err != nil && !errors.Is(err, syscall.ESRCH) {
+			return err
+		}
+	}
+	if len(controllers) == 0 {
+		return nil
+	}
+	enable := make([]string, len(controllers))
+	for i, c := range controllers {
+		enable[i] = "+" + c
+	}
+	return writeFile(d.path, "cgroup.subtree_control", strings.Join(enable, " "))
+}
+
+// Create creates a control group in the delegated control group, or returns
+// the existing one.
+func (d *Delegation) Create(name string) (*Group, error) {
+	if name == "" || name == "." || name == ".." || strings.Contains(name, "/") {
+		return nil, fmt.Errorf("sdcgroup: invalid control group name %q", name)
+	}
+	g := &Group{path: path.Join(d.path, name)}
+	if err := os.Mkdir(cgroupDir(g.path), 0o755); err != nil && !errors.Is(err, os.ErrExist) {
+		return nil, fmt.Errorf("sdcgroup: unable to create %s: %w", g.path, err)
+	}
+	return g, nil
+}
+
+// Group is a control group created in a [Delegation].
+type Group struct {
+	path string
+}
+
+// Path returns the path of the control group.
+func (g *Group) Path() string {
+	return g.path
+}
+
+// AddProcess moves a process into the control group, such as a worker process
+// right after starting it.
+func (g *Group) AddProcess(pid int) error {
+	return writeFile(g.path, "cgroup.procs", strconv.Itoa(pid))
+}
+
+// formatLimit formats a limit, writing [Unlimited] as `max`.
+func formatLimit(v uint64) string {
+	if v == Unlimited {
+		return "max"
+	}
+	return strconv.FormatUint(v, 10)
+}
+
+// SetMemoryHigh sets the amount of memory in bytes above which the control
+// group is throttled and reclaimed, `memory.high`.
+func (g *Group) SetMemoryHigh(bytes uint64) error {
+	return writeFile(g.path, "memory.high", formatLimit(bytes))
+}
+
+// SetMemoryMax sets the amount of memory in bytes above which processes of
+// the control group are killed by the OOM killer, `memory.max`.
+func (g *Group) SetMemoryMax(bytes uint64) error {
+	return writeFile(g.path, "memory.max", formatLimit(bytes))
+}
+
+// SetCPUWeight sets the relative share of CPU time of the control group,
+// between 1 and 10000, `cpu.weight`.
+func (g *Group) SetCPUWeight(weight uint64) error {
+	return writeFile(g.path, "cpu.weight", strconv.FormatUint(weight, 10))
+}
+
+// SetPidsMax sets the number of tasks the control group may have,
+// `pids.max`.
+func (g *Group) SetPidsMax(n uint64) error {
+	return writeFile(g.path, "pids.max", formatLimit(n))
+}
+
+// Limits reads the limits of the control group, see [ReadLimits].
+func (g *Group) Limits() (*Limits, error) {
+	return ReadLimits(g.path)
+}
+
+// Usage reads the resource usage of the control group, see [ReadUsage].
+func (g *Group) Usage() (*Usage, error) {
+	return ReadUsage(g.path)
+}
+
+// Kill kills all processes of the control group, `cgroup.kill`.
+func (g *Group) Kill() error {
+	return writeFile(g.path, "cgroup.kill", "1")
+}
+
+// Remove removes the control group, which must not contain any processes.
+func (g *Group) Remove() error {
+	if err := os.Remove(cgroupDir(g.path)); err != nil {
+		return fmt.Errorf("sdcgroup: unable to remove %s: %w", g.path, err)
+	}
+	return nil
+}
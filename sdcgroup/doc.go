@@ -0,0 +1,16 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdcgroup provides access to the control group systemd placed the
+// process in, such as the resource limits configured by `MemoryMax=`,
+// `CPUQuota=`, and `TasksMax=`, so services can size themselves to the
+// resources they are allowed to use.
+//
+// Only the unified hierarchy of cgroup v2 is supported.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://docs.kernel.org/admin-guide/cgroup-v2.html
+package sdcgroup
@@ -0,0 +1,210 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rootDir is the directory used to resolve all the paths read by this package,
+// it is only changed by tests.
+var rootDir = "/"
+
+// readFile reads a file of a control group.
+func readFile(cgroup, name string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(rootDir, "sys/fs/cgroup", cgroup, name))
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(b)), nil
+}
+
+// Own returns the path of the control group of the current process, such as
+// `/system.slice/app.service`.
+func Own() (string, error) {
+	b, err := os.ReadFile(filepath.Join(rootDir, "proc/self/cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("sdcgroup: unable to read /proc/self/cgroup: %w", err)
+	}
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		if p, ok := bytes.CutPrefix(line, []byte("0::")); ok {
+			return string(p), nil
+		}
+	}
+	return "", ErrNoUnified
+}
+
+// ReadLimits reads the limits of a control group, such as
+// `/system.slice/app.service`. Limits of controllers that are not enabled for
+// the control group are [Unlimited].
+//
+// The limits of parent control groups also apply to the control group, see
+// [OwnLimits] for the effective limits.
+func ReadLimits(cgroup string) (*Limits, error) {
+	cgroup = path.Clean("/" + cgroup)
+	controllers, err := readFile(cgroup, "cgroup.controllers")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			if _, err := os.Stat(filepath.Join(rootDir, "sys/fs/cgroup/cgroup.controllers")); err != nil {
+				return nil, ErrNoUnified
+			}
+		}
+		return nil, fmt.Errorf("sdcgroup: unable to read limits of %s: %w", cgroup, err)
+	}
+
+	l := &Limits{Path: cgroup, Controllers: strings.Fields(controllers)}
+	for _, v := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"memory.min", &l.MemoryMin},
+		{"memory.low", &l.MemoryLow},
+		{"memory.high", &l.MemoryHigh},
+		{"memory.max", &l.MemoryMax},
+		{"memory.swap.max", &l.SwapMax},
+		{"pids.max", &l.PidsMax},
+	} {
+		if *v.value, err = readLimit(cgroup, v.name); err != nil {
+			return nil, fmt.Errorf("sdcgroup: unable to read limits of %s: %w", cgroup, err)
+		}
+	}
+	// The root control group has no protection, which is reported as zero.
+	if l.MemoryMin == Unlimited {
+		l.MemoryMin = 0
+	}
+	if l.MemoryLow == Unlimited {
+		l.MemoryLow = 0
+	}
+
+	if s, err := readFile(cgroup, "cpu.max"); err == nil {
+		quota, period, _ := strings.Cut(s, " ")
+		if usec, err := strconv.ParseUint(period, 10, 64); err == nil {
+			l.CPUPeriod = time.Duration(usec) * time.Microsecond
+		}
+		if usec, err := strconv.ParseUint(quota, 10, 64); err == nil {
+			l.CPUQuota = time.Duration(usec) * time.Microsecond
+		}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("sdcgroup: unable to read limits of %s: %w", cgroup, err)
+	}
+	if s, err := readFile(cgroup, "cpu.weight"); err == nil {
+		l.CPUWeight, _ = strconv.ParseUint(s, 10, 64)
+	}
+
+	if s, err := readFile(cgroup, "io.max"); err == nil {
+		l.IO = parseIOMax(s)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("sdcgroup: unable to read limits of %s: %w", cgroup, err)
+	}
+	return l, nil
+}
+
+// readLimit reads a file containing a single limit, which is [Unlimited] if
+// the file is missing because the controller is not enabled.
+func readLimit(cgroup, name string) (uint64, error) {
+	s, err := readFile(cgroup, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Unlimited, nil
+		}
+		return 0, err
+	}
+	return parseLimit(s)
+}
+
+// parseLimit parses a limit, which is either a number or `max`.
+func parseLimit(s string) (uint64, error) {
+	if s == "max" {
+		return Unlimited, nil
+	}
+	return strconv.ParseUint(s, 10, 64)
+}
+
+// parseIOMax parses the contents of `io.max`, which contains a line for each
+// device in the format of `8:0 rbps=max wbps=1048576 riops=max wiops=max`.
+func parseIOMax(s string) []IOLimit {
+	var limits []IOLimit
+	for line := range strings.SplitSeq(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+		maj, err1 := strconv.ParseUint(major, 10, 32)
+		min, err2 := strconv.ParseUint(minor, 10, 32)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		l := IOLimit{
+			Major:     uint32(maj),
+			Minor:     uint32(min),
+			ReadBPS:   Unlimited,
+			WriteBPS:  Unlimited,
+			ReadIOPS:  Unlimited,
+			WriteIOPS: Unlimited,
+		}
+		for _, f := range fields[1:] {
+			k, v, _ := strings.Cut(f, "=")
+			n, err := parseLimit(v)
+			if err != nil {
+				continue
+			}
+			switch k {
+			case "rbps":
+				l.ReadBPS = n
+			case "wbps":
+				l.WriteBPS = n
+			case "riops":
+				l.ReadIOPS = n
+			case "wiops":
+				l.WriteIOPS = n
+			}
+		}
+		limits = append(limits, l)
+	}
+	return limits
+}
+
+// OwnLimits returns the effective limits of the control group of the current
+// process, which are the lowest limits of the control group and all of its
+// parents, such as `MemoryMax=` set on a slice containing the service.
+func OwnLimits() (*Limits, error) {
+	cgroup, err := Own()
+	if err != nil {
+		return nil, err
+	}
+	l, err := ReadLimits(cgroup)
+	if err != nil {
+		return nil, err
+	}
+	for p := path.Dir(l.Path); p != l.Path && p != "/"; p = path.Dir(p) {
+		parent, err := ReadLimits(p)
+		if err != nil {
+			// The parents may not be visible inside of a container.
+			break
+		}
+		l.MemoryHigh = min(l.MemoryHigh, parent.MemoryHigh)
+		l.MemoryMax = min(l.MemoryMax, parent.MemoryMax)
+		l.SwapMax = min(l.SwapMax, parent.SwapMax)
+		l.PidsMax = min(l.PidsMax, parent.PidsMax)
+		if parent.CPUQuota > 0 && (l.CPUQuota == 0 || parent.CPUs() < l.CPUs()) {
+			l.CPUQuota, l.CPUPeriod = parent.CPUQuota, parent.CPUPeriod
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,187 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// cgroupState is the state of a control group used to detect OOM kills.
+type cgroupState struct {
+	kills uint64
+	pids  []int
+}
+
+// WatchOOM watches the control group of the current process and its children
+// for processes killed by the OOM killer, such as a worker process of a
+// service being killed for exceeding `MemoryMax=`. Events are delivered on the
+// returned channel until ctx is done, after which it is closed.
+//
+// The channel must be read from, events are not dropped.
+func WatchOOM(ctx context.Context, opts OOMOptions) (<-chan OOMEvent, error) {
+	cgroup, err := Own()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(rootDir, "sys/fs/cgroup", cgroup)
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("sdcgroup: unable to create inotify instance: %w", err)
+	}
+	// The descriptor is non-blocking, so reads use the runtime poller and may
+	// be interrupted by closing the file.
+	f := os.NewFile(uintptr(fd), "inotify")
+	// The counters of memory.events include all children of the control
+	// group, and the kernel notifies modifications of it for each event.
+	if _, err := syscall.InotifyAddWatch(fd, filepath.Join(dir, "memory.events"), syscall.IN_MODIFY); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("sdcgroup: unable to watch %s: %w", filepath.Join(dir, "memory.events"), err)
+	}
+	state, err := scanOOM(dir)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	ch := make(chan OOMEvent)
+	stop := context.AfterFunc(ctx, func() { _ = f.Close() })
+	go func() {
+		defer close(ch)
+		defer stop()
+		defer f.Close()
+
+		buf := make([]byte, 16*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			// The contents of the events do not matter, all control groups
+			// are scanned for changes.
+			if _, err := f.Read(buf); err != nil {
+				return
+			}
+			next, err := scanOOM(dir)
+			if err != nil {
+				// The control group has been removed.
+				return
+			}
+			events := diffOOM(cgroup, state, next)
+			state = next
+			for _, ev := range events {
+				report(ev, opts)
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// scanOOM reads the number of OOM kills and the processes of a control group
+// and all of its children, keyed by their path relative to dir.
+func scanOOM(dir string) (map[string]cgroupState, error) {
+	state := make(map[string]cgroupState)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Children may be removed while walking.
+			if p != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		var s cgroupState
+		// memory.events.local only counts the control group itself, but is
+		// not supported by older kernels.
+		b, err := os.ReadFile(filepath.Join(p, "memory.events.local"))
+		if errors.Is(err, fs.ErrNotExist) && p == dir {
+			b, err = os.ReadFile(filepath.Join(p, "memory.events"))
+		}
+		if err == nil {
+			s.kills = parseOOMKills(string(b))
+		}
+		if b, err := os.ReadFile(filepath.Join(p, "cgroup.procs")); err == nil {
+			for _, line := range strings.Fields(string(b)) {
+				if pid, err := strconv.Atoi(line); err == nil {
+					s.pids = append(s.pids, pid)
+				}
+			}
+		}
+		state[filepath.ToSlash(rel)] = s
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sdcgroup: unable to scan %s: %w", dir, err)
+	}
+	return state, nil
+}
+
+// parseOOMKills returns the `oom_kill` counter of `memory.events`.
+func parseOOMKills(s string) uint64 {
+	for line := range strings.SplitSeq(s, "\n") {
+		if v, ok := strings.CutPrefix(line, "oom_kill "); ok {
+			n, _ := strconv.ParseUint(strings.TrimSpace(v), 10, 64)
+			return n
+		}
+	}
+	return 0
+}
+
+// diffOOM returns an event for each control group whose OOM kills increased,
+// sorted by path.
+func diffOOM(cgroup string, prev, next map[string]cgroupState) []OOMEvent {
+	now := time.Now()
+	var events []OOMEvent
+	for rel, s := range next {
+		p, ok := prev[rel]
+		// Control groups created since the previous scan start without kills.
+		if s.kills <= p.kills {
+			continue
+		}
+		ev := OOMEvent{Time: now, Cgroup: path.Join(cgroup, rel), Kills: s.kills - p.kills}
+		if ok {
+			for _, pid := range p.pids {
+				if !slices.Contains(s.pids, pid) {
+					ev.PIDs = append(ev.PIDs, pid)
+				}
+			}
+		}
+		events = append(events, ev)
+	}
+	slices.SortFunc(events, func(a, b OOMEvent) int { return strings.Compare(a.Cgroup, b.Cgroup) })
+	return events
+}
+
+// report reports an event as configured by opts.
+func report(ev OOMEvent, opts OOMOptions) {
+	if opts.NotifyStatus {
+		_ = sdnotify.Status(ev.String())
+	}
+	if opts.Log != nil {
+		// `<3>` is the error priority, see sd-daemon(3).
+		_, _ = fmt.Fprintf(opts.Log, "<3>%s\n", ev)
+	}
+}
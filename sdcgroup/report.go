@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// notifyStatus reports the status of the service, it is only changed by
+// tests.
+var notifyStatus = sdnotify.Status
+
+// Report reports a summary of the resource usage of the control group of the
+// process compared to its limits as the status of the service every
+// [ReportOptions.Interval] until ctx is done, such as
+// `mem 780M/1G, cpu 65% of quota, psi some=2.1` shown by `systemctl status`.
+//
+// The first report is sent before Report returns, without CPU usage as it is
+// measured over the interval.
+func Report(ctx context.Context, opts ReportOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = 10 * time.Second
+	}
+	if opts.Format == nil {
+		opts.Format = Summary.String
+	}
+	cgroup, err := Own()
+	if err != nil {
+		return err
+	}
+	s, err := summarize(cgroup, nil)
+	if err != nil {
+		return err
+	}
+	_ = notifyStatus(opts.Format(s))
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				next, err := summarize(cgroup, s.Usage)
+				if err != nil {
+					// The status is reported again once the usage can be
+					// read.
+					continue
+				}
+				s = next
+				_ = notifyStatus(opts.Format(s))
+			}
+		}
+	}()
+	return nil
+}
+
+// summarize reads the usage, limits, and memory pressure of a control group,
+// computing the change in usage since prev if it is not nil.
+func summarize(cgroup string, prev *Usage) (Summary, error) {
+	l, err := OwnLimits()
+	if err != nil {
+		return Summary{}, err
+	}
+	u, err := ReadUsage(cgroup)
+	if err != nil {
+		return Summary{}, err
+	}
+	s := Summary{Limits: l, Usage: u, Pressure: readPressure(cgroup)}
+	if prev != nil {
+		s.Delta = u.Sub(prev)
+	}
+	return s, nil
+}
+
+// readPressure returns the `some avg10` memory pressure of a control group,
+// or -1 if it cannot be read.
+func readPressure(cgroup string) float64 {
+	s, err := readFile(cgroup, "memory.pressure")
+	if err != nil {
+		return -1
+	}
+	for line := range strings.SplitSeq(s, "\n") {
+		rest, ok := strings.CutPrefix(line, "some ")
+		if !ok {
+			continue
+		}
+		for _, f := range strings.Fields(rest) {
+			if v, ok := strings.CutPrefix(f, "avg10="); ok {
+				if avg, err := strconv.ParseFloat(v, 64); err == nil {
+					return avg
+				}
+			}
+		}
+	}
+	return -1
+}
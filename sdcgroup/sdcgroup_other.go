@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdcgroup
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+func Own() (string, error) { return "", errors.ErrUnsupported }
+
+func ReadLimits(string) (*Limits, error) { return nil, errors.ErrUnsupported }
+
+func OwnLimits() (*Limits, error) { return nil, errors.ErrUnsupported }
+
+func AutoTune(context.Context, TuneOptions) error { return errors.ErrUnsupported }
+
+func ReadUsage(string) (*Usage, error) { return nil, errors.ErrUnsupported }
+
+func OwnUsage() (*Usage, error) { return nil, errors.ErrUnsupported }
+
+func WatchUsage(context.Context, time.Duration) (<-chan Sample, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func WatchOOM(context.Context, OOMOptions) (<-chan OOMEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+type Delegation struct{}
+
+func Delegate() (*Delegation, error) { return nil, errors.ErrUnsupported }
+
+func (*Delegation) Path() string { return "" }
+
+func (*Delegation) Init(string, ...string) error { return errors.ErrUnsupported }
+
+func (*Delegation) Create(string) (*Group, error) { return nil, errors.ErrUnsupported }
+
+type Group struct{}
+
+func (*Group) Path() string { return "" }
+
+func (*Group) AddProcess(int) error { return errors.ErrUnsupported }
+
+func (*Group) SetMemoryHigh(uint64) error { return errors.ErrUnsupported }
+
+func (*Group) SetMemoryMax(uint64) error { return errors.ErrUnsupported }
+
+func (*Group) SetCPUWeight(uint64) error { return errors.ErrUnsupported }
+
+func (*Group) SetPidsMax(uint64) error { return errors.ErrUnsupported }
+
+func (*Group) Limits() (*Limits, error) { return nil, errors.ErrUnsupported }
+
+func (*Group) Usage() (*Usage, error) { return nil, errors.ErrUnsupported }
+
+func (*Group) Kill() error { return errors.ErrUnsupported }
+
+func (*Group) Remove() error { return errors.ErrUnsupported }
+
+func Report(context.Context, ReportOptions) error { return errors.ErrUnsupported }
@@ -0,0 +1,448 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useRoot makes the package read files from a temporary directory containing
+// files for the duration of the test.
+func useRoot(t *testing.T, files map[string]string) {
+	t.Helper()
+	prev := rootDir
+	t.Cleanup(func() { rootDir = prev })
+	rootDir = t.TempDir()
+	for name, data := range files {
+		p := filepath.Join(rootDir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestOwnLimits(t *testing.T) {
+	useRoot(t, map[string]string{
+		"proc/self/cgroup": "0::/app.slice/app.service\n",
+
+		"sys/fs/cgroup/cgroup.controllers": "cpuset cpu io memory pids\n",
+
+		"sys/fs/cgroup/app.slice/cgroup.controllers": "cpu io memory pids\n",
+		"sys/fs/cgroup/app.slice/memory.max":         "1073741824\n",
+		"sys/fs/cgroup/app.slice/memory.high":        "max\n",
+		"sys/fs/cgroup/app.slice/cpu.max":            "100000 100000\n",
+		"sys/fs/cgroup/app.slice/pids.max":           "max\n",
+
+		"sys/fs/cgroup/app.slice/app.service/cgroup.controllers": "cpu io memory pids\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.min":         "0\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.low":         "0\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.high":        "805306368\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.max":         "2147483648\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.swap.max":    "0\n",
+		"sys/fs/cgroup/app.slice/app.service/cpu.max":            "150000 100000\n",
+		"sys/fs/cgroup/app.slice/app.service/cpu.weight":         "100\n",
+		"sys/fs/cgroup/app.slice/app.service/pids.max":           "4915\n",
+		"sys/fs/cgroup/app.slice/app.service/io.max":             "8:0 rbps=1048576 wbps=max riops=max wiops=100\n259:0 rbps=max wbps=2097152 riops=max wiops=max\n",
+	})
+
+	l, err := ReadLimits("/app.slice/app.service")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := &Limits{
+		Path:        "/app.slice/app.service",
+		Controllers: []string{"cpu", "io", "memory", "pids"},
+		MemoryHigh:  805306368,
+		MemoryMax:   2147483648,
+		CPUQuota:    150 * time.Millisecond,
+		CPUPeriod:   100 * time.Millisecond,
+		CPUWeight:   100,
+		PidsMax:     4915,
+		IO: []IOLimit{
+			{Major: 8, Minor: 0, ReadBPS: 1048576, WriteBPS: Unlimited, ReadIOPS: Unlimited, WriteIOPS: 100},
+			{Major: 259, Minor: 0, ReadBPS: Unlimited, WriteBPS: 2097152, ReadIOPS: Unlimited, WriteIOPS: Unlimited},
+		},
+	}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, l)
+	}
+	if v := l.CPUs(); v != 1.5 {
+		t.Errorf("expected %v, but got %v", 1.5, v)
+	}
+	if !l.HasController("memory") || l.HasController("cpuset") {
+		t.Errorf("unexpected controllers %v", l.Controllers)
+	}
+
+	// The limits of the slice are lower than those of the service.
+	l, err = OwnLimits()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if l.MemoryMax != 1073741824 || l.MemoryHigh != 805306368 || l.CPUs() != 1 || l.PidsMax != 4915 {
+		t.Errorf("unexpected effective limits %+v", l)
+	}
+}
+
+func TestReadLimitsNoController(t *testing.T) {
+	useRoot(t, map[string]string{
+		"sys/fs/cgroup/cgroup.controllers":           "cpu memory pids\n",
+		"sys/fs/cgroup/app.scope/cgroup.controllers": "\n",
+	})
+	l, err := ReadLimits("app.scope")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if l.MemoryMax != Unlimited || l.PidsMax != Unlimited || l.CPUs() != 0 || len(l.Controllers) != 0 {
+		t.Errorf("expected no limits, but got %+v", l)
+	}
+
+	if _, err := ReadLimits("/missing.scope"); err == nil || errors.Is(err, ErrNoUnified) {
+		t.Errorf("expected an error reading a missing cgroup, but got \"%v\"", err)
+	}
+
+	useRoot(t, map[string]string{"sys/fs/cgroup/memory/memory.limit_in_bytes": "9223372036854771712\n"})
+	if _, err := ReadLimits("/"); !errors.Is(err, ErrNoUnified) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNoUnified, err)
+	}
+}
+
+func TestAutoTune(t *testing.T) {
+	files := map[string]string{
+		"proc/self/cgroup":                                       "0::/app.slice/app.service\n",
+		"sys/fs/cgroup/cgroup.controllers":                       "cpu memory pids\n",
+		"sys/fs/cgroup/app.slice/cgroup.controllers":             "cpu memory pids\n",
+		"sys/fs/cgroup/app.slice/app.service/cgroup.controllers": "cpu memory pids\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.high":        "max\n",
+		"sys/fs/cgroup/app.slice/app.service/memory.max":         "1000000000\n",
+		"sys/fs/cgroup/app.slice/app.service/cpu.max":            "50000 100000\n",
+	}
+	useRoot(t, files)
+	t.Setenv("GOMEMLIMIT", "")
+	t.Setenv("GOMAXPROCS", "")
+
+	var memLimit int64 = 1 << 62
+	procs := 64
+	prevMem, prevProcs := setMemoryLimit, setMaxProcs
+	t.Cleanup(func() { setMemoryLimit, setMaxProcs = prevMem, prevProcs })
+	setMemoryLimit = func(v int64) int64 {
+		prev := memLimit
+		if v >= 0 {
+			memLimit = v
+		}
+		return prev
+	}
+	setMaxProcs = func(v int) int {
+		prev := procs
+		if v > 0 {
+			procs = v
+		}
+		return prev
+	}
+
+	var changes []Tuning
+	opts := TuneOptions{Headroom: 0.2, Interval: -1, OnChange: func(v Tuning) { changes = append(changes, v) }}
+	if err := AutoTune(context.Background(), opts); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if memLimit != 800000000 || procs != 1 {
+		t.Errorf("expected a memory limit of 800000000 and 1 proc, but got %d and %d", memLimit, procs)
+	}
+
+	// Removing the limits restores the defaults, and MemoryHigh= takes
+	// precedence over a higher MemoryMax=.
+	tn := &tuner{opts: opts, memory: true, procs: true, defProcs: 64, current: Tuning{MemoryLimit: memLimit, MaxProcs: procs}}
+	if err := os.WriteFile(filepath.Join(rootDir, "sys/fs/cgroup/app.slice/app.service/cpu.max"), []byte("max 100000\n"), 0o644); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "sys/fs/cgroup/app.slice/app.service/memory.high"), []byte("500000000\n"), 0o644); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := tn.apply(); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if memLimit != 400000000 || procs != 64 {
+		t.Errorf("expected a memory limit of 400000000 and 64 procs, but got %d and %d", memLimit, procs)
+	}
+	expected := []Tuning{{MemoryLimit: 800000000, MaxProcs: 1}, {MemoryLimit: 400000000, MaxProcs: 64}}
+	if !reflect.DeepEqual(expected, changes) {
+		t.Errorf("expected %v, but got %v", expected, changes)
+	}
+
+	// Settings from the environment are left alone.
+	t.Setenv("GOMEMLIMIT", "1GiB")
+	memLimit = 1 << 30
+	if err := AutoTune(context.Background(), opts); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if memLimit != 1<<30 {
+		t.Errorf("expected the memory limit to be left alone, but got %d", memLimit)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	const dir = "sys/fs/cgroup/app.slice/app.service/"
+	useRoot(t, map[string]string{
+		"proc/self/cgroup":         "0::/app.slice/app.service\n",
+		dir + "memory.current":     "104857600\n",
+		dir + "memory.peak":        "209715200\n",
+		dir + "memory.stat":        "anon 52428800\nfile 41943040\ninactive_file 20971520\n",
+		dir + "pids.current":       "12\n",
+		dir + "cpu.stat":           "usage_usec 2000000\nuser_usec 1500000\nsystem_usec 500000\nnr_periods 10\nnr_throttled 2\nthrottled_usec 30000\n",
+		dir + "io.stat":            "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n",
+		dir + "cgroup.controllers": "cpu io memory pids\n",
+	})
+
+	u, err := OwnUsage()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if u.MemoryCurrent != 104857600 || u.MemoryPeak != 209715200 || u.PidsCurrent != 12 {
+		t.Errorf("unexpected usage %+v", u)
+	}
+	if v := u.WorkingSet(); v != 83886080 {
+		t.Errorf("expected %d, but got %d", 83886080, v)
+	}
+	if u.CPUUsage != 2*time.Second || u.CPUUser != 1500*time.Millisecond || u.CPUThrottled != 30*time.Millisecond || u.CPUThrottledPeriods != 2 {
+		t.Errorf("unexpected CPU usage %+v", u)
+	}
+	if expected := []IOStat{{Major: 8, ReadBytes: 4096, WriteBytes: 8192, ReadIOs: 1, WriteIOs: 2}}; !reflect.DeepEqual(expected, u.IO) {
+		t.Errorf("expected %+v, but got %+v", expected, u.IO)
+	}
+
+	next := *u
+	next.Time = u.Time.Add(2 * time.Second)
+	next.CPUUsage += time.Second
+	next.IO = []IOStat{{Major: 8, ReadBytes: 8192, WriteBytes: 8192, ReadIOs: 2, WriteIOs: 2}}
+	d := next.Sub(u)
+	if d.CPUs() != 0.5 || d.ReadBytes != 4096 || d.ReadIOs != 1 || d.WriteBytes != 0 {
+		t.Errorf("unexpected delta %+v", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	samples, err := WatchUsage(ctx, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	first := <-samples
+	if first.Delta != (UsageDelta{}) {
+		t.Errorf("expected no delta for the first sample, but got %+v", first.Delta)
+	}
+	// The file is replaced atomically, as it is read concurrently.
+	if err := os.WriteFile(filepath.Join(rootDir, dir+"cpu.stat.tmp"), []byte("usage_usec 3000000\n"), 0o644); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := os.Rename(filepath.Join(rootDir, dir+"cpu.stat.tmp"), filepath.Join(rootDir, dir+"cpu.stat")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	for s := range samples {
+		if s.Delta.CPUUsage == time.Second {
+			break
+		}
+		if s.Delta.CPUUsage != 0 {
+			t.Errorf("unexpected delta %+v", s.Delta)
+			break
+		}
+	}
+	cancel()
+	for range samples {
+	}
+}
+
+func TestWatchOOM(t *testing.T) {
+	const dir = "sys/fs/cgroup/app.service/"
+	useRoot(t, map[string]string{
+		"proc/self/cgroup":                 "0::/app.service\n",
+		dir + "memory.events":              "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n",
+		dir + "memory.events.local":        "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n",
+		dir + "cgroup.procs":               "",
+		dir + "main/memory.events.local":   "oom_kill 0\n",
+		dir + "main/cgroup.procs":          "10\n",
+		dir + "worker/memory.events.local": "oom 0\noom_kill 1\n",
+		dir + "worker/cgroup.procs":        "100\n101\n102\n",
+	})
+
+	var log strings.Builder
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchOOM(ctx, OOMOptions{Log: &log})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	for name, data := range map[string]string{
+		dir + "worker/cgroup.procs":        "100\n",
+		dir + "worker/memory.events.local": "oom 1\noom_kill 3\n",
+	} {
+		if err := os.WriteFile(filepath.Join(rootDir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, dir+"memory.events"), []byte("low 0\nhigh 0\nmax 1\noom 1\noom_kill 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	select {
+	case ev := <-events:
+		if ev.Cgroup != "/app.service/worker" || ev.Kills != 2 || !reflect.DeepEqual(ev.PIDs, []int{101, 102}) {
+			t.Errorf("unexpected event %+v", ev)
+		}
+		if expected := "<3>2 processes of /app.service/worker killed by the OOM killer (PIDs 101, 102)\n"; log.String() != expected {
+			t.Errorf("expected \"%s\", but got \"%s\"", expected, log.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestDelegate(t *testing.T) {
+	const dir = "sys/fs/cgroup/app.service/"
+	useRoot(t, map[string]string{
+		"proc/self/cgroup":   "0::/app.service\n",
+		dir + "cgroup.procs": "10\n",
+	})
+
+	d, err := Delegate()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if d.Path() != "/app.service" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "/app.service", d.Path())
+	}
+	if err := d.Init("main", "memory", "cpu"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	g, err := d.Create("worker-1")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if g.Path() != "/app.service/worker-1" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "/app.service/worker-1", g.Path())
+	}
+	if err := g.AddProcess(100); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := g.SetMemoryHigh(512 << 20); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := g.SetMemoryMax(Unlimited); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := g.SetCPUWeight(50); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	for name, expected := range map[string]string{
+		dir + "main/cgroup.procs":      "10",
+		dir + "cgroup.subtree_control": "+memory +cpu",
+		dir + "worker-1/cgroup.procs":  "100",
+		dir + "worker-1/memory.high":   "536870912",
+		dir + "worker-1/memory.max":    "max",
+		dir + "worker-1/cpu.weight":    "50",
+	} {
+		b, err := os.ReadFile(filepath.Join(rootDir, name))
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if string(b) != expected {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", name, expected, string(b))
+		}
+	}
+
+	if _, err := d.Create("../escape"); err == nil {
+		t.Error("expected an error for an invalid name")
+	}
+	if err := os.Remove(filepath.Join(rootDir, dir, "worker-1/cgroup.procs")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	for _, name := range []string{"memory.high", "memory.max", "cpu.weight"} {
+		_ = os.Remove(filepath.Join(rootDir, dir, "worker-1", name))
+	}
+	if err := g.Remove(); err != nil {
+		t.Fatal(err)
+		return
+	}
+}
+
+func TestReport(t *testing.T) {
+	const dir = "sys/fs/cgroup/app.service/"
+	useRoot(t, map[string]string{
+		"proc/self/cgroup":         "0::/app.service\n",
+		dir + "cgroup.controllers": "cpu memory pids\n",
+		dir + "memory.max":         "1073741824\n",
+		dir + "memory.high":        "max\n",
+		dir + "cpu.max":            "200000 100000\n",
+		dir + "pids.max":           "max\n",
+		dir + "memory.current":     "817889280\n",
+		dir + "memory.pressure":    "some avg10=2.13 avg60=1.00 avg300=0.50 total=1000\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n",
+	})
+
+	statuses := make(chan string, 1)
+	prev := notifyStatus
+	t.Cleanup(func() { notifyStatus = prev })
+	notifyStatus = func(s string) error {
+		statuses <- s
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := Report(ctx, ReportOptions{Interval: time.Hour}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if s, expected := <-statuses, "mem 780M/1G, psi some=2.1"; s != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, s)
+	}
+
+	s := Summary{
+		Limits:   &Limits{MemoryHigh: Unlimited, MemoryMax: Unlimited, CPUQuota: 200 * time.Millisecond, CPUPeriod: 100 * time.Millisecond, PidsMax: 100},
+		Usage:    &Usage{MemoryCurrent: 1610612736, PidsCurrent: 12},
+		Delta:    UsageDelta{Interval: 10 * time.Second, CPUUsage: 13 * time.Second},
+		Pressure: -1,
+	}
+	if v, expected := s.String(), "mem 1.5G, cpu 65% of quota, tasks 12/100"; v != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, v)
+	}
+}
@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"context"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+var (
+	// setMemoryLimit and setMaxProcs change the settings of the runtime, they
+	// are only changed by tests.
+	setMemoryLimit = debug.SetMemoryLimit
+	setMaxProcs    = runtime.GOMAXPROCS
+)
+
+// AutoTune sets the soft memory limit of the Go runtime (`GOMEMLIMIT`) from
+// `MemoryHigh=` and `MemoryMax=`, and the number of CPUs executing Go code
+// (`GOMAXPROCS`) from `CPUQuota=`, so the runtime collects garbage before the
+// service is throttled or killed, and does not run more threads than its CPU
+// quota allows.
+//
+// The limits are applied before AutoTune returns, and then re-evaluated every
+// [TuneOptions.Interval] in the background until ctx is done, following
+// changes such as `systemctl set-property`. Setting `GOMEMLIMIT` or
+// `GOMAXPROCS` in the environment disables tuning the respective setting.
+func AutoTune(ctx context.Context, opts TuneOptions) error {
+	if opts.Headroom == 0 {
+		opts.Headroom = 0.1
+	}
+	if opts.Interval == 0 {
+		opts.Interval = time.Minute
+	}
+	t := &tuner{
+		opts:     opts,
+		memory:   os.Getenv("GOMEMLIMIT") == "",
+		procs:    os.Getenv("GOMAXPROCS") == "",
+		defProcs: setMaxProcs(0),
+		current:  Tuning{MemoryLimit: setMemoryLimit(-1), MaxProcs: setMaxProcs(0)},
+	}
+	if err := t.apply(); err != nil {
+		return err
+	}
+	if opts.Interval < 0 {
+		return nil
+	}
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// The limits of the previous evaluation are kept if they
+				// cannot be read.
+				_ = t.apply()
+			}
+		}
+	}()
+	return nil
+}
+
+// tuner applies the limits of the control group to the runtime.
+type tuner struct {
+	opts TuneOptions
+	// memory and procs are whether the memory limit and GOMAXPROCS are tuned.
+	memory bool
+	procs  bool
+	// defProcs is the GOMAXPROCS of the runtime before tuning, which is
+	// restored if the CPU quota is removed.
+	defProcs int
+	current  Tuning
+}
+
+// apply reads the limits of the control group and applies them to the
+// runtime.
+func (t *tuner) apply() error {
+	l, err := OwnLimits()
+	if err != nil {
+		return err
+	}
+
+	next := t.current
+	if t.memory {
+		next.MemoryLimit = memoryLimit(l, t.opts.Headroom)
+	}
+	if t.procs {
+		next.MaxProcs = maxProcs(l, t.defProcs)
+	}
+	if next == t.current {
+		return nil
+	}
+	if next.MemoryLimit != t.current.MemoryLimit {
+		setMemoryLimit(next.MemoryLimit)
+	}
+	if next.MaxProcs != t.current.MaxProcs {
+		setMaxProcs(next.MaxProcs)
+	}
+	t.current = next
+	if t.opts.OnChange != nil {
+		t.opts.OnChange(next)
+	}
+	return nil
+}
+
+// memoryLimit returns the soft memory limit for the runtime, leaving headroom
+// below the lower of the high and max memory limits.
+func memoryLimit(l *Limits, headroom float64) int64 {
+	limit := min(l.MemoryHigh, l.MemoryMax)
+	if limit == Unlimited || limit > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(float64(limit) * (1 - headroom))
+}
+
+// maxProcs returns GOMAXPROCS for the CPU quota, rounded up so a quota of
+// 150% may use two CPUs, or def if there is no quota.
+func maxProcs(l *Limits, def int) int {
+	cpus := l.CPUs()
+	if cpus == 0 {
+		return def
+	}
+	return max(1, min(int(math.Ceil(cpus)), runtime.NumCPU()))
+}
@@ -0,0 +1,196 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdcgroup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReadUsage reads the resource usage of a control group, such as
+// `/system.slice/app.service`. Statistics of controllers that are not enabled
+// for the control group are zero.
+func ReadUsage(cgroup string) (*Usage, error) {
+	cgroup = path.Clean("/" + cgroup)
+	u := &Usage{Time: time.Now()}
+	for _, v := range []struct {
+		name  string
+		value *uint64
+	}{
+		{"memory.current", &u.MemoryCurrent},
+		{"memory.peak", &u.MemoryPeak},
+		{"memory.swap.current", &u.SwapCurrent},
+		{"pids.current", &u.PidsCurrent},
+	} {
+		s, err := readFile(cgroup, v.name)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("sdcgroup: unable to read usage of %s: %w", cgroup, err)
+		}
+		*v.value, _ = strconv.ParseUint(s, 10, 64)
+	}
+
+	var err error
+	if u.MemoryStat, err = readKeyed(cgroup, "memory.stat"); err != nil {
+		return nil, fmt.Errorf("sdcgroup: unable to read usage of %s: %w", cgroup, err)
+	}
+	// cpu.stat is always present, even if the cpu controller is not enabled.
+	cpu, err := readKeyed(cgroup, "cpu.stat")
+	if err != nil {
+		return nil, fmt.Errorf("sdcgroup: unable to read usage of %s: %w", cgroup, err)
+	}
+	u.CPUUsage = time.Duration(cpu["usage_usec"]) * time.Microsecond
+	u.CPUUser = time.Duration(cpu["user_usec"]) * time.Microsecond
+	u.CPUSystem = time.Duration(cpu["system_usec"]) * time.Microsecond
+	u.CPUThrottled = time.Duration(cpu["throttled_usec"]) * time.Microsecond
+	u.CPUThrottledPeriods = cpu["nr_throttled"]
+
+	if s, err := readFile(cgroup, "io.stat"); err == nil {
+		u.IO = parseIOStat(s)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("sdcgroup: unable to read usage of %s: %w", cgroup, err)
+	}
+	return u, nil
+}
+
+// OwnUsage reads the resource usage of the control group of the current
+// process, see [ReadUsage].
+func OwnUsage() (*Usage, error) {
+	cgroup, err := Own()
+	if err != nil {
+		return nil, err
+	}
+	return ReadUsage(cgroup)
+}
+
+// readKeyed reads a file containing a `key value` pair on each line, which is
+// empty if the file does not exist.
+func readKeyed(cgroup, name string) (map[string]uint64, error) {
+	s, err := readFile(cgroup, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]uint64{}, nil
+		}
+		return nil, err
+	}
+	m := make(map[string]uint64)
+	for line := range strings.SplitSeq(s, "\n") {
+		k, v, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.ParseUint(v, 10, 64); err == nil {
+			m[k] = n
+		}
+	}
+	return m, nil
+}
+
+// parseIOStat parses the contents of `io.stat`, which contains a line for each
+// device in the format of `8:0 rbytes=1 wbytes=2 rios=3 wios=4 dbytes=5 dios=6`.
+func parseIOStat(s string) []IOStat {
+	var stats []IOStat
+	for line := range strings.SplitSeq(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		major, minor, ok := strings.Cut(fields[0], ":")
+		if !ok {
+			continue
+		}
+		maj, err1 := strconv.ParseUint(major, 10, 32)
+		min, err2 := strconv.ParseUint(minor, 10, 32)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		st := IOStat{Major: uint32(maj), Minor: uint32(min)}
+		for _, f := range fields[1:] {
+			k, v, _ := strings.Cut(f, "=")
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				continue
+			}
+			switch k {
+			case "rbytes":
+				st.ReadBytes = n
+			case "wbytes":
+				st.WriteBytes = n
+			case "rios":
+				st.ReadIOs = n
+			case "wios":
+				st.WriteIOs = n
+			case "dbytes":
+				st.DiscardBytes = n
+			case "dios":
+				st.DiscardIOs = n
+			}
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// WatchUsage samples the resource usage of the control group of the current
+// process every interval, sending each sample on the returned channel. If a
+// sample has not been received by the time the next one is taken, it is
+// replaced, the delta of each sample is always relative to the previous sample
+// received.
+//
+// The channel is closed once ctx is done or reading the usage fails.
+func WatchUsage(ctx context.Context, interval time.Duration) (<-chan Sample, error) {
+	if interval <= 0 {
+		return nil, errors.New("sdcgroup: unable to watch usage: interval must be positive")
+	}
+	cgroup, err := Own()
+	if err != nil {
+		return nil, err
+	}
+	first, err := ReadUsage(cgroup)
+	if err != nil {
+		return nil, err
+	}
+
+	samples := make(chan Sample)
+	go func() {
+		defer close(samples)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var last *Usage
+		next, pending := Sample{Usage: first}, true
+		for {
+			var out chan<- Sample
+			if pending {
+				out = samples
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- next:
+				last, pending = next.Usage, false
+			case <-ticker.C:
+				u, err := ReadUsage(cgroup)
+				if err != nil {
+					return
+				}
+				next, pending = Sample{Usage: u}, true
+				if last != nil {
+					next.Delta = u.Sub(last)
+				}
+			}
+		}
+	}()
+	return samples, nil
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// activationEnv is the list of environment variables that make up the
+// activation environment, used to strip them from the environment of a child
+// process before re-applying them.
+var activationEnv = []string{
+	"LISTEN_PID",
+	"LISTEN_FDS",
+	"LISTEN_FDNAMES",
+	"NOTIFY_SOCKET",
+	"WATCHDOG_PID",
+	"WATCHDOG_USEC",
+	"CREDENTIALS_DIRECTORY",
+}
+
+// Activation is the activation environment passed to the application by
+// systemd, captured using [CaptureActivation].
+//
+// It is intended to be used by launcher or shim binaries that need to execute
+// the real application while preserving its integration with systemd.
+type Activation struct {
+	// Files are the file descriptors passed by systemd, see [sdlisten.Files].
+	// The name of each file is used for `LISTEN_FDNAMES`.
+	//
+	// [sdlisten.Files]: https://pkg.go.dev/github.com/matthewpi/sd/sdlisten#Files
+	Files []*os.File
+
+	// NotifySocket is the value of `NOTIFY_SOCKET`.
+	NotifySocket string
+
+	// Watchdog is the watchdog interval, from `WATCHDOG_USEC`. This is only set
+	// if the watchdog was configured for our process.
+	Watchdog time.Duration
+
+	// CredentialsDirectory is the value of `CREDENTIALS_DIRECTORY`.
+	CredentialsDirectory string
+}
+
+// Environ returns the activation environment as a list of `KEY=value` strings,
+// excluding `LISTEN_PID` and `WATCHDOG_PID` which must be set to the PID of the
+// process that will consume them.
+func (a *Activation) Environ() []string {
+	env := make([]string, 0, len(activationEnv))
+	if len(a.Files) > 0 {
+		names := make([]string, len(a.Files))
+		for i, f := range a.Files {
+			names[i] = f.Name()
+		}
+		env = append(env,
+			"LISTEN_FDS="+strconv.Itoa(len(a.Files)),
+			"LISTEN_FDNAMES="+strings.Join(names, ":"),
+		)
+	}
+	if a.NotifySocket != "" {
+		env = append(env, "NOTIFY_SOCKET="+a.NotifySocket)
+	}
+	if a.Watchdog > 0 {
+		env = append(env, "WATCHDOG_USEC="+strconv.FormatInt(a.Watchdog.Microseconds(), 10))
+	}
+	if a.CredentialsDirectory != "" {
+		env = append(env, "CREDENTIALS_DIRECTORY="+a.CredentialsDirectory)
+	}
+	return env
+}
+
+// mergeEnviron returns env with all the activation environment variables
+// removed and the values from [Activation.Environ] appended.
+func (a *Activation) mergeEnviron(env []string) []string {
+	merged := make([]string, 0, len(env)+len(activationEnv))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if !isActivationEnv(key) {
+			merged = append(merged, kv)
+		}
+	}
+	return append(merged, a.Environ()...)
+}
+
+// isActivationEnv reports whether key is part of the activation environment.
+func isActivationEnv(key string) bool {
+	for _, v := range activationEnv {
+		if key == v {
+			return true
+		}
+	}
+	return false
+}
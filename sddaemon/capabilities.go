@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Integrations reports which integrations with systemd are currently usable by
+// the application, returned by [Capabilities].
+type Integrations struct {
+	// Invoked is whether the application was started by systemd, see [Invoked].
+	Invoked bool
+
+	// Notify is whether `NOTIFY_SOCKET` is set and reachable.
+	Notify bool
+
+	// Watchdog is the watchdog interval, or `0` if the watchdog is not armed
+	// for the application.
+	Watchdog time.Duration
+
+	// Listeners is the number of file descriptors passed to the application
+	// using socket activation.
+	Listeners int
+
+	// Credentials is whether `CREDENTIALS_DIRECTORY` is set and exists.
+	Credentials bool
+
+	// Journal is whether the journal's native protocol socket is reachable.
+	Journal bool
+
+	// FDStore is the maximum number of file descriptors the application is
+	// allowed to store in the service manager using `FDSTORE=1`, from
+	// [FileDescriptorStoreMax=]. This requires systemd v254 or newer, older
+	// versions will always report `0`.
+	//
+	// [FileDescriptorStoreMax=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#FileDescriptorStoreMax=
+	FDStore int
+}
+
+// String returns a single line summary of the integrations, suitable for
+// logging at startup.
+func (i Integrations) String() string {
+	var b strings.Builder
+	b.WriteString("invoked=")
+	b.WriteString(strconv.FormatBool(i.Invoked))
+	b.WriteString(" notify=")
+	b.WriteString(strconv.FormatBool(i.Notify))
+	b.WriteString(" watchdog=")
+	b.WriteString(i.Watchdog.String())
+	b.WriteString(" listeners=")
+	b.WriteString(strconv.Itoa(i.Listeners))
+	b.WriteString(" credentials=")
+	b.WriteString(strconv.FormatBool(i.Credentials))
+	b.WriteString(" journal=")
+	b.WriteString(strconv.FormatBool(i.Journal))
+	b.WriteString(" fdstore=")
+	b.WriteString(strconv.Itoa(i.FDStore))
+	return b.String()
+}
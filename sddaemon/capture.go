@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+
+	"github.com/matthewpi/sd/sdlisten"
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// listenFdsStart corresponds to [SD_LISTEN_FDS_START].
+//
+// [SD_LISTEN_FDS_START]: https://github.com/systemd/systemd/blob/v257.5/src/systemd/sd-daemon.h#L56
+const listenFdsStart = 3
+
+// trampoline is a shell script used by [Activation.Apply] to set `LISTEN_PID`
+// and `WATCHDOG_PID` to the PID of the child process, which is unknown until
+// after it has been spawned.
+const trampoline = `LISTEN_PID=$$; export LISTEN_PID; ` +
+	`if [ -n "$WATCHDOG_USEC" ]; then WATCHDOG_PID=$$; export WATCHDOG_PID; fi; ` +
+	`exec "$0" "$@"`
+
+// CaptureActivation captures the activation environment passed to the
+// application by systemd. The environment variables that make up the
+// activation environment are unset, to prevent them from being used twice.
+func CaptureActivation() (*Activation, error) {
+	wd, err := sdnotify.WatchdogInterval()
+	if err != nil {
+		return nil, fmt.Errorf("sddaemon: unable to capture activation: %w", err)
+	}
+	a := &Activation{
+		Files:                sdlisten.Files(true),
+		NotifySocket:         os.Getenv("NOTIFY_SOCKET"),
+		Watchdog:             wd,
+		CredentialsDirectory: os.Getenv("CREDENTIALS_DIRECTORY"),
+	}
+	for _, key := range activationEnv {
+		_ = os.Unsetenv(key)
+	}
+	return a, nil
+}
+
+// Apply applies the activation environment to cmd, it must be called before
+// the command is started.
+//
+// The captured files are prepended to [exec.Cmd.ExtraFiles], so they start at
+// file descriptor 3 in the child process as expected by `sd_listen_fds`. Any
+// files already in [exec.Cmd.ExtraFiles] are shifted after them.
+//
+// As the PID of the child is not known until it has been spawned, the command
+// is wrapped with `/bin/sh` in order to set `LISTEN_PID` and `WATCHDOG_PID`
+// before the real command is executed.
+func (a *Activation) Apply(cmd *exec.Cmd) error {
+	if cmd.Process != nil {
+		return errors.New("sddaemon: unable to apply activation: command already started")
+	}
+
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = a.mergeEnviron(env)
+	cmd.ExtraFiles = append(a.Files[:len(a.Files):len(a.Files)], cmd.ExtraFiles...)
+
+	if len(a.Files) < 1 && a.Watchdog < 1 {
+		return nil
+	}
+
+	sh, err := exec.LookPath("/bin/sh")
+	if err != nil {
+		return fmt.Errorf("sddaemon: unable to apply activation: %w", err)
+	}
+	path := cmd.Path
+	if path == "" && len(cmd.Args) > 0 {
+		path = cmd.Args[0]
+	}
+	args := []string{"sh", "-c", trampoline, path}
+	if len(cmd.Args) > 1 {
+		args = append(args, cmd.Args[1:]...)
+	}
+	cmd.Path = sh
+	cmd.Args = args
+	return nil
+}
+
+// Exec replaces the current process with argv0 using [syscall.Exec], applying
+// the activation environment to env. Unlike [Activation.Apply], the PID does not
+// change, so `LISTEN_PID` and `WATCHDOG_PID` are set to our PID.
+//
+// Exec only returns if an error occurred.
+func (a *Activation) Exec(argv0 string, argv, env []string) error {
+	// Duplicate all the files above the range we are remapping into first, so
+	// remapping one file doesn't clobber another. The duplicates are marked as
+	// close-on-exec, so they will not be inherited by the new process.
+	n := len(a.Files)
+	fds := make([]int, n)
+	for i, f := range a.Files {
+		fd, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_DUPFD_CLOEXEC, uintptr(listenFdsStart+n))
+		if errno != 0 {
+			return fmt.Errorf("sddaemon: unable to duplicate file descriptor (%s): %w", f.Name(), errno)
+		}
+		fds[i] = int(fd)
+	}
+	for i, fd := range fds {
+		if err := syscall.Dup3(fd, listenFdsStart+i, 0); err != nil {
+			return fmt.Errorf("sddaemon: unable to remap file descriptor (%s): %w", a.Files[i].Name(), err)
+		}
+	}
+	// Ensure the original file descriptors are not leaked, unless they were
+	// replaced while remapping.
+	for _, f := range a.Files {
+		if fd := int(f.Fd()); fd >= listenFdsStart+n {
+			syscall.CloseOnExec(fd)
+		}
+	}
+
+	env = a.mergeEnviron(env)
+	if len(a.Files) > 0 {
+		env = append(env, "LISTEN_PID="+strconv.Itoa(os.Getpid()))
+	}
+	if a.Watchdog > 0 {
+		env = append(env, "WATCHDOG_PID="+strconv.Itoa(os.Getpid()))
+	}
+	return syscall.Exec(argv0, argv, env)
+}
@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sddaemon
+
+import "os/exec"
+
+func CaptureActivation() (*Activation, error)               { return &Activation{}, nil }
+func (a *Activation) Apply(*exec.Cmd) error                 { return nil }
+func (a *Activation) Exec(string, []string, []string) error { return nil }
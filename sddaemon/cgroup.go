@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// ownCgroup returns the cgroup path of the current process, relative to the
+// root of the cgroup hierarchy.
+//
+// On systems using the unified (v2) hierarchy this is the path of the `0::`
+// entry, otherwise the path of the `name=systemd` hierarchy is used.
+func ownCgroup() (string, error) {
+	b, err := readFile("proc/self/cgroup")
+	if err != nil {
+		return "", fmt.Errorf("sddaemon: unable to read /proc/self/cgroup: %w", err)
+	}
+
+	var legacy string
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		// Each line is in the format of `hierarchy-ID:controller-list:cgroup-path`.
+		_, rest, ok := bytes.Cut(line, []byte{':'})
+		if !ok {
+			continue
+		}
+		controllers, path, ok := bytes.Cut(rest, []byte{':'})
+		if !ok {
+			continue
+		}
+		switch {
+		case len(controllers) == 0:
+			return string(path), nil
+		case string(controllers) == "name=systemd":
+			legacy = string(path)
+		}
+	}
+	if legacy != "" {
+		return legacy, nil
+	}
+	return "", errors.New("sddaemon: unable to find cgroup in /proc/self/cgroup")
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import "encoding/binary"
+
+// cpuid executes the CPUID instruction with the given leaf and sub-leaf.
+//
+//go:noescape
+func cpuid(leaf, subleaf uint32) (eax, ebx, ecx, edx uint32)
+
+// hypervisorVendor returns the hypervisor vendor signature from CPUID leaf
+// `0x40000000` if the hypervisor bit is set in CPUID leaf `0x1`.
+func hypervisorVendor() (string, bool) {
+	// Bit 31 of ECX is the hypervisor present bit.
+	if _, _, ecx, _ := cpuid(0x1, 0); ecx&(1<<31) == 0 {
+		return "", false
+	}
+	_, ebx, ecx, edx := cpuid(0x40000000, 0)
+	var b [12]byte
+	binary.LittleEndian.PutUint32(b[0:], ebx)
+	binary.LittleEndian.PutUint32(b[4:], ecx)
+	binary.LittleEndian.PutUint32(b[8:], edx)
+	return string(b[:]), true
+}
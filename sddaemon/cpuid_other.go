@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux && !amd64
+
+package sddaemon
+
+// hypervisorVendor is only implemented on `amd64`, other architectures rely on
+// DMI and device-tree detection instead.
+func hypervisorVendor() (string, bool) { return "", false }
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"os"
+	"strings"
+)
+
+// ManagerScope detects the type of service manager the application is running
+// under.
+//
+// The cgroup of the process is checked first, processes spawned by a user
+// manager live under `user@$UID.service`, while processes spawned by the system
+// manager live under `system.slice` (or another slice at the root of the
+// hierarchy). If the cgroup is inconclusive, `MANAGERPID` is used instead,
+// which systemd sets to the PID of the manager that spawned the process.
+//
+// If the application is not running under a service manager, [ScopeNone] will
+// be returned.
+func ManagerScope() (Scope, error) {
+	cgroup, err := ownCgroup()
+	if err == nil {
+		if s := cgroupScope(cgroup); s != ScopeNone {
+			return s, nil
+		}
+	}
+
+	switch v := os.Getenv("MANAGERPID"); v {
+	case "":
+	case "1":
+		return ScopeSystem, nil
+	default:
+		// A manager that is not PID 1 is a user manager, unless we are running
+		// inside a container where the manager isn't PID 1 in our namespace.
+		if os.Getuid() != 0 || os.Getenv("XDG_RUNTIME_DIR") != "" {
+			return ScopeUser, nil
+		}
+		return ScopeSystem, nil
+	}
+
+	return ScopeNone, nil
+}
+
+// cgroupScope returns the [Scope] for a cgroup path.
+func cgroupScope(cgroup string) Scope {
+	if cgroup == "" || cgroup == "/" {
+		return ScopeNone
+	}
+	for elem := range strings.SplitSeq(strings.Trim(cgroup, "/"), "/") {
+		if strings.HasPrefix(elem, "user@") && strings.HasSuffix(elem, ".service") {
+			return ScopeUser
+		}
+	}
+	// Session scopes are created by logind for login sessions and are not
+	// managed by a service manager.
+	if strings.HasPrefix(cgroup, "/user.slice/") {
+		return ScopeNone
+	}
+	if strings.HasSuffix(cgroup, ".service") || strings.Contains(cgroup, ".service/") ||
+		strings.HasPrefix(cgroup, "/init.scope") {
+		return ScopeSystem
+	}
+	return ScopeNone
+}
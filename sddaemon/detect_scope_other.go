@@ -0,0 +1,8 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sddaemon
+
+func ManagerScope() (Scope, error) { return ScopeNone, nil }
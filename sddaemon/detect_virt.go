@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// rootDir is the directory used to resolve all the paths read while detecting
+// virtualization, used to override the implementation during tests.
+var rootDir = "/"
+
+// readFile reads a file relative to [rootDir].
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(rootDir, name))
+}
+
+// exists reports whether a file exists relative to [rootDir].
+func exists(name string) bool {
+	_, err := os.Lstat(filepath.Join(rootDir, name))
+	return err == nil
+}
+
+// DetectVirtualization detects whether the application is running in a
+// container or virtual machine. Like [systemd-detect-virt(1)], containers take
+// precedence over virtual machines, as a container may also be running inside
+// of a virtual machine.
+//
+// If no virtualization is detected, [VirtNone] will be returned.
+//
+// [systemd-detect-virt(1)]: https://www.freedesktop.org/software/systemd/man/latest/systemd-detect-virt.html
+func DetectVirtualization() (Virtualization, error) {
+	v, err := DetectContainer()
+	if err != nil || v != VirtNone {
+		return v, err
+	}
+	return DetectVM()
+}
+
+// DetectVM detects whether the application is running in a virtual machine,
+// using DMI, CPUID, and `/proc` / `/sys` heuristics.
+//
+// If no virtual machine is detected, [VirtNone] will be returned.
+func DetectVM() (Virtualization, error) {
+	// Some hypervisors expose a CPUID signature of another hypervisor they are
+	// built on top of, so prefer the more specific DMI vendor for these.
+	dmi := detectVMDMI()
+	switch dmi {
+	case VirtOracle, VirtXen, VirtAmazon, VirtParallels, VirtGoogle:
+		return dmi, nil
+	}
+
+	cpuid := detectVMCPUID()
+	if cpuid != VirtNone && cpuid != VirtVMOther {
+		return cpuid, nil
+	}
+	if dmi != VirtNone {
+		return dmi, nil
+	}
+
+	if v := detectVMXen(); v != VirtNone {
+		return v, nil
+	}
+	if v, err := detectVMProc(); err != nil || v != VirtNone {
+		return v, err
+	}
+
+	// The hypervisor bit was set, but we were unable to figure out what it is.
+	return cpuid, nil
+}
+
+// dmiVendors maps DMI vendor prefixes to the [Virtualization] they belong to.
+var dmiVendors = []struct {
+	prefix string
+	virt   Virtualization
+}{
+	{prefix: "KVM", virt: VirtKVM},
+	{prefix: "OpenStack", virt: VirtKVM},
+	{prefix: "KubeVirt", virt: VirtKVM},
+	{prefix: "Amazon EC2", virt: VirtAmazon},
+	{prefix: "QEMU", virt: VirtQEMU},
+	{prefix: "VMware", virt: VirtVMware},
+	{prefix: "VMW", virt: VirtVMware},
+	{prefix: "innotek GmbH", virt: VirtOracle},
+	{prefix: "VirtualBox", virt: VirtOracle},
+	{prefix: "Oracle Corporation", virt: VirtOracle},
+	{prefix: "Xen", virt: VirtXen},
+	{prefix: "Bochs", virt: VirtBochs},
+	{prefix: "Parallels", virt: VirtParallels},
+	{prefix: "BHYVE", virt: VirtBhyve},
+	{prefix: "Hyper-V", virt: VirtMicrosoft},
+	{prefix: "Apple Virtualization", virt: VirtApple},
+	{prefix: "Google Compute Engine", virt: VirtGoogle},
+}
+
+// detectVMDMI detects a virtual machine using the DMI vendor information
+// exposed by the kernel.
+func detectVMDMI() Virtualization {
+	for _, name := range []string{
+		"sys/class/dmi/id/product_name",
+		"sys/class/dmi/id/sys_vendor",
+		"sys/class/dmi/id/board_vendor",
+		"sys/class/dmi/id/bios_vendor",
+		"sys/class/dmi/id/product_version",
+	} {
+		b, err := readFile(name)
+		if err != nil {
+			continue
+		}
+		for _, v := range dmiVendors {
+			if bytes.HasPrefix(b, []byte(v.prefix)) {
+				return v.virt
+			}
+		}
+	}
+	return VirtNone
+}
+
+// cpuidVendors maps hypervisor CPUID vendor signatures to the [Virtualization]
+// they belong to.
+var cpuidVendors = map[string]Virtualization{
+	"XenVMMXenVMM":             VirtXen,
+	"KVMKVMKVM\x00\x00\x00":    VirtKVM,
+	"Linux KVM Hv":             VirtKVM,
+	"TCGTCGTCGTCG":             VirtQEMU,
+	"VMwareVMware":             VirtVMware,
+	"Microsoft Hv":             VirtMicrosoft,
+	"bhyve bhyve ":             VirtBhyve,
+	"QNXQVMBSQG\x00\x00":       VirtQNX,
+	"ACRNACRNACRN":             VirtACRN,
+	"SRESRESRESRE":             VirtSRE,
+	"Apple VZ\x00\x00\x00\x00": VirtApple,
+}
+
+// detectVMCPUID detects a virtual machine using the hypervisor CPUID leaves.
+func detectVMCPUID() Virtualization {
+	vendor, ok := hypervisorVendor()
+	if !ok {
+		return VirtNone
+	}
+	if v, ok := cpuidVendors[vendor]; ok {
+		return v
+	}
+	return VirtVMOther
+}
+
+// detectVMXen detects a Xen virtual machine (including dom0) using `/proc/xen`
+// and `/sys/hypervisor/type`.
+func detectVMXen() Virtualization {
+	if b, err := readFile("sys/hypervisor/type"); err == nil {
+		if string(bytes.TrimSpace(b)) == "xen" {
+			return VirtXen
+		}
+	}
+	if exists("proc/xen") {
+		return VirtXen
+	}
+	return VirtNone
+}
+
+// detectVMProc detects virtual machines that don't expose themselves via DMI or
+// CPUID, using `/proc` heuristics.
+func detectVMProc() (Virtualization, error) {
+	b, err := readFile("proc/cpuinfo")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return VirtNone, fmt.Errorf("sddaemon: unable to read /proc/cpuinfo: %w", err)
+	}
+	if bytes.Contains(b, []byte("\nvendor_id\t: User Mode Linux")) {
+		return VirtUML, nil
+	}
+
+	b, err = readFile("proc/sysinfo")
+	if err == nil {
+		for line := range strings.Lines(string(b)) {
+			if !strings.HasPrefix(line, "VM00 Control Program:") {
+				continue
+			}
+			if strings.Contains(line, "z/VM") {
+				return VirtZVM, nil
+			}
+			return VirtKVM, nil
+		}
+	}
+
+	if exists("proc/device-tree/hypervisor/compatible") {
+		b, err := readFile("proc/device-tree/hypervisor/compatible")
+		if err == nil && bytes.HasPrefix(b, []byte("linux,kvm")) {
+			return VirtKVM, nil
+		}
+		if err == nil && bytes.Contains(b, []byte("xen")) {
+			return VirtXen, nil
+		}
+		return VirtVMOther, nil
+	}
+	if exists("proc/device-tree/ibm,partition-name") &&
+		exists("proc/device-tree/hmc-managed?") &&
+		!exists("proc/device-tree/chosen/qemu,graphic-width") {
+		return VirtPowerVM, nil
+	}
+
+	return VirtNone, nil
+}
+
+// containerNames maps the values systemd and container managers use for the
+// `container` environment variable to a [Virtualization].
+var containerNames = map[string]Virtualization{
+	"lxc":            ContainerLXC,
+	"lxc-libvirt":    ContainerLXCLibvirt,
+	"systemd-nspawn": ContainerSystemdNspawn,
+	"docker":         ContainerDocker,
+	"podman":         ContainerPodman,
+	"rkt":            ContainerRkt,
+	"wsl":            ContainerWSL,
+	"proot":          ContainerProot,
+	"pouch":          ContainerPouch,
+}
+
+// containerName translates the value of a `container` environment variable to
+// a [Virtualization].
+func containerName(v string) Virtualization {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return VirtNone
+	}
+	if c, ok := containerNames[v]; ok {
+		return c
+	}
+	return ContainerOther
+}
+
+// DetectContainer detects whether the application is running inside of a
+// container.
+//
+// If no container is detected, [VirtNone] will be returned.
+func DetectContainer() (Virtualization, error) {
+	// OpenVZ exposes `/proc/vz` inside containers and `/proc/bc` on the host.
+	if exists("proc/vz") && !exists("proc/bc") {
+		return ContainerOpenVZ, nil
+	}
+
+	// WSL identifies itself in the kernel release.
+	if b, err := readFile("proc/sys/kernel/osrelease"); err == nil {
+		if bytes.Contains(b, []byte("Microsoft")) || bytes.Contains(b, []byte("WSL")) {
+			return ContainerWSL, nil
+		}
+	}
+
+	// systemd-nspawn and other container managers that follow the
+	// [Container Interface] write the container manager to this file.
+	//
+	// [Container Interface]: https://systemd.io/CONTAINER_INTERFACE/
+	if b, err := readFile("run/systemd/container"); err == nil {
+		return containerName(string(b)), nil
+	}
+
+	// Check the environment of PID 1 for `container=`. This usually requires
+	// privileges, so permission errors are ignored.
+	b, err := readFile("proc/1/environ")
+	switch {
+	case err == nil:
+		for kv := range bytes.SplitSeq(b, []byte{0}) {
+			if v, ok := bytes.CutPrefix(kv, []byte("container=")); ok {
+				return containerName(string(v)), nil
+			}
+		}
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, fs.ErrPermission):
+	default:
+		return VirtNone, fmt.Errorf("sddaemon: unable to read /proc/1/environ: %w", err)
+	}
+
+	if b, err := readFile("run/host/container-manager"); err == nil {
+		return containerName(string(b)), nil
+	}
+
+	// Fallback to the marker files created by docker and podman.
+	if exists(".dockerenv") {
+		return ContainerDocker, nil
+	}
+	if exists("run/.containerenv") {
+		return ContainerPodman, nil
+	}
+
+	return VirtNone, nil
+}
+
+// InChroot reports whether the application is running inside a chroot, by
+// checking if our root directory is the same as the root directory of PID 1.
+//
+// NOTE: inspecting the root directory of PID 1 usually requires privileges, if
+// we are unable to, an error will be returned.
+func InChroot() (bool, error) {
+	root, err := os.Stat(rootDir)
+	if err != nil {
+		return false, fmt.Errorf("sddaemon: unable to stat /: %w", err)
+	}
+	pid1Root, err := os.Stat(filepath.Join(rootDir, "proc/1/root"))
+	if err != nil {
+		return false, fmt.Errorf("sddaemon: unable to stat /proc/1/root: %w", err)
+	}
+	return !os.SameFile(root, pid1Root), nil
+}
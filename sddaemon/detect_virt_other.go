@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sddaemon
+
+func DetectVirtualization() (Virtualization, error) { return VirtNone, nil }
+func DetectVM() (Virtualization, error)             { return VirtNone, nil }
+func DetectContainer() (Virtualization, error)      { return VirtNone, nil }
+func InChroot() (bool, error)                       { return false, nil }
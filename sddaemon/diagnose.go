@@ -0,0 +1,156 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// Doctor gathers a diagnostics report of the application's integration with
+// systemd, including the socket activation file descriptors, notify socket,
+// watchdog, credentials, directories, unit and systemd version.
+//
+// Doctor is non-destructive, it does not modify the environment or consume the
+// file descriptors passed by systemd, so it is safe to call before the other
+// functions in this module. The report can be printed using [Report.String],
+// which is useful when debugging why an integration is not working.
+func Doctor() *Report {
+	r := &Report{
+		ListenPID:            os.Getenv("LISTEN_PID"),
+		NotifySocket:         os.Getenv("NOTIFY_SOCKET"),
+		NotifyReachable:      notifyReachable(),
+		CredentialsDirectory: os.Getenv("CREDENTIALS_DIRECTORY"),
+		Directories:          make(map[string]string),
+	}
+	r.InvocationID, _ = Invocation()
+
+	var err error
+	r.Unit, r.Slice, err = CurrentUnit()
+	r.addError("unit", err)
+	r.Scope, err = ManagerScope()
+	r.addError("scope", err)
+	_, r.Version, err = Version()
+	r.addError("version", err)
+	r.Virtualization, err = DetectVirtualization()
+	r.addError("virtualization", err)
+	r.Watchdog, err = sdnotify.WatchdogInterval()
+	r.addError("watchdog", err)
+
+	r.Listeners, err = listenFDs()
+	r.addError("listeners", err)
+
+	if r.CredentialsDirectory != "" {
+		entries, err := os.ReadDir(r.CredentialsDirectory)
+		r.addError("credentials", err)
+		for _, e := range entries {
+			r.Credentials = append(r.Credentials, e.Name())
+		}
+	}
+
+	for _, key := range directoryKeys {
+		if v := os.Getenv(key); v != "" {
+			r.Directories[key] = v
+		}
+	}
+
+	return r
+}
+
+// listenFDs returns the file descriptors passed using socket activation,
+// without consuming them.
+func listenFDs() ([]ListenFD, error) {
+	v := os.Getenv("LISTEN_FDS")
+	if v == "" {
+		return nil, nil
+	}
+	nfds, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, err
+	}
+	if pid := os.Getenv("LISTEN_PID"); pid != strconv.Itoa(os.Getpid()) {
+		return nil, fmt.Errorf("sddaemon: LISTEN_PID (%s) does not match our PID (%d)", pid, os.Getpid())
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	fds := make([]ListenFD, nfds)
+	for i := range nfds {
+		fd := listenFdsStart + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(names) && names[i] != "" {
+			name = names[i]
+		}
+		fds[i] = ListenFD{
+			FD:   fd,
+			Name: name,
+			Kind: classifyFD(fd),
+		}
+	}
+	return fds, nil
+}
+
+// classifyFD returns a human-readable classification of a file descriptor.
+func classifyFD(fd int) string {
+	var st syscall.Stat_t
+	if err := syscall.Fstat(fd, &st); err != nil {
+		return "invalid (" + err.Error() + ")"
+	}
+	switch st.Mode & syscall.S_IFMT {
+	case syscall.S_IFSOCK:
+	case syscall.S_IFIFO:
+		return "fifo"
+	case syscall.S_IFREG:
+		return "file"
+	case syscall.S_IFCHR:
+		return "character device"
+	case syscall.S_IFDIR:
+		return "directory"
+	default:
+		return "unknown"
+	}
+
+	domain, err := syscall.GetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_DOMAIN)
+	if err != nil {
+		return "socket"
+	}
+	typ, err := syscall.GetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_TYPE)
+	if err != nil {
+		return "socket"
+	}
+	listening, _ := syscall.GetsockoptInt(fd, syscall.SOL_SOCKET, syscall.SO_ACCEPTCONN)
+
+	var kind string
+	switch domain {
+	case syscall.AF_INET:
+		kind = map[int]string{syscall.SOCK_STREAM: "tcp4", syscall.SOCK_DGRAM: "udp4"}[typ]
+	case syscall.AF_INET6:
+		kind = map[int]string{syscall.SOCK_STREAM: "tcp6", syscall.SOCK_DGRAM: "udp6"}[typ]
+	case syscall.AF_UNIX:
+		kind = map[int]string{
+			syscall.SOCK_STREAM:    "unix stream",
+			syscall.SOCK_DGRAM:     "unix datagram",
+			syscall.SOCK_SEQPACKET: "unix seqpacket",
+		}[typ]
+	case syscall.AF_NETLINK:
+		kind = "netlink"
+	}
+	if kind == "" {
+		kind = "socket (domain=" + strconv.Itoa(domain) + ", type=" + strconv.Itoa(typ) + ")"
+	}
+	if typ == syscall.SOCK_STREAM || typ == syscall.SOCK_SEQPACKET {
+		if listening == 1 {
+			kind += " listener"
+		} else {
+			kind += " conn"
+		}
+	}
+	return kind
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// directoryMode is the mode used when creating a missing directory, it matches
+// the default used by systemd for [RuntimeDirectoryMode=] and friends.
+//
+// [RuntimeDirectoryMode=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#RuntimeDirectoryMode=
+const directoryMode = 0o755
+
+// directories parses the value of the environment variable named by key into a
+// list of absolute paths, creating any that are missing.
+//
+// systemd sets these environment variables to a colon-separated list of
+// absolute paths, one for each directory configured on the unit.
+func directories(key string) ([]string, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return nil, nil
+	}
+	paths := strings.Split(v, ":")
+	for i, p := range paths {
+		if !filepath.IsAbs(p) {
+			return nil, fmt.Errorf("sddaemon: %s contains a non-absolute path (%s)", key, p)
+		}
+		p = filepath.Clean(p)
+		if err := ensureDirectory(p); err != nil {
+			return nil, fmt.Errorf("sddaemon: %s: %w", key, err)
+		}
+		paths[i] = p
+	}
+	return paths, nil
+}
+
+// directory is like [directories] except that it only returns the first path.
+func directory(key string) (string, error) {
+	paths, err := directories(key)
+	if err != nil || len(paths) < 1 {
+		return "", err
+	}
+	return paths[0], nil
+}
+
+// ensureDirectory ensures that a directory exists at path, creating it if it
+// does not already exist.
+func ensureDirectory(path string) error {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		return os.MkdirAll(path, directoryMode)
+	}
+	if !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	return nil
+}
+
+// RuntimeDirectory returns the first path from `RUNTIME_DIRECTORY`, configured
+// with [RuntimeDirectory=].
+//
+// If the environment variable is unset, an empty string and an error of `nil`
+// will be returned. If the directory does not exist, it will be created.
+//
+// [RuntimeDirectory=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#RuntimeDirectory=
+func RuntimeDirectory() (string, error) {
+	return directory("RUNTIME_DIRECTORY")
+}
+
+// RuntimeDirectories is like [RuntimeDirectory] except that it returns all the
+// paths, for units that configure more than one directory.
+func RuntimeDirectories() ([]string, error) {
+	return directories("RUNTIME_DIRECTORY")
+}
+
+// StateDirectory returns the first path from `STATE_DIRECTORY`, configured
+// with [StateDirectory=].
+//
+// If the environment variable is unset, an empty string and an error of `nil`
+// will be returned. If the directory does not exist, it will be created.
+//
+// [StateDirectory=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#StateDirectory=
+func StateDirectory() (string, error) {
+	return directory("STATE_DIRECTORY")
+}
+
+// StateDirectories is like [StateDirectory] except that it returns all the
+// paths, for units that configure more than one directory.
+func StateDirectories() ([]string, error) {
+	return directories("STATE_DIRECTORY")
+}
+
+// CacheDirectory returns the first path from `CACHE_DIRECTORY`, configured
+// with [CacheDirectory=].
+//
+// If the environment variable is unset, an empty string and an error of `nil`
+// will be returned. If the directory does not exist, it will be created.
+//
+// [CacheDirectory=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#CacheDirectory=
+func CacheDirectory() (string, error) {
+	return directory("CACHE_DIRECTORY")
+}
+
+// CacheDirectories is like [CacheDirectory] except that it returns all the
+// paths, for units that configure more than one directory.
+func CacheDirectories() ([]string, error) {
+	return directories("CACHE_DIRECTORY")
+}
+
+// LogsDirectory returns the first path from `LOGS_DIRECTORY`, configured
+// with [LogsDirectory=].
+//
+// If the environment variable is unset, an empty string and an error of `nil`
+// will be returned. If the directory does not exist, it will be created.
+//
+// [LogsDirectory=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#LogsDirectory=
+func LogsDirectory() (string, error) {
+	return directory("LOGS_DIRECTORY")
+}
+
+// LogsDirectories is like [LogsDirectory] except that it returns all the
+// paths, for units that configure more than one directory.
+func LogsDirectories() ([]string, error) {
+	return directories("LOGS_DIRECTORY")
+}
+
+// ConfigurationDirectory returns the first path from `CONFIGURATION_DIRECTORY`,
+// configured with [ConfigurationDirectory=].
+//
+// If the environment variable is unset, an empty string and an error of `nil`
+// will be returned. Unlike the other directories, systemd does not expect a
+// service to write to its configuration directory, however it will still be
+// created if it does not exist.
+//
+// [ConfigurationDirectory=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#ConfigurationDirectory=
+func ConfigurationDirectory() (string, error) {
+	return directory("CONFIGURATION_DIRECTORY")
+}
+
+// ConfigurationDirectories is like [ConfigurationDirectory] except that it
+// returns all the paths, for units that configure more than one directory.
+func ConfigurationDirectories() ([]string, error) {
+	return directories("CONFIGURATION_DIRECTORY")
+}
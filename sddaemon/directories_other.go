@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sddaemon
+
+func RuntimeDirectory() (string, error)           { return "", nil }
+func RuntimeDirectories() ([]string, error)       { return nil, nil }
+func StateDirectory() (string, error)             { return "", nil }
+func StateDirectories() ([]string, error)         { return nil, nil }
+func CacheDirectory() (string, error)             { return "", nil }
+func CacheDirectories() ([]string, error)         { return nil, nil }
+func LogsDirectory() (string, error)              { return "", nil }
+func LogsDirectories() ([]string, error)          { return nil, nil }
+func ConfigurationDirectory() (string, error)     { return "", nil }
+func ConfigurationDirectories() ([]string, error) { return nil, nil }
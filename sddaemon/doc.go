@@ -0,0 +1,14 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sddaemon provides helpers for inspecting the environment systemd
+// sets up for the services it manages.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package are a no-op on other operating systems.
+//
+// See [systemd.exec(5)] for details on the environment variables systemd
+// passes to the processes it spawns.
+//
+// [systemd.exec(5)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html
+package sddaemon
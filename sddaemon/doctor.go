@@ -0,0 +1,174 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ListenFD describes a file descriptor passed to the application using socket
+// activation.
+type ListenFD struct {
+	// FD is the file descriptor number.
+	FD int
+
+	// Name is the name of the file descriptor, from `LISTEN_FDNAMES`.
+	Name string
+
+	// Kind is a human-readable classification of the file descriptor, such as
+	// `tcp6 listener`, `unix stream conn`, `udp` or `fifo`.
+	Kind string
+}
+
+// Report is a diagnostics report of the application's integration with
+// systemd, returned by [Doctor].
+type Report struct {
+	// InvocationID is the invocation ID of the unit, if started by systemd.
+	InvocationID InvocationID
+
+	// Unit and Slice are the unit and slice the application is running in.
+	Unit, Slice string
+
+	// Scope is the type of service manager the application is running under.
+	Scope Scope
+
+	// Version is the full version of systemd installed on the system.
+	Version string
+
+	// Virtualization is the detected virtualization technology.
+	Virtualization Virtualization
+
+	// ListenPID is the value of `LISTEN_PID`, if set.
+	ListenPID string
+
+	// Listeners are the file descriptors passed using socket activation.
+	Listeners []ListenFD
+
+	// NotifySocket is the value of `NOTIFY_SOCKET`, if set.
+	NotifySocket string
+
+	// NotifyReachable is whether a connection could be established to the
+	// notify socket.
+	NotifyReachable bool
+
+	// Watchdog is the watchdog interval, if armed for the application.
+	Watchdog time.Duration
+
+	// Credentials are the names of the credentials in the credentials
+	// directory.
+	Credentials []string
+
+	// CredentialsDirectory is the value of `CREDENTIALS_DIRECTORY`, if set.
+	CredentialsDirectory string
+
+	// Directories maps the name of each environment variable for the
+	// directories managed by systemd (such as `STATE_DIRECTORY`) to its value.
+	Directories map[string]string
+
+	// Errors are any errors that occurred while gathering the report, keyed
+	// by the section of the report they occurred in.
+	Errors map[string]error
+}
+
+// addError records an error for the given section of the report.
+func (r *Report) addError(section string, err error) {
+	if err == nil {
+		return
+	}
+	if r.Errors == nil {
+		r.Errors = make(map[string]error)
+	}
+	r.Errors[section] = err
+}
+
+// WriteTo writes a human-readable version of the report to w.
+func (r *Report) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	line := func(key, format string, args ...any) {
+		fmt.Fprintf(&b, "%-16s "+format+"\n", append([]any{key + ":"}, args...)...)
+	}
+	orNone := func(v string) string {
+		if v == "" {
+			return "(none)"
+		}
+		return v
+	}
+
+	line("systemd", "%s", orNone(r.Version))
+	line("scope", "%s", r.Scope)
+	line("virtualization", "%s", r.Virtualization)
+	if r.InvocationID.IsZero() {
+		line("invocation", "(none)")
+	} else {
+		line("invocation", "%s", r.InvocationID)
+	}
+	line("unit", "%s", orNone(r.Unit))
+	line("slice", "%s", orNone(r.Slice))
+
+	line("listen pid", "%s", orNone(r.ListenPID))
+	if len(r.Listeners) < 1 {
+		line("listeners", "(none)")
+	}
+	for _, l := range r.Listeners {
+		line("listener", "fd=%d name=%s kind=%s", l.FD, l.Name, l.Kind)
+	}
+
+	if r.NotifySocket == "" {
+		line("notify", "(none)")
+	} else {
+		line("notify", "%s (reachable=%t)", r.NotifySocket, r.NotifyReachable)
+	}
+	if r.Watchdog > 0 {
+		line("watchdog", "%s", r.Watchdog)
+	} else {
+		line("watchdog", "(disarmed)")
+	}
+
+	if r.CredentialsDirectory == "" {
+		line("credentials", "(none)")
+	} else {
+		line("credentials", "%s [%s]", r.CredentialsDirectory, strings.Join(r.Credentials, ", "))
+	}
+
+	for _, key := range directoryKeys {
+		if v, ok := r.Directories[key]; ok {
+			line(strings.ToLower(strings.TrimSuffix(key, "_DIRECTORY")), "%s", v)
+		}
+	}
+
+	for _, section := range sortedKeys(r.Errors) {
+		line("error", "%s: %v", section, r.Errors[section])
+	}
+
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// String returns a human-readable version of the report.
+func (r *Report) String() string {
+	var b strings.Builder
+	_, _ = r.WriteTo(&b)
+	return b.String()
+}
+
+// directoryKeys are the environment variables for the directories managed by
+// systemd, in the order they are included in a [Report].
+var directoryKeys = []string{
+	"RUNTIME_DIRECTORY",
+	"STATE_DIRECTORY",
+	"CACHE_DIRECTORY",
+	"LOGS_DIRECTORY",
+	"CONFIGURATION_DIRECTORY",
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
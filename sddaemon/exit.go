@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"strconv"
+	"syscall"
+)
+
+// ServiceResult is the result of a service, as passed to `ExecStop=` and
+// `ExecStopPost=` commands in `SERVICE_RESULT`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24SERVICE_RESULT
+type ServiceResult string
+
+const (
+	// ResultSuccess indicates the service ran successfully and exited cleanly.
+	ResultSuccess ServiceResult = "success"
+	// ResultProtocol indicates the service did not fulfill the protocol
+	// required by its `Type=`, such as a missing PID file or `READY=1`.
+	ResultProtocol ServiceResult = "protocol"
+	// ResultTimeout indicates one of the steps of the service timed out.
+	ResultTimeout ServiceResult = "timeout"
+	// ResultExitCode indicates the service exited with a non-zero exit code.
+	ResultExitCode ServiceResult = "exit-code"
+	// ResultSignal indicates the service was terminated by a signal.
+	ResultSignal ServiceResult = "signal"
+	// ResultCoreDump indicates the service was terminated by a signal and
+	// dumped core.
+	ResultCoreDump ServiceResult = "core-dump"
+	// ResultWatchdog indicates the watchdog of the service timed out.
+	ResultWatchdog ServiceResult = "watchdog"
+	// ResultStartLimitHit indicates the service was started too often.
+	ResultStartLimitHit ServiceResult = "start-limit-hit"
+	// ResultResources indicates a resource error, such as being unable to
+	// fork, occurred while starting the service.
+	ResultResources ServiceResult = "resources"
+	// ResultOOMKill indicates the service was killed by the kernel's or
+	// systemd-oomd's OOM killer.
+	ResultOOMKill ServiceResult = "oom-kill"
+)
+
+// ExitCode is how the main process of a service exited, as passed to `ExecStop=`
+// and `ExecStopPost=` commands in `EXIT_CODE`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24EXIT_CODE
+type ExitCode string
+
+const (
+	// ExitCodeExited indicates the process exited on its own.
+	ExitCodeExited ExitCode = "exited"
+	// ExitCodeKilled indicates the process was killed by a signal.
+	ExitCodeKilled ExitCode = "killed"
+	// ExitCodeDumped indicates the process was killed by a signal and dumped
+	// core.
+	ExitCodeDumped ExitCode = "dumped"
+)
+
+// ExitInfo describes why the main process of a service exited.
+type ExitInfo struct {
+	// Result is the overall result of the service.
+	Result ServiceResult
+
+	// Code is how the main process exited. It may be empty if the main process
+	// never ran, for example if the service hit the start limit.
+	Code ExitCode
+
+	// Status is the exit status of the main process, only set if Code is
+	// [ExitCodeExited].
+	Status int
+
+	// Signal is the signal that killed the main process, only set if Code is
+	// [ExitCodeKilled] or [ExitCodeDumped].
+	Signal syscall.Signal
+}
+
+// Success reports whether the service exited successfully.
+func (e ExitInfo) Success() bool {
+	return e.Result == ResultSuccess
+}
+
+// String returns a human-readable description of the exit, similar to the
+// format used by `systemctl status`, such as `exit-code (code=exited, status=1)`.
+func (e ExitInfo) String() string {
+	if e.Result == "" {
+		return ""
+	}
+	s := string(e.Result)
+	switch e.Code {
+	case ExitCodeExited:
+		s += " (code=exited, status=" + strconv.Itoa(e.Status) + ")"
+	case ExitCodeKilled, ExitCodeDumped:
+		s += " (code=" + string(e.Code) + ", signal=" + e.Signal.String() + ")"
+	}
+	return s
+}
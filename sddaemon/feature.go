@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+// Feature is a feature of systemd that is only available in certain versions.
+// Use [Supports] to check if the installed version of systemd supports it.
+type Feature int
+
+const (
+	// FeatureExtendTimeout is support for `EXTEND_TIMEOUT_USEC=` notifications.
+	FeatureExtendTimeout Feature = iota
+	// FeatureFDStore is support for `FDSTORE=1` notifications.
+	FeatureFDStore
+	// FeatureFDStoreRemove is support for `FDSTOREREMOVE=1` notifications.
+	FeatureFDStoreRemove
+	// FeatureFDPoll is support for `FDPOLL=0` notifications.
+	FeatureFDPoll
+	// FeatureBarrier is support for `BARRIER=1` notifications.
+	FeatureBarrier
+	// FeatureMonotonicUsec is support for `MONOTONIC_USEC=` notifications and
+	// `Type=notify-reload` services.
+	FeatureMonotonicUsec
+	// FeatureVsockNotify is support for `NOTIFY_SOCKET` pointing at an
+	// `AF_VSOCK` address.
+	FeatureVsockNotify
+	// FeatureSoftReboot is support for `systemctl soft-reboot`.
+	FeatureSoftReboot
+	// FeatureMemoryPressure is support for `MEMORY_PRESSURE_WATCH`.
+	FeatureMemoryPressure
+	// FeatureBusError is support for `BUSERROR=` notifications.
+	FeatureBusError
+)
+
+// featureVersions maps a [Feature] to the first version of systemd that
+// supports it.
+var featureVersions = map[Feature]int{
+	FeatureExtendTimeout:  236,
+	FeatureFDStore:        219,
+	FeatureFDStoreRemove:  236,
+	FeatureFDPoll:         246,
+	FeatureBarrier:        246,
+	FeatureMonotonicUsec:  253,
+	FeatureVsockNotify:    254,
+	FeatureSoftReboot:     254,
+	FeatureMemoryPressure: 254,
+	FeatureBusError:       219,
+}
+
+// MinVersion returns the first version of systemd that supports the feature.
+func (f Feature) MinVersion() int {
+	return featureVersions[f]
+}
+
+// Supports reports whether the installed version of systemd supports the given
+// feature. If the version of systemd is unable to be detected, false will be
+// returned.
+func Supports(f Feature) bool {
+	major, _, err := Version()
+	if err != nil || major < 1 {
+		return false
+	}
+	minVersion, ok := featureVersions[f]
+	return ok && major >= minVersion
+}
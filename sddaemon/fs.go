@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DirFS is a [fs.FS] rooted at a directory managed by systemd, such as the one
+// returned by [StateDirectory].
+//
+// In addition to the read-only methods provided by [fs.FS], DirFS provides
+// helpers to atomically write and rename files within the directory. Files
+// written by DirFS use the mode of the directory, minus the executable bits,
+// so a directory configured with `StateDirectoryMode=0700` will only ever
+// contain files with a mode of `0600`.
+type DirFS struct {
+	fs.FS
+
+	dir  string
+	mode fs.FileMode
+}
+
+// newDirFS returns a new [*DirFS] for dir, creating the directory if it does not
+// already exist.
+func newDirFS(dir string) (*DirFS, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("sddaemon: unable to create directory: %w", err)
+	}
+	fi, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("sddaemon: unable to stat directory: %w", err)
+	}
+	return &DirFS{
+		FS:   os.DirFS(dir),
+		dir:  dir,
+		mode: fi.Mode().Perm() &^ 0o111,
+	}, nil
+}
+
+// Dir returns the absolute path of the directory.
+func (d *DirFS) Dir() string {
+	return d.dir
+}
+
+// path returns the absolute path to name, ensuring it doesn't escape the
+// directory.
+func (d *DirFS) path(op, name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+	return filepath.Join(d.dir, filepath.FromSlash(name)), nil
+}
+
+// WriteFile atomically writes data to the named file, creating any missing
+// parent directories. See [WriteFileAtomic] for details.
+func (d *DirFS) WriteFile(name string, data []byte) error {
+	p, err := d.path("write", name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), d.mode|0o700); err != nil {
+		return err
+	}
+	return WriteFileAtomic(p, data, d.mode)
+}
+
+// Rename atomically renames oldname to newname, replacing newname if it
+// already exists. See [RenameAtomic] for details.
+func (d *DirFS) Rename(oldname, newname string) error {
+	oldpath, err := d.path("rename", oldname)
+	if err != nil {
+		return err
+	}
+	newpath, err := d.path("rename", newname)
+	if err != nil {
+		return err
+	}
+	return RenameAtomic(oldpath, newpath)
+}
+
+// Remove removes the named file or (empty) directory.
+func (d *DirFS) Remove(name string) error {
+	p, err := d.path("remove", name)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+// WriteFileAtomic writes data to the named file atomically, either the file
+// will contain all of data, or the previous contents of the file will be left
+// as-is.
+//
+// The data is first written to a temporary file in the same directory, synced
+// to disk, then renamed over the named file using [RenameAtomic].
+func WriteFileAtomic(name string, data []byte, perm fs.FileMode) error {
+	dir, base := filepath.Split(name)
+	f, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return RenameAtomic(tmp, name)
+}
+
+// RenameAtomic renames oldpath to newpath, then syncs the parent directory of
+// newpath to ensure the rename is persisted to disk.
+//
+// Both paths must be on the same filesystem, otherwise the rename will fail.
+func RenameAtomic(oldpath, newpath string) error {
+	if err := os.Rename(oldpath, newpath); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(newpath))
+}
+
+// syncDir syncs a directory to disk. Syncing a directory is not supported on
+// Windows, so it is skipped there.
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// appName returns the name used for fallback directories when not running
+// under systemd, the base name of the executable without any extension.
+func appName() string {
+	name := filepath.Base(os.Args[0])
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+// dirFS returns a [*DirFS] for the directory returned by fn, or fallback if fn
+// returns an empty path.
+func dirFS(fn func() (string, error), fallback func() (string, error)) (*DirFS, error) {
+	dir, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	if dir == "" {
+		base, err := fallback()
+		if err != nil {
+			return nil, fmt.Errorf("sddaemon: unable to get fallback directory: %w", err)
+		}
+		dir = filepath.Join(base, appName())
+	}
+	return newDirFS(dir)
+}
+
+// userStateDir returns `$XDG_STATE_HOME` or `~/.local/state`.
+func userStateDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state"), nil
+}
+
+// userRuntimeDir returns `$XDG_RUNTIME_DIR` or [os.TempDir].
+func userRuntimeDir() (string, error) {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return dir, nil
+	}
+	return os.TempDir(), nil
+}
+
+// userLogsDir returns the `log` directory within [userStateDir].
+func userLogsDir() (string, error) {
+	dir, err := userStateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "log"), nil
+}
+
+// RuntimeFS returns a [*DirFS] for [RuntimeDirectory].
+//
+// If not running under systemd, `$XDG_RUNTIME_DIR/<name>` will be used, falling
+// back to [os.TempDir] if `$XDG_RUNTIME_DIR` is unset. `<name>` is the base
+// name of the executable.
+func RuntimeFS() (*DirFS, error) {
+	return dirFS(RuntimeDirectory, userRuntimeDir)
+}
+
+// StateFS returns a [*DirFS] for [StateDirectory].
+//
+// If not running under systemd, `$XDG_STATE_HOME/<name>` will be used, falling
+// back to `~/.local/state` if `$XDG_STATE_HOME` is unset. `<name>` is the base
+// name of the executable.
+func StateFS() (*DirFS, error) {
+	return dirFS(StateDirectory, userStateDir)
+}
+
+// CacheFS returns a [*DirFS] for [CacheDirectory].
+//
+// If not running under systemd, [os.UserCacheDir] will be used with the base
+// name of the executable appended to it.
+func CacheFS() (*DirFS, error) {
+	return dirFS(CacheDirectory, os.UserCacheDir)
+}
+
+// LogsFS returns a [*DirFS] for [LogsDirectory].
+//
+// If not running under systemd, `$XDG_STATE_HOME/log/<name>` will be used,
+// falling back to `~/.local/state/log` if `$XDG_STATE_HOME` is unset. `<name>`
+// is the base name of the executable.
+func LogsFS() (*DirFS, error) {
+	return dirFS(LogsDirectory, userLogsDir)
+}
+
+// ConfigurationFS returns a [*DirFS] for [ConfigurationDirectory].
+//
+// If not running under systemd, [os.UserConfigDir] will be used with the base
+// name of the executable appended to it.
+func ConfigurationFS() (*DirFS, error) {
+	return dirFS(ConfigurationDirectory, os.UserConfigDir)
+}
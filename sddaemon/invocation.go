@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import "github.com/matthewpi/sd/sdid128"
+
+// Invoked reports whether the application was started by systemd as part of a
+// unit, determined by `INVOCATION_ID` being set to a valid invocation ID.
+func Invoked() bool {
+	_, ok := Invocation()
+	return ok
+}
+
+// Invocation returns the [InvocationID] of the unit the application was started
+// by. If the application was not started by systemd, or `INVOCATION_ID` is not
+// a valid invocation ID, a zero [InvocationID] and `false` will be returned.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24INVOCATION_ID
+func Invocation() (InvocationID, bool) {
+	id, ok := sdid128.InvocationID()
+	if !ok {
+		return InvocationID{}, false
+	}
+	return InvocationID(id), true
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sddaemon
+
+func Invoked() bool                    { return false }
+func Invocation() (InvocationID, bool) { return InvocationID{}, false }
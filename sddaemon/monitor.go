@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+// Monitor describes the unit that caused a unit configured with `OnFailure=` or
+// `OnSuccess=` to be activated.
+type Monitor struct {
+	// Unit is the name of the monitored unit, from `MONITOR_UNIT`.
+	Unit string
+
+	// InvocationID is the invocation ID of the monitored unit, from
+	// `MONITOR_INVOCATION_ID`.
+	InvocationID InvocationID
+
+	// Exit describes why the main process of the monitored unit exited, from
+	// `MONITOR_SERVICE_RESULT`, `MONITOR_EXIT_CODE` and `MONITOR_EXIT_STATUS`.
+	Exit ExitInfo
+}
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"os"
+)
+
+// MonitoredUnit returns the unit that caused the activation of a unit
+// configured as an `OnFailure=` or `OnSuccess=` handler, using the `MONITOR_*`
+// environment variables.
+//
+// If `MONITOR_UNIT` is unset, a zero [Monitor] and an error of `nil` will be
+// returned.
+//
+// NOTE: systemd only sets these environment variables if the handler was only
+// triggered by a single unit, if multiple units triggered the handler at the
+// same time they will be unset.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24MONITOR_SERVICE_RESULT
+func MonitoredUnit() (Monitor, error) {
+	unit := os.Getenv("MONITOR_UNIT")
+	if unit == "" {
+		return Monitor{}, nil
+	}
+	m := Monitor{Unit: unit}
+
+	if v := os.Getenv("MONITOR_INVOCATION_ID"); v != "" {
+		id, err := ParseInvocationID(v)
+		if err != nil {
+			return Monitor{}, fmt.Errorf("sddaemon: unable to parse MONITOR_INVOCATION_ID: %w", err)
+		}
+		m.InvocationID = id
+	}
+
+	exit, err := exitInfo("MONITOR_")
+	if err != nil {
+		return Monitor{}, err
+	}
+	m.Exit = exit
+	return m, nil
+}
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// journalSocket is the path to the socket used by the journal's native
+// protocol.
+const journalSocket = "/run/systemd/journal/socket"
+
+// Capabilities probes which integrations with systemd are currently usable by
+// the application.
+//
+// Unlike the functions used to consume them, such as [sdlisten.Files], probing
+// is non-destructive and does not modify the environment.
+//
+// [sdlisten.Files]: https://pkg.go.dev/github.com/matthewpi/sd/sdlisten#Files
+func Capabilities() Integrations {
+	wd, _ := sdnotify.WatchdogInterval()
+	return Integrations{
+		Invoked:     Invoked(),
+		Notify:      notifyReachable(),
+		Watchdog:    wd,
+		Listeners:   listenFds(),
+		Credentials: isDir(os.Getenv("CREDENTIALS_DIRECTORY")),
+		Journal:     dialable("unixgram", journalSocket),
+		FDStore:     atoi(os.Getenv("FDSTORE")),
+	}
+}
+
+// notifyReachable reports whether `NOTIFY_SOCKET` is set and reachable.
+func notifyReachable() bool {
+	v := os.Getenv("NOTIFY_SOCKET")
+	switch {
+	case v == "":
+		return false
+	case v[0] == '@':
+		// Abstract sockets can't be checked for on the filesystem.
+		return dialable("unixgram", v)
+	case filepath.IsAbs(v):
+		return dialable("unixgram", v)
+	default:
+		return false
+	}
+}
+
+// listenFds returns the number of file descriptors passed using socket
+// activation, if `LISTEN_PID` matches our PID.
+func listenFds() int {
+	if atoi(os.Getenv("LISTEN_PID")) != os.Getpid() {
+		return 0
+	}
+	return max(atoi(os.Getenv("LISTEN_FDS")), 0)
+}
+
+// dialable reports whether a connection can be established to address.
+func dialable(network, address string) bool {
+	c, err := net.Dial(network, address)
+	if err != nil {
+		return false
+	}
+	_ = c.Close()
+	return true
+}
+
+// isDir reports whether path is an absolute path to a directory.
+func isDir(path string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// atoi is like [strconv.Atoi] except that it returns `0` on error.
+func atoi(v string) int {
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,34 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/matthewpi/sd/internal/sdenv"
+)
+
+// RemoteAddr returns the address of the remote peer for services spawned by a
+// socket unit with `Accept=yes`, using `REMOTE_ADDR` and `REMOTE_PORT`.
+//
+// If `REMOTE_ADDR` is unset, a nil value and an error of `nil` will be returned.
+// [sdlisten.Connections] uses this address as the [net.Conn.RemoteAddr] of the
+// connections passed to the service.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24REMOTE_ADDR
+//
+// [sdlisten.Connections]: https://pkg.go.dev/github.com/matthewpi/sd/sdlisten#Connections
+func RemoteAddr() (net.Addr, error) {
+	addr, err := sdenv.RemoteAddr()
+	if err != nil {
+		return nil, fmt.Errorf("sddaemon: %w", err)
+	}
+	if addr == nil {
+		return nil, nil
+	}
+	return addr, nil
+}
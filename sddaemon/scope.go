@@ -0,0 +1,53 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Scope is the type of service manager an application is running under, either
+// the system manager (PID 1) or a per-user `systemd --user` instance.
+type Scope int
+
+const (
+	// ScopeNone indicates the application is not running under a service
+	// manager, or the manager was unable to be detected.
+	ScopeNone Scope = iota
+	// ScopeSystem is the system service manager.
+	ScopeSystem
+	// ScopeUser is a per-user service manager.
+	ScopeUser
+)
+
+// String returns the name of the scope, matching the `--system` and `--user`
+// flags used by `systemctl`.
+func (s Scope) String() string {
+	switch s {
+	case ScopeSystem:
+		return "system"
+	case ScopeUser:
+		return "user"
+	default:
+		return "none"
+	}
+}
+
+// RuntimeDir returns the runtime directory of the service manager, this is the
+// directory that contains the `systemd` directory with the manager's private
+// sockets.
+//
+// For [ScopeSystem] (and [ScopeNone]) this is `/run`, for [ScopeUser] this is
+// `$XDG_RUNTIME_DIR`, falling back to `/run/user/$UID` if it is unset.
+func (s Scope) RuntimeDir() string {
+	if s != ScopeUser {
+		return "/run"
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return dir
+	}
+	return "/run/user/" + strconv.Itoa(os.Getuid())
+}
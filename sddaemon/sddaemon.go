@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"encoding/hex"
+	"errors"
+)
+
+// ErrInvalidInvocationID is returned by [ParseInvocationID] when the provided
+// value is not a valid invocation ID.
+var ErrInvalidInvocationID = errors.New("sddaemon: invalid invocation id")
+
+// InvocationID is the 128-bit ID systemd assigns to every runtime cycle of a
+// unit. A new ID is generated each time a unit is started (or restarted), so it
+// may be used to tag logs and telemetry per service invocation.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24INVOCATION_ID
+type InvocationID [16]byte
+
+// ParseInvocationID parses an invocation ID in the format systemd uses for the
+// `INVOCATION_ID` environment variable, 32 hexadecimal characters.
+func ParseInvocationID(s string) (InvocationID, error) {
+	var id InvocationID
+	if len(s) != hex.EncodedLen(len(id)) {
+		return InvocationID{}, ErrInvalidInvocationID
+	}
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return InvocationID{}, ErrInvalidInvocationID
+	}
+	if id.IsZero() {
+		return InvocationID{}, ErrInvalidInvocationID
+	}
+	return id, nil
+}
+
+// IsZero reports whether the invocation ID is all zeros.
+func (id InvocationID) IsZero() bool {
+	return id == InvocationID{}
+}
+
+// String returns the invocation ID formatted as 32 lowercase hexadecimal
+// characters, the same format used by systemd.
+func (id InvocationID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (id InvocationID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (id *InvocationID) UnmarshalText(text []byte) error {
+	v, err := ParseInvocationID(string(text))
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
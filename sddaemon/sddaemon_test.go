@@ -0,0 +1,564 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestInvocation(t *testing.T) {
+	const raw = "8d3c2a1b4e5f60718293a4b5c6d7e8f9"
+
+	t.Setenv("INVOCATION_ID", "")
+	if Invoked() {
+		t.Error("expected Invoked to be false when INVOCATION_ID is unset")
+	}
+
+	t.Setenv("INVOCATION_ID", "not-a-valid-id")
+	if Invoked() {
+		t.Error("expected Invoked to be false when INVOCATION_ID is invalid")
+	}
+
+	t.Setenv("INVOCATION_ID", raw)
+	id, ok := Invocation()
+	if !ok {
+		t.Fatal("expected Invocation to succeed")
+		return
+	}
+	if expected, got := raw, id.String(); expected != got {
+		t.Errorf("expected invocation id to be \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestParseInvocationID(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		err   error
+	}{
+		{value: "8d3c2a1b4e5f60718293a4b5c6d7e8f9"},
+		{value: "8D3C2A1B4E5F60718293A4B5C6D7E8F9"},
+		{value: "", err: ErrInvalidInvocationID},
+		{value: "8d3c2a1b4e5f60718293a4b5c6d7e8f", err: ErrInvalidInvocationID},
+		{value: "8d3c2a1b-4e5f-6071-8293-a4b5c6d7e8f9", err: ErrInvalidInvocationID},
+		{value: "zz3c2a1b4e5f60718293a4b5c6d7e8f9", err: ErrInvalidInvocationID},
+		{value: "00000000000000000000000000000000", err: ErrInvalidInvocationID},
+	} {
+		if _, err := ParseInvocationID(tc.value); !errors.Is(err, tc.err) {
+			t.Errorf("%q: expected error %v, but got %v", tc.value, tc.err, err)
+		}
+	}
+}
+
+func TestDetectContainer(t *testing.T) {
+	defer func(v string) { rootDir = v }(rootDir)
+
+	for _, tc := range []struct {
+		name   string
+		files  map[string]string
+		expect Virtualization
+	}{
+		{
+			name:   "none",
+			expect: VirtNone,
+		},
+		{
+			name:   "systemd-nspawn",
+			files:  map[string]string{"run/systemd/container": "systemd-nspawn\n"},
+			expect: ContainerSystemdNspawn,
+		},
+		{
+			name:   "pid1 environ",
+			files:  map[string]string{"proc/1/environ": "PATH=/bin\x00container=lxc\x00"},
+			expect: ContainerLXC,
+		},
+		{
+			name:   "unknown manager",
+			files:  map[string]string{"run/systemd/container": "something-new"},
+			expect: ContainerOther,
+		},
+		{
+			name:   "docker",
+			files:  map[string]string{".dockerenv": ""},
+			expect: ContainerDocker,
+		},
+		{
+			name:   "podman",
+			files:  map[string]string{"run/.containerenv": ""},
+			expect: ContainerPodman,
+		},
+		{
+			name:   "wsl",
+			files:  map[string]string{"proc/sys/kernel/osrelease": "5.15.153.1-microsoft-standard-WSL2\n"},
+			expect: ContainerWSL,
+		},
+	} {
+		rootDir = t.TempDir()
+		writeFiles(t, rootDir, tc.files)
+
+		v, err := DetectContainer()
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if v != tc.expect {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", tc.name, tc.expect, v)
+		}
+		if tc.expect != VirtNone && !v.IsContainer() {
+			t.Errorf("%s: expected IsContainer to be true", tc.name)
+		}
+	}
+}
+
+func TestDetectVMDMI(t *testing.T) {
+	defer func(v string) { rootDir = v }(rootDir)
+
+	rootDir = t.TempDir()
+	if v := detectVMDMI(); v != VirtNone {
+		t.Errorf("expected \"%s\", but got \"%s\"", VirtNone, v)
+	}
+
+	writeFiles(t, rootDir, map[string]string{"sys/class/dmi/id/sys_vendor": "QEMU\n"})
+	if v := detectVMDMI(); v != VirtQEMU {
+		t.Errorf("expected \"%s\", but got \"%s\"", VirtQEMU, v)
+	}
+	if !VirtQEMU.IsVM() || VirtQEMU.IsContainer() {
+		t.Errorf("expected \"%s\" to be a VM", VirtQEMU)
+	}
+}
+
+// writeFiles writes files relative to dir, creating any parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDirectories(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a"), filepath.Join(dir, "b", "c")
+
+	t.Setenv("STATE_DIRECTORY", "")
+	if p, err := StateDirectory(); err != nil || p != "" {
+		t.Errorf("expected an empty path and no error, but got \"%s\" and %v", p, err)
+	}
+
+	t.Setenv("STATE_DIRECTORY", a+":"+b+"/")
+	paths, err := StateDirectories()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(paths) != 2 || paths[0] != a || paths[1] != b {
+		t.Errorf("expected [%s %s], but got %v", a, b, paths)
+	}
+	for _, p := range paths {
+		if fi, err := os.Stat(p); err != nil || !fi.IsDir() {
+			t.Errorf("expected \"%s\" to be created as a directory", p)
+		}
+	}
+
+	t.Setenv("STATE_DIRECTORY", "relative/path")
+	if _, err := StateDirectory(); err == nil {
+		t.Error("expected an error for a relative path")
+	}
+
+	f := filepath.Join(dir, "file")
+	writeFiles(t, dir, map[string]string{"file": ""})
+	t.Setenv("STATE_DIRECTORY", f)
+	if _, err := StateDirectory(); err == nil {
+		t.Error("expected an error for a path that is not a directory")
+	}
+}
+
+func TestStateFS(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatal(err)
+		return
+	}
+	t.Setenv("STATE_DIRECTORY", dir)
+
+	d, err := StateFS()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := dir, d.Dir(); expected != got {
+		t.Errorf("expected directory to be \"%s\", but got \"%s\"", expected, got)
+	}
+
+	if err := d.WriteFile("sub/data.json", []byte("{}")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := d.Rename("sub/data.json", "data.json"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	b, err := fs.ReadFile(d, "data.json")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "{}", string(b); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	fi, err := fs.Stat(d, "data.json")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := fs.FileMode(0o600), fi.Mode().Perm(); expected != got {
+		t.Errorf("expected mode to be %s, but got %s", expected, got)
+	}
+
+	if err := d.WriteFile("../escape", nil); err == nil {
+		t.Error("expected an error when writing outside of the directory")
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		out   string
+		major int
+		full  string
+		err   bool
+	}{
+		{out: "systemd 257 (257.5-2-arch)\n+PAM +AUDIT\n", major: 257, full: "257.5-2-arch"},
+		{out: "systemd 219\n+PAM\n", major: 219, full: "219"},
+		{out: "not systemd", err: true},
+		{out: "systemd abc (abc)", err: true},
+		{out: "", err: true},
+	} {
+		v, err := parseVersion([]byte(tc.out))
+		if tc.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tc.out)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.out, err)
+			continue
+		}
+		if v.major != tc.major || v.full != tc.full {
+			t.Errorf("%q: expected %d \"%s\", but got %d \"%s\"", tc.out, tc.major, tc.full, v.major, v.full)
+		}
+	}
+}
+
+func TestSupports(t *testing.T) {
+	defer func(fn func() (versionInfo, error)) { version = fn }(version)
+	version = func() (versionInfo, error) { return versionInfo{major: 250, full: "250"}, nil }
+
+	if !Supports(FeatureBarrier) {
+		t.Error("expected FeatureBarrier to be supported")
+	}
+	if Supports(FeatureMonotonicUsec) {
+		t.Error("expected FeatureMonotonicUsec to be unsupported")
+	}
+}
+
+func TestManagerScope(t *testing.T) {
+	defer func(v string) { rootDir = v }(rootDir)
+
+	for _, tc := range []struct {
+		cgroup string
+		expect Scope
+	}{
+		{cgroup: "0::/system.slice/nginx.service\n", expect: ScopeSystem},
+		{cgroup: "0::/user.slice/user-1000.slice/user@1000.service/app.slice/foo.service\n", expect: ScopeUser},
+		{cgroup: "0::/user.slice/user-1000.slice/session-2.scope\n", expect: ScopeNone},
+		{cgroup: "12:cpu:/\n1:name=systemd:/system.slice/foo.service\n", expect: ScopeSystem},
+	} {
+		rootDir = t.TempDir()
+		writeFiles(t, rootDir, map[string]string{"proc/self/cgroup": tc.cgroup})
+		t.Setenv("MANAGERPID", "")
+
+		s, err := ManagerScope()
+		if err != nil {
+			t.Errorf("%q: %v", tc.cgroup, err)
+			continue
+		}
+		if s != tc.expect {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.cgroup, tc.expect, s)
+		}
+	}
+}
+
+func TestActivationApply(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer w.Close()
+
+	// Re-open the pipe with a name, as if it was passed to us by systemd.
+	fd, err := syscall.Dup(int(r.Fd()))
+	_ = r.Close()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	f := os.NewFile(uintptr(fd), "web")
+	defer f.Close()
+
+	a := &Activation{
+		Files:        []*os.File{f},
+		NotifySocket: "/run/systemd/notify",
+	}
+	cmd := exec.Command("/bin/sh", "-c", `echo "$LISTEN_PID $$ $LISTEN_FDS $LISTEN_FDNAMES $NOTIFY_SOCKET"`)
+	cmd.Env = []string{"PATH=/usr/bin:/bin", "LISTEN_FDS=5"}
+	if err := a.Apply(cmd); err != nil {
+		t.Fatal(err)
+		return
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	fields := strings.Fields(string(out))
+	if len(fields) != 5 {
+		t.Fatalf("unexpected output: %q", out)
+		return
+	}
+	if fields[0] != fields[1] {
+		t.Errorf("expected LISTEN_PID to be \"%s\", but got \"%s\"", fields[1], fields[0])
+	}
+	if expected, got := []string{"1", "web", "/run/systemd/notify"}, fields[2:]; !slices.Equal(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+func TestServiceExit(t *testing.T) {
+	for _, tc := range []struct {
+		result, code, status string
+		expect               ExitInfo
+		err                  bool
+	}{
+		{expect: ExitInfo{}},
+		{
+			result: "success", code: "exited", status: "0",
+			expect: ExitInfo{Result: ResultSuccess, Code: ExitCodeExited},
+		},
+		{
+			result: "exit-code", code: "exited", status: "3",
+			expect: ExitInfo{Result: ResultExitCode, Code: ExitCodeExited, Status: 3},
+		},
+		{
+			result: "watchdog", code: "dumped", status: "ABRT",
+			expect: ExitInfo{Result: ResultWatchdog, Code: ExitCodeDumped, Signal: syscall.SIGABRT},
+		},
+		{
+			result: "start-limit-hit",
+			expect: ExitInfo{Result: ResultStartLimitHit},
+		},
+		{result: "signal", code: "killed", status: "NOPE", err: true},
+		{result: "exit-code", code: "exited", status: "abc", err: true},
+	} {
+		t.Setenv("SERVICE_RESULT", tc.result)
+		t.Setenv("EXIT_CODE", tc.code)
+		t.Setenv("EXIT_STATUS", tc.status)
+
+		info, err := ServiceExit()
+		if tc.err {
+			if err == nil {
+				t.Errorf("%s: expected an error", tc.result)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tc.result, err)
+			continue
+		}
+		if info != tc.expect {
+			t.Errorf("%s: expected %#v, but got %#v", tc.result, tc.expect, info)
+		}
+	}
+
+	info := ExitInfo{Result: ResultSignal, Code: ExitCodeKilled, Signal: syscall.SIGTERM}
+	if expected, got := "signal (code=killed, signal=terminated)", info.String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestRemoteAddr(t *testing.T) {
+	t.Setenv("REMOTE_ADDR", "")
+	if addr, err := RemoteAddr(); err != nil || addr != nil {
+		t.Errorf("expected a nil address and no error, but got %v and %v", addr, err)
+	}
+
+	t.Setenv("REMOTE_ADDR", "2001:db8::1")
+	t.Setenv("REMOTE_PORT", "51234")
+	addr, err := RemoteAddr()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "[2001:db8::1]:51234", addr.String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	t.Setenv("REMOTE_PORT", "99999")
+	if _, err := RemoteAddr(); err == nil {
+		t.Error("expected an error for an invalid port")
+	}
+}
+
+func TestTriggeredBy(t *testing.T) {
+	t.Setenv("TRIGGER_UNIT", "backup.timer")
+	t.Setenv("TRIGGER_PATH", "")
+	t.Setenv("TRIGGER_TIMER_REALTIME_USEC", "1700000000000000")
+	t.Setenv("TRIGGER_TIMER_MONOTONIC_USEC", "5000000")
+
+	trigger, err := TriggeredBy()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !trigger.IsTimer() || trigger.IsPath() {
+		t.Errorf("expected trigger to be a timer, but got \"%s\"", trigger.Unit)
+	}
+	if expected, got := time.Unix(1700000000, 0), trigger.TimerRealtime; !expected.Equal(got) {
+		t.Errorf("expected realtime to be %s, but got %s", expected, got)
+	}
+	if expected, got := 5*time.Second, trigger.TimerMonotonic; expected != got {
+		t.Errorf("expected monotonic to be %s, but got %s", expected, got)
+	}
+
+	t.Setenv("TRIGGER_TIMER_REALTIME_USEC", "abc")
+	if _, err := TriggeredBy(); err == nil {
+		t.Error("expected an error for an invalid timestamp")
+	}
+}
+
+func TestMonitoredUnit(t *testing.T) {
+	t.Setenv("MONITOR_UNIT", "app.service")
+	t.Setenv("MONITOR_INVOCATION_ID", "8d3c2a1b4e5f60718293a4b5c6d7e8f9")
+	t.Setenv("MONITOR_SERVICE_RESULT", "oom-kill")
+	t.Setenv("MONITOR_EXIT_CODE", "killed")
+	t.Setenv("MONITOR_EXIT_STATUS", "KILL")
+
+	m, err := MonitoredUnit()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "app.service", m.Unit; expected != got {
+		t.Errorf("expected unit to be \"%s\", but got \"%s\"", expected, got)
+	}
+	if expected, got := "8d3c2a1b4e5f60718293a4b5c6d7e8f9", m.InvocationID.String(); expected != got {
+		t.Errorf("expected invocation id to be \"%s\", but got \"%s\"", expected, got)
+	}
+	expected := ExitInfo{Result: ResultOOMKill, Code: ExitCodeKilled, Signal: syscall.SIGKILL}
+	if m.Exit != expected {
+		t.Errorf("expected %#v, but got %#v", expected, m.Exit)
+	}
+}
+
+func TestCgroupUnit(t *testing.T) {
+	for _, tc := range []struct {
+		cgroup, unit, slice string
+		ok                  bool
+	}{
+		{cgroup: "/system.slice/nginx.service", unit: "nginx.service", slice: "system.slice", ok: true},
+		{cgroup: "/system.slice/system-getty.slice/getty@tty1.service", unit: "getty@tty1.service", slice: "system-getty.slice", ok: true},
+		{cgroup: "/system.slice/app.service/worker", unit: "app.service", slice: "system.slice", ok: true},
+		{cgroup: "/init.scope", unit: "init.scope", slice: "-.slice", ok: true},
+		{cgroup: "/user.slice/user-1000.slice/session-2.scope", unit: "session-2.scope", slice: "user-1000.slice", ok: true},
+		{cgroup: "/user.slice/user-1000.slice/user@1000.service/app.slice/foo.service", unit: "foo.service", slice: "app.slice", ok: true},
+		{cgroup: "/user.slice/user-1000.slice/user@1000.service", unit: "user@1000.service", slice: "user-1000.slice", ok: true},
+		{cgroup: "/"},
+	} {
+		unit, slice, ok := cgroupUnit(tc.cgroup)
+		if ok != tc.ok || unit != tc.unit || slice != tc.slice {
+			t.Errorf("%s: expected (%s, %s, %t), but got (%s, %s, %t)", tc.cgroup, tc.unit, tc.slice, tc.ok, unit, slice, ok)
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	t.Setenv("LISTEN_FDS", "2")
+	t.Setenv("CREDENTIALS_DIRECTORY", dir)
+	t.Setenv("FDSTORE", "16")
+	t.Setenv("NOTIFY_SOCKET", filepath.Join(dir, "missing.sock"))
+
+	c := Capabilities()
+	if expected, got := 2, c.Listeners; expected != got {
+		t.Errorf("expected %d listeners, but got %d", expected, got)
+	}
+	if !c.Credentials {
+		t.Error("expected credentials to be available")
+	}
+	if expected, got := 16, c.FDStore; expected != got {
+		t.Errorf("expected fdstore to be %d, but got %d", expected, got)
+	}
+	if c.Notify {
+		t.Error("expected notify to be unreachable")
+	}
+
+	// Ensure probing didn't modify the environment.
+	if os.Getenv("LISTEN_FDS") != "2" {
+		t.Error("expected LISTEN_FDS to be left as-is")
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := &Report{
+		Version:   "257.5-2-arch",
+		Scope:     ScopeSystem,
+		Unit:      "app.service",
+		Slice:     "system.slice",
+		ListenPID: "1234",
+		Listeners: []ListenFD{{FD: 3, Name: "web", Kind: "tcp6 listener"}},
+		Directories: map[string]string{
+			"STATE_DIRECTORY": "/var/lib/app",
+		},
+	}
+	r.addError("version", errors.New("oops"))
+
+	s := r.String()
+	for _, expected := range []string{
+		"systemd:         257.5-2-arch\n",
+		"scope:           system\n",
+		"listener:        fd=3 name=web kind=tcp6 listener\n",
+		"state:           /var/lib/app\n",
+		"error:           version: oops\n",
+	} {
+		if !strings.Contains(s, expected) {
+			t.Errorf("expected report to contain %q, but got:\n%s", expected, s)
+		}
+	}
+
+	// Ensure Doctor doesn't modify the environment.
+	t.Setenv("LISTEN_FDS", "0")
+	_ = Doctor()
+	if v := os.Getenv("LISTEN_FDS"); v != "0" {
+		t.Errorf("expected LISTEN_FDS to be left as-is, but got \"%s\"", v)
+	}
+}
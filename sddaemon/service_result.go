@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// signals maps the signal names used by systemd (without the `SIG` prefix) to
+// their [syscall.Signal].
+var signals = map[string]syscall.Signal{
+	"HUP":    syscall.SIGHUP,
+	"INT":    syscall.SIGINT,
+	"QUIT":   syscall.SIGQUIT,
+	"ILL":    syscall.SIGILL,
+	"TRAP":   syscall.SIGTRAP,
+	"ABRT":   syscall.SIGABRT,
+	"BUS":    syscall.SIGBUS,
+	"FPE":    syscall.SIGFPE,
+	"KILL":   syscall.SIGKILL,
+	"USR1":   syscall.SIGUSR1,
+	"SEGV":   syscall.SIGSEGV,
+	"USR2":   syscall.SIGUSR2,
+	"PIPE":   syscall.SIGPIPE,
+	"ALRM":   syscall.SIGALRM,
+	"TERM":   syscall.SIGTERM,
+	"STKFLT": syscall.SIGSTKFLT,
+	"CHLD":   syscall.SIGCHLD,
+	"CONT":   syscall.SIGCONT,
+	"STOP":   syscall.SIGSTOP,
+	"TSTP":   syscall.SIGTSTP,
+	"TTIN":   syscall.SIGTTIN,
+	"TTOU":   syscall.SIGTTOU,
+	"URG":    syscall.SIGURG,
+	"XCPU":   syscall.SIGXCPU,
+	"XFSZ":   syscall.SIGXFSZ,
+	"VTALRM": syscall.SIGVTALRM,
+	"PROF":   syscall.SIGPROF,
+	"WINCH":  syscall.SIGWINCH,
+	"IO":     syscall.SIGIO,
+	"PWR":    syscall.SIGPWR,
+	"SYS":    syscall.SIGSYS,
+}
+
+// parseSignal parses a signal name as used by systemd, with or without the
+// `SIG` prefix, or a signal number.
+func parseSignal(v string) (syscall.Signal, bool) {
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return syscall.Signal(n), true
+	}
+	if len(v) > 3 && v[:3] == "SIG" {
+		v = v[3:]
+	}
+	s, ok := signals[v]
+	return s, ok
+}
+
+// exitInfo parses an [ExitInfo] from the environment variables with the given
+// prefix, `prefix+"SERVICE_RESULT"`, `prefix+"EXIT_CODE"` and
+// `prefix+"EXIT_STATUS"`.
+func exitInfo(prefix string) (ExitInfo, error) {
+	result := os.Getenv(prefix + "SERVICE_RESULT")
+	if result == "" {
+		return ExitInfo{}, nil
+	}
+	info := ExitInfo{
+		Result: ServiceResult(result),
+		Code:   ExitCode(os.Getenv(prefix + "EXIT_CODE")),
+	}
+
+	status := os.Getenv(prefix + "EXIT_STATUS")
+	switch info.Code {
+	case "":
+	case ExitCodeExited:
+		n, err := strconv.Atoi(status)
+		if err != nil {
+			return ExitInfo{}, fmt.Errorf("sddaemon: unable to convert %sEXIT_STATUS to an integer: %w", prefix, err)
+		}
+		info.Status = n
+	case ExitCodeKilled, ExitCodeDumped:
+		s, ok := parseSignal(status)
+		if !ok {
+			return ExitInfo{}, fmt.Errorf("sddaemon: unknown signal in %sEXIT_STATUS (%s)", prefix, status)
+		}
+		info.Signal = s
+	default:
+		return ExitInfo{}, fmt.Errorf("sddaemon: unknown %sEXIT_CODE (%s)", prefix, info.Code)
+	}
+	return info, nil
+}
+
+// ServiceExit returns why the main process of the service exited, using
+// `SERVICE_RESULT`, `EXIT_CODE` and `EXIT_STATUS`. These environment variables
+// are only set for commands run by `ExecStop=` and `ExecStopPost=`.
+//
+// If `SERVICE_RESULT` is unset, a zero [ExitInfo] and an error of `nil` will be
+// returned.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24SERVICE_RESULT
+func ServiceExit() (ExitInfo, error) {
+	return exitInfo("")
+}
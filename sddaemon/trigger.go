@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+import (
+	"strings"
+	"time"
+)
+
+// Trigger describes the unit that triggered the activation of a service, for
+// services activated by a `.path` or `.timer` unit.
+type Trigger struct {
+	// Unit is the name of the unit that triggered the service, from
+	// `TRIGGER_UNIT`.
+	Unit string
+
+	// Path is the path that triggered a `.path` unit, from `TRIGGER_PATH`.
+	Path string
+
+	// TimerRealtime is the realtime clock timestamp of when a `.timer` unit
+	// elapsed, from `TRIGGER_TIMER_REALTIME_USEC`.
+	TimerRealtime time.Time
+
+	// TimerMonotonic is the monotonic clock timestamp of when a `.timer` unit
+	// elapsed, from `TRIGGER_TIMER_MONOTONIC_USEC`. This value is relative to
+	// the same clock used by `CLOCK_MONOTONIC`.
+	TimerMonotonic time.Duration
+}
+
+// IsPath reports whether the service was triggered by a `.path` unit.
+func (t Trigger) IsPath() bool {
+	return strings.HasSuffix(t.Unit, ".path")
+}
+
+// IsTimer reports whether the service was triggered by a `.timer` unit.
+func (t Trigger) IsTimer() bool {
+	return strings.HasSuffix(t.Unit, ".timer")
+}
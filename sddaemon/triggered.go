@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// TriggeredBy returns the unit that triggered the activation of the service,
+// using `TRIGGER_UNIT`, `TRIGGER_PATH`, `TRIGGER_TIMER_REALTIME_USEC` and
+// `TRIGGER_TIMER_MONOTONIC_USEC`.
+//
+// If `TRIGGER_UNIT` is unset, a zero [Trigger] and an error of `nil` will be
+// returned.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24TRIGGER_UNIT
+func TriggeredBy() (Trigger, error) {
+	unit := os.Getenv("TRIGGER_UNIT")
+	if unit == "" {
+		return Trigger{}, nil
+	}
+	t := Trigger{
+		Unit: unit,
+		Path: os.Getenv("TRIGGER_PATH"),
+	}
+
+	if v := os.Getenv("TRIGGER_TIMER_REALTIME_USEC"); v != "" {
+		usec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Trigger{}, fmt.Errorf("sddaemon: unable to convert TRIGGER_TIMER_REALTIME_USEC to an integer: %w", err)
+		}
+		t.TimerRealtime = time.UnixMicro(usec)
+	}
+	if v := os.Getenv("TRIGGER_TIMER_MONOTONIC_USEC"); v != "" {
+		usec, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Trigger{}, fmt.Errorf("sddaemon: unable to convert TRIGGER_TIMER_MONOTONIC_USEC to an integer: %w", err)
+		}
+		t.TimerMonotonic = time.Duration(usec) * time.Microsecond
+	}
+	return t, nil
+}
@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"fmt"
+	"strings"
+)
+
+// rootSlice is the name of the root slice, which all other slices are
+// children of.
+const rootSlice = "-.slice"
+
+// CurrentUnit returns the name of the unit and slice the application is running
+// in, determined by parsing the cgroup of the process from `/proc/self/cgroup`.
+//
+// For processes spawned by a user manager, the unit and slice within the user
+// manager will be returned, rather than the `user@$UID.service` unit of the user
+// manager itself.
+func CurrentUnit() (unit, slice string, err error) {
+	cgroup, err := ownCgroup()
+	if err != nil {
+		return "", "", err
+	}
+	unit, slice, ok := cgroupUnit(cgroup)
+	if !ok {
+		return "", "", fmt.Errorf("sddaemon: unable to find unit in cgroup (%s)", cgroup)
+	}
+	return unit, slice, nil
+}
+
+// cgroupUnit parses the unit and slice from a cgroup path.
+func cgroupUnit(cgroup string) (unit, slice string, ok bool) {
+	elems := strings.Split(strings.Trim(cgroup, "/"), "/")
+
+	// Skip to the inside of the innermost user manager, if any.
+	for i := len(elems) - 1; i >= 0; i-- {
+		if strings.HasPrefix(elems[i], "user@") && strings.HasSuffix(elems[i], ".service") {
+			if i == len(elems)-1 {
+				// The process is the user manager itself.
+				break
+			}
+			elems = elems[i+1:]
+			break
+		}
+	}
+
+	slice = rootSlice
+	for _, elem := range elems {
+		if strings.HasSuffix(elem, ".slice") {
+			slice = elem
+			continue
+		}
+		if isUnitName(elem) {
+			return elem, slice, true
+		}
+		break
+	}
+	return "", "", false
+}
+
+// unitSuffixes are the suffixes of unit types that may contain processes.
+var unitSuffixes = []string{".service", ".scope", ".socket", ".mount", ".swap"}
+
+// isUnitName reports whether v is the name of a unit that may contain processes.
+func isUnitName(v string) bool {
+	for _, suffix := range unitSuffixes {
+		if len(v) > len(suffix) && strings.HasSuffix(v, suffix) {
+			return true
+		}
+	}
+	return false
+}
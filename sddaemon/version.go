@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sddaemon
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// systemctlTimeout is the maximum amount of time to wait for `systemctl` when
+// detecting the version of systemd.
+const systemctlTimeout = 5 * time.Second
+
+// versionInfo holds the version of systemd.
+type versionInfo struct {
+	major int
+	full  string
+}
+
+// version holds a function that returns the version of systemd, used to
+// override the implementation during tests.
+var version = sync.OnceValues(func() (versionInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), systemctlTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "systemctl", "--version").Output()
+	if err != nil {
+		return versionInfo{}, fmt.Errorf("sddaemon: unable to run systemctl --version: %w", err)
+	}
+	v, err := parseVersion(out)
+	if err != nil {
+		return versionInfo{}, err
+	}
+	return v, nil
+})
+
+// Version returns the version of systemd installed on the system. The first
+// value is the major version number, such as `257`, the second value is the
+// full version string, such as `257.5-2-arch`.
+//
+// The version is detected by parsing the output of `systemctl --version`, the
+// result is cached for the lifetime of the process.
+func Version() (int, string, error) {
+	v, err := version()
+	if err != nil {
+		return 0, "", err
+	}
+	return v.major, v.full, nil
+}
+
+// parseVersion parses the output of `systemctl --version`.
+//
+// The first line of the output is in the format of `systemd 257 (257.5-2-arch)`,
+// older versions of systemd omit the full version in parentheses.
+func parseVersion(out []byte) (versionInfo, error) {
+	line, _, _ := bytes.Cut(out, []byte{'\n'})
+	fields := bytes.Fields(line)
+	if len(fields) < 2 || string(fields[0]) != "systemd" {
+		return versionInfo{}, fmt.Errorf("sddaemon: unable to parse systemd version: %q", line)
+	}
+
+	var v versionInfo
+	for _, c := range fields[1] {
+		if c < '0' || c > '9' {
+			return versionInfo{}, fmt.Errorf("sddaemon: unable to parse systemd version: %q", line)
+		}
+		v.major = v.major*10 + int(c-'0')
+	}
+	if v.major < 1 {
+		return versionInfo{}, fmt.Errorf("sddaemon: unable to parse systemd version: %q", line)
+	}
+
+	v.full = string(fields[1])
+	if len(fields) > 2 {
+		full := bytes.TrimSuffix(bytes.TrimPrefix(fields[2], []byte{'('}), []byte{')'})
+		if len(full) > 0 {
+			v.full = string(full)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sddaemon
+
+// Virtualization is a virtualization or container technology, named the same
+// as the values returned by [systemd-detect-virt(1)].
+//
+// [systemd-detect-virt(1)]: https://www.freedesktop.org/software/systemd/man/latest/systemd-detect-virt.html
+type Virtualization string
+
+// Virtual machines.
+const (
+	VirtQEMU      Virtualization = "qemu"
+	VirtKVM       Virtualization = "kvm"
+	VirtAmazon    Virtualization = "amazon"
+	VirtZVM       Virtualization = "zvm"
+	VirtVMware    Virtualization = "vmware"
+	VirtMicrosoft Virtualization = "microsoft"
+	VirtOracle    Virtualization = "oracle"
+	VirtPowerVM   Virtualization = "powervm"
+	VirtXen       Virtualization = "xen"
+	VirtBochs     Virtualization = "bochs"
+	VirtUML       Virtualization = "uml"
+	VirtParallels Virtualization = "parallels"
+	VirtBhyve     Virtualization = "bhyve"
+	VirtQNX       Virtualization = "qnx"
+	VirtACRN      Virtualization = "acrn"
+	VirtApple     Virtualization = "apple"
+	VirtSRE       Virtualization = "sre"
+	VirtGoogle    Virtualization = "google"
+	VirtVMOther   Virtualization = "vm-other"
+)
+
+// Containers.
+const (
+	ContainerOpenVZ        Virtualization = "openvz"
+	ContainerLXC           Virtualization = "lxc"
+	ContainerLXCLibvirt    Virtualization = "lxc-libvirt"
+	ContainerSystemdNspawn Virtualization = "systemd-nspawn"
+	ContainerDocker        Virtualization = "docker"
+	ContainerPodman        Virtualization = "podman"
+	ContainerRkt           Virtualization = "rkt"
+	ContainerWSL           Virtualization = "wsl"
+	ContainerProot         Virtualization = "proot"
+	ContainerPouch         Virtualization = "pouch"
+	ContainerOther         Virtualization = "container-other"
+)
+
+// VirtNone is returned when no virtualization or container was detected.
+const VirtNone Virtualization = "none"
+
+// IsVM reports whether v is a virtual machine.
+func (v Virtualization) IsVM() bool {
+	switch v {
+	case VirtQEMU, VirtKVM, VirtAmazon, VirtZVM, VirtVMware, VirtMicrosoft,
+		VirtOracle, VirtPowerVM, VirtXen, VirtBochs, VirtUML, VirtParallels,
+		VirtBhyve, VirtQNX, VirtACRN, VirtApple, VirtSRE, VirtGoogle, VirtVMOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsContainer reports whether v is a container.
+func (v Virtualization) IsContainer() bool {
+	switch v {
+	case ContainerOpenVZ, ContainerLXC, ContainerLXCLibvirt, ContainerSystemdNspawn,
+		ContainerDocker, ContainerPodman, ContainerRkt, ContainerWSL, ContainerProot,
+		ContainerPouch, ContainerOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// String returns the name of the virtualization technology.
+func (v Virtualization) String() string {
+	if v == "" {
+		return string(VirtNone)
+	}
+	return string(v)
+}
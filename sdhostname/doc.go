@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdhostname provides a client for systemd-hostnamed, which manages
+// the hostname of the system along with metadata describing the machine, the
+// same as `hostnamectl`.
+//
+// Changing the hostname through hostnamed instead of editing `/etc/hostname`
+// keeps the transient hostname of the kernel, the static hostname, and any
+// programs watching hostnamed in sync.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.hostname1.html
+package sdhostname
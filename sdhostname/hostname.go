@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdhostname
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/matthewpi/sd/sdid128"
+)
+
+// ErrAccessDenied is returned when the caller is not authorized to change the
+// hostname or metadata of the machine.
+var ErrAccessDenied = errors.New("sdhostname: access denied")
+
+// ErrInvalid is returned when a hostname or metadata value is rejected by
+// hostnamed, such as an invalid hostname or an unknown chassis.
+var ErrInvalid = errors.New("sdhostname: invalid value")
+
+// Error is an error returned by hostnamed.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.DBus.Error.InvalidArgs`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdhostname: " + e.Name
+	}
+	return "sdhostname: " + e.Message
+}
+
+// Is allows the error to be matched against the sentinel errors of this
+// package using [errors.Is].
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrAccessDenied:
+		switch e.Name {
+		case "org.freedesktop.DBus.Error.AccessDenied", "org.freedesktop.DBus.Error.InteractiveAuthorizationRequired":
+			return true
+		}
+		return false
+	case ErrInvalid:
+		return e.Name == "org.freedesktop.DBus.Error.InvalidArgs"
+	default:
+		return false
+	}
+}
+
+// Chassis is the type of the machine.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/machine-info.html#CHASSIS=
+type Chassis string
+
+const (
+	ChassisDesktop     Chassis = "desktop"
+	ChassisLaptop      Chassis = "laptop"
+	ChassisConvertible Chassis = "convertible"
+	ChassisServer      Chassis = "server"
+	ChassisTablet      Chassis = "tablet"
+	ChassisHandset     Chassis = "handset"
+	ChassisWatch       Chassis = "watch"
+	ChassisEmbedded    Chassis = "embedded"
+	ChassisVM          Chassis = "vm"
+	ChassisContainer   Chassis = "container"
+)
+
+// HostnameSource is where the current hostname of the system came from.
+type HostnameSource string
+
+const (
+	HostnameSourceStatic    HostnameSource = "static"
+	HostnameSourceTransient HostnameSource = "transient"
+	HostnameSourceDefault   HostnameSource = "default"
+)
+
+// Info is the hostname and metadata of the machine, as returned by
+// [Conn.Info].
+type Info struct {
+	// Hostname is the current hostname of the kernel.
+	Hostname string
+	// StaticHostname is the hostname configured in `/etc/hostname`.
+	StaticHostname string
+	// PrettyHostname is the free-form hostname configured in
+	// `/etc/machine-info`, which may contain any characters.
+	PrettyHostname string
+	// DefaultHostname is the hostname used when no hostname is configured.
+	DefaultHostname string
+	// HostnameSource is where [Info.Hostname] came from.
+	HostnameSource HostnameSource
+
+	// IconName is the name of the icon representing the machine.
+	IconName string
+	// Chassis is the type of the machine, either configured or detected.
+	Chassis Chassis
+	// Deployment is the environment the machine is deployed in, such as
+	// `production` or `staging`.
+	Deployment string
+	// Location is the physical location of the machine, such as
+	// `Berlin, Rack 12`.
+	Location string
+
+	KernelName    string
+	KernelRelease string
+	KernelVersion string
+
+	OperatingSystemPrettyName string
+	OperatingSystemCPEName    string
+	OperatingSystemHomeURL    string
+
+	HardwareVendor  string
+	HardwareModel   string
+	FirmwareVersion string
+}
+
+// Description is the description of the machine returned by
+// [Conn.Describe], which includes the identifiers of the machine that are not
+// available as properties of hostnamed.
+type Description struct {
+	Info
+
+	// MachineID is the ID of the machine, see [sdid128.MachineID].
+	MachineID sdid128.ID128
+	// BootID is the ID of the current boot, see [sdid128.BootID].
+	BootID sdid128.ID128
+	// ProductUUID is the UUID of the hardware from the firmware, which is only
+	// available to privileged callers.
+	ProductUUID string
+	// HardwareSerial is the serial number of the hardware, which is only
+	// available to privileged callers.
+	HardwareSerial string
+
+	// Raw is the JSON returned by hostnamed, including any fields not parsed
+	// by this package.
+	Raw json.RawMessage
+}
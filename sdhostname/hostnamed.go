@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdhostname
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+const (
+	// destination is the bus name of hostnamed.
+	destination = "org.freedesktop.hostname1"
+
+	// objectPath is the object path of hostnamed.
+	objectPath dbus.ObjectPath = "/org/freedesktop/hostname1"
+
+	// iface is the interface of hostnamed.
+	iface = "org.freedesktop.hostname1"
+)
+
+// Conn is a connection to systemd-hostnamed on the system bus.
+type Conn struct {
+	conn *dbus.Conn
+}
+
+// New connects to hostnamed on the system bus.
+func New(ctx context.Context) (*Conn, error) {
+	c, err := dbus.SystemBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdhostname: unable to connect to bus: %w", err)
+	}
+	return newConn(c), nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection.
+func newConn(c *dbus.Conn) *Conn {
+	return &Conn{conn: c}
+}
+
+// Close closes the connection to hostnamed.
+func (h *Conn) Close() error {
+	return h.conn.Close()
+}
+
+// call calls a method on hostnamed, returning the body of the reply.
+func (h *Conn) call(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	body, err := h.conn.Call(ctx, destination, objectPath, iface, method, sig, args...)
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return nil, &Error{Name: e.Name, Message: e.Message()}
+	}
+	return body, err
+}
+
+// Info returns the hostname and metadata of the machine, the same as
+// `hostnamectl status`.
+func (h *Conn) Info(ctx context.Context) (*Info, error) {
+	props, err := h.conn.GetAllProperties(ctx, destination, objectPath, iface)
+	if err != nil {
+		var e *dbus.Error
+		if errors.As(err, &e) {
+			err = &Error{Name: e.Name, Message: e.Message()}
+		}
+		return nil, fmt.Errorf("sdhostname: unable to get properties: %w", err)
+	}
+	str := func(name string) string {
+		s, _ := props[name].Value.(string)
+		return s
+	}
+	return &Info{
+		Hostname:                  str("Hostname"),
+		StaticHostname:            str("StaticHostname"),
+		PrettyHostname:            str("PrettyHostname"),
+		DefaultHostname:           str("DefaultHostname"),
+		HostnameSource:            HostnameSource(str("HostnameSource")),
+		IconName:                  str("IconName"),
+		Chassis:                   Chassis(str("Chassis")),
+		Deployment:                str("Deployment"),
+		Location:                  str("Location"),
+		KernelName:                str("KernelName"),
+		KernelRelease:             str("KernelRelease"),
+		KernelVersion:             str("KernelVersion"),
+		OperatingSystemPrettyName: str("OperatingSystemPrettyName"),
+		OperatingSystemCPEName:    str("OperatingSystemCPEName"),
+		OperatingSystemHomeURL:    str("OperatingSystemHomeURL"),
+		HardwareVendor:            str("HardwareVendor"),
+		HardwareModel:             str("HardwareModel"),
+		FirmwareVersion:           str("FirmwareVersion"),
+	}, nil
+}
+
+// Describe returns the description of the machine as JSON, the same as
+// `hostnamectl --json=short`.
+func (h *Conn) Describe(ctx context.Context) (*Description, error) {
+	body, err := h.call(ctx, "Describe", "")
+	if err != nil {
+		return nil, fmt.Errorf("sdhostname: unable to describe machine: %w", err)
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdhostname: unable to describe machine: unexpected reply of %d values", len(body))
+	}
+	s, ok := body[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("sdhostname: unable to describe machine: unexpected reply of type %T", body[0])
+	}
+
+	var v struct {
+		Info
+		MachineID      string
+		BootID         string
+		ProductUUID    string
+		HardwareSerial string
+	}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, fmt.Errorf("sdhostname: unable to describe machine: %w", err)
+	}
+	d := &Description{
+		Info:           v.Info,
+		ProductUUID:    v.ProductUUID,
+		HardwareSerial: v.HardwareSerial,
+		Raw:            json.RawMessage(s),
+	}
+	// The IDs are missing if they cannot be determined, such as in a
+	// container without a machine ID.
+	d.MachineID, _ = sdid128.Parse(v.MachineID)
+	d.BootID, _ = sdid128.Parse(v.BootID)
+	return d, nil
+}
+
+// set calls a setter of hostnamed, without allowing interactive
+// authorization.
+func (h *Conn) set(ctx context.Context, method, name, value string) error {
+	if _, err := h.call(ctx, method, "sb", value, false); err != nil {
+		return fmt.Errorf("sdhostname: unable to set %s: %w", name, err)
+	}
+	return nil
+}
+
+// SetHostname sets the transient hostname of the kernel, which is replaced by
+// the static hostname on the next boot. An empty hostname resets it to the
+// static or default hostname.
+func (h *Conn) SetHostname(ctx context.Context, hostname string) error {
+	return h.set(ctx, "SetHostname", "hostname", hostname)
+}
+
+// SetStaticHostname sets the hostname stored in `/etc/hostname`, which also
+// changes the transient hostname. An empty hostname removes the static
+// hostname.
+func (h *Conn) SetStaticHostname(ctx context.Context, hostname string) error {
+	return h.set(ctx, "SetStaticHostname", "static hostname", hostname)
+}
+
+// SetPrettyHostname sets the free-form hostname stored in `/etc/machine-info`.
+// An empty hostname removes the pretty hostname.
+func (h *Conn) SetPrettyHostname(ctx context.Context, hostname string) error {
+	return h.set(ctx, "SetPrettyHostname", "pretty hostname", hostname)
+}
+
+// SetIconName sets the name of the icon representing the machine. An empty
+// name resets it to the icon derived from the chassis.
+func (h *Conn) SetIconName(ctx context.Context, name string) error {
+	return h.set(ctx, "SetIconName", "icon name", name)
+}
+
+// SetChassis sets the type of the machine. An empty chassis resets it to the
+// detected chassis.
+func (h *Conn) SetChassis(ctx context.Context, chassis Chassis) error {
+	return h.set(ctx, "SetChassis", "chassis", string(chassis))
+}
+
+// SetDeployment sets the environment the machine is deployed in, such as
+// `production`. An empty deployment removes it.
+func (h *Conn) SetDeployment(ctx context.Context, deployment string) error {
+	return h.set(ctx, "SetDeployment", "deployment", deployment)
+}
+
+// SetLocation sets the physical location of the machine. An empty location
+// removes it.
+func (h *Conn) SetLocation(ctx context.Context, location string) error {
+	return h.set(ctx, "SetLocation", "location", location)
+}
@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdhostname
+
+import (
+	"context"
+	"errors"
+)
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) Info(context.Context) (*Info, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Describe(context.Context) (*Description, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) SetHostname(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetStaticHostname(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetPrettyHostname(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetIconName(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetChassis(context.Context, Chassis) error { return errors.ErrUnsupported }
+
+func (*Conn) SetDeployment(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLocation(context.Context, string) error { return errors.ErrUnsupported }
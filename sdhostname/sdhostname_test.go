@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdhostname
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+func newTestConn(t *testing.T) (*dbustest.Bus, *Conn) {
+	t.Helper()
+	bus, c := dbustest.New(t)
+	return bus, newConn(c)
+}
+
+func TestHostname(t *testing.T) {
+	bus, h := newTestConn(t)
+
+	var (
+		mu    sync.Mutex
+		props = map[string]dbus.Variant{
+			"Hostname":       dbus.MakeVariant("localhost"),
+			"StaticHostname": dbus.MakeVariant(""),
+			"HostnameSource": dbus.MakeVariant("default"),
+			"Chassis":        dbus.MakeVariant("vm"),
+		}
+	)
+	setter := func(prop string) dbus.MethodHandler {
+		return func(m *dbus.Message) (dbus.Signature, []any, error) {
+			if m.Body[1] != false {
+				t.Errorf("expected interactive authorization to be disabled")
+			}
+			value := m.Body[0].(string)
+			if value == "-invalid-" {
+				return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.InvalidArgs", Body: []any{"Invalid hostname '-invalid-'"}}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			props[prop] = dbus.MakeVariant(value)
+			if prop == "StaticHostname" {
+				props["Hostname"] = dbus.MakeVariant(value)
+				props["HostnameSource"] = dbus.MakeVariant("static")
+			}
+			return "", nil, nil
+		}
+	}
+	bus.Handle(iface, "SetStaticHostname", setter("StaticHostname"))
+	bus.Handle(iface, "SetPrettyHostname", setter("PrettyHostname"))
+	bus.Handle(iface, "SetDeployment", setter("Deployment"))
+	bus.Handle(iface, "SetLocation", setter("Location"))
+	bus.Handle(iface, "SetChassis", setter("Chassis"))
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(*dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return "a{sv}", []any{props}, nil
+	})
+
+	ctx := context.Background()
+	for _, err := range []error{
+		h.SetStaticHostname(ctx, "web-1"),
+		h.SetPrettyHostname(ctx, "Web Server #1"),
+		h.SetDeployment(ctx, "production"),
+		h.SetLocation(ctx, "Berlin, Rack 12"),
+		h.SetChassis(ctx, ChassisServer),
+	} {
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	info, err := h.Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := Info{
+		Hostname:       "web-1",
+		StaticHostname: "web-1",
+		PrettyHostname: "Web Server #1",
+		HostnameSource: HostnameSourceStatic,
+		Chassis:        ChassisServer,
+		Deployment:     "production",
+		Location:       "Berlin, Rack 12",
+	}
+	if *info != expected {
+		t.Errorf("expected %+v, but got %+v", expected, *info)
+	}
+
+	err = h.SetStaticHostname(ctx, "-invalid-")
+	if !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrInvalid, err)
+	}
+	if errors.Is(err, ErrAccessDenied) {
+		t.Errorf("expected \"%v\" not to match \"%v\"", err, ErrAccessDenied)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	bus, h := newTestConn(t)
+	const raw = `{"Hostname":"web-1","StaticHostname":"web-1","PrettyHostname":null,"DefaultHostname":"localhost",` +
+		`"HostnameSource":"static","IconName":"computer-server","Chassis":"server","Deployment":"production","Location":null,` +
+		`"KernelName":"Linux","KernelRelease":"6.12.0","OperatingSystemPrettyName":"Fedora Linux 42",` +
+		`"HardwareVendor":"QEMU","HardwareModel":"Standard PC","HardwareSerial":null,"ProductUUID":null,` +
+		`"MachineID":"d3b07384d1134ec49f5d0d2e9a5c6e3c","BootID":"2e4a1c1bb3a04b5e8bc1e2a90f6d5f01"}`
+	bus.Handle(iface, "Describe", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "s", []any{raw}, nil
+	})
+
+	d, err := h.Describe(context.Background())
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := Info{
+		Hostname:                  "web-1",
+		StaticHostname:            "web-1",
+		DefaultHostname:           "localhost",
+		HostnameSource:            HostnameSourceStatic,
+		IconName:                  "computer-server",
+		Chassis:                   ChassisServer,
+		Deployment:                "production",
+		KernelName:                "Linux",
+		KernelRelease:             "6.12.0",
+		OperatingSystemPrettyName: "Fedora Linux 42",
+		HardwareVendor:            "QEMU",
+		HardwareModel:             "Standard PC",
+	}
+	if d.Info != expected {
+		t.Errorf("expected %+v, but got %+v", expected, d.Info)
+	}
+	if v := sdid128.MustParse("d3b07384d1134ec49f5d0d2e9a5c6e3c"); d.MachineID != v {
+		t.Errorf("expected \"%s\", but got \"%s\"", v, d.MachineID)
+	}
+	if d.ProductUUID != "" {
+		t.Errorf("expected no product UUID, but got \"%s\"", d.ProductUUID)
+	}
+	if string(d.Raw) != raw {
+		t.Errorf("expected \"%s\", but got \"%s\"", raw, d.Raw)
+	}
+}
@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdid128
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+)
+
+// AppSpecific derives an application-specific ID from base, such as the machine
+// ID, and app, an ID identifying the application.
+//
+// The ID is derived using HMAC-SHA256 keyed by base over app, truncated to 128
+// bits and turned into a version 4 UUID, the same as
+// [sd_id128_get_machine_app_specific]. The derived ID is stable for the same
+// base and app, but does not leak base.
+//
+// [sd_id128_get_machine_app_specific]: https://www.freedesktop.org/software/systemd/man/latest/sd_id128_get_machine.html
+func AppSpecific(base, app ID128) ID128 {
+	h := hmac.New(sha256.New, base[:])
+	_, _ = h.Write(app[:])
+
+	var id ID128
+	copy(id[:], h.Sum(nil))
+	return MakeV4UUID(id)
+}
+
+// AppSpecificMachineID returns an application-specific ID derived from the
+// machine ID using [AppSpecific].
+//
+// Applications should use this instead of [MachineID] when they need a stable
+// per-machine identifier that is exposed to external systems, as the machine
+// ID should be considered confidential.
+func AppSpecificMachineID(app ID128) (ID128, error) {
+	id, err := MachineID()
+	if err != nil {
+		return Null, err
+	}
+	return AppSpecific(id, app), nil
+}
+
+// AppSpecificBootID returns an application-specific ID derived from the boot ID
+// using [AppSpecific].
+func AppSpecificBootID(app ID128) (ID128, error) {
+	id, err := BootID()
+	if err != nil {
+		return Null, err
+	}
+	return AppSpecific(id, app), nil
+}
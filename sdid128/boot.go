@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdid128
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+)
+
+// bootID holds a function that returns the boot ID, cached for the lifetime of
+// the process.
+var bootID = sync.OnceValues(readBootID)
+
+// readBootID reads the boot ID from `/proc/sys/kernel/random/boot_id`.
+func readBootID() (ID128, error) {
+	b, err := readFile("proc/sys/kernel/random/boot_id")
+	if err != nil {
+		return Null, fmt.Errorf("sdid128: unable to read boot id: %w", err)
+	}
+	id, err := ParseBytes(bytes.TrimSpace(b))
+	if err != nil {
+		return Null, fmt.Errorf("sdid128: unable to parse boot id: %w", err)
+	}
+	return id, nil
+}
+
+// BootID returns the ID of the current boot, which is randomly generated by the
+// kernel every time the system boots. The result is cached for the lifetime of
+// the process.
+//
+// Comparing a previously stored boot ID against the current one can be used to
+// detect if the machine has rebooted since.
+func BootID() (ID128, error) {
+	return bootID()
+}
@@ -0,0 +1,11 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdid128 provides a 128-bit ID type matching the semantics of
+// [sd-id128], used by systemd for machine IDs, boot IDs, invocation IDs and
+// journal message IDs.
+//
+// See the [sd-id128] docs for more details.
+//
+// [sd-id128]: https://www.freedesktop.org/software/systemd/man/latest/sd-id128.html
+package sdid128
@@ -0,0 +1,191 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdid128
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+)
+
+// ErrInvalid is returned when parsing an invalid 128-bit ID.
+var ErrInvalid = errors.New("sdid128: invalid id")
+
+// ErrNoMachineID is returned by [MachineID] when no machine ID is available.
+var ErrNoMachineID = errors.New("sdid128: no machine id available")
+
+// ID128 is a 128-bit ID.
+//
+// IDs are formatted as 32 lowercase hexadecimal characters by default, the same
+// as systemd, but may also be formatted as a UUID using [ID128.UUID]. Both
+// formats are accepted when parsing.
+type ID128 [16]byte
+
+// Null is the null ID, with all bits set to zero.
+var Null = ID128{}
+
+// AllF is the ID with all bits set to one, used by systemd as a marker for an
+// invalid or unknown ID.
+var AllF = ID128{
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+	0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+}
+
+const (
+	// plainLen is the length of a formatted ID without dashes.
+	plainLen = 32
+	// uuidLen is the length of a formatted ID in the UUID format.
+	uuidLen = 36
+	// urnPrefix is the prefix of a UUID formatted as a URN.
+	urnPrefix = "urn:uuid:"
+)
+
+// Parse parses an ID in either the plain format of 32 hexadecimal characters,
+// or the UUID format of 32 hexadecimal characters separated by dashes (such as
+// `d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c`).
+//
+// For compatibility with `github.com/google/uuid`, the UUID format may also be
+// wrapped in braces (`{...}`) or prefixed with `urn:uuid:`.
+func Parse(s string) (ID128, error) {
+	return ParseBytes([]byte(s))
+}
+
+// ParseBytes is like [Parse] except that it takes a byte-slice instead of a
+// string.
+func ParseBytes(b []byte) (ID128, error) {
+	switch {
+	case len(b) == uuidLen+2 && b[0] == '{' && b[len(b)-1] == '}':
+		b = b[1 : len(b)-1]
+	case len(b) == uuidLen+len(urnPrefix) && bytes.EqualFold(b[:len(urnPrefix)], []byte(urnPrefix)):
+		b = b[len(urnPrefix):]
+	}
+
+	var id ID128
+	switch len(b) {
+	case plainLen:
+		if _, err := hex.Decode(id[:], b); err != nil {
+			return Null, ErrInvalid
+		}
+	case uuidLen:
+		if b[8] != '-' || b[13] != '-' || b[18] != '-' || b[23] != '-' {
+			return Null, ErrInvalid
+		}
+		var plain [plainLen]byte
+		n := copy(plain[:], b[0:8])
+		n += copy(plain[n:], b[9:13])
+		n += copy(plain[n:], b[14:18])
+		n += copy(plain[n:], b[19:23])
+		copy(plain[n:], b[24:36])
+		if _, err := hex.Decode(id[:], plain[:]); err != nil {
+			return Null, ErrInvalid
+		}
+	default:
+		return Null, ErrInvalid
+	}
+	return id, nil
+}
+
+// MustParse is like [Parse] except that it panics if the ID is invalid. It is
+// intended for use with constant IDs, such as journal message IDs.
+func MustParse(s string) ID128 {
+	id, err := Parse(s)
+	if err != nil {
+		panic(`sdid128: Parse(` + s + `): ` + err.Error())
+	}
+	return id
+}
+
+// String returns the ID formatted as 32 lowercase hexadecimal characters.
+func (id ID128) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// UUID returns the ID formatted as a UUID, 32 lowercase hexadecimal characters
+// separated by dashes, such as `d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c`.
+func (id ID128) UUID() string {
+	var b [uuidLen]byte
+	hex.Encode(b[0:8], id[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], id[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], id[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], id[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], id[10:])
+	return string(b[:])
+}
+
+// URN returns the ID formatted as a UUID URN, such as
+// `urn:uuid:d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c`.
+func (id ID128) URN() string {
+	return urnPrefix + id.UUID()
+}
+
+// FromBytes returns the ID contained in b, which must be exactly 16 bytes.
+func FromBytes(b []byte) (ID128, error) {
+	if len(b) != len(ID128{}) {
+		return Null, ErrInvalid
+	}
+	return ID128(b), nil
+}
+
+// Bytes returns the ID as a byte-slice.
+func (id ID128) Bytes() []byte {
+	return id[:]
+}
+
+// IsNull reports whether all bits of the ID are zero.
+func (id ID128) IsNull() bool {
+	return id == Null
+}
+
+// IsAllF reports whether all bits of the ID are one.
+func (id ID128) IsAllF() bool {
+	return id == AllF
+}
+
+// Equal reports whether id and other are the same ID.
+func (id ID128) Equal(other ID128) bool {
+	return id == other
+}
+
+// Compare compares two IDs byte-wise, returning `-1` if id is less than other,
+// `0` if they are equal, and `1` if id is greater than other.
+func (id ID128) Compare(other ID128) int {
+	return bytes.Compare(id[:], other[:])
+}
+
+// MarshalText implements [encoding.TextMarshaler], using the plain format.
+func (id ID128) MarshalText() ([]byte, error) {
+	b := make([]byte, plainLen)
+	hex.Encode(b, id[:])
+	return b, nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], accepting both the
+// plain and UUID formats.
+func (id *ID128) UnmarshalText(text []byte) error {
+	v, err := ParseBytes(text)
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler].
+func (id ID128) MarshalBinary() ([]byte, error) {
+	return id[:], nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (id *ID128) UnmarshalBinary(data []byte) error {
+	v, err := FromBytes(data)
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdid128
+
+import "os"
+
+// InvocationID returns the invocation ID of the unit the application was
+// started by, from `INVOCATION_ID`. systemd generates a new invocation ID each
+// time a unit is started, so it may be used to correlate logs and telemetry
+// from a single run of a service.
+//
+// The invocation ID must be in the plain format of 32 hexadecimal characters
+// and must not be [Null]. If `INVOCATION_ID` is unset or invalid, [Null] and
+// `false` will be returned.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#%24INVOCATION_ID
+func InvocationID() (ID128, bool) {
+	v := os.Getenv("INVOCATION_ID")
+	if len(v) != plainLen {
+		return Null, false
+	}
+	id, err := Parse(v)
+	if err != nil || id.IsNull() {
+		return Null, false
+	}
+	return id, true
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdid128
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rootDir is the directory used to resolve all the paths read while getting
+// IDs, used to override the implementation during tests.
+var rootDir = "/"
+
+// readFile reads a file relative to [rootDir].
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(rootDir, name))
+}
+
+// machineID holds a function that returns the machine ID, cached for the
+// lifetime of the process.
+var machineID = sync.OnceValues(readMachineID)
+
+// ephemeralMachineID holds a function that returns a random ID generated once
+// per process.
+var ephemeralMachineID = sync.OnceValue(func() ID128 {
+	id, _ := random()
+	return id
+})
+
+// readMachineID reads the machine ID.
+//
+// `/etc/machine-id` is read first, followed by `/run/machine-id` which is used
+// by systemd when `/etc` is read-only. If neither exist, such as in containers
+// that don't provide a machine ID, the `container_uuid` environment variable
+// set by container managers (such as systemd-nspawn) is used, first from our
+// environment and then from the environment of PID 1.
+func readMachineID() (ID128, error) {
+	for _, name := range []string{"etc/machine-id", "run/machine-id"} {
+		b, err := readFile(name)
+		if err != nil {
+			continue
+		}
+		b = bytes.TrimSpace(b)
+		if len(b) != plainLen {
+			// `/etc/machine-id` may contain `uninitialized` during first boot.
+			continue
+		}
+		if id, err := ParseBytes(b); err == nil && !id.IsNull() {
+			return id, nil
+		}
+	}
+
+	if id, err := Parse(os.Getenv("container_uuid")); err == nil && !id.IsNull() {
+		return id, nil
+	}
+	if b, err := readFile("proc/1/environ"); err == nil {
+		for kv := range bytes.SplitSeq(b, []byte{0}) {
+			if v, ok := bytes.CutPrefix(kv, []byte("container_uuid=")); ok {
+				if id, err := ParseBytes(v); err == nil && !id.IsNull() {
+					return id, nil
+				}
+			}
+		}
+	}
+
+	return Null, ErrNoMachineID
+}
+
+// MachineID returns the ID of the local machine, see [machine-id(5)].
+//
+// In addition to `/etc/machine-id`, `/run/machine-id` and the `container_uuid`
+// provided by container managers are used as fallbacks. The result is cached
+// for the lifetime of the process. If no machine ID is available,
+// [ErrNoMachineID] will be returned.
+//
+// [machine-id(5)]: https://www.freedesktop.org/software/systemd/man/latest/machine-id.html
+func MachineID() (ID128, error) {
+	return machineID()
+}
+
+// MachineIDOrEphemeral is like [MachineID] except that if no machine ID is
+// available, a random ID is generated instead. The random ID is generated once
+// and is the same for the lifetime of the process, ephemeral reports whether it
+// was used.
+func MachineIDOrEphemeral() (id ID128, ephemeral bool) {
+	id, err := MachineID()
+	if err == nil {
+		return id, false
+	}
+	return ephemeralMachineID(), true
+}
@@ -0,0 +1,9 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdid128
+
+func MachineID() (ID128, error)                        { return Null, ErrNoMachineID }
+func MachineIDOrEphemeral() (id ID128, ephemeral bool) { return Null, true }
@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdid128
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadMachineID(t *testing.T) {
+	defer func(v string) { rootDir = v }(rootDir)
+	t.Setenv("container_uuid", "")
+
+	const raw = "d3b07384d1134ec49f5d0d2e9a5c6e3c"
+	for _, tc := range []struct {
+		name  string
+		files map[string]string
+		env   string
+		err   error
+	}{
+		{name: "etc", files: map[string]string{"etc/machine-id": raw + "\n"}},
+		{name: "uninitialized", files: map[string]string{"etc/machine-id": "uninitialized\n", "run/machine-id": raw + "\n"}},
+		{name: "pid1 environ", files: map[string]string{"proc/1/environ": "container=systemd-nspawn\x00container_uuid=d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c\x00"}},
+		{name: "env", env: "d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c"},
+		{name: "missing", err: ErrNoMachineID},
+	} {
+		rootDir = t.TempDir()
+		t.Setenv("container_uuid", tc.env)
+		for name, data := range tc.files {
+			p := filepath.Join(rootDir, name)
+			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		id, err := readMachineID()
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%s: expected error %v, but got %v", tc.name, tc.err, err)
+			continue
+		}
+		if tc.err == nil && id.String() != raw {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", tc.name, raw, id)
+		}
+	}
+}
+
+func TestBootID(t *testing.T) {
+	defer func(v string) { rootDir = v }(rootDir)
+
+	rootDir = t.TempDir()
+	if _, err := readBootID(); err == nil {
+		t.Error("expected an error when boot_id is missing")
+	}
+
+	p := filepath.Join(rootDir, "proc/sys/kernel/random/boot_id")
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte("d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c\n"), 0o444); err != nil {
+		t.Fatal(err)
+	}
+	id, err := readBootID()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "d3b07384d1134ec49f5d0d2e9a5c6e3c", id.String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestInvocationID(t *testing.T) {
+	for _, tc := range []struct {
+		value string
+		ok    bool
+	}{
+		{value: "8d3c2a1b4e5f60718293a4b5c6d7e8f9", ok: true},
+		{value: ""},
+		{value: "8d3c2a1b-4e5f-6071-8293-a4b5c6d7e8f9"},
+		{value: "00000000000000000000000000000000"},
+		{value: "garbage"},
+	} {
+		t.Setenv("INVOCATION_ID", tc.value)
+		id, ok := InvocationID()
+		if ok != tc.ok {
+			t.Errorf("%q: expected %t, but got %t", tc.value, tc.ok, ok)
+			continue
+		}
+		if ok && id.String() != tc.value {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.value, tc.value, id)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdid128
+
+import "crypto/rand"
+
+// random generates a random ID using [crypto/rand].
+func random() (ID128, error) {
+	var id ID128
+	if _, err := rand.Read(id[:]); err != nil {
+		return Null, err
+	}
+	return id, nil
+}
+
+// Random generates a cryptographically random ID, formatted as a version 4
+// UUID, the same as [sd_id128_randomize].
+//
+// The returned ID is suitable for use as both a journal message ID and a
+// standard UUID.
+//
+// [sd_id128_randomize]: https://www.freedesktop.org/software/systemd/man/latest/sd_id128_randomize.html
+func Random() (ID128, error) {
+	id, err := random()
+	if err != nil {
+		return Null, err
+	}
+	return MakeV4UUID(id), nil
+}
+
+// MakeV4UUID sets the variant and version bits of id to turn it into a
+// version 4 UUID as defined by RFC 4122.
+func MakeV4UUID(id ID128) ID128 {
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return id
+}
+
+// Version returns the UUID version of the ID, from the four most significant
+// bits of the seventh byte. The version is only meaningful if the ID uses the
+// RFC 4122 variant, see [ID128.IsRFC4122].
+func (id ID128) Version() int {
+	return int(id[6] >> 4)
+}
+
+// IsRFC4122 reports whether the ID uses the RFC 4122 UUID variant.
+func (id ID128) IsRFC4122() bool {
+	return id[8]&0xc0 == 0x80
+}
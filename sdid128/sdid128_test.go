@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdid128
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const (
+		plain = "d3b07384d1134ec49f5d0d2e9a5c6e3c"
+		uuid  = "d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c"
+	)
+
+	for _, v := range []string{plain, uuid, "D3B07384D1134EC49F5D0D2E9A5C6E3C"} {
+		id, err := Parse(v)
+		if err != nil {
+			t.Errorf("%q: %v", v, err)
+			continue
+		}
+		if expected, got := plain, id.String(); expected != got {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", v, expected, got)
+		}
+		if expected, got := uuid, id.UUID(); expected != got {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", v, expected, got)
+		}
+	}
+
+	for _, v := range []string{
+		"",
+		"d3b07384d1134ec49f5d0d2e9a5c6e3",
+		"d3b07384_d113_4ec4_9f5d_0d2e9a5c6e3c",
+		"z3b07384d1134ec49f5d0d2e9a5c6e3c",
+	} {
+		if _, err := Parse(v); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%q: expected %v, but got %v", v, ErrInvalid, err)
+		}
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	id := MustParse("d3b07384d1134ec49f5d0d2e9a5c6e3c")
+
+	b, err := json.Marshal(map[string]ID128{"id": id})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := `{"id":"d3b07384d1134ec49f5d0d2e9a5c6e3c"}`, string(b); expected != got {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+
+	var v struct{ ID ID128 }
+	if err := json.Unmarshal([]byte(`{"ID":"d3b07384-d113-4ec4-9f5d-0d2e9a5c6e3c"}`), &v); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !v.ID.Equal(id) {
+		t.Errorf("expected %s, but got %s", id, v.ID)
+	}
+
+	if Null.Compare(id) != -1 || id.Compare(AllF) != -1 || id.Compare(id) != 0 {
+		t.Error("unexpected result from Compare")
+	}
+	if !Null.IsNull() || !AllF.IsAllF() || id.IsNull() {
+		t.Error("unexpected result from IsNull or IsAllF")
+	}
+}
+
+func TestAppSpecific(t *testing.T) {
+	base := MustParse("d3b07384d1134ec49f5d0d2e9a5c6e3c")
+	app := MustParse("8d3c2a1b4e5f60718293a4b5c6d7e8f9")
+
+	id := AppSpecific(base, app)
+	if expected, got := "b15d2a9a17004c0988f1f17e124effe1", id.String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if id == base || id == app {
+		t.Error("expected the derived id to differ from the inputs")
+	}
+	if !id.Equal(AppSpecific(base, app)) {
+		t.Error("expected the derived id to be stable")
+	}
+	if id.Equal(AppSpecific(base, MustParse("00000000000000000000000000000001"))) {
+		t.Error("expected different apps to derive different ids")
+	}
+	if v := id[6] >> 4; v != 4 {
+		t.Errorf("expected version 4, but got %d", v)
+	}
+	if v := id[8] >> 6; v != 0b10 {
+		t.Errorf("expected RFC variant, but got %b", v)
+	}
+}
+
+func TestRandom(t *testing.T) {
+	a, err := Random()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	b, err := Random()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if a.Equal(b) {
+		t.Error("expected random ids to differ")
+	}
+	if a.Version() != 4 || !a.IsRFC4122() {
+		t.Errorf("expected a version 4 RFC 4122 UUID, but got %s", a.UUID())
+	}
+
+	for _, v := range []string{"{" + a.UUID() + "}", a.URN(), "URN:UUID:" + a.UUID()} {
+		id, err := Parse(v)
+		if err != nil {
+			t.Errorf("%q: %v", v, err)
+			continue
+		}
+		if !id.Equal(a) {
+			t.Errorf("%q: expected %s, but got %s", v, a, id)
+		}
+	}
+
+	id, err := FromBytes(a.Bytes())
+	if err != nil || !id.Equal(a) {
+		t.Errorf("expected FromBytes to return %s, but got %s (%v)", a, id, err)
+	}
+	if _, err := FromBytes(a.Bytes()[:15]); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected %v, but got %v", ErrInvalid, err)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"fmt"
 	"net"
 	"slices"
+
+	"github.com/matthewpi/sd/internal/sdenv"
 )
 
 // Listener is a wrapper around a [net.Listener] used to attach additional data
@@ -129,3 +131,83 @@ func PacketConns() ([]PacketConn, error) {
 	}
 	return slices.Clip(conns), errs
 }
+
+// Conn is a wrapper around a [net.Conn] used to attach additional data to the
+// connection.
+//
+// Connections are passed to services spawned by a socket unit with `Accept=yes`,
+// where systemd accepts the connection and spawns a new instance of the service
+// for each one.
+type Conn struct {
+	// Conn is the underlying [net.Conn].
+	net.Conn
+
+	// Name of the connection, provided by systemd.
+	//
+	// See [Listener.Name] for details.
+	Name string
+
+	// remote is the address of the remote peer, parsed from `REMOTE_ADDR` and
+	// `REMOTE_PORT`.
+	remote net.Addr
+}
+
+// RemoteAddr returns the address of the remote peer.
+//
+// systemd provides the address of the remote peer using `REMOTE_ADDR` and
+// `REMOTE_PORT`, if they are set, they take precedence over the address
+// returned by the underlying [net.Conn].
+func (c Conn) RemoteAddr() net.Addr {
+	if c.remote != nil {
+		return c.remote
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// Connections opens [Conn] on the file descriptors provided by [Files].
+func Connections() ([]Conn, error) {
+	var remote net.Addr
+	addr, err := sdenv.RemoteAddr()
+	if err != nil {
+		return nil, fmt.Errorf("sdlisten: %w", err)
+	}
+	if addr != nil {
+		remote = addr
+	}
+
+	files := Files(true)
+	conns := make([]Conn, 0, len(files))
+	var errs error
+	for _, f := range files {
+		name := f.Name()
+		c, err := net.FileConn(f)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("sdlisten: unable to open conn (%s): %w", name, err))
+			continue
+		}
+		_ = f.Close()
+		conns = append(conns, Conn{
+			Conn:   c,
+			Name:   name,
+			remote: remote,
+		})
+	}
+	return slices.Clip(conns), errs
+}
+
+// ListenersByName is the same as [Listeners] except that the listeners are
+// grouped by their name, such as the [FileDescriptorName=] of the `.socket`
+// unit they came from.
+//
+// [FileDescriptorName=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.socket.html#FileDescriptorName=
+func ListenersByName() (map[string][]Listener, error) {
+	listeners, err := Listeners()
+	if len(listeners) == 0 {
+		return nil, err
+	}
+	byName := make(map[string][]Listener, len(listeners))
+	for _, l := range listeners {
+		byName[l.Name] = append(byName[l.Name], l)
+	}
+	return byName, err
+}
@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdlisten
+
+// Socket describes a socket an application expects to be passed by systemd,
+// allowing the application to declare its sockets in one place and generate
+// matching `.socket` units from them, such as using
+// [sdunit.SocketFor].
+//
+// [sdunit.SocketFor]: https://pkg.go.dev/github.com/matthewpi/sd/sdunit#SocketFor
+type Socket struct {
+	// Name of the socket, used as the [FileDescriptorName=] of the socket and
+	// as the key of the listeners returned by [ListenersByName].
+	//
+	// [FileDescriptorName=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.socket.html#FileDescriptorName=
+	Name string
+
+	// Network of the socket, one of `tcp`, `tcp4`, `tcp6`, `udp`, `udp4`,
+	// `udp6`, `unix`, `unixgram`, or `unixpacket`, the same as [net.Listen]
+	// and [net.ListenPacket].
+	Network string
+
+	// Address of the socket, such as `:8080` or `/run/app/app.sock`, the same
+	// as [net.Listen] and [net.ListenPacket]. Unix socket addresses starting
+	// with `@` are in the abstract namespace.
+	Address string
+}
@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// Controller is control over a session, taken using [Conn.TakeControl]. It
+// allows opening the devices attached to the seat of the session, such as DRM
+// and evdev devices, without running as root.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.login1.html#Session%20Objects
+type Controller struct {
+	l    *Conn
+	id   string
+	path dbus.ObjectPath
+
+	events chan DeviceEvent
+	cancel context.CancelFunc
+	once   sync.Once
+}
+
+// TakeControl takes control over a session, typically done by a compositor. If
+// id is empty, the session of the current process is used, see
+// [CurrentSession]. Only the owner of the session may take control over it,
+// and only one controller may exist at a time unless force is set, which
+// requires root.
+//
+// Devices are paused and resumed as the session is activated and deactivated,
+// see [Controller.Events].
+func (l *Conn) TakeControl(ctx context.Context, id string, force bool) (*Controller, error) {
+	id, path, err := l.sessionPath(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// The signals are watched before taking control, so none are missed in
+	// between. They are watched until control is released.
+	wctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	signals, err := l.watch(wctx, path, sessionInterface, "PauseDevice", "ResumeDevice")
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if _, err := l.conn.Call(ctx, destination, path, sessionInterface, "TakeControl", "b", force); err != nil {
+		cancel()
+		return nil, fmt.Errorf("sdlogin: unable to take control of session %s: %w", id, convertError(err))
+	}
+
+	c := &Controller{l: l, id: id, path: path, events: make(chan DeviceEvent), cancel: cancel}
+	go c.run(wctx, signals)
+	return c, nil
+}
+
+// run converts signals to events until ctx is done.
+func (c *Controller) run(ctx context.Context, signals <-chan *dbus.Message) {
+	defer close(c.events)
+	for msg := range signals {
+		ev, ok := parseDeviceEvent(msg)
+		if !ok {
+			continue
+		}
+		select {
+		case c.events <- ev:
+		case <-ctx.Done():
+			if ev.File != nil {
+				_ = ev.File.Close()
+			}
+			return
+		}
+	}
+}
+
+// parseDeviceEvent parses a PauseDevice or ResumeDevice signal.
+func parseDeviceEvent(msg *dbus.Message) (DeviceEvent, bool) {
+	if len(msg.Body) != 3 {
+		return DeviceEvent{}, false
+	}
+	major, ok1 := msg.Body[0].(uint32)
+	minor, ok2 := msg.Body[1].(uint32)
+	if !ok1 || !ok2 {
+		return DeviceEvent{}, false
+	}
+	ev := DeviceEvent{Major: major, Minor: minor}
+	switch msg.Member {
+	case "PauseDevice":
+		typ, ok := msg.Body[2].(string)
+		if !ok {
+			return DeviceEvent{}, false
+		}
+		ev.Type = DeviceEventType(typ)
+	case "ResumeDevice":
+		f, ok := msg.Body[2].(*os.File)
+		if !ok {
+			return DeviceEvent{}, false
+		}
+		ev.Type, ev.File = DeviceResume, f
+	default:
+		return DeviceEvent{}, false
+	}
+	return ev, true
+}
+
+// Session returns the ID of the controlled session.
+func (c *Controller) Session() string {
+	return c.id
+}
+
+// Events returns a channel that receives an event whenever a device taken
+// using [Controller.TakeDevice] is paused or resumed, until control is
+// released or the connection is closed, after which the channel is closed.
+//
+// Events are queued and never dropped.
+func (c *Controller) Events() <-chan DeviceEvent {
+	return c.events
+}
+
+// Release releases control over the session, releasing all devices taken. It
+// is safe to call Release multiple times.
+func (c *Controller) Release(ctx context.Context) error {
+	var err error
+	c.once.Do(func() {
+		defer c.cancel()
+		if _, cerr := c.l.conn.Call(ctx, destination, c.path, sessionInterface, "ReleaseControl", ""); cerr != nil {
+			err = fmt.Errorf("sdlogin: unable to release control of session %s: %w", c.id, convertError(cerr))
+		}
+	})
+	return err
+}
+
+// TakeDevice opens a device of the seat of the session by its device number.
+// The returned file is revoked when the device is paused, it is replaced by
+// the file of a [DeviceResume] event.
+//
+// If the session is not active, paused is true, and the device must not be
+// used until it is resumed.
+func (c *Controller) TakeDevice(ctx context.Context, major, minor uint32) (f *os.File, paused bool, err error) {
+	if !c.l.conn.SupportsUnixFDs() {
+		return nil, false, errors.New("sdlogin: unable to take device: bus does not support passing file descriptors")
+	}
+	body, err := c.l.conn.Call(ctx, destination, c.path, sessionInterface, "TakeDevice", "uu", major, minor)
+	if err != nil {
+		return nil, false, fmt.Errorf("sdlogin: unable to take device %d:%d: %w", major, minor, convertError(err))
+	}
+	if len(body) != 2 {
+		return nil, false, fmt.Errorf("sdlogin: unable to take device %d:%d: invalid reply", major, minor)
+	}
+	f, ok := body[0].(*os.File)
+	if !ok {
+		return nil, false, fmt.Errorf("sdlogin: unable to take device %d:%d: invalid reply", major, minor)
+	}
+	paused, _ = body[1].(bool)
+	return f, paused, nil
+}
+
+// TakeDevicePath is like [Controller.TakeDevice], but takes the device node at
+// path, such as `/dev/dri/card0` or `/dev/input/event3`.
+func (c *Controller) TakeDevicePath(ctx context.Context, path string) (f *os.File, paused bool, err error) {
+	major, minor, err := deviceNumber(path)
+	if err != nil {
+		return nil, false, err
+	}
+	return c.TakeDevice(ctx, major, minor)
+}
+
+// deviceNumber returns the device number of the device node at path.
+func deviceNumber(path string) (major, minor uint32, err error) {
+	var st syscall.Stat_t
+	if err := syscall.Stat(path, &st); err != nil {
+		return 0, 0, fmt.Errorf("sdlogin: unable to stat %s: %w", path, err)
+	}
+	if st.Mode&syscall.S_IFMT != syscall.S_IFCHR {
+		return 0, 0, fmt.Errorf("sdlogin: %s is not a character device", path)
+	}
+	// Decode the device number the same as `major(3)` and `minor(3)`.
+	dev := uint64(st.Rdev)
+	major = uint32((dev>>8)&0xfff | (dev>>32)&^0xfff)
+	minor = uint32(dev&0xff | (dev>>12)&^0xff)
+	return major, minor, nil
+}
+
+// ReleaseDevice releases a device taken using [Controller.TakeDevice].
+func (c *Controller) ReleaseDevice(ctx context.Context, major, minor uint32) error {
+	if _, err := c.l.conn.Call(ctx, destination, c.path, sessionInterface, "ReleaseDevice", "uu", major, minor); err != nil {
+		return fmt.Errorf("sdlogin: unable to release device %d:%d: %w", major, minor, convertError(err))
+	}
+	return nil
+}
+
+// PauseDeviceComplete acknowledges a [DevicePause] event, once the device is
+// no longer used.
+func (c *Controller) PauseDeviceComplete(ctx context.Context, major, minor uint32) error {
+	if _, err := c.l.conn.Call(ctx, destination, c.path, sessionInterface, "PauseDeviceComplete", "uu", major, minor); err != nil {
+		return fmt.Errorf("sdlogin: unable to complete pausing device %d:%d: %w", major, minor, convertError(err))
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdlogin provides access to the sessions, users, and seats tracked by
+// systemd-logind, similar to the [sd-login(3)] APIs of libsystemd.
+//
+// Like libsystemd, information is read from the state files logind maintains
+// under `/run/systemd`, no connection to logind is required. Operations that
+// change the state of logind, such as taking inhibitor locks, require a [Conn]
+// to logind on the system bus.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// [sd-login(3)]: https://www.freedesktop.org/software/systemd/man/latest/sd-login.html
+package sdlogin
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdlogin
+
+import "os"
+
+// EventType is the type of an [Event].
+type EventType string
+
+const (
+	EventSessionNew     EventType = "session-new"
+	EventSessionChanged EventType = "session-changed"
+	EventSessionRemoved EventType = "session-removed"
+	EventUserNew        EventType = "user-new"
+	EventUserChanged    EventType = "user-changed"
+	EventUserRemoved    EventType = "user-removed"
+	EventSeatNew        EventType = "seat-new"
+	EventSeatChanged    EventType = "seat-changed"
+	EventSeatRemoved    EventType = "seat-removed"
+)
+
+// Event is a change to a session, user, or seat, as delivered by [Monitor].
+type Event struct {
+	Type EventType
+	// Session is the ID of the session for session events.
+	Session string
+	// UID is the ID of the user for user events.
+	UID int
+	// Seat is the ID of the seat for seat events.
+	Seat string
+}
+
+// DeviceEventType is the type of a [DeviceEvent].
+type DeviceEventType string
+
+const (
+	// DevicePause is a device being paused, such as when switching to another
+	// session. [Controller.PauseDeviceComplete] must be called once the device
+	// is no longer used, logind waits for it before switching sessions.
+	DevicePause DeviceEventType = "pause"
+	// DeviceForcePause is a device that has already been paused.
+	DeviceForcePause DeviceEventType = "force"
+	// DeviceGone is a device that has been removed.
+	DeviceGone DeviceEventType = "gone"
+	// DeviceResume is a device being resumed, along with a new file
+	// descriptor for it.
+	DeviceResume DeviceEventType = "resume"
+)
+
+// DeviceEvent is a change to a device taken using [Controller.TakeDevice].
+type DeviceEvent struct {
+	Type DeviceEventType
+	// Major and Minor are the device number of the device.
+	Major, Minor uint32
+	// File is the new file descriptor of a resumed device, it replaces the
+	// previous one, which no longer has access to the device.
+	File *os.File
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdlogin
+
+import (
+	"os"
+	"sync"
+)
+
+// InhibitWhat is an operation that may be inhibited.
+//
+// ref; https://systemd.io/INHIBITOR_LOCKS/
+type InhibitWhat string
+
+const (
+	InhibitShutdown           InhibitWhat = "shutdown"
+	InhibitSleep              InhibitWhat = "sleep"
+	InhibitIdle               InhibitWhat = "idle"
+	InhibitHandlePowerKey     InhibitWhat = "handle-power-key"
+	InhibitHandleSuspendKey   InhibitWhat = "handle-suspend-key"
+	InhibitHandleHibernateKey InhibitWhat = "handle-hibernate-key"
+	InhibitHandleLidSwitch    InhibitWhat = "handle-lid-switch"
+	InhibitHandleRebootKey    InhibitWhat = "handle-reboot-key"
+)
+
+// InhibitMode is the mode of an inhibitor lock.
+type InhibitMode string
+
+const (
+	// InhibitBlock prevents the operation from happening at all, until the
+	// lock is released.
+	InhibitBlock InhibitMode = "block"
+	// InhibitBlockWeak is like [InhibitBlock], but is ignored when the
+	// operation is requested by a privileged user.
+	InhibitBlockWeak InhibitMode = "block-weak"
+	// InhibitDelay delays the operation until the lock is released, or the
+	// delay configured by `InhibitDelayMaxSec=` in logind.conf expires.
+	InhibitDelay InhibitMode = "delay"
+)
+
+// Inhibitor is an inhibitor lock taken using [Conn.Inhibit]. The lock is held
+// until it is released, or the process exits.
+type Inhibitor struct {
+	f    *os.File
+	once sync.Once
+	err  error
+}
+
+// File returns the file descriptor referencing the lock. The lock is released
+// once every copy of the file descriptor is closed, such as when passing it to
+// a child process that should keep holding the lock.
+func (i *Inhibitor) File() *os.File {
+	return i.f
+}
+
+// Release releases the lock. It is safe to call Release multiple times.
+func (i *Inhibitor) Release() error {
+	i.once.Do(func() { i.err = i.f.Close() })
+	return i.err
+}
+
+// PrepareEvent is an event received from [Conn.WatchSleep] or
+// [Conn.WatchShutdown].
+type PrepareEvent struct {
+	// Active is true when the operation is about to happen, and false once it
+	// has completed or was canceled, such as when the system resumes from
+	// sleep.
+	Active bool
+	// Err is set if the delay inhibitor lock could not be taken again after
+	// the operation completed, later operations will not be delayed.
+	Err error
+
+	release func()
+}
+
+// Done releases the delay inhibitor lock held for the operation, allowing it
+// to proceed. It must be called once the caller is prepared for an active
+// event, otherwise the operation is delayed until the maximum delay
+// configured in logind expires. It is safe to call Done multiple times, and on
+// events that are not active.
+func (e PrepareEvent) Done() {
+	if e.release != nil {
+		e.release()
+	}
+}
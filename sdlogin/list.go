@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// listDir returns the names of the files in a state directory of logind,
+// which is empty if logind is not running.
+func listDir(name string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(rootDir, name))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("sdlogin: unable to read /%s: %w", name, err)
+	}
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		// logind writes files atomically by renaming temporary files, which
+		// must be skipped.
+		if e.Type().IsRegular() && validID(e.Name()) {
+			names = append(names, e.Name())
+		}
+	}
+	return names, nil
+}
+
+// ListSessions returns the current login sessions, sorted by ID, equivalent to
+// `loginctl list-sessions`.
+func ListSessions() ([]Session, error) {
+	ids, err := listDir("run/systemd/sessions")
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		s, err := GetSession(id)
+		if err != nil {
+			// The session may have ended since listing them.
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		sessions = append(sessions, *s)
+	}
+	slices.SortFunc(sessions, func(a, b Session) int { return cmpID(a.ID, b.ID) })
+	return sessions, nil
+}
+
+// cmpID compares session IDs, ordering numeric IDs numerically.
+func cmpID(a, b string) int {
+	na, erra := strconv.Atoi(a)
+	nb, errb := strconv.Atoi(b)
+	if erra == nil && errb == nil {
+		return cmp.Compare(na, nb)
+	}
+	return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+}
+
+// GetUser returns information about a user tracked by logind.
+//
+// If the user is not logged in and does not have lingering enabled, an error
+// matching [ErrNotFound] is returned.
+func GetUser(uid int) (*User, error) {
+	if uid < 0 {
+		return nil, fmt.Errorf("sdlogin: invalid uid %d", uid)
+	}
+	env, err := readEnvFile("run/systemd/users/" + strconv.Itoa(uid))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdlogin: user %d: %w", uid, ErrNotFound)
+		}
+		return nil, fmt.Errorf("sdlogin: unable to read user %d: %w", uid, err)
+	}
+	return &User{
+		UID:         uid,
+		Name:        env["NAME"],
+		State:       UserState(env["STATE"]),
+		Sessions:    strings.Fields(env["SESSIONS"]),
+		Seats:       strings.Fields(env["SEATS"]),
+		Display:     env["DISPLAY"],
+		RuntimePath: env["RUNTIME"],
+		Slice:       env["SLICE"],
+		Service:     env["SERVICE"],
+		Started:     parseTimestamp(env["REALTIME"]),
+	}, nil
+}
+
+// ListUsers returns the users that are logged in or have lingering enabled,
+// sorted by UID, equivalent to `loginctl list-users`.
+func ListUsers() ([]User, error) {
+	names, err := listDir("run/systemd/users")
+	if err != nil {
+		return nil, err
+	}
+	users := make([]User, 0, len(names))
+	for _, name := range names {
+		uid, err := strconv.Atoi(name)
+		if err != nil {
+			continue
+		}
+		u, err := GetUser(uid)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		users = append(users, *u)
+	}
+	slices.SortFunc(users, func(a, b User) int { return cmp.Compare(a.UID, b.UID) })
+	return users, nil
+}
+
+// GetSeat returns information about a seat.
+//
+// If the seat does not exist, an error matching [ErrNotFound] is returned.
+func GetSeat(id string) (*Seat, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("sdlogin: invalid seat id %q", id)
+	}
+	env, err := readEnvFile("run/systemd/seats/" + id)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdlogin: seat %s: %w", id, ErrNotFound)
+		}
+		return nil, fmt.Errorf("sdlogin: unable to read seat %s: %w", id, err)
+	}
+	s := &Seat{
+		ID:              id,
+		Active:          env["ACTIVE"],
+		Sessions:        strings.Fields(env["SESSIONS"]),
+		CanTTY:          env["CAN_TTY"] == "1",
+		CanGraphical:    env["CAN_GRAPHICAL"] == "1",
+		CanMultiSession: env["CAN_MULTI_SESSION"] == "1",
+	}
+	s.ActiveUID, _ = strconv.Atoi(env["ACTIVE_UID"])
+	for _, v := range strings.Fields(env["UIDS"]) {
+		if uid, err := strconv.Atoi(v); err == nil {
+			s.UIDs = append(s.UIDs, uid)
+		}
+	}
+	return s, nil
+}
+
+// ListSeats returns the seats, sorted by ID, equivalent to
+// `loginctl list-seats`.
+func ListSeats() ([]Seat, error) {
+	ids, err := listDir("run/systemd/seats")
+	if err != nil {
+		return nil, err
+	}
+	seats := make([]Seat, 0, len(ids))
+	for _, id := range ids {
+		s, err := GetSeat(id)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		seats = append(seats, *s)
+	}
+	slices.SortFunc(seats, func(a, b Seat) int { return cmp.Compare(a.ID, b.ID) })
+	return seats, nil
+}
@@ -0,0 +1,225 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of logind.
+	destination = "org.freedesktop.login1"
+
+	// managerPath is the object path of logind.
+	managerPath dbus.ObjectPath = "/org/freedesktop/login1"
+
+	// managerInterface is the interface of logind.
+	managerInterface = "org.freedesktop.login1.Manager"
+
+	// sessionInterface is the interface of sessions.
+	sessionInterface = "org.freedesktop.login1.Session"
+)
+
+// Conn is a connection to systemd-logind on the system bus, used for the
+// operations that cannot be performed by reading the state files of logind.
+type Conn struct {
+	conn *dbus.Conn
+}
+
+// New connects to logind on the system bus.
+func New(ctx context.Context) (*Conn, error) {
+	c, err := dbus.SystemBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdlogin: unable to connect to bus: %w", err)
+	}
+	return newConn(c), nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection.
+func newConn(c *dbus.Conn) *Conn {
+	return &Conn{conn: c}
+}
+
+// Close closes the connection to logind.
+func (l *Conn) Close() error {
+	return l.conn.Close()
+}
+
+// call calls a method on logind, returning the body of the reply.
+func (l *Conn) call(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	body, err := l.conn.Call(ctx, destination, managerPath, managerInterface, method, sig, args...)
+	return body, convertError(err)
+}
+
+// sessionPath returns the object path of a session, along with its ID. If id
+// is empty, the session of the current process is used, see
+// [CurrentSession].
+//
+// If the session does not exist, an error matching [ErrNotFound] is returned.
+func (l *Conn) sessionPath(ctx context.Context, id string) (string, dbus.ObjectPath, error) {
+	if id == "" {
+		var err error
+		if id, err = CurrentSession(); err != nil {
+			return "", "", err
+		}
+	}
+	body, err := l.call(ctx, "GetSession", "s", id)
+	if err != nil {
+		return "", "", fmt.Errorf("sdlogin: unable to get session %s: %w", id, err)
+	}
+	if len(body) != 1 {
+		return "", "", fmt.Errorf("sdlogin: unable to get session %s: invalid reply", id)
+	}
+	path, _ := body[0].(dbus.ObjectPath)
+	return id, path, nil
+}
+
+// convertError converts a D-Bus error returned by logind to an [*Error].
+func convertError(err error) error {
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Message: e.Message()}
+	}
+	return err
+}
+
+// Inhibit takes an inhibitor lock, blocking or delaying the operations in what
+// until [Inhibitor.Release] is called, the same as `systemd-inhibit`. who is a
+// human-readable name of the program taking the lock, and why describes the
+// reason it is being taken.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.login1.html#Methods
+func (l *Conn) Inhibit(ctx context.Context, what []InhibitWhat, who, why string, mode InhibitMode) (*Inhibitor, error) {
+	if len(what) == 0 {
+		return nil, errors.New("sdlogin: unable to inhibit: no operations to inhibit")
+	}
+	if !l.conn.SupportsUnixFDs() {
+		return nil, errors.New("sdlogin: unable to inhibit: bus does not support passing file descriptors")
+	}
+	parts := make([]string, len(what))
+	for i, w := range what {
+		parts[i] = string(w)
+	}
+	if mode == "" {
+		mode = InhibitBlock
+	}
+	body, err := l.call(ctx, "Inhibit", "ssss", strings.Join(parts, ":"), who, why, string(mode))
+	if err != nil {
+		return nil, fmt.Errorf("sdlogin: unable to inhibit %s: %w", strings.Join(parts, ", "), err)
+	}
+	if len(body) != 1 {
+		return nil, errors.New("sdlogin: unable to inhibit: invalid reply")
+	}
+	f, ok := body[0].(*os.File)
+	if !ok {
+		return nil, errors.New("sdlogin: unable to inhibit: invalid reply")
+	}
+	return &Inhibitor{f: f}, nil
+}
+
+// LockSession asks a session to lock its screen, the same as `loginctl
+// lock-session`. The request is forwarded to the screen locker of the session
+// using the Lock signal, see [Conn.WatchLock].
+func (l *Conn) LockSession(ctx context.Context, id string) error {
+	if _, err := l.call(ctx, "LockSession", "s", id); err != nil {
+		return fmt.Errorf("sdlogin: unable to lock session %s: %w", id, err)
+	}
+	return nil
+}
+
+// UnlockSession asks a session to unlock its screen, the same as `loginctl
+// unlock-session`.
+func (l *Conn) UnlockSession(ctx context.Context, id string) error {
+	if _, err := l.call(ctx, "UnlockSession", "s", id); err != nil {
+		return fmt.Errorf("sdlogin: unable to unlock session %s: %w", id, err)
+	}
+	return nil
+}
+
+// LockSessions asks all sessions to lock their screens, the same as `loginctl
+// lock-sessions`.
+func (l *Conn) LockSessions(ctx context.Context) error {
+	if _, err := l.call(ctx, "LockSessions", ""); err != nil {
+		return fmt.Errorf("sdlogin: unable to lock sessions: %w", err)
+	}
+	return nil
+}
+
+// UnlockSessions asks all sessions to unlock their screens, the same as
+// `loginctl unlock-sessions`.
+func (l *Conn) UnlockSessions(ctx context.Context) error {
+	if _, err := l.call(ctx, "UnlockSessions", ""); err != nil {
+		return fmt.Errorf("sdlogin: unable to unlock sessions: %w", err)
+	}
+	return nil
+}
+
+// SetIdleHint marks the session of the current process as idle or not, which
+// is taken into account by the idle action of logind, `IdleAction=` in
+// logind.conf. Only the owner of the session may set its idle hint.
+//
+// Sessions that are not graphical, or whose display server does not report
+// idleness itself, such as kiosks and terminal applications, should call this
+// to participate in idle tracking.
+func (l *Conn) SetIdleHint(ctx context.Context, idle bool) error {
+	id, path, err := l.sessionPath(ctx, "")
+	if err != nil {
+		return err
+	}
+	if _, err := l.conn.Call(ctx, destination, path, sessionInterface, "SetIdleHint", "b", idle); err != nil {
+		return fmt.Errorf("sdlogin: unable to set idle hint of session %s: %w", id, convertError(err))
+	}
+	return nil
+}
+
+// SessionIdleHint returns the idle state of a session. If id is empty, the
+// session of the current process is used, see [CurrentSession].
+func (l *Conn) SessionIdleHint(ctx context.Context, id string) (IdleHint, error) {
+	id, path, err := l.sessionPath(ctx, id)
+	if err != nil {
+		return IdleHint{}, err
+	}
+	hint, err := l.idleHint(ctx, path, sessionInterface)
+	if err != nil {
+		return IdleHint{}, fmt.Errorf("sdlogin: unable to get idle hint of session %s: %w", id, err)
+	}
+	return hint, nil
+}
+
+// IdleHint returns the idle state of the system, which is idle only if all
+// sessions are idle.
+func (l *Conn) IdleHint(ctx context.Context) (IdleHint, error) {
+	hint, err := l.idleHint(ctx, managerPath, managerInterface)
+	if err != nil {
+		return IdleHint{}, fmt.Errorf("sdlogin: unable to get idle hint: %w", err)
+	}
+	return hint, nil
+}
+
+// idleHint reads the IdleHint and IdleSinceHint properties of an object.
+func (l *Conn) idleHint(ctx context.Context, path dbus.ObjectPath, iface string) (IdleHint, error) {
+	idle, err := l.conn.GetProperty(ctx, destination, path, iface, "IdleHint")
+	if err != nil {
+		return IdleHint{}, convertError(err)
+	}
+	since, err := l.conn.GetProperty(ctx, destination, path, iface, "IdleSinceHint")
+	if err != nil {
+		return IdleHint{}, convertError(err)
+	}
+	var hint IdleHint
+	hint.Idle, _ = idle.Value.(bool)
+	if usec, _ := since.Value.(uint64); usec > 0 {
+		hint.Since = time.UnixMicro(int64(usec))
+	}
+	return hint, nil
+}
@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// watchMask are the inotify events watched on the state directories of
+// logind. State files are written to a temporary file that is renamed over the
+// previous one, and removed once the session, user, or seat is gone.
+const watchMask = syscall.IN_MOVED_TO | syscall.IN_CLOSE_WRITE | syscall.IN_DELETE | syscall.IN_MOVED_FROM
+
+// category is a state directory of logind along with the events for it.
+type category struct {
+	dir                  string
+	added, changed, gone EventType
+}
+
+var categories = []category{
+	{dir: "run/systemd/sessions", added: EventSessionNew, changed: EventSessionChanged, gone: EventSessionRemoved},
+	{dir: "run/systemd/users", added: EventUserNew, changed: EventUserChanged, gone: EventUserRemoved},
+	{dir: "run/systemd/seats", added: EventSeatNew, changed: EventSeatChanged, gone: EventSeatRemoved},
+}
+
+// event returns the event for an entry of the category.
+func (c category) event(typ EventType, name string) (Event, bool) {
+	ev := Event{Type: typ}
+	switch c.dir {
+	case "run/systemd/sessions":
+		ev.Session = name
+	case "run/systemd/users":
+		uid, err := strconv.Atoi(name)
+		if err != nil {
+			return Event{}, false
+		}
+		ev.UID = uid
+	default:
+		ev.Seat = name
+	}
+	return ev, true
+}
+
+// monitor watches the state directories of logind using inotify.
+type monitor struct {
+	f *os.File
+	// wds maps watch descriptors to the index of their category.
+	wds map[int32]int
+	// known are the entries of each category that exist.
+	known []map[string]struct{}
+}
+
+// Monitor watches for sessions, users, and seats being added, changed, or
+// removed, similar to `sd_login_monitor`. Events are delivered on the returned
+// channel until ctx is canceled, after which it is closed.
+//
+// The channel must be read from, events are not dropped, but the kernel may
+// drop events if they are not read quickly enough. When that happens, the
+// state directories are scanned again and events are delivered for the
+// differences, so changes to existing entries may be missed.
+func Monitor(ctx context.Context) (<-chan Event, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("sdlogin: unable to create inotify instance: %w", err)
+	}
+	// The descriptor is non-blocking, so reads use the runtime poller and may
+	// be interrupted by closing the file.
+	m := &monitor{f: os.NewFile(uintptr(fd), "inotify"), wds: make(map[int32]int)}
+	for i, c := range categories {
+		dir := filepath.Join(rootDir, c.dir)
+		wd, err := syscall.InotifyAddWatch(fd, dir, watchMask)
+		if err != nil {
+			_ = m.f.Close()
+			return nil, fmt.Errorf("sdlogin: unable to watch %s: %w", dir, err)
+		}
+		m.wds[int32(wd)] = i
+	}
+	if err := m.scan(nil); err != nil {
+		_ = m.f.Close()
+		return nil, err
+	}
+
+	ch := make(chan Event)
+	stop := context.AfterFunc(ctx, func() { _ = m.f.Close() })
+	go func() {
+		defer close(ch)
+		defer stop()
+		defer m.f.Close()
+		m.run(ctx, ch)
+	}()
+	return ch, nil
+}
+
+// scan lists the entries of every category, calling emit for each difference
+// from the entries previously known if it is not nil.
+func (m *monitor) scan(emit func(Event)) error {
+	known := make([]map[string]struct{}, len(categories))
+	for i, c := range categories {
+		names, err := listDir(c.dir)
+		if err != nil {
+			return err
+		}
+		known[i] = make(map[string]struct{}, len(names))
+		for _, name := range names {
+			known[i][name] = struct{}{}
+		}
+		if emit == nil {
+			continue
+		}
+		for name := range known[i] {
+			if _, ok := m.known[i][name]; !ok {
+				if ev, ok := c.event(c.added, name); ok {
+					emit(ev)
+				}
+			}
+		}
+		for name := range m.known[i] {
+			if _, ok := known[i][name]; !ok {
+				if ev, ok := c.event(c.gone, name); ok {
+					emit(ev)
+				}
+			}
+		}
+	}
+	m.known = known
+	return nil
+}
+
+// run reads inotify events, delivering the corresponding events to ch until
+// ctx is canceled or reading fails.
+func (m *monitor) run(ctx context.Context, ch chan<- Event) {
+	var pending []Event
+	emit := func(ev Event) { pending = append(pending, ev) }
+
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		n, err := m.f.Read(buf)
+		if err != nil {
+			return
+		}
+		m.parse(buf[:n], emit)
+		for _, ev := range pending {
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+		pending = pending[:0]
+	}
+}
+
+// parse parses inotify events, calling emit for each corresponding event.
+func (m *monitor) parse(b []byte, emit func(Event)) {
+	for len(b) >= syscall.SizeofInotifyEvent {
+		raw := (*syscall.InotifyEvent)(unsafe.Pointer(&b[0]))
+		end := syscall.SizeofInotifyEvent + int(raw.Len)
+		if end > len(b) {
+			return
+		}
+		name := string(bytes.TrimRight(b[syscall.SizeofInotifyEvent:end], "\x00"))
+		b = b[end:]
+
+		if raw.Mask&syscall.IN_Q_OVERFLOW != 0 {
+			if err := m.scan(emit); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return
+			}
+			continue
+		}
+		i, ok := m.wds[raw.Wd]
+		if !ok || !validID(name) {
+			continue
+		}
+		c := categories[i]
+		typ := c.changed
+		switch {
+		case raw.Mask&(syscall.IN_DELETE|syscall.IN_MOVED_FROM) != 0:
+			if _, ok := m.known[i][name]; !ok {
+				continue
+			}
+			delete(m.known[i], name)
+			typ = c.gone
+		default:
+			if _, ok := m.known[i][name]; !ok {
+				m.known[i][name] = struct{}{}
+				typ = c.added
+			}
+		}
+		if ev, ok := c.event(typ, name); ok {
+			emit(ev)
+		}
+	}
+}
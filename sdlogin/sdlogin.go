@@ -0,0 +1,255 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rootDir is the directory used to resolve all the paths read by this package,
+// it is only changed by tests.
+var rootDir = "/"
+
+// readFile reads a file relative to [rootDir].
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(rootDir, name))
+}
+
+// readEnvFile parses a state file written by logind, which contains one
+// `KEY=value` assignment per line.
+func readEnvFile(name string) (map[string]string, error) {
+	b, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		k, v, ok := bytes.Cut(line, []byte{'='})
+		if !ok || len(k) == 0 || k[0] == '#' {
+			continue
+		}
+		env[string(k)] = unquote(string(v))
+	}
+	return env, nil
+}
+
+// unquote removes the quoting added by logind to values containing special
+// characters.
+func unquote(v string) string {
+	if len(v) < 2 || v[0] != '"' || v[len(v)-1] != '"' {
+		return v
+	}
+	v = v[1 : len(v)-1]
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+// validID reports whether id is a valid session or seat ID, which prevents it
+// from escaping the state directory of logind.
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for i := range len(id) {
+		c := id[i]
+		if !('a' <= c && c <= 'z') && !('A' <= c && c <= 'Z') && !('0' <= c && c <= '9') && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
+
+// pidCgroup returns the control group of a process, pid 0 is the current
+// process.
+func pidCgroup(pid int) (string, error) {
+	name := "proc/self/cgroup"
+	if pid != 0 {
+		name = "proc/" + strconv.Itoa(pid) + "/cgroup"
+	}
+	b, err := readFile(name)
+	if err != nil {
+		return "", fmt.Errorf("sdlogin: unable to read /%s: %w", name, err)
+	}
+
+	var legacy string
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		// Each line is in the format of `hierarchy-ID:controller-list:cgroup-path`.
+		_, rest, ok := bytes.Cut(line, []byte{':'})
+		if !ok {
+			continue
+		}
+		controllers, path, ok := bytes.Cut(rest, []byte{':'})
+		if !ok {
+			continue
+		}
+		switch {
+		case len(controllers) == 0:
+			return string(path), nil
+		case string(controllers) == "name=systemd":
+			legacy = string(path)
+		}
+	}
+	if legacy != "" {
+		return legacy, nil
+	}
+	return "", fmt.Errorf("sdlogin: unable to find cgroup in /%s", name)
+}
+
+// cgroupSlice returns the innermost slice and the first unit of a cgroup path,
+// such as `user-1000.slice` and `session-2.scope` for
+// `/user.slice/user-1000.slice/session-2.scope`.
+func cgroupSlice(cgroup string) (slice, unit string) {
+	for _, elem := range strings.Split(strings.Trim(cgroup, "/"), "/") {
+		if !strings.HasSuffix(elem, ".slice") {
+			return slice, elem
+		}
+		slice = elem
+	}
+	return slice, ""
+}
+
+// CurrentSession returns the ID of the login session of the current process,
+// equivalent to `sd_pid_get_session(0)`.
+//
+// Processes started by the user service manager, such as those of many
+// graphical desktops, are not part of the scope of their session. For those,
+// `$XDG_SESSION_ID` is used if set. If the process does not belong to a
+// session, [ErrNoSession] is returned.
+func CurrentSession() (string, error) {
+	id, err := PIDSession(0)
+	if errors.Is(err, ErrNoSession) {
+		if v := os.Getenv("XDG_SESSION_ID"); validID(v) {
+			return v, nil
+		}
+	}
+	return id, err
+}
+
+// PIDSession returns the ID of the login session of a process, equivalent to
+// `sd_pid_get_session`. A pid of 0 is the current process.
+//
+// If the process does not belong to a session, [ErrNoSession] is returned.
+func PIDSession(pid int) (string, error) {
+	cgroup, err := pidCgroup(pid)
+	if err != nil {
+		return "", err
+	}
+	_, unit := cgroupSlice(cgroup)
+	id, ok := strings.CutPrefix(unit, "session-")
+	if !ok {
+		return "", ErrNoSession
+	}
+	id, ok = strings.CutSuffix(id, ".scope")
+	if !ok || !validID(id) {
+		return "", ErrNoSession
+	}
+	return id, nil
+}
+
+// OwnerUID returns the ID of the user that owns the current process,
+// equivalent to `sd_pid_get_owner_uid(0)`. This is the user of the login
+// session or user service manager the process belongs to, rather than the
+// user the process is running as.
+//
+// If the process does not belong to a user, [ErrNoSession] is returned.
+func OwnerUID() (int, error) {
+	return PIDOwnerUID(0)
+}
+
+// PIDOwnerUID returns the ID of the user that owns a process, equivalent to
+// `sd_pid_get_owner_uid`. A pid of 0 is the current process.
+//
+// If the process does not belong to a user, [ErrNoSession] is returned.
+func PIDOwnerUID(pid int) (int, error) {
+	cgroup, err := pidCgroup(pid)
+	if err != nil {
+		return 0, err
+	}
+	slice, _ := cgroupSlice(cgroup)
+	v, ok := strings.CutPrefix(slice, "user-")
+	if !ok {
+		return 0, ErrNoSession
+	}
+	v, _ = strings.CutSuffix(v, ".slice")
+	uid, err := strconv.Atoi(v)
+	if err != nil || uid < 0 {
+		return 0, ErrNoSession
+	}
+	return uid, nil
+}
+
+// GetSession returns information about a login session.
+//
+// If the session does not exist, an error matching [ErrNotFound] is returned.
+func GetSession(id string) (*Session, error) {
+	if !validID(id) {
+		return nil, fmt.Errorf("sdlogin: invalid session id %q", id)
+	}
+	env, err := readEnvFile("run/systemd/sessions/" + id)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdlogin: session %s: %w", id, ErrNotFound)
+		}
+		return nil, fmt.Errorf("sdlogin: unable to read session %s: %w", id, err)
+	}
+
+	s := &Session{
+		ID:         id,
+		User:       env["USER"],
+		Class:      SessionClass(env["CLASS"]),
+		Type:       SessionType(env["TYPE"]),
+		State:      SessionState(env["STATE"]),
+		Seat:       env["SEAT"],
+		TTY:        env["TTY"],
+		Display:    env["DISPLAY"],
+		Remote:     env["REMOTE"] == "1",
+		RemoteHost: env["REMOTE_HOST"],
+		RemoteUser: env["REMOTE_USER"],
+		Service:    env["SERVICE"],
+		Desktop:    env["DESKTOP"],
+		Scope:      env["SCOPE"],
+	}
+	s.Active = env["ACTIVE"] == "1" || s.State == StateActive
+	s.UID, _ = strconv.Atoi(env["UID"])
+	s.VTNr, _ = strconv.Atoi(env["VTNR"])
+	s.Leader, _ = strconv.Atoi(env["LEADER"])
+	s.Started = parseTimestamp(env["REALTIME"])
+	return s, nil
+}
+
+// SessionSeat returns the seat a session is attached to, equivalent to
+// `sd_session_get_seat`. It is empty if the session is not attached to a seat.
+func SessionSeat(id string) (string, error) {
+	s, err := GetSession(id)
+	if err != nil {
+		return "", err
+	}
+	return s.Seat, nil
+}
+
+// parseTimestamp parses a timestamp in microseconds since the epoch, as
+// written by logind.
+func parseTimestamp(v string) time.Time {
+	usec, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || usec <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMicro(usec)
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdlogin
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+func CurrentSession() (string, error) { return "", errors.ErrUnsupported }
+
+func PIDSession(int) (string, error) { return "", errors.ErrUnsupported }
+
+func OwnerUID() (int, error) { return 0, errors.ErrUnsupported }
+
+func PIDOwnerUID(int) (int, error) { return 0, errors.ErrUnsupported }
+
+func GetSession(string) (*Session, error) { return nil, errors.ErrUnsupported }
+
+func SessionSeat(string) (string, error) { return "", errors.ErrUnsupported }
+
+func ListSessions() ([]Session, error) { return nil, errors.ErrUnsupported }
+
+func GetUser(int) (*User, error) { return nil, errors.ErrUnsupported }
+
+func ListUsers() ([]User, error) { return nil, errors.ErrUnsupported }
+
+func GetSeat(string) (*Seat, error) { return nil, errors.ErrUnsupported }
+
+func ListSeats() ([]Seat, error) { return nil, errors.ErrUnsupported }
+
+func Monitor(context.Context) (<-chan Event, error) { return nil, errors.ErrUnsupported }
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) Inhibit(context.Context, []InhibitWhat, string, string, InhibitMode) (*Inhibitor, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) WatchSleep(context.Context, string, string) (<-chan PrepareEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) WatchShutdown(context.Context, string, string) (<-chan PrepareEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) WatchLock(context.Context, string) (<-chan LockEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) LockSession(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) UnlockSession(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) LockSessions(context.Context) error { return errors.ErrUnsupported }
+
+func (*Conn) UnlockSessions(context.Context) error { return errors.ErrUnsupported }
+
+func (*Conn) SetIdleHint(context.Context, bool) error { return errors.ErrUnsupported }
+
+func (*Conn) SessionIdleHint(context.Context, string) (IdleHint, error) {
+	return IdleHint{}, errors.ErrUnsupported
+}
+
+func (*Conn) IdleHint(context.Context) (IdleHint, error) { return IdleHint{}, errors.ErrUnsupported }
+
+type Controller struct{}
+
+func (*Conn) TakeControl(context.Context, string, bool) (*Controller, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Controller) Session() string { return "" }
+
+func (*Controller) Events() <-chan DeviceEvent { return nil }
+
+func (*Controller) Release(context.Context) error { return errors.ErrUnsupported }
+
+func (*Controller) TakeDevice(context.Context, uint32, uint32) (*os.File, bool, error) {
+	return nil, false, errors.ErrUnsupported
+}
+
+func (*Controller) TakeDevicePath(context.Context, string) (*os.File, bool, error) {
+	return nil, false, errors.ErrUnsupported
+}
+
+func (*Controller) ReleaseDevice(context.Context, uint32, uint32) error {
+	return errors.ErrUnsupported
+}
+
+func (*Controller) PauseDeviceComplete(context.Context, uint32, uint32) error {
+	return errors.ErrUnsupported
+}
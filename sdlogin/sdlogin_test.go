@@ -0,0 +1,637 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+)
+
+// writeFiles writes files relative to dir, creating any parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// useRoot makes the package read files from a temporary directory containing
+// files for the duration of the test.
+func useRoot(t *testing.T, files map[string]string) {
+	t.Helper()
+	prev := rootDir
+	t.Cleanup(func() { rootDir = prev })
+	rootDir = t.TempDir()
+	writeFiles(t, rootDir, files)
+}
+
+func TestPIDSession(t *testing.T) {
+	for _, tc := range []struct {
+		cgroup  string
+		session string
+		uid     int
+		err     error
+	}{
+		{cgroup: "0::/user.slice/user-1000.slice/session-2.scope\n", session: "2", uid: 1000},
+		{cgroup: "0::/user.slice/user-1000.slice/session-c1.scope\n", session: "c1", uid: 1000},
+		{cgroup: "0::/user.slice/user-1000.slice/user@1000.service/app.slice/app-gnome-terminal.scope\n", uid: 1000, err: ErrNoSession},
+		{cgroup: "0::/system.slice/sshd.service\n", uid: -1, err: ErrNoSession},
+		{cgroup: "12:pids:/\n1:name=systemd:/user.slice/user-0.slice/session-7.scope\n", session: "7", uid: 0},
+	} {
+		useRoot(t, map[string]string{"proc/42/cgroup": tc.cgroup})
+
+		session, err := PIDSession(42)
+		if !errors.Is(err, tc.err) {
+			t.Errorf("%q: expected error %v, but got %v", tc.cgroup, tc.err, err)
+		}
+		if expected, got := tc.session, session; expected != got {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.cgroup, expected, got)
+		}
+
+		uid, err := PIDOwnerUID(42)
+		if tc.uid < 0 {
+			if !errors.Is(err, ErrNoSession) {
+				t.Errorf("%q: expected error %v, but got %v", tc.cgroup, ErrNoSession, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.cgroup, err)
+		}
+		if expected, got := tc.uid, uid; expected != got {
+			t.Errorf("%q: expected %d, but got %d", tc.cgroup, expected, got)
+		}
+	}
+}
+
+func TestCurrentSession(t *testing.T) {
+	useRoot(t, map[string]string{
+		"proc/self/cgroup": "0::/user.slice/user-1000.slice/user@1000.service/app.slice/example.service\n",
+	})
+
+	t.Setenv("XDG_SESSION_ID", "")
+	if _, err := CurrentSession(); !errors.Is(err, ErrNoSession) {
+		t.Errorf("expected %v, but got %v", ErrNoSession, err)
+	}
+
+	t.Setenv("XDG_SESSION_ID", "3")
+	session, err := CurrentSession()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "3", session; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestGetSession(t *testing.T) {
+	useRoot(t, map[string]string{
+		"run/systemd/sessions/2": `# This is private data. Do not parse.
+UID=1000
+USER=alice
+ACTIVE=1
+IS_DISPLAY=1
+STATE=active
+REMOTE=0
+TYPE=wayland
+CLASS=user
+SCOPE=session-2.scope
+SEAT=seat0
+TTY=tty2
+SERVICE=gdm-password
+DESKTOP=GNOME
+VTNR=2
+LEADER=1234
+REALTIME=1700000000000000
+`,
+		"run/systemd/sessions/5": `UID=1001
+USER=bob
+ACTIVE=0
+STATE=online
+REMOTE=1
+TYPE=tty
+CLASS=user
+TTY=pts/0
+REMOTE_HOST="host with \"quotes\""
+SERVICE=sshd
+`,
+	})
+
+	s, err := GetSession("2")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := Session{
+		ID:      "2",
+		UID:     1000,
+		User:    "alice",
+		Class:   ClassUser,
+		Type:    TypeWayland,
+		State:   StateActive,
+		Active:  true,
+		Seat:    "seat0",
+		VTNr:    2,
+		TTY:     "tty2",
+		Service: "gdm-password",
+		Desktop: "GNOME",
+		Scope:   "session-2.scope",
+		Leader:  1234,
+		Started: time.UnixMicro(1700000000000000),
+	}
+	if *s != expected {
+		t.Errorf("expected %#v, but got %#v", expected, *s)
+	}
+
+	s, err = GetSession("5")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !s.Remote || s.Active || s.RemoteHost != `host with "quotes"` || s.Seat != "" {
+		t.Errorf("unexpected remote session %#v", s)
+	}
+	seat, err := SessionSeat("2")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "seat0", seat; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	if _, err := GetSession("9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+	if _, err := GetSession("../../etc/passwd"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected an invalid session id error, but got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	useRoot(t, map[string]string{
+		"run/systemd/sessions/10":     "UID=1000\nUSER=alice\nSTATE=online\nTTY=pts/1\nREMOTE=1\nREMOTE_HOST=10.0.0.1\n",
+		"run/systemd/sessions/2":      "UID=1000\nUSER=alice\nACTIVE=1\nSTATE=active\nSEAT=seat0\n",
+		"run/systemd/sessions/c1":     "UID=120\nUSER=gdm\nSTATE=online\nCLASS=greeter\nSEAT=seat0\n",
+		"run/systemd/sessions/.#2abc": "UID=1000\n",
+		"run/systemd/users/1000":      "NAME=alice\nSTATE=active\nRUNTIME=/run/user/1000\nSLICE=user-1000.slice\nSERVICE=user@1000.service\nDISPLAY=2\nSESSIONS=2 10\nSEATS=seat0\n",
+		"run/systemd/users/120":       "NAME=gdm\nSTATE=online\nSESSIONS=c1\n",
+		"run/systemd/seats/seat0":     "IS_SEAT0=1\nCAN_MULTI_SESSION=1\nCAN_TTY=1\nCAN_GRAPHICAL=1\nACTIVE=2\nACTIVE_UID=1000\nSESSIONS=2 c1\nUIDS=1000 120\n",
+	})
+
+	sessions, err := ListSessions()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var ids []string
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+	if expected, got := []string{"2", "10", "c1"}, ids; !slices.Equal(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if s := sessions[1]; !s.Remote || s.RemoteHost != "10.0.0.1" || s.TTY != "pts/1" || s.Active {
+		t.Errorf("unexpected session %#v", s)
+	}
+
+	users, err := ListUsers()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(users) != 2 || users[0].Name != "gdm" || users[1].UID != 1000 {
+		t.Fatalf("unexpected users %#v", users)
+		return
+	}
+	if u := users[1]; u.State != UserActive || !slices.Equal(u.Sessions, []string{"2", "10"}) || u.Display != "2" || u.RuntimePath != "/run/user/1000" {
+		t.Errorf("unexpected user %#v", u)
+	}
+	if _, err := GetUser(1001); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+
+	seats, err := ListSeats()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := Seat{
+		ID:              "seat0",
+		Active:          "2",
+		ActiveUID:       1000,
+		Sessions:        []string{"2", "c1"},
+		UIDs:            []int{1000, 120},
+		CanTTY:          true,
+		CanGraphical:    true,
+		CanMultiSession: true,
+	}
+	if len(seats) != 1 || !reflect.DeepEqual(expected, seats[0]) {
+		t.Errorf("expected %#v, but got %#v", expected, seats)
+	}
+
+	// logind is not running.
+	useRoot(t, nil)
+	if sessions, err := ListSessions(); err != nil || len(sessions) != 0 {
+		t.Errorf("expected no sessions, but got %v, %v", sessions, err)
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	useRoot(t, map[string]string{
+		"run/systemd/sessions/2":  "UID=1000\n",
+		"run/systemd/users/1000":  "NAME=alice\n",
+		"run/systemd/seats/seat0": "ACTIVE=2\n",
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := Monitor(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	// State files are replaced by renaming a temporary file over them.
+	replace := func(name, data string) {
+		tmp := filepath.Join(rootDir, filepath.Dir(name), ".#tmp")
+		if err := os.WriteFile(tmp, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, filepath.Join(rootDir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	next := func() Event {
+		select {
+		case ev := <-events:
+			return ev
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+			return Event{}
+		}
+	}
+
+	replace("run/systemd/sessions/3", "UID=1001\n")
+	if expected, got := (Event{Type: EventSessionNew, Session: "3"}), next(); expected != got {
+		t.Errorf("expected %#v, but got %#v", expected, got)
+	}
+	replace("run/systemd/users/1001", "NAME=bob\n")
+	if expected, got := (Event{Type: EventUserNew, UID: 1001}), next(); expected != got {
+		t.Errorf("expected %#v, but got %#v", expected, got)
+	}
+	replace("run/systemd/seats/seat0", "ACTIVE=3\n")
+	if expected, got := (Event{Type: EventSeatChanged, Seat: "seat0"}), next(); expected != got {
+		t.Errorf("expected %#v, but got %#v", expected, got)
+	}
+	if err := os.Remove(filepath.Join(rootDir, "run/systemd/sessions/2")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := (Event{Type: EventSessionRemoved, Session: "2"}), next(); expected != got {
+		t.Errorf("expected %#v, but got %#v", expected, got)
+	}
+
+	cancel()
+	for range events {
+	}
+
+	// logind is not running.
+	useRoot(t, nil)
+	if _, err := Monitor(context.Background()); err == nil {
+		t.Error("expected an error, but got nil")
+	}
+}
+
+// newTestConn returns a [Conn] connected to a fake bus.
+func newTestConn(t *testing.T) (*dbustest.Bus, *Conn) {
+	t.Helper()
+	bus, c := dbustest.New(t)
+	return bus, newConn(c)
+}
+
+func TestInhibit(t *testing.T) {
+	bus, l := newTestConn(t)
+	bus.Handle(managerInterface, "Inhibit", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] == "idle" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied", Body: []any{"Permission denied"}}
+		}
+		// The reply is written asynchronously, so the file is left to be
+		// closed by its finalizer.
+		f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		return "h", []any{f}, nil
+	})
+
+	ctx := context.Background()
+	i, err := l.Inhibit(ctx, []InhibitWhat{InhibitShutdown, InhibitSleep}, "backup", "Backup in progress", InhibitDelay)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	calls := bus.Calls()
+	if expected, got := []any{"shutdown:sleep", "backup", "Backup in progress", "delay"}, calls[len(calls)-1].Body; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+
+	if _, err := i.File().Write([]byte{0}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := i.Release(); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := i.Release(); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+	if _, err := i.File().Write([]byte{0}); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("expected %v, but got %v", os.ErrClosed, err)
+	}
+
+	var e *Error
+	if _, err := l.Inhibit(ctx, []InhibitWhat{InhibitIdle}, "test", "test", ""); !errors.As(err, &e) || e.Name != "org.freedesktop.DBus.Error.AccessDenied" {
+		t.Errorf("expected an AccessDenied error, but got %v", err)
+	}
+	if _, err := l.Inhibit(ctx, nil, "test", "test", ""); err == nil {
+		t.Error("expected an error, but got nil")
+	}
+}
+
+func TestWatchSleep(t *testing.T) {
+	bus, l := newTestConn(t)
+	var inhibits atomic.Uint32
+	bus.Handle(managerInterface, "Inhibit", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] != "sleep" || m.Body[3] != "delay" {
+			return "", nil, fmt.Errorf("unexpected inhibit %v", m.Body)
+		}
+		inhibits.Add(1)
+		f, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		return "h", []any{f}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := l.WatchSleep(ctx, "example", "Closing connections")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	next := func() PrepareEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+			return PrepareEvent{}
+		}
+	}
+
+	if err := bus.Emit(managerPath, managerInterface, "PrepareForSleep", "b", true); err != nil {
+		t.Fatal(err)
+		return
+	}
+	ev := next()
+	if !ev.Active {
+		t.Errorf("expected an active event, but got %#v", ev)
+	}
+	ev.Done()
+	ev.Done()
+
+	if err := bus.Emit(managerPath, managerInterface, "PrepareForSleep", "b", false); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if ev := next(); ev.Active || ev.Err != nil {
+		t.Errorf("expected an inactive event, but got %#v", ev)
+	}
+	// The lock is taken again after resuming.
+	if expected, got := uint32(2), inhibits.Load(); expected != got {
+		t.Errorf("expected %d, but got %d", expected, got)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestLock(t *testing.T) {
+	bus, l := newTestConn(t)
+	const path dbus.ObjectPath = "/org/freedesktop/login1/session/_32"
+	bus.Handle(managerInterface, "GetSession", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] != "2" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.login1.NoSuchSession", Body: []any{"No session '" + m.Body[0].(string) + "' known"}}
+		}
+		return "o", []any{path}, nil
+	})
+	for _, method := range []string{"LockSession", "UnlockSession", "LockSessions", "UnlockSessions"} {
+		bus.Handle(managerInterface, method, func(*dbus.Message) (dbus.Signature, []any, error) { return "", nil, nil })
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := l.WatchLock(ctx, "9"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+	useRoot(t, map[string]string{"proc/self/cgroup": "0::/user.slice/user-1000.slice/session-2.scope\n"})
+	events, err := l.WatchLock(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	for _, locked := range []bool{true, false} {
+		member := "Unlock"
+		if locked {
+			member = "Lock"
+		}
+		if err := bus.Emit(path, sessionInterface, member, ""); err != nil {
+			t.Fatal(err)
+			return
+		}
+		select {
+		case ev := <-events:
+			if expected := (LockEvent{Session: "2", Locked: locked}); ev != expected {
+				t.Errorf("expected %#v, but got %#v", expected, ev)
+			}
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+			return
+		}
+	}
+
+	if err := l.LockSession(ctx, "2"); err != nil {
+		t.Error(err)
+	}
+	if err := l.LockSessions(ctx); err != nil {
+		t.Error(err)
+	}
+	calls := bus.Calls()
+	if expected, got := "LockSessions", calls[len(calls)-1].Member; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestIdleHint(t *testing.T) {
+	bus, l := newTestConn(t)
+	const path dbus.ObjectPath = "/org/freedesktop/login1/session/_32"
+	bus.Handle(managerInterface, "GetSession", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		return "o", []any{path}, nil
+	})
+	var idle atomic.Bool
+	bus.Handle(sessionInterface, "SetIdleHint", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Path != path {
+			return "", nil, fmt.Errorf("unexpected path %s", m.Path)
+		}
+		idle.Store(m.Body[0].(bool))
+		return "", nil, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "Get", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		switch m.Body[1] {
+		case "IdleHint":
+			return "v", []any{dbus.MakeVariant(idle.Load() && m.Path == path)}, nil
+		case "IdleSinceHint":
+			return "v", []any{dbus.MakeVariant(uint64(1700000000000000))}, nil
+		}
+		return "", nil, fmt.Errorf("unexpected property %v", m.Body)
+	})
+	useRoot(t, map[string]string{"proc/self/cgroup": "0::/user.slice/user-1000.slice/session-2.scope\n"})
+
+	ctx := context.Background()
+	if err := l.SetIdleHint(ctx, true); err != nil {
+		t.Fatal(err)
+		return
+	}
+	hint, err := l.SessionIdleHint(ctx, "")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := (IdleHint{Idle: true, Since: time.UnixMicro(1700000000000000)}); hint != expected {
+		t.Errorf("expected %#v, but got %#v", expected, hint)
+	}
+	if hint, err := l.IdleHint(ctx); err != nil || hint.Idle {
+		t.Errorf("expected the system not to be idle, but got %#v, %v", hint, err)
+	}
+}
+
+func TestTakeControl(t *testing.T) {
+	bus, l := newTestConn(t)
+	const path dbus.ObjectPath = "/org/freedesktop/login1/session/_32"
+	bus.Handle(managerInterface, "GetSession", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		return "o", []any{path}, nil
+	})
+	for _, method := range []string{"TakeControl", "ReleaseControl", "ReleaseDevice", "PauseDeviceComplete"} {
+		bus.Handle(sessionInterface, method, func(*dbus.Message) (dbus.Signature, []any, error) { return "", nil, nil })
+	}
+	bus.Handle(sessionInterface, "TakeDevice", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] != uint32(1) || m.Body[1] != uint32(3) {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.login1.DeviceNotTaken"}
+		}
+		f, err := os.OpenFile(os.DevNull, os.O_RDWR, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		return "hb", []any{f, true}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	c, err := l.TakeControl(ctx, "2", false)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "2", c.Session(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	f, paused, err := c.TakeDevicePath(ctx, os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer f.Close()
+	if !paused {
+		t.Error("expected the device to be paused")
+	}
+
+	next := func() DeviceEvent {
+		select {
+		case ev := <-c.Events():
+			return ev
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+			return DeviceEvent{}
+		}
+	}
+	if err := bus.Emit(path, sessionInterface, "PauseDevice", "uus", uint32(1), uint32(3), "pause"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := (DeviceEvent{Type: DevicePause, Major: 1, Minor: 3}), next(); expected != got {
+		t.Errorf("expected %#v, but got %#v", expected, got)
+	}
+	if err := c.PauseDeviceComplete(ctx, 1, 3); err != nil {
+		t.Error(err)
+	}
+
+	resumed, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer resumed.Close()
+	if err := bus.Emit(path, sessionInterface, "ResumeDevice", "uuh", uint32(1), uint32(3), resumed); err != nil {
+		t.Fatal(err)
+		return
+	}
+	ev := next()
+	if ev.Type != DeviceResume || ev.Major != 1 || ev.Minor != 3 || ev.File == nil {
+		t.Errorf("unexpected event %#v", ev)
+	} else {
+		_ = ev.File.Close()
+	}
+
+	if err := c.ReleaseDevice(ctx, 1, 3); err != nil {
+		t.Error(err)
+	}
+	if err := c.Release(ctx); err != nil {
+		t.Fatal(err)
+		return
+	}
+	for range c.Events() {
+	}
+	if err := c.Release(ctx); err != nil {
+		t.Errorf("expected no error, but got %v", err)
+	}
+}
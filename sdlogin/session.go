@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdlogin
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoSession is returned when a process does not belong to a login session.
+var ErrNoSession = errors.New("sdlogin: not in a session")
+
+// ErrNotFound is returned when a session, user, or seat does not exist.
+var ErrNotFound = errors.New("sdlogin: not found")
+
+// Error is an error returned by logind.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.login1.NoSuchSession`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdlogin: " + e.Name
+	}
+	return "sdlogin: " + e.Message
+}
+
+// Is reports whether the error matches target, [ErrNotFound] matches errors
+// about sessions, users, and seats that do not exist.
+func (e *Error) Is(target error) bool {
+	if target != ErrNotFound {
+		return false
+	}
+	switch e.Name {
+	case "org.freedesktop.login1.NoSuchSession", "org.freedesktop.login1.NoSuchUser", "org.freedesktop.login1.NoSuchSeat":
+		return true
+	}
+	return false
+}
+
+// SessionClass is the class of a session.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/sd_session_is_active.html
+type SessionClass string
+
+const (
+	ClassUser            SessionClass = "user"
+	ClassUserEarly       SessionClass = "user-early"
+	ClassUserIncomplete  SessionClass = "user-incomplete"
+	ClassUserLight       SessionClass = "user-light"
+	ClassGreeter         SessionClass = "greeter"
+	ClassLockScreen      SessionClass = "lock-screen"
+	ClassBackground      SessionClass = "background"
+	ClassBackgroundLight SessionClass = "background-light"
+	ClassManager         SessionClass = "manager"
+	ClassManagerEarly    SessionClass = "manager-early"
+	ClassNone            SessionClass = "none"
+)
+
+// SessionType is the type of a session.
+type SessionType string
+
+const (
+	TypeUnspecified SessionType = "unspecified"
+	TypeTTY         SessionType = "tty"
+	TypeX11         SessionType = "x11"
+	TypeWayland     SessionType = "wayland"
+	TypeMir         SessionType = "mir"
+	TypeWeb         SessionType = "web"
+)
+
+// SessionState is the state of a session.
+type SessionState string
+
+const (
+	// StateOnline is a session that is logged in, but not in the foreground.
+	StateOnline SessionState = "online"
+	// StateActive is a session that is logged in and in the foreground of its
+	// seat.
+	StateActive SessionState = "active"
+	// StateClosing is a session that is logged out, but still has processes
+	// running.
+	StateClosing SessionState = "closing"
+)
+
+// Session is a login session.
+type Session struct {
+	// ID is the ID of the session, such as `2` or `c1`.
+	ID string
+	// UID is the ID of the user the session belongs to.
+	UID int
+	// User is the name of the user the session belongs to.
+	User string
+
+	Class SessionClass
+	Type  SessionType
+	State SessionState
+	// Active reports whether the session is in the foreground of its seat.
+	Active bool
+
+	// Seat is the seat the session is attached to, it is empty if the
+	// session is not attached to a seat, such as remote sessions.
+	Seat string
+	// VTNr is the virtual terminal of the session, or zero if it does not
+	// have one.
+	VTNr int
+	// TTY is the TTY of the session, such as `pts/0`.
+	TTY string
+	// Display is the X11 display of the session, such as `:0`.
+	Display string
+
+	// Remote reports whether the session is remote, such as an SSH login.
+	Remote bool
+	// RemoteHost and RemoteUser are the host and user the session was
+	// logged in from, if known.
+	RemoteHost string
+	RemoteUser string
+
+	// Service is the PAM service that registered the session, such as
+	// `sshd` or `gdm-password`.
+	Service string
+	// Desktop is the desktop environment of the session, such as `GNOME`.
+	Desktop string
+	// Scope is the scope unit of the session, such as `session-2.scope`.
+	Scope string
+	// Leader is the PID of the process that registered the session.
+	Leader int
+	// Started is when the session was created.
+	Started time.Time
+}
+
+// UserState is the state of a user.
+type UserState string
+
+const (
+	// UserOffline is a user that is not logged in.
+	UserOffline UserState = "offline"
+	// UserLingering is a user that is not logged in, but has lingering
+	// enabled so their service manager keeps running.
+	UserLingering UserState = "lingering"
+	// UserOnline is a user that is logged in, but none of their sessions are
+	// in the foreground.
+	UserOnline UserState = "online"
+	// UserActive is a user that is logged in with a session in the foreground
+	// of a seat.
+	UserActive UserState = "active"
+	// UserClosing is a user that is logged out, but still has processes
+	// running.
+	UserClosing UserState = "closing"
+)
+
+// User is a user tracked by logind, either as they are logged in or have
+// lingering enabled.
+type User struct {
+	// UID is the ID of the user.
+	UID int
+	// Name is the name of the user.
+	Name  string
+	State UserState
+
+	// Sessions are the IDs of the sessions of the user.
+	Sessions []string
+	// Seats are the seats the user has sessions on.
+	Seats []string
+	// Display is the ID of the primary graphical session of the user, if any.
+	Display string
+
+	// RuntimePath is the runtime directory of the user, such as
+	// `/run/user/1000`.
+	RuntimePath string
+	// Slice is the slice unit of the user, such as `user-1000.slice`.
+	Slice string
+	// Service is the service unit of the service manager of the user, such
+	// as `user@1000.service`.
+	Service string
+	// Started is when the user logged in.
+	Started time.Time
+}
+
+// Seat is a seat, a set of hardware devices a user sits in front of, such as
+// a display, keyboard, and mouse.
+type Seat struct {
+	// ID is the ID of the seat, such as `seat0`.
+	ID string
+	// Active is the ID of the session in the foreground of the seat, if any.
+	Active string
+	// ActiveUID is the user of the session in the foreground of the seat, it
+	// is only valid if Active is not empty.
+	ActiveUID int
+	// Sessions are the IDs of the sessions on the seat.
+	Sessions []string
+	// UIDs are the users with sessions on the seat.
+	UIDs []int
+
+	// CanTTY reports whether the seat has text consoles.
+	CanTTY bool
+	// CanGraphical reports whether the seat has a graphics device.
+	CanGraphical bool
+	// CanMultiSession reports whether the seat supports multiple sessions.
+	CanMultiSession bool
+}
+
+// LockEvent is an event received from [Conn.WatchLock].
+type LockEvent struct {
+	// Session is the ID of the session.
+	Session string
+	// Locked is true if the session is asked to lock, and false if it is
+	// asked to unlock.
+	Locked bool
+}
+
+// IdleHint is the idle state of a session, or of the system as a whole.
+type IdleHint struct {
+	// Idle is true if the session is idle.
+	Idle bool
+	// Since is when the idle state last changed, it is zero if it never did.
+	Since time.Time
+}
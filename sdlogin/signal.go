@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdlogin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// signalRule returns the match rule for a signal emitted by logind.
+func signalRule(path dbus.ObjectPath, iface, member string) string {
+	return "type='signal',sender='" + destination + "',path='" + string(path) + "',interface='" + iface + "',member='" + member + "'"
+}
+
+// watch returns a channel that receives the signals emitted by logind on path
+// matching iface and one of members, until ctx is done or the connection is
+// closed, after which the channel is closed.
+//
+// Signals are queued and never dropped.
+func (l *Conn) watch(ctx context.Context, path dbus.ObjectPath, iface string, members ...string) (<-chan *dbus.Message, error) {
+	rules := make([]string, 0, len(members))
+	removeMatches := func() {
+		for _, rule := range rules {
+			_ = l.conn.RemoveMatch(context.WithoutCancel(ctx), rule)
+		}
+	}
+	for _, member := range members {
+		rule := signalRule(path, iface, member)
+		if err := l.conn.AddMatch(ctx, rule); err != nil {
+			removeMatches()
+			return nil, fmt.Errorf("sdlogin: unable to add match rule: %w", convertError(err))
+		}
+		rules = append(rules, rule)
+	}
+
+	var (
+		mu     sync.Mutex
+		queue  []*dbus.Message
+		notify = make(chan struct{}, 1)
+	)
+	remove := l.conn.AddSignalHandler(func(msg *dbus.Message) {
+		if msg.Path != path || msg.Interface != iface || !slices.Contains(members, msg.Member) {
+			return
+		}
+		mu.Lock()
+		queue = append(queue, msg)
+		mu.Unlock()
+		select {
+		case notify <- struct{}{}:
+		default:
+		}
+	})
+
+	ch := make(chan *dbus.Message)
+	go func() {
+		defer close(ch)
+		defer func() {
+			remove()
+			removeMatches()
+		}()
+		for {
+			mu.Lock()
+			pending := queue
+			queue = nil
+			mu.Unlock()
+
+			for _, msg := range pending {
+				select {
+				case ch <- msg:
+				case <-ctx.Done():
+					return
+				case <-l.conn.Done():
+					return
+				}
+			}
+
+			select {
+			case <-notify:
+			case <-ctx.Done():
+				return
+			case <-l.conn.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// WatchSleep returns a channel that receives an event before the system is
+// suspended or hibernated, and another once it resumes, until ctx is done or
+// the connection is closed, after which the channel is closed.
+//
+// A delay inhibitor lock is held on behalf of the caller, see [Conn.Inhibit]
+// for who and why, so the system does not go to sleep until
+// [PrepareEvent.Done] is called, giving the caller the chance to flush state
+// and close connections. The lock is taken again once the system resumes.
+func (l *Conn) WatchSleep(ctx context.Context, who, why string) (<-chan PrepareEvent, error) {
+	return l.watchPrepare(ctx, InhibitSleep, "PrepareForSleep", who, why)
+}
+
+// WatchShutdown is like [Conn.WatchSleep], but for the system being powered
+// off or rebooted. The second event is only received if the shutdown is
+// canceled.
+func (l *Conn) WatchShutdown(ctx context.Context, who, why string) (<-chan PrepareEvent, error) {
+	return l.watchPrepare(ctx, InhibitShutdown, "PrepareForShutdown", who, why)
+}
+
+// watchPrepare watches the member signal of logind while holding a delay
+// inhibitor lock for what.
+func (l *Conn) watchPrepare(ctx context.Context, what InhibitWhat, member, who, why string) (<-chan PrepareEvent, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	// The signal is watched before taking the lock, so it cannot be missed in
+	// between.
+	signals, err := l.watch(ctx, managerPath, managerInterface, member)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	inhibit := func() (*Inhibitor, error) {
+		return l.Inhibit(ctx, []InhibitWhat{what}, who, why, InhibitDelay)
+	}
+	lock, err := inhibit()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	ch := make(chan PrepareEvent)
+	go func() {
+		defer close(ch)
+		defer cancel()
+		defer func() {
+			if lock != nil {
+				_ = lock.Release()
+			}
+		}()
+		for msg := range signals {
+			if len(msg.Body) != 1 {
+				continue
+			}
+			active, ok := msg.Body[0].(bool)
+			if !ok {
+				continue
+			}
+			ev := PrepareEvent{Active: active}
+			switch {
+			case active && lock != nil:
+				// Ownership of the lock is passed on to the event.
+				held := lock
+				ev.release = sync.OnceFunc(func() { _ = held.Release() })
+				lock = nil
+			case !active && lock == nil:
+				lock, ev.Err = inhibit()
+			}
+			select {
+			case ch <- ev:
+			case <-ctx.Done():
+				ev.Done()
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// WatchLock returns a channel that receives an event whenever a session is
+// asked to lock or unlock its screen, such as by `loginctl lock-session`,
+// until ctx is done or the connection is closed, after which the channel is
+// closed. If id is empty, the session of the current process is watched, see
+// [CurrentSession].
+//
+// Screen lockers and agents that pause work or drop sensitive caches while
+// the session is locked should use this, events are queued and never dropped.
+func (l *Conn) WatchLock(ctx context.Context, id string) (<-chan LockEvent, error) {
+	id, path, err := l.sessionPath(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := l.watch(ctx, path, sessionInterface, "Lock", "Unlock")
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan LockEvent)
+	go func() {
+		defer close(ch)
+		for msg := range signals {
+			select {
+			case ch <- LockEvent{Session: id, Locked: msg.Member == "Lock"}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdmachine provides a client for systemd-machined, which tracks the
+// containers and virtual machines running on the host, the same as
+// `machinectl`.
+//
+// Registering a machine with machined makes it visible to `machinectl`, places
+// its processes in a scope unit of its own, and allows the journal of the host
+// to be filtered by the machine.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.machine1.html
+package sdmachine
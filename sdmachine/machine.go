@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmachine
+
+import (
+	"errors"
+
+	"github.com/matthewpi/sd/sdid128"
+)
+
+// ErrNotFound is returned when a machine does not exist.
+var ErrNotFound = errors.New("sdmachine: no such machine")
+
+// ErrExists is returned when registering a machine with the name of a machine
+// that is already registered.
+var ErrExists = errors.New("sdmachine: machine already exists")
+
+// Error is an error returned by machined.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.machine1.NoSuchMachine`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdmachine: " + e.Name
+	}
+	return "sdmachine: " + e.Message
+}
+
+// Is allows the error to be matched against the sentinel errors of this
+// package using [errors.Is].
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Name == "org.freedesktop.machine1.NoSuchMachine"
+	case ErrExists:
+		return e.Name == "org.freedesktop.machine1.MachineExists"
+	default:
+		return false
+	}
+}
+
+// Class is the class of a machine.
+type Class string
+
+const (
+	ClassContainer Class = "container"
+	ClassVM        Class = "vm"
+)
+
+// Machine is a machine registered with machined, as returned by
+// [Conn.ListMachines].
+type Machine struct {
+	// Name is the name of the machine.
+	Name string
+	// Class is the class of the machine.
+	Class Class
+	// Service is the program that registered the machine, such as `nspawn`.
+	Service string
+}
+
+// MachineOptions are the options used to register a machine with
+// [Conn.RegisterMachine] or [Conn.CreateMachine].
+type MachineOptions struct {
+	// ID is the ID of the machine, usually its machine ID, which may be
+	// [sdid128.Null] if unknown.
+	ID sdid128.ID128
+	// Service is a short name of the program registering the machine, such as
+	// `nspawn` or `qemu`.
+	Service string
+	// Class is the class of the machine, [ClassContainer] if empty.
+	Class Class
+	// Leader is the PID of the init process of a container or the process of
+	// the hypervisor of a virtual machine. If zero, the calling process is
+	// used.
+	Leader int
+	// RootDirectory is the path of the root directory of a container on the
+	// host, if any.
+	RootDirectory string
+	// NetworkInterfaces are the indices of the network interfaces on the host
+	// that belong to the machine, such as the host side of a veth pair.
+	NetworkInterfaces []int
+}
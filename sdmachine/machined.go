@@ -0,0 +1,214 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmachine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of machined.
+	destination = "org.freedesktop.machine1"
+
+	// managerPath is the object path of machined.
+	managerPath dbus.ObjectPath = "/org/freedesktop/machine1"
+
+	// managerInterface is the interface of machined.
+	managerInterface = "org.freedesktop.machine1.Manager"
+
+	// machineInterface is the interface of machines.
+	machineInterface = "org.freedesktop.machine1.Machine"
+)
+
+// Conn is a connection to systemd-machined on the system bus.
+type Conn struct {
+	conn *dbus.Conn
+}
+
+// New connects to machined on the system bus.
+func New(ctx context.Context) (*Conn, error) {
+	c, err := dbus.SystemBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdmachine: unable to connect to bus: %w", err)
+	}
+	return newConn(c), nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection.
+func newConn(c *dbus.Conn) *Conn {
+	return &Conn{conn: c}
+}
+
+// Close closes the connection to machined.
+func (m *Conn) Close() error {
+	return m.conn.Close()
+}
+
+// convertError converts a D-Bus error into an [Error].
+func convertError(err error) error {
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Message: e.Message()}
+	}
+	return err
+}
+
+// call calls a method on machined, returning the body of the reply.
+func (m *Conn) call(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	body, err := m.conn.Call(ctx, destination, managerPath, managerInterface, method, sig, args...)
+	return body, convertError(err)
+}
+
+// register calls CreateMachine if create is true, otherwise RegisterMachine,
+// using the variant taking network interfaces if any are given.
+func (m *Conn) register(ctx context.Context, name string, opts MachineOptions, create bool) error {
+	class := opts.Class
+	if class == "" {
+		class = ClassContainer
+	}
+	// machined expects an empty ID if it is unknown.
+	var id []byte
+	if !opts.ID.IsNull() {
+		id = opts.ID.Bytes()
+	}
+	method := "RegisterMachine"
+	if create {
+		method = "CreateMachine"
+	}
+	sig := dbus.Signature("sayssus")
+	args := []any{name, id, opts.Service, string(class), uint32(opts.Leader), opts.RootDirectory}
+	if len(opts.NetworkInterfaces) > 0 {
+		ifindices := make([]any, len(opts.NetworkInterfaces))
+		for i, v := range opts.NetworkInterfaces {
+			ifindices[i] = int32(v)
+		}
+		method += "WithNetwork"
+		sig += "ai"
+		args = append(args, ifindices)
+	}
+	if create {
+		// No properties are set on the scope unit.
+		sig += "a(sv)"
+		args = append(args, []any{})
+	}
+	if _, err := m.call(ctx, method, sig, args...); err != nil {
+		return fmt.Errorf("sdmachine: unable to register machine %s: %w", name, err)
+	}
+	return nil
+}
+
+// RegisterMachine registers a machine whose processes are already running in
+// a unit of their own, such as a service started by the caller, with the
+// leader process of the machine.
+//
+// If a machine with the same name is already registered, an error matching
+// [ErrExists] is returned.
+func (m *Conn) RegisterMachine(ctx context.Context, name string, opts MachineOptions) error {
+	return m.register(ctx, name, opts, false)
+}
+
+// CreateMachine registers a machine, moving its leader process into a new
+// scope unit named after the machine, such as `machine-<name>.scope`.
+//
+// If a machine with the same name is already registered, an error matching
+// [ErrExists] is returned.
+func (m *Conn) CreateMachine(ctx context.Context, name string, opts MachineOptions) error {
+	return m.register(ctx, name, opts, true)
+}
+
+// TerminateMachine terminates a machine, killing all of its processes.
+//
+// If the machine does not exist, an error matching [ErrNotFound] is returned.
+func (m *Conn) TerminateMachine(ctx context.Context, name string) error {
+	if _, err := m.call(ctx, "TerminateMachine", "s", name); err != nil {
+		return fmt.Errorf("sdmachine: unable to terminate machine %s: %w", name, err)
+	}
+	return nil
+}
+
+// ListMachines returns the machines registered with machined, the same as
+// `machinectl list`.
+func (m *Conn) ListMachines(ctx context.Context) ([]Machine, error) {
+	body, err := m.call(ctx, "ListMachines", "")
+	if err != nil {
+		return nil, fmt.Errorf("sdmachine: unable to list machines: %w", err)
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdmachine: unable to list machines: unexpected reply of %d values", len(body))
+	}
+	values, _ := body[0].([]any)
+	machines := make([]Machine, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(dbus.Struct)
+		if !ok || len(s) != 4 {
+			continue
+		}
+		var machine Machine
+		machine.Name, _ = s[0].(string)
+		class, _ := s[1].(string)
+		machine.Class = Class(class)
+		machine.Service, _ = s[2].(string)
+		machines = append(machines, machine)
+	}
+	return machines, nil
+}
+
+// GetMachineLeader returns the PID of the leader process of a machine, such as
+// the init process of a container.
+//
+// If the machine does not exist, an error matching [ErrNotFound] is returned.
+func (m *Conn) GetMachineLeader(ctx context.Context, name string) (int, error) {
+	body, err := m.call(ctx, "GetMachine", "s", name)
+	if err != nil {
+		return 0, fmt.Errorf("sdmachine: unable to get machine %s: %w", name, err)
+	}
+	if len(body) != 1 {
+		return 0, fmt.Errorf("sdmachine: unable to get machine %s: unexpected reply of %d values", name, len(body))
+	}
+	path, _ := body[0].(dbus.ObjectPath)
+	v, err := m.conn.GetProperty(ctx, destination, path, machineInterface, "Leader")
+	if err != nil {
+		return 0, fmt.Errorf("sdmachine: unable to get leader of machine %s: %w", name, convertError(err))
+	}
+	leader, ok := v.Value.(uint32)
+	if !ok {
+		return 0, fmt.Errorf("sdmachine: unable to get leader of machine %s: unexpected value of type %T", name, v.Value)
+	}
+	return int(leader), nil
+}
+
+// GetMachineAddresses returns the IP addresses of a machine, the same as
+// shown by `machinectl status`. Only containers with their own network
+// namespace have addresses.
+//
+// If the machine does not exist, an error matching [ErrNotFound] is returned.
+func (m *Conn) GetMachineAddresses(ctx context.Context, name string) ([]netip.Addr, error) {
+	body, err := m.call(ctx, "GetMachineAddresses", "s", name)
+	if err != nil {
+		return nil, fmt.Errorf("sdmachine: unable to get addresses of machine %s: %w", name, err)
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdmachine: unable to get addresses of machine %s: unexpected reply of %d values", name, len(body))
+	}
+	values, _ := body[0].([]any)
+	addrs := make([]netip.Addr, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(dbus.Struct)
+		if !ok || len(s) != 2 {
+			continue
+		}
+		raw, _ := s[1].([]byte)
+		if addr, ok := netip.AddrFromSlice(raw); ok {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs, nil
+}
@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdmachine
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+)
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) RegisterMachine(context.Context, string, MachineOptions) error {
+	return errors.ErrUnsupported
+}
+
+func (*Conn) CreateMachine(context.Context, string, MachineOptions) error {
+	return errors.ErrUnsupported
+}
+
+func (*Conn) TerminateMachine(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) ListMachines(context.Context) ([]Machine, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) GetMachineLeader(context.Context, string) (int, error) {
+	return 0, errors.ErrUnsupported
+}
+
+func (*Conn) GetMachineAddresses(context.Context, string) ([]netip.Addr, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmachine
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+func newTestConn(t *testing.T) (*dbustest.Bus, *Conn) {
+	t.Helper()
+	bus, c := dbustest.New(t)
+	return bus, newConn(c)
+}
+
+func TestRegisterMachine(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	var (
+		mu       sync.Mutex
+		machines = make(map[string]bool)
+	)
+	register := func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := msg.Body[0].(string)
+		if machines[name] {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.machine1.MachineExists", Body: []any{"Machine '" + name + "' already exists"}}
+		}
+		machines[name] = true
+		return "o", []any{dbus.ObjectPath("/org/freedesktop/machine1/machine/" + name)}, nil
+	}
+	for _, method := range []string{"RegisterMachine", "CreateMachine", "RegisterMachineWithNetwork", "CreateMachineWithNetwork"} {
+		bus.Handle(managerInterface, method, register)
+	}
+	bus.Handle(managerInterface, "TerminateMachine", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		name := msg.Body[0].(string)
+		if !machines[name] {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.machine1.NoSuchMachine", Body: []any{"No machine '" + name + "' known"}}
+		}
+		delete(machines, name)
+		return "", nil, nil
+	})
+
+	ctx := context.Background()
+	id := sdid128.MustParse("d3b07384d1134ec49f5d0d2e9a5c6e3c")
+	if err := m.RegisterMachine(ctx, "web", MachineOptions{ID: id, Service: "runtime", Leader: 1234, RootDirectory: "/var/lib/machines/web"}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := m.CreateMachine(ctx, "db", MachineOptions{Service: "runtime", Class: ClassVM, Leader: 4321, NetworkInterfaces: []int{7}}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := m.CreateMachine(ctx, "web", MachineOptions{}); !errors.Is(err, ErrExists) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrExists, err)
+	}
+	if err := m.TerminateMachine(ctx, "web"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := m.TerminateMachine(ctx, "web"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNotFound, err)
+	}
+
+	calls := bus.Calls()
+	if len(calls) != 5 {
+		t.Fatalf("expected 5 calls, but got %d", len(calls))
+		return
+	}
+	for i, tc := range []struct {
+		member string
+		sig    dbus.Signature
+		body   []any
+	}{
+		{"RegisterMachine", "sayssus", []any{"web", id.Bytes(), "runtime", "container", uint32(1234), "/var/lib/machines/web"}},
+		{"CreateMachineWithNetwork", "sayssusaia(sv)", []any{"db", []byte(nil), "runtime", "vm", uint32(4321), "", []any{int32(7)}, []any{}}},
+		{"CreateMachine", "sayssusa(sv)", []any{"web", []byte(nil), "", "container", uint32(0), "", []any{}}},
+	} {
+		c := calls[i]
+		if c.Member != tc.member || c.Signature != tc.sig {
+			t.Errorf("expected call to %s(%s), but got %s(%s)", tc.member, tc.sig, c.Member, c.Signature)
+			continue
+		}
+		if !reflect.DeepEqual(c.Body, tc.body) {
+			t.Errorf("expected %#v, but got %#v", tc.body, c.Body)
+		}
+	}
+}
+
+func TestListMachines(t *testing.T) {
+	bus, m := newTestConn(t)
+	const path dbus.ObjectPath = "/org/freedesktop/machine1/machine/web"
+	bus.Handle(managerInterface, "ListMachines", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "a(ssso)", []any{[]any{
+			dbus.Struct{".host", "host", "", dbus.ObjectPath("/org/freedesktop/machine1/machine/_2ehost")},
+			dbus.Struct{"web", "container", "nspawn", path},
+		}}, nil
+	})
+	bus.Handle(managerInterface, "GetMachine", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if msg.Body[0] != "web" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.machine1.NoSuchMachine", Body: []any{"No machine known"}}
+		}
+		return "o", []any{path}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "Get", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if msg.Path != path || msg.Body[0] != machineInterface || msg.Body[1] != "Leader" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownProperty"}
+		}
+		return "v", []any{dbus.MakeVariant(uint32(1234))}, nil
+	})
+	bus.Handle(managerInterface, "GetMachineAddresses", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "a(iay)", []any{[]any{
+			dbus.Struct{int32(2), []byte{10, 0, 0, 2}},
+			dbus.Struct{int32(10), netip.MustParseAddr("fd00::2").AsSlice()},
+		}}, nil
+	})
+
+	ctx := context.Background()
+	machines, err := m.ListMachines(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := []Machine{{Name: ".host", Class: "host"}, {Name: "web", Class: ClassContainer, Service: "nspawn"}}
+	if !reflect.DeepEqual(expected, machines) {
+		t.Errorf("expected %v, but got %v", expected, machines)
+	}
+
+	leader, err := m.GetMachineLeader(ctx, "web")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if leader != 1234 {
+		t.Errorf("expected %d, but got %d", 1234, leader)
+	}
+	if _, err := m.GetMachineLeader(ctx, "db"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNotFound, err)
+	}
+
+	addrs, err := m.GetMachineAddresses(ctx, "web")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []netip.Addr{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("fd00::2")}; !reflect.DeepEqual(expected, addrs) {
+		t.Errorf("expected %v, but got %v", expected, addrs)
+	}
+}
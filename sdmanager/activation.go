@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// ListenStream returns a [Listen] for a stream socket, such as `[::]:8080` or
+// `/run/example.sock`, equivalent to `ListenStream=`.
+func ListenStream(address string) Listen {
+	return Listen{Type: "Stream", Address: address}
+}
+
+// ListenDatagram returns a [Listen] for a datagram socket, equivalent to
+// `ListenDatagram=`.
+func ListenDatagram(address string) Listen {
+	return Listen{Type: "Datagram", Address: address}
+}
+
+// ListenSequentialPacket returns a [Listen] for a sequential packet socket,
+// equivalent to `ListenSequentialPacket=`.
+func ListenSequentialPacket(address string) Listen {
+	return Listen{Type: "SequentialPacket", Address: address}
+}
+
+// SocketSpec describes the addresses a transient socket listens on and the
+// service it activates.
+//
+// Exactly one of Service or Exec must be set.
+type SocketSpec struct {
+	// Listen are the addresses the socket listens on.
+	Listen []Listen
+
+	// FileDescriptorName names the file descriptors passed to the service,
+	// allowing them to be identified using `sdlisten`.
+	FileDescriptorName string
+
+	// Accept spawns an instance of the service for each incoming connection,
+	// Service must be a template unit such as `echo@.service`.
+	Accept bool
+
+	// Service is the name of an existing service to activate when a
+	// connection or datagram arrives.
+	Service string
+
+	// Exec is a command to run as a transient service, created along with the
+	// socket, when a connection or datagram arrives. It cannot be used with
+	// Accept.
+	Exec []string
+
+	// ServiceProperties are additional properties of the transient service
+	// created for Exec.
+	ServiceProperties []Property
+}
+
+// properties returns the properties of the socket unit.
+func (s SocketSpec) properties() ([]Property, error) {
+	if len(s.Listen) == 0 {
+		return nil, errors.New("sdmanager: socket requires at least one address to listen on")
+	}
+	if (s.Service == "") == (len(s.Exec) == 0) {
+		return nil, errors.New("sdmanager: socket requires exactly one of Service or Exec")
+	}
+	if s.Accept && !strings.Contains(s.Service, "@.") {
+		return nil, errors.New("sdmanager: socket with Accept requires a template Service")
+	}
+
+	listen := make([]dbus.Struct, len(s.Listen))
+	for i, l := range s.Listen {
+		listen[i] = dbus.Struct{l.Type, l.Address}
+	}
+	props := []Property{
+		{Name: "Listen", Value: listen, Signature: "a(ss)"},
+		{Name: "Accept", Value: s.Accept, Signature: "b"},
+	}
+	if s.FileDescriptorName != "" {
+		props = append(props, Property{Name: "FileDescriptorName", Value: s.FileDescriptorName, Signature: "s"})
+	}
+	if s.Service != "" {
+		props = append(props, Property{Name: "Triggers", Value: []string{s.Service}, Signature: "as"})
+	}
+	return props, nil
+}
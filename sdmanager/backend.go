@@ -0,0 +1,22 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import "errors"
+
+// Backend is a transport used to communicate with the service manager.
+type Backend string
+
+const (
+	// BackendDBus communicates with the service manager over D-Bus, all
+	// operations are supported.
+	BackendDBus Backend = "dbus"
+	// BackendVarlink communicates with the service manager using its Varlink
+	// interfaces, only a subset of operations are supported.
+	BackendVarlink Backend = "varlink"
+)
+
+// ErrUnsupportedBackend is returned by operations that are not supported by
+// the [Backend] of a connection.
+var ErrUnsupportedBackend = errors.New("sdmanager: operation not supported by backend")
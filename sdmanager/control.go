@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/matthewpi/sd/sdunit"
+)
+
+// SetUnitProperties changes properties of a unit while it is running,
+// equivalent to `systemctl set-property`. This is mostly useful for resource
+// control properties, such as [PropMemoryMax], [PropCPUQuota],
+// [PropTasksMax], and [PropIPAddressDeny].
+//
+// If runtime is true, the changes are lost on reboot, otherwise they are
+// persisted as a drop-in under `/etc`. If name does not have a unit type
+// suffix, `.service` is assumed.
+func (m *Conn) SetUnitProperties(ctx context.Context, name string, runtime bool, props ...Property) error {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return fmt.Errorf("sdmanager: %w", err)
+	}
+	p, err := encodeProperties(props)
+	if err != nil {
+		return err
+	}
+	if err := m.call(ctx, "SetUnitProperties", "sba(sv)", name, runtime, p); err != nil {
+		return fmt.Errorf("sdmanager: unable to set properties of %s: %w", name, err)
+	}
+	return nil
+}
+
+// KillUnit sends a signal to the processes of a unit, equivalent to
+// `systemctl kill`. Unlike signalling a PID directly, this cannot race with the
+// unit restarting, as the service manager resolves the processes.
+//
+// If who is empty, [KillAll] is used. If name does not have a unit type
+// suffix, `.service` is assumed.
+func (m *Conn) KillUnit(ctx context.Context, name string, who KillWho, signal syscall.Signal) error {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return fmt.Errorf("sdmanager: %w", err)
+	}
+	if who == "" {
+		who = KillAll
+	}
+	if err := m.call(ctx, "KillUnit", "ssi", name, string(who), int32(signal)); err != nil {
+		return fmt.Errorf("sdmanager: unable to kill %s: %w", name, err)
+	}
+	return nil
+}
+
+// ResetFailedUnit resets the failed state of a unit, along with its restart
+// counter and start rate limit, equivalent to `systemctl reset-failed`. If
+// name does not have a unit type suffix, `.service` is assumed.
+func (m *Conn) ResetFailedUnit(ctx context.Context, name string) error {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return fmt.Errorf("sdmanager: %w", err)
+	}
+	if err := m.call(ctx, "ResetFailedUnit", "s", name); err != nil {
+		return fmt.Errorf("sdmanager: unable to reset failed state of %s: %w", name, err)
+	}
+	return nil
+}
+
+// ResetFailed resets the failed state of all units.
+func (m *Conn) ResetFailed(ctx context.Context) error {
+	if err := m.call(ctx, "ResetFailed", ""); err != nil {
+		return fmt.Errorf("sdmanager: unable to reset failed units: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DependencyType is the type of a dependency between two units, named after
+// the unit property it is read from.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html#%5BUnit%5D%20Section%20Options
+type DependencyType string
+
+const (
+	DependencyRequires DependencyType = "Requires"
+	DependencyWants    DependencyType = "Wants"
+	DependencyBindsTo  DependencyType = "BindsTo"
+	DependencyAfter    DependencyType = "After"
+
+	DependencyRequiredBy DependencyType = "RequiredBy"
+	DependencyWantedBy   DependencyType = "WantedBy"
+	DependencyBoundBy    DependencyType = "BoundBy"
+	DependencyBefore     DependencyType = "Before"
+)
+
+// ErrOrderingCycle is returned when units cannot be ordered as their ordering
+// dependencies form a cycle.
+var ErrOrderingCycle = errors.New("sdmanager: ordering cycle")
+
+// Dependency is a dependency of one unit on another.
+type Dependency struct {
+	// From is the unit the dependency is configured on.
+	From string
+	// To is the unit that is depended on.
+	To string
+	// Type is the type of the dependency.
+	Type DependencyType
+}
+
+// DependencyGraph is a graph of the dependencies between units, as returned by
+// [Conn.ListDependencies].
+type DependencyGraph struct {
+	// Root is the unit the graph was built from.
+	Root string
+	// Units are the properties of every unit in the graph, keyed by name.
+	Units map[string]*Unit
+	// Dependencies are the dependencies between the units, sorted by From,
+	// Type, and To.
+	Dependencies []Dependency
+}
+
+// dependencies returns the dependencies of u, or the units that depend on u
+// if reverse is true.
+func dependencies(u *Unit, reverse bool) []Dependency {
+	types := map[DependencyType][]string{
+		DependencyRequires: u.Requires,
+		DependencyWants:    u.Wants,
+		DependencyBindsTo:  u.BindsTo,
+		DependencyAfter:    u.After,
+	}
+	if reverse {
+		types = map[DependencyType][]string{
+			DependencyRequiredBy: u.RequiredBy,
+			DependencyWantedBy:   u.WantedBy,
+			DependencyBoundBy:    u.BoundBy,
+			DependencyBefore:     u.Before,
+		}
+	}
+	var deps []Dependency
+	for typ, names := range types {
+		for _, name := range names {
+			deps = append(deps, Dependency{From: u.Name, To: name, Type: typ})
+		}
+	}
+	return deps
+}
+
+// DependenciesOf returns the dependencies configured on the named unit.
+func (g *DependencyGraph) DependenciesOf(name string) []Dependency {
+	var deps []Dependency
+	for _, d := range g.Dependencies {
+		if d.From == name {
+			deps = append(deps, d)
+		}
+	}
+	return deps
+}
+
+// ordering returns the units each unit must be started after, according to
+// the `After=` and `Before=` dependencies in the graph.
+func (g *DependencyGraph) ordering() map[string][]string {
+	after := make(map[string][]string, len(g.Units))
+	for name := range g.Units {
+		after[name] = nil
+	}
+	for _, d := range g.Dependencies {
+		switch d.Type {
+		case DependencyAfter:
+			after[d.From] = append(after[d.From], d.To)
+		case DependencyBefore:
+			after[d.To] = append(after[d.To], d.From)
+		}
+	}
+	return after
+}
+
+// StartOrder returns the units in the graph in the order they are started,
+// every unit comes after the units it is ordered after. Units are stopped in
+// the reverse order. Units without an ordering between them are sorted by
+// name.
+//
+// If the ordering dependencies form a cycle, an error wrapping
+// [ErrOrderingCycle] is returned, see [DependencyGraph.Cycles].
+func (g *DependencyGraph) StartOrder() ([]string, error) {
+	after := g.ordering()
+	// pending is the number of units each unit is waiting on, blocks are the
+	// units waiting on each unit.
+	pending := make(map[string]int, len(after))
+	blocks := make(map[string][]string, len(after))
+	for name := range after {
+		pending[name] = 0
+	}
+	for name, deps := range after {
+		for _, dep := range slices.Compact(slices.Sorted(slices.Values(deps))) {
+			if _, ok := after[dep]; !ok {
+				continue
+			}
+			pending[name]++
+			blocks[dep] = append(blocks[dep], name)
+		}
+	}
+
+	var ready []string
+	for name, n := range pending {
+		if n == 0 {
+			ready = append(ready, name)
+		}
+	}
+	order := make([]string, 0, len(after))
+	for len(ready) > 0 {
+		slices.Sort(ready)
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for _, next := range blocks[name] {
+			pending[next]--
+			if pending[next] == 0 {
+				ready = append(ready, next)
+			}
+		}
+	}
+	if len(order) != len(after) {
+		cycles := g.Cycles()
+		parts := make([]string, len(cycles))
+		for i, c := range cycles {
+			parts[i] = strings.Join(c, ", ")
+		}
+		return nil, fmt.Errorf("%w between %s", ErrOrderingCycle, strings.Join(parts, "; "))
+	}
+	return order, nil
+}
+
+// Cycles returns the groups of units whose ordering dependencies form a
+// cycle. Each group is sorted by name, and the groups are sorted by their
+// first unit.
+func (g *DependencyGraph) Cycles() [][]string {
+	after := g.ordering()
+	names := slices.Sorted(maps.Keys(after))
+
+	// Tarjan's strongly connected components algorithm.
+	var (
+		index   = make(map[string]int, len(after))
+		low     = make(map[string]int, len(after))
+		stacked = make(map[string]bool, len(after))
+		stack   []string
+		cycles  [][]string
+		visit   func(string)
+	)
+	visit = func(name string) {
+		index[name] = len(index)
+		low[name] = index[name]
+		stack = append(stack, name)
+		stacked[name] = true
+		self := false
+		for _, dep := range after[name] {
+			if _, ok := after[dep]; !ok {
+				continue
+			}
+			if dep == name {
+				self = true
+			}
+			if _, ok := index[dep]; !ok {
+				visit(dep)
+				low[name] = min(low[name], low[dep])
+			} else if stacked[dep] {
+				low[name] = min(low[name], index[dep])
+			}
+		}
+		if low[name] != index[name] {
+			return
+		}
+		var scc []string
+		for {
+			n := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			stacked[n] = false
+			scc = append(scc, n)
+			if n == name {
+				break
+			}
+		}
+		if len(scc) > 1 || self {
+			slices.Sort(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, name := range names {
+		if _, ok := index[name]; !ok {
+			visit(name)
+		}
+	}
+	slices.SortFunc(cycles, func(a, b []string) int { return cmp.Compare(a[0], b[0]) })
+	return cycles
+}
@@ -0,0 +1,20 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdmanager provides a client for controlling the systemd service
+// manager over D-Bus, allowing units to be started, stopped, and inspected
+// without shelling out to `systemctl`.
+//
+// NOTE: this package is only useful on `linux` operating systems. Connecting
+// to the service manager returns [errors.ErrUnsupported] on other operating
+// systems.
+//
+// The client uses a small D-Bus implementation embedded in this module, no
+// third-party dependencies are required. If the system bus is unavailable, a
+// subset of operations are available using the Varlink interfaces of the
+// service manager, see [NewVarlink].
+//
+// See the [org.freedesktop.systemd1(5)] docs for more details.
+//
+// [org.freedesktop.systemd1(5)]: https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html
+package sdmanager
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+// EventType is the type of an [Event].
+type EventType string
+
+const (
+	// EventUnitNew is sent when a unit is loaded.
+	EventUnitNew EventType = "unit-new"
+	// EventUnitRemoved is sent when a unit is unloaded.
+	EventUnitRemoved EventType = "unit-removed"
+	// EventJobNew is sent when a job is queued.
+	EventJobNew EventType = "job-new"
+	// EventJobRemoved is sent when a job completes.
+	EventJobRemoved EventType = "job-removed"
+	// EventPropertiesChanged is sent when the properties of a unit change,
+	// such as its active state.
+	EventPropertiesChanged EventType = "properties-changed"
+	// EventReconnected is sent after the connection to the bus was lost and
+	// re-established, events may have been missed while disconnected so any
+	// cached state should be refreshed.
+	EventReconnected EventType = "reconnected"
+)
+
+// Event is a change reported by the service manager.
+type Event struct {
+	// Type is the type of the event.
+	Type EventType
+
+	// Unit is the name of the unit the event is for, it is empty for
+	// [EventReconnected].
+	Unit string
+
+	// Job is the ID of the job, only set for [EventJobNew] and
+	// [EventJobRemoved].
+	Job uint32
+
+	// Result is the result of the job, only set for [EventJobRemoved].
+	Result JobResult
+
+	// Changed holds the properties that changed along with their new values,
+	// only set for [EventPropertiesChanged].
+	Changed map[string]any
+
+	// Invalidated holds the properties that changed without their new values
+	// being included, only set for [EventPropertiesChanged].
+	Invalidated []string
+}
+
+// ActiveState returns the new active state of the unit, if it changed.
+func (e Event) ActiveState() (ActiveState, bool) {
+	s, ok := e.Changed["ActiveState"].(string)
+	return ActiveState(s), ok
+}
+
+// SubState returns the new sub-state of the unit, if it changed.
+func (e Event) SubState() (string, bool) {
+	s, ok := e.Changed["SubState"].(string)
+	return s, ok
+}
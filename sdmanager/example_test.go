@@ -0,0 +1,50 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager_test
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/matthewpi/sd/sdmanager"
+)
+
+func Example_provision() {
+	ctx := context.Background()
+
+	m, err := sdmanager.New(ctx)
+	if err != nil {
+		slog.Error("failed to connect to systemd", slog.Any("err", err))
+		return
+	}
+	defer m.Close()
+
+	// Link a generated unit file into the unit search path.
+	changes, err := m.LinkUnitFiles(ctx, []string{"/opt/example/example.service"}, sdmanager.UnitFileOptions{})
+	if err != nil {
+		slog.Error("failed to link unit file", slog.Any("err", err))
+		return
+	}
+	for _, c := range changes {
+		slog.Info("unit file changed", slog.String("type", string(c.Type)), slog.String("file", c.Filename))
+	}
+
+	// Reload systemd so it picks up the new unit file.
+	if err := m.Reload(ctx); err != nil {
+		slog.Error("failed to reload systemd", slog.Any("err", err))
+		return
+	}
+
+	// Enable the unit according to the preset policy and start it.
+	if _, _, err := m.PresetUnitFiles(ctx, []string{"example.service"}, sdmanager.PresetFull, sdmanager.UnitFileOptions{}); err != nil {
+		slog.Error("failed to preset unit file", slog.Any("err", err))
+		return
+	}
+	if _, err := m.StartUnit(ctx, "example.service", sdmanager.ModeReplace); err != nil {
+		slog.Error("failed to start unit", slog.Any("err", err))
+		return
+	}
+}
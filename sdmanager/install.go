@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/matthewpi/sd/sdunit"
+)
+
+// Reload reloads the configuration of the service manager, equivalent to
+// `systemctl daemon-reload`. It returns once the reload has completed.
+//
+// A reload is required for the service manager to pick up unit files that
+// were added or modified.
+func (m *Conn) Reload(ctx context.Context) error {
+	var err error
+	if m.varlink != "" {
+		err = m.callVarlink(ctx, "io.systemd.Manager.Reload", nil, false, nil)
+	} else {
+		err = m.call(ctx, "Reload", "")
+	}
+	if err != nil {
+		return fmt.Errorf("sdmanager: unable to reload: %w", err)
+	}
+	return nil
+}
+
+// LinkUnitFiles links unit files that are located outside the unit search
+// path into it, equivalent to `systemctl link`. Files must be absolute paths.
+//
+// The service manager must be reloaded using [Conn.Reload] before the linked
+// units may be used.
+func (m *Conn) LinkUnitFiles(ctx context.Context, files []string, opts UnitFileOptions) ([]UnitFileChange, error) {
+	body, err := m.callBus(ctx, "LinkUnitFiles", "asbb", files, opts.Runtime, opts.Force)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to link unit files: %w", err)
+	}
+	return decodeChanges("LinkUnitFiles", body, 0)
+}
+
+// PresetUnitFiles enables or disables unit files according to the preset
+// policy, equivalent to `systemctl preset`. It returns whether any of the unit
+// files contain an `[Install]` section, along with the changes made.
+func (m *Conn) PresetUnitFiles(ctx context.Context, files []string, mode PresetMode, opts UnitFileOptions) (bool, []UnitFileChange, error) {
+	if mode == "" {
+		mode = PresetFull
+	}
+	body, err := m.callBus(ctx, "PresetUnitFilesWithMode", "assbb", files, string(mode), opts.Runtime, opts.Force)
+	if err != nil {
+		return false, nil, fmt.Errorf("sdmanager: unable to preset unit files: %w", err)
+	}
+	changes, err := decodeChanges("PresetUnitFilesWithMode", body, 1)
+	if err != nil {
+		return false, nil, err
+	}
+	installInfo, _ := body[0].(bool)
+	return installInfo, changes, nil
+}
+
+// EnableUnitFiles enables unit files, equivalent to `systemctl enable`. It
+// returns whether any of the unit files contain an `[Install]` section, along
+// with the changes made.
+//
+// The service manager must be reloaded using [Conn.Reload] before the changes
+// take effect, see [ReloadRequired]. Enabling a unit does not start it.
+func (m *Conn) EnableUnitFiles(ctx context.Context, files []string, opts UnitFileOptions) (bool, []UnitFileChange, error) {
+	body, err := m.callBus(ctx, "EnableUnitFiles", "asbb", files, opts.Runtime, opts.Force)
+	if err != nil {
+		return false, nil, fmt.Errorf("sdmanager: unable to enable unit files: %w", err)
+	}
+	changes, err := decodeChanges("EnableUnitFiles", body, 1)
+	if err != nil {
+		return false, nil, err
+	}
+	installInfo, _ := body[0].(bool)
+	return installInfo, changes, nil
+}
+
+// DisableUnitFiles disables unit files, equivalent to `systemctl disable`.
+// [UnitFileOptions.Force] is ignored.
+//
+// The service manager must be reloaded using [Conn.Reload] before the changes
+// take effect, see [ReloadRequired]. Disabling a unit does not stop it.
+func (m *Conn) DisableUnitFiles(ctx context.Context, files []string, opts UnitFileOptions) ([]UnitFileChange, error) {
+	body, err := m.callBus(ctx, "DisableUnitFiles", "asb", files, opts.Runtime)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to disable unit files: %w", err)
+	}
+	return decodeChanges("DisableUnitFiles", body, 0)
+}
+
+// MaskUnitFiles masks unit files, preventing them from being started,
+// equivalent to `systemctl mask`.
+//
+// The service manager must be reloaded using [Conn.Reload] before the changes
+// take effect, see [ReloadRequired].
+func (m *Conn) MaskUnitFiles(ctx context.Context, files []string, opts UnitFileOptions) ([]UnitFileChange, error) {
+	body, err := m.callBus(ctx, "MaskUnitFiles", "asbb", files, opts.Runtime, opts.Force)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to mask unit files: %w", err)
+	}
+	return decodeChanges("MaskUnitFiles", body, 0)
+}
+
+// UnmaskUnitFiles unmasks unit files, equivalent to `systemctl unmask`.
+// [UnitFileOptions.Force] is ignored.
+//
+// The service manager must be reloaded using [Conn.Reload] before the changes
+// take effect, see [ReloadRequired].
+func (m *Conn) UnmaskUnitFiles(ctx context.Context, files []string, opts UnitFileOptions) ([]UnitFileChange, error) {
+	body, err := m.callBus(ctx, "UnmaskUnitFiles", "asb", files, opts.Runtime)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to unmask unit files: %w", err)
+	}
+	return decodeChanges("UnmaskUnitFiles", body, 0)
+}
+
+// GetUnitFileState returns the enablement state of a unit file, equivalent to
+// `systemctl is-enabled`. If name does not have a unit type suffix, `.service`
+// is assumed.
+func (m *Conn) GetUnitFileState(ctx context.Context, name string) (UnitFileState, error) {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: %w", err)
+	}
+	body, err := m.callBus(ctx, "GetUnitFileState", "s", name)
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: unable to get unit file state of %s: %w", name, err)
+	}
+	if len(body) != 1 {
+		return "", errors.New("sdmanager: invalid reply to GetUnitFileState")
+	}
+	state, ok := body[0].(string)
+	if !ok {
+		return "", errors.New("sdmanager: invalid reply to GetUnitFileState")
+	}
+	return UnitFileState(state), nil
+}
+
+// decodeChanges decodes the `a(sss)` changes at index i of the reply to
+// method.
+func decodeChanges(method string, body []any, i int) ([]UnitFileChange, error) {
+	var changes []UnitFileChange
+	if len(body) != i+1 {
+		return nil, fmt.Errorf("sdmanager: invalid reply to %s", method)
+	}
+	if err := setValue(reflect.ValueOf(&changes).Elem(), body[i], false); err != nil {
+		return nil, fmt.Errorf("sdmanager: invalid reply to %s: %w", method, err)
+	}
+	return changes, nil
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Mode controls how a job interacts with jobs that are already queued.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Methods
+type Mode string
+
+const (
+	// ModeReplace replaces any conflicting jobs that are already queued.
+	ModeReplace Mode = "replace"
+	// ModeFail fails if the job would conflict with a job that is already
+	// queued.
+	ModeFail Mode = "fail"
+	// ModeIsolate stops all other units when starting the unit, it is only
+	// valid for starting units.
+	ModeIsolate Mode = "isolate"
+	// ModeIgnoreDependencies ignores all dependencies of the unit.
+	ModeIgnoreDependencies Mode = "ignore-dependencies"
+	// ModeIgnoreRequirements ignores the requirement dependencies of the
+	// unit, but still honors ordering.
+	ModeIgnoreRequirements Mode = "ignore-requirements"
+)
+
+// KillWho selects which processes of a unit are sent a signal.
+type KillWho string
+
+const (
+	// KillMain sends the signal to the main process of the unit.
+	KillMain KillWho = "main"
+	// KillControl sends the signal to the control process of the unit, such
+	// as a running `ExecReload=` command.
+	KillControl KillWho = "control"
+	// KillAll sends the signal to all processes in the cgroup of the unit.
+	KillAll KillWho = "all"
+)
+
+// JobResult is the result of a completed job.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Signals
+type JobResult string
+
+const (
+	// JobDone indicates the job completed successfully.
+	JobDone JobResult = "done"
+	// JobCanceled indicates the job was canceled before it finished.
+	JobCanceled JobResult = "canceled"
+	// JobTimeout indicates the job timed out.
+	JobTimeout JobResult = "timeout"
+	// JobFailed indicates the job failed.
+	JobFailed JobResult = "failed"
+	// JobDependency indicates a job this job depended on failed.
+	JobDependency JobResult = "dependency"
+	// JobSkipped indicates the job was not applicable to the unit, such as
+	// reloading a unit that is not running.
+	JobSkipped JobResult = "skipped"
+	// JobInvalid indicates the job type is not applicable to the unit.
+	JobInvalid JobResult = "invalid"
+	// JobAssert indicates an assertion of the unit failed.
+	JobAssert JobResult = "assert"
+	// JobUnsupported indicates the unit type is not supported.
+	JobUnsupported JobResult = "unsupported"
+	// JobCollected indicates the unit was garbage collected before the job
+	// completed.
+	JobCollected JobResult = "collected"
+	// JobOnce indicates the unit may only be started once and has already
+	// been started.
+	JobOnce JobResult = "once"
+	// JobFrozen indicates the unit is frozen.
+	JobFrozen JobResult = "frozen"
+	// JobConcurrency indicates the concurrency limit of the unit's slice was
+	// hit.
+	JobConcurrency JobResult = "concurrency"
+)
+
+// Success reports whether the job completed successfully, a skipped job is
+// considered successful.
+func (r JobResult) Success() bool {
+	return r == JobDone || r == JobSkipped
+}
+
+// String implements [fmt.Stringer].
+func (r JobResult) String() string {
+	return string(r)
+}
+
+// JobError is returned when a job does not complete successfully.
+type JobError struct {
+	// Unit is the name of the unit the job was for.
+	Unit string
+	// Job is the ID of the job.
+	Job uint32
+	// Result is the result of the job.
+	Result JobResult
+}
+
+// Error implements [error].
+func (e *JobError) Error() string {
+	return fmt.Sprintf("sdmanager: job %d for %s finished with result %q", e.Job, e.Unit, e.Result)
+}
+
+// ErrNoSuchUnit is returned when a unit does not exist.
+var ErrNoSuchUnit = errors.New("sdmanager: no such unit")
+
+// Error is an error returned by the service manager.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.systemd1.NoSuchUnit` or `io.systemd.Unit.NoSuchUnit`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdmanager: " + e.Name
+	}
+	return "sdmanager: " + e.Message
+}
+
+// Is allows the error to be matched against the sentinel errors of this
+// package using [errors.Is].
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNoSuchUnit:
+		switch e.Name {
+		case "org.freedesktop.systemd1.NoSuchUnit", "org.freedesktop.systemd1.NoUnitForPID", "io.systemd.Unit.NoSuchUnit":
+			return true
+		}
+		return false
+	default:
+		return false
+	}
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdunit"
+)
+
+// ListUnits returns the units currently loaded in memory, equivalent to
+// `systemctl list-units --all`.
+//
+// If patterns is not empty, only units with a name matching one of the glob
+// patterns are returned, such as `*.service`. If states is not empty, only
+// units with a load, active, or sub-state matching one of the states are
+// returned, such as `failed`.
+func (m *Conn) ListUnits(ctx context.Context, patterns, states []string) ([]UnitStatus, error) {
+	if m.varlink != "" {
+		return m.listUnitsVarlink(ctx, patterns, states)
+	}
+	rows, err := m.listUnits(ctx, patterns, states)
+	if err != nil {
+		return nil, err
+	}
+	units := make([]UnitStatus, len(rows))
+	for i, r := range rows {
+		units[i] = r.status
+	}
+	return units, nil
+}
+
+// ListInstances returns the loaded instances of a template unit, such as
+// `app@.service`, equivalent to `systemctl list-units --all 'app@*.service'`.
+// Use [sdunit.SplitInstance] to get the instance of each unit, and
+// [sdunit.ReadInstances] to find instances that are enabled but not loaded.
+func (m *Conn) ListInstances(ctx context.Context, template string) ([]UnitStatus, error) {
+	if !sdunit.IsTemplate(template) {
+		return nil, fmt.Errorf("sdmanager: invalid template unit name (%s)", template)
+	}
+	prefix, suffix, _ := strings.Cut(template, "@")
+	// Backslashes of escaped characters would be taken as escapes in the
+	// pattern.
+	pattern := strings.ReplaceAll(prefix, `\`, `\\`) + "@*" + suffix
+	units, err := m.ListUnits(ctx, []string{pattern}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(units, func(u UnitStatus) bool {
+		t, _, err := sdunit.SplitInstance(u.Name)
+		return err != nil || t != template
+	}), nil
+}
+
+// unitRow is a row returned by `ListUnitsByPatterns`.
+type unitRow struct {
+	status UnitStatus
+	path   dbus.ObjectPath
+}
+
+func (m *Conn) listUnits(ctx context.Context, patterns, states []string) ([]unitRow, error) {
+	body, err := m.callBus(ctx, "ListUnitsByPatterns", "asas", states, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to list units: %w", err)
+	}
+	if len(body) != 1 {
+		return nil, errors.New("sdmanager: invalid reply to ListUnitsByPatterns")
+	}
+	items, ok := body[0].([]any)
+	if !ok {
+		return nil, errors.New("sdmanager: invalid reply to ListUnitsByPatterns")
+	}
+
+	rows := make([]unitRow, 0, len(items))
+	for _, item := range items {
+		// (s name, s description, s load_state, s active_state, s sub_state,
+		// s following, o unit_path, u job_id, s job_type, o job_path)
+		s, ok := item.(dbus.Struct)
+		if !ok || len(s) != 10 {
+			return nil, errors.New("sdmanager: invalid reply to ListUnitsByPatterns")
+		}
+		var (
+			r   unitRow
+			str = func(i int) string { v, _ := s[i].(string); return v }
+		)
+		r.status.Name = str(0)
+		r.status.Description = str(1)
+		r.status.LoadState = LoadState(str(2))
+		r.status.ActiveState = ActiveState(str(3))
+		r.status.SubState = str(4)
+		r.status.Following = str(5)
+		r.path, _ = s[6].(dbus.ObjectPath)
+		r.status.JobID, _ = s[7].(uint32)
+		r.status.JobType = str(8)
+		rows = append(rows, r)
+	}
+	slices.SortFunc(rows, func(a, b unitRow) int { return cmp.Compare(a.status.Name, b.status.Name) })
+	return rows, nil
+}
+
+// ListSockets returns the addresses socket units listen on along with the
+// units they activate, equivalent to `systemctl list-sockets --all`. The
+// listings are sorted by address.
+func (m *Conn) ListSockets(ctx context.Context) ([]SocketListing, error) {
+	c, err := m.bus()
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to list sockets: %w", err)
+	}
+	rows, err := m.listUnits(ctx, []string{"*.socket"}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var listings []SocketListing
+	for _, r := range rows {
+		var props struct {
+			Listen   []Listen
+			Triggers []string
+		}
+		socket, err := c.GetAllProperties(ctx, destination, r.path, socketInterface)
+		if err != nil {
+			return nil, fmt.Errorf("sdmanager: unable to get properties of %s: %w", r.status.Name, convertError(err))
+		}
+		unit, err := c.GetAllProperties(ctx, destination, r.path, unitInterface)
+		if err != nil {
+			return nil, fmt.Errorf("sdmanager: unable to get properties of %s: %w", r.status.Name, convertError(err))
+		}
+		if v, ok := unit["Triggers"]; ok {
+			socket["Triggers"] = v
+		}
+		if err := decodeProperties(socket, &props); err != nil {
+			return nil, err
+		}
+		for _, l := range props.Listen {
+			listings = append(listings, SocketListing{
+				Listen:    l,
+				Unit:      r.status.Name,
+				Activates: props.Triggers,
+			})
+		}
+	}
+	slices.SortStableFunc(listings, func(a, b SocketListing) int {
+		return cmp.Compare(a.Listen.Address, b.Listen.Address)
+	})
+	return listings, nil
+}
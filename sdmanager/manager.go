@@ -0,0 +1,554 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of the service manager.
+	destination = "org.freedesktop.systemd1"
+
+	// managerPath is the object path of the service manager.
+	managerPath dbus.ObjectPath = "/org/freedesktop/systemd1"
+
+	// managerInterface is the interface of the service manager.
+	managerInterface = "org.freedesktop.systemd1.Manager"
+
+	// managerRule is the match rule for signals emitted by the service
+	// manager, such as when a job completes.
+	managerRule = "type='signal',sender='" + destination + "',path='" + string(managerPath) + "',interface='" + managerInterface + "'"
+
+	// privateAddress is the address of the private socket of the system
+	// service manager, which speaks D-Bus directly without a bus daemon.
+	privateAddress = "unix:path=/run/systemd/private"
+
+	// reconnectTimeout is the maximum time to spend on a single reconnection
+	// attempt.
+	reconnectTimeout = 30 * time.Second
+
+	// minReconnectDelay and maxReconnectDelay bound the delay between
+	// reconnection attempts.
+	minReconnectDelay = 100 * time.Millisecond
+	maxReconnectDelay = 5 * time.Second
+)
+
+// errClosed is returned when the connection is closed while reconnecting.
+var errClosed = errors.New("sdmanager: connection closed")
+
+// Conn is a connection to the systemd service manager.
+//
+// If the connection to the bus is lost, such as when the bus is restarted, it
+// is re-established automatically. Calls made while disconnected fail.
+type Conn struct {
+	// dial establishes a new connection to the bus, it is nil if the
+	// connection cannot be re-established.
+	dial func(context.Context) (*dbus.Conn, error)
+
+	// done is closed once the connection is closed.
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// varlink is the path of the Varlink socket of the service manager, it is
+	// set instead of conn when the service manager is connected to using
+	// Varlink.
+	varlink string
+
+	mu            sync.Mutex
+	conn          *dbus.Conn
+	removeHandler func()
+
+	// waiters are the jobs being waited on, keyed by their object path.
+	waiters map[dbus.ObjectPath]*Job
+	// removed holds the results of jobs that completed while a job was being
+	// queued, the service manager may remove a job before replying with its
+	// path.
+	removed map[dbus.ObjectPath]JobResult
+	// queuing is the number of jobs currently being queued.
+	queuing int
+
+	// subscribers receive events for signals emitted by the service manager.
+	subscribers map[*subscriber]struct{}
+	// watching is set once the match rule for property changes has been
+	// added.
+	watching bool
+}
+
+// New connects to the system service manager.
+//
+// The system bus is preferred, if it is unavailable, such as in early boot or
+// minimal images without a bus daemon, the private socket of the service
+// manager is used when running as root, see [NewPrivate]. Otherwise the Varlink
+// interface of the service manager is used, see [NewVarlink] for the
+// operations available over Varlink.
+func New(ctx context.Context) (*Conn, error) {
+	m, err := dial(ctx, dbus.SystemBus)
+	if err == nil {
+		return m, nil
+	}
+	if m, perr := NewPrivate(ctx); perr == nil {
+		return m, nil
+	}
+	if m, verr := NewVarlink(ctx); verr == nil {
+		return m, nil
+	}
+	return nil, err
+}
+
+// NewPrivate connects directly to the private socket of the system service
+// manager, the same as `systemctl` does when running as root. No bus daemon is
+// required, allowing the service manager to be controlled in early boot,
+// emergency mode, and minimal containers where D-Bus is not running.
+//
+// The private socket is only accessible by root.
+func NewPrivate(ctx context.Context) (*Conn, error) {
+	return dial(ctx, dialPrivate)
+}
+
+// dialPrivate connects to the private socket of the system service manager.
+func dialPrivate(ctx context.Context) (*dbus.Conn, error) {
+	return dbus.Dial(ctx, privateAddress)
+}
+
+// NewUser connects to the service manager of the current user, `systemd
+// --user`, on the session bus. The session bus is located using
+// `$DBUS_SESSION_BUS_ADDRESS`, or `$XDG_RUNTIME_DIR/bus` if it is unset.
+//
+// The user service manager supports the same operations as the system service
+// manager, limited to the units of the user.
+func NewUser(ctx context.Context) (*Conn, error) {
+	return dial(ctx, dbus.SessionBus)
+}
+
+// dial connects to the service manager on the bus returned by fn.
+func dial(ctx context.Context, fn func(context.Context) (*dbus.Conn, error)) (*Conn, error) {
+	c, err := fn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to connect to bus: %w", err)
+	}
+	m, err := newConn(ctx, c, fn)
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection. If dial
+// is not nil, it is used to re-establish the connection if it is lost.
+func newConn(ctx context.Context, c *dbus.Conn, dial func(context.Context) (*dbus.Conn, error)) (*Conn, error) {
+	m := &Conn{
+		dial:        dial,
+		done:        make(chan struct{}),
+		waiters:     make(map[dbus.ObjectPath]*Job),
+		removed:     make(map[dbus.ObjectPath]JobResult),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+	if err := m.setup(ctx, c, false); err != nil {
+		return nil, err
+	}
+	go m.reconnectLoop()
+	return m, nil
+}
+
+// setup subscribes to the signals of the service manager on c and makes it
+// the current connection.
+func (m *Conn) setup(ctx context.Context, c *dbus.Conn, watch bool) error {
+	removeHandler := c.AddSignalHandler(m.handleSignal)
+
+	var rules []string
+	// Match rules are only needed on a bus, the service manager sends signals
+	// directly to subscribed peers of its private socket.
+	if !isPeer(c) {
+		rules = append(rules, managerRule)
+		if watch {
+			rules = append(rules, propertiesRule)
+		}
+	}
+	for _, rule := range rules {
+		if err := c.AddMatch(ctx, rule); err != nil {
+			removeHandler()
+			return fmt.Errorf("sdmanager: unable to add match rule: %w", err)
+		}
+	}
+	// The service manager only emits signals once at least one client has
+	// subscribed.
+	if _, err := c.Call(ctx, destination, managerPath, managerInterface, "Subscribe", ""); err != nil {
+		removeHandler()
+		return fmt.Errorf("sdmanager: unable to subscribe: %w", convertError(err))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-m.done:
+		removeHandler()
+		return errClosed
+	default:
+	}
+	m.conn = c
+	m.removeHandler = removeHandler
+	return nil
+}
+
+// reconnectLoop re-establishes the connection whenever it is lost, until the
+// [Conn] is closed.
+func (m *Conn) reconnectLoop() {
+	for {
+		m.mu.Lock()
+		c := m.conn
+		m.mu.Unlock()
+		select {
+		case <-m.done:
+			return
+		case <-c.Done():
+		}
+		if m.dial == nil {
+			m.shutdown()
+			return
+		}
+
+		delay := minReconnectDelay
+		for {
+			select {
+			case <-m.done:
+				return
+			case <-time.After(delay):
+			}
+			if m.reconnect() {
+				break
+			}
+			delay = min(delay*2, maxReconnectDelay)
+		}
+		m.broadcast(Event{Type: EventReconnected})
+	}
+}
+
+// reconnect attempts to re-establish the connection, reporting whether it was
+// successful.
+func (m *Conn) reconnect() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), reconnectTimeout)
+	defer cancel()
+
+	c, err := m.dial(ctx)
+	if err != nil {
+		return false
+	}
+	m.mu.Lock()
+	watch := m.watching
+	m.removeHandler()
+	m.mu.Unlock()
+	if err := m.setup(ctx, c, watch); err != nil {
+		_ = c.Close()
+		return false
+	}
+	return true
+}
+
+// isPeer reports whether c is connected directly to the service manager rather
+// than through a message bus.
+func isPeer(c *dbus.Conn) bool {
+	return c.UniqueName() == ""
+}
+
+// Backend returns the transport used to communicate with the service manager.
+func (m *Conn) Backend() Backend {
+	if m.varlink != "" {
+		return BackendVarlink
+	}
+	return BackendDBus
+}
+
+// bus returns the current D-Bus connection, or [ErrUnsupportedBackend] if the
+// service manager is not connected to using D-Bus.
+func (m *Conn) bus() (*dbus.Conn, error) {
+	if m.varlink != "" {
+		return nil, ErrUnsupportedBackend
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.conn, nil
+}
+
+// Close closes the connection to the service manager.
+func (m *Conn) Close() error {
+	m.shutdown()
+	if m.varlink != "" {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeHandler()
+	return m.conn.Close()
+}
+
+// shutdown marks the connection as closed, ending all subscriptions.
+func (m *Conn) shutdown() {
+	m.closeOnce.Do(func() { close(m.done) })
+}
+
+// call calls a method on the service manager, discarding the reply.
+func (m *Conn) call(ctx context.Context, method string, sig dbus.Signature, args ...any) error {
+	_, err := m.callBus(ctx, method, sig, args...)
+	return err
+}
+
+// callBus calls a method on the service manager, returning the body of the
+// reply.
+func (m *Conn) callBus(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	c, err := m.bus()
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Call(ctx, destination, managerPath, managerInterface, method, sig, args...)
+	return body, convertError(err)
+}
+
+// convertError converts a D-Bus error returned by the service manager to an
+// [*Error].
+func convertError(err error) error {
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Message: e.Message()}
+	}
+	return err
+}
+
+// handleSignal handles signals received from the service manager.
+func (m *Conn) handleSignal(msg *dbus.Message) {
+	ev, ok := parseEvent(msg)
+	if !ok {
+		return
+	}
+	if ev.Type == EventJobRemoved {
+		m.jobRemoved(msg.Body[1].(dbus.ObjectPath), ev.Result)
+	}
+	m.broadcast(ev)
+}
+
+// jobRemoved delivers the result of a job to its waiter.
+func (m *Conn) jobRemoved(job dbus.ObjectPath, result JobResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if j, ok := m.waiters[job]; ok {
+		j.complete(result)
+		delete(m.waiters, job)
+		return
+	}
+	if m.queuing > 0 {
+		m.removed[job] = result
+	}
+}
+
+// StartUnit starts a unit and waits for the job to complete. If the job does
+// not complete successfully, a [*JobError] will be returned.
+func (m *Conn) StartUnit(ctx context.Context, name string, mode Mode) (JobResult, error) {
+	return m.runJob(ctx, "start", name, "StartUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// StopUnit stops a unit and waits for the job to complete. If the job does not
+// complete successfully, a [*JobError] will be returned.
+func (m *Conn) StopUnit(ctx context.Context, name string, mode Mode) (JobResult, error) {
+	return m.runJob(ctx, "stop", name, "StopUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// RestartUnit restarts a unit, starting it if it is not running, and waits for
+// the job to complete. If the job does not complete successfully, a
+// [*JobError] will be returned.
+func (m *Conn) RestartUnit(ctx context.Context, name string, mode Mode) (JobResult, error) {
+	return m.runJob(ctx, "restart", name, "RestartUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// ReloadUnit reloads a unit and waits for the job to complete. If the job does
+// not complete successfully, a [*JobError] will be returned.
+func (m *Conn) ReloadUnit(ctx context.Context, name string, mode Mode) (JobResult, error) {
+	return m.runJob(ctx, "reload", name, "ReloadUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// StartUnitJob queues a job to start a unit without waiting for it to
+// complete, use [Job.Wait] to wait for the result.
+func (m *Conn) StartUnitJob(ctx context.Context, name string, mode Mode) (*Job, error) {
+	return m.queueJob(ctx, "start", name, "StartUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// StopUnitJob queues a job to stop a unit without waiting for it to complete,
+// use [Job.Wait] to wait for the result.
+func (m *Conn) StopUnitJob(ctx context.Context, name string, mode Mode) (*Job, error) {
+	return m.queueJob(ctx, "stop", name, "StopUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// RestartUnitJob queues a job to restart a unit without waiting for it to
+// complete, use [Job.Wait] to wait for the result.
+func (m *Conn) RestartUnitJob(ctx context.Context, name string, mode Mode) (*Job, error) {
+	return m.queueJob(ctx, "restart", name, "RestartUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// ReloadUnitJob queues a job to reload a unit without waiting for it to
+// complete, use [Job.Wait] to wait for the result.
+func (m *Conn) ReloadUnitJob(ctx context.Context, name string, mode Mode) (*Job, error) {
+	return m.queueJob(ctx, "reload", name, "ReloadUnit", "ss", name, string(defaultMode(mode)))
+}
+
+// defaultMode returns mode, or [ModeReplace] if it is empty.
+func defaultMode(mode Mode) Mode {
+	if mode == "" {
+		return ModeReplace
+	}
+	return mode
+}
+
+// runJob queues a job for the named unit by calling method, and waits for it
+// to complete.
+func (m *Conn) runJob(ctx context.Context, verb, name, method string, sig dbus.Signature, args ...any) (JobResult, error) {
+	job, err := m.queueJob(ctx, verb, name, method, sig, args...)
+	if err != nil {
+		return "", err
+	}
+	result, err := job.Wait(ctx)
+	if ctx.Err() != nil {
+		m.forget(job)
+	}
+	return result, err
+}
+
+// queueJob queues a job for the named unit by calling method. The method must
+// return the object path of the job, verb describes the job in error messages.
+func (m *Conn) queueJob(ctx context.Context, verb, name, method string, sig dbus.Signature, args ...any) (*Job, error) {
+	c, err := m.bus()
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to %s %s: %w", verb, name, err)
+	}
+
+	m.mu.Lock()
+	m.queuing++
+	m.mu.Unlock()
+
+	body, err := c.Call(ctx, destination, managerPath, managerInterface, method, sig, args...)
+	if err == nil && len(body) != 1 {
+		err = errors.New("invalid reply")
+	}
+	var job *Job
+	if err == nil {
+		path, _ := body[0].(dbus.ObjectPath)
+		job = &Job{m: m, conn: c, path: path, unit: name, done: make(chan struct{})}
+	}
+
+	m.mu.Lock()
+	m.queuing--
+	if err == nil {
+		if result, ok := m.removed[job.path]; ok {
+			job.complete(result)
+		} else {
+			m.waiters[job.path] = job
+		}
+	}
+	if m.queuing == 0 {
+		clear(m.removed)
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to %s %s: %w", verb, name, convertError(err))
+	}
+	return job, nil
+}
+
+// forget stops tracking a job.
+func (m *Conn) forget(job *Job) {
+	m.mu.Lock()
+	if m.waiters[job.path] == job {
+		delete(m.waiters, job.path)
+	}
+	m.mu.Unlock()
+}
+
+// Job is a job queued by the service manager.
+type Job struct {
+	m    *Conn
+	conn *dbus.Conn
+	path dbus.ObjectPath
+	unit string
+
+	// done is closed once the job has been removed, after result is set.
+	done   chan struct{}
+	result JobResult
+}
+
+// ID returns the ID of the job.
+func (j *Job) ID() uint32 {
+	return jobID(j.path)
+}
+
+// Unit returns the name of the unit the job was queued for.
+func (j *Job) Unit() string {
+	return j.unit
+}
+
+// Done returns a channel that is closed once the job completes.
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// Result returns the result of the job, or an empty result if it has not
+// completed yet.
+func (j *Job) Result() JobResult {
+	select {
+	case <-j.done:
+		return j.result
+	default:
+		return ""
+	}
+}
+
+// Wait waits for the job to complete and returns its result. If the job does
+// not complete successfully, a [*JobError] will be returned.
+//
+// Wait may be called multiple times, and from multiple goroutines. If ctx is
+// canceled the job keeps running and may be waited on again.
+func (j *Job) Wait(ctx context.Context) (JobResult, error) {
+	select {
+	case <-j.done:
+	default:
+		select {
+		case <-j.done:
+		case <-j.conn.Done():
+			// The signal for the job may be missed while reconnecting, so it
+			// can no longer be tracked.
+			j.m.forget(j)
+			return "", fmt.Errorf("sdmanager: connection closed waiting for job %s: %w", j.path, j.conn.Err())
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	if !j.result.Success() {
+		return j.result, &JobError{Unit: j.unit, Job: j.ID(), Result: j.result}
+	}
+	return j.result, nil
+}
+
+// complete sets the result of the job, it must only be called once.
+func (j *Job) complete(result JobResult) {
+	j.result = result
+	close(j.done)
+}
+
+// jobID returns the ID of a job from its object path, such as
+// `/org/freedesktop/systemd1/job/1234`.
+func jobID(job dbus.ObjectPath) uint32 {
+	id, err := strconv.ParseUint(path.Base(string(job)), 10, 32)
+	if err != nil {
+		return 0
+	}
+	return uint32(id)
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdmanager
+
+import (
+	"context"
+	"errors"
+	"syscall"
+)
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func NewUser(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func NewPrivate(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func NewVarlink(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (m *Conn) Backend() Backend { return "" }
+
+func (m *Conn) Close() error { return nil }
+
+func (m *Conn) StartUnit(context.Context, string, Mode) (JobResult, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) StopUnit(context.Context, string, Mode) (JobResult, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) RestartUnit(context.Context, string, Mode) (JobResult, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) ReloadUnit(context.Context, string, Mode) (JobResult, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) StartUnitJob(context.Context, string, Mode) (*Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) StopUnitJob(context.Context, string, Mode) (*Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) RestartUnitJob(context.Context, string, Mode) (*Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) ReloadUnitJob(context.Context, string, Mode) (*Job, error) {
+	return nil, errors.ErrUnsupported
+}
+
+type Job struct{}
+
+func (j *Job) ID() uint32 { return 0 }
+
+func (j *Job) Unit() string { return "" }
+
+func (j *Job) Done() <-chan struct{} { return nil }
+
+func (j *Job) Result() JobResult { return "" }
+
+func (j *Job) Wait(context.Context) (JobResult, error) { return "", errors.ErrUnsupported }
+
+func (m *Conn) GetUnit(context.Context, string) (*Unit, error) { return nil, errors.ErrUnsupported }
+
+func (m *Conn) GetService(context.Context, string) (*Service, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) GetTimeouts(context.Context, string) (*Timeouts, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) GetFailureReport(context.Context, string) (*FailureReport, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) ListDependencies(context.Context, string, bool) (*DependencyGraph, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) GetSocket(context.Context, string) (*Socket, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) GetTimer(context.Context, string) (*Timer, error) { return nil, errors.ErrUnsupported }
+
+func (m *Conn) Subscribe(context.Context) (<-chan Event, error) { return nil, errors.ErrUnsupported }
+
+func (m *Conn) WatchUnit(context.Context, string) (<-chan Event, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) Reload(context.Context) error { return errors.ErrUnsupported }
+
+func (m *Conn) LinkUnitFiles(context.Context, []string, UnitFileOptions) ([]UnitFileChange, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) EnableUnitFiles(context.Context, []string, UnitFileOptions) (bool, []UnitFileChange, error) {
+	return false, nil, errors.ErrUnsupported
+}
+
+func (m *Conn) DisableUnitFiles(context.Context, []string, UnitFileOptions) ([]UnitFileChange, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) MaskUnitFiles(context.Context, []string, UnitFileOptions) ([]UnitFileChange, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) UnmaskUnitFiles(context.Context, []string, UnitFileOptions) ([]UnitFileChange, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) GetUnitFileState(context.Context, string) (UnitFileState, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) PresetUnitFiles(context.Context, []string, PresetMode, UnitFileOptions) (bool, []UnitFileChange, error) {
+	return false, nil, errors.ErrUnsupported
+}
+
+func (m *Conn) StartTransientUnit(context.Context, string, Mode, ...Property) (JobResult, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) RunTransientService(context.Context, string, []string, ...Property) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) StartScope(context.Context, string, []int, ...Property) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) ScheduleTransientTimer(context.Context, string, TimerSpec, ...Property) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) StartTransientSocket(context.Context, string, SocketSpec, ...Property) (string, error) {
+	return "", errors.ErrUnsupported
+}
+
+func (m *Conn) ListUnits(context.Context, []string, []string) ([]UnitStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) ListInstances(context.Context, string) ([]UnitStatus, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) ListSockets(context.Context) ([]SocketListing, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (m *Conn) SetUnitProperties(context.Context, string, bool, ...Property) error {
+	return errors.ErrUnsupported
+}
+
+func (m *Conn) KillUnit(context.Context, string, KillWho, syscall.Signal) error {
+	return errors.ErrUnsupported
+}
+
+func (m *Conn) ResetFailedUnit(context.Context, string) error { return errors.ErrUnsupported }
+
+func (m *Conn) ResetFailed(context.Context) error { return errors.ErrUnsupported }
+
+func (m *Conn) UnitForPID(context.Context, int) (string, error) { return "", errors.ErrUnsupported }
+
+func (m *Conn) OwnUnit(context.Context) (string, error) { return "", errors.ErrUnsupported }
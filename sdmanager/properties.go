@@ -0,0 +1,188 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+var (
+	timeType     = reflect.TypeFor[time.Time]()
+	durationType = reflect.TypeFor[time.Duration]()
+	id128Type    = reflect.TypeFor[sdid128.ID128]()
+)
+
+// decodeProperties sets the fields of the struct pointed to by dst from
+// props. Fields are matched by the `property` struct tag or their name,
+// properties that are missing are left as the zero value.
+//
+// Timestamps are decoded from microseconds since the epoch and durations from
+// microseconds, unless the `nsec` option is set on the tag.
+func decodeProperties(props map[string]dbus.Variant, dst any) error {
+	return decodeStruct(props, reflect.ValueOf(dst).Elem())
+}
+
+func decodeStruct(props map[string]dbus.Variant, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeStruct(props, rv.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		name, opts := f.Name, ""
+		if tag, ok := f.Tag.Lookup("property"); ok {
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ = strings.Cut(tag, ",")
+		}
+		v, ok := props[name]
+		if !ok {
+			continue
+		}
+		if err := setValue(rv.Field(i), v.Value, opts == "nsec"); err != nil {
+			return fmt.Errorf("sdmanager: unable to decode property %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// setValue sets rv to the decoded D-Bus value v.
+func setValue(rv reflect.Value, v any, nsec bool) error {
+	mismatch := func() error {
+		return fmt.Errorf("cannot decode %T into %s", v, rv.Type())
+	}
+
+	switch rv.Type() {
+	case timeType:
+		usec, ok := v.(uint64)
+		if !ok {
+			return mismatch()
+		}
+		if usec != 0 && usec != math.MaxUint64 {
+			rv.Set(reflect.ValueOf(time.UnixMicro(int64(usec))))
+		}
+		return nil
+	case durationType:
+		n, ok := v.(uint64)
+		if !ok {
+			return mismatch()
+		}
+		d := Infinity
+		if n != math.MaxUint64 {
+			if nsec {
+				d = time.Duration(n)
+			} else {
+				d = time.Duration(n) * time.Microsecond
+			}
+		}
+		rv.Set(reflect.ValueOf(d))
+		return nil
+	case id128Type:
+		b, ok := v.([]byte)
+		if !ok {
+			return mismatch()
+		}
+		if len(b) == 0 {
+			return nil
+		}
+		id, err := sdid128.FromBytes(b)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(id))
+		return nil
+	}
+
+	val := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		if val.Kind() != reflect.String {
+			return mismatch()
+		}
+		rv.SetString(val.String())
+	case reflect.Bool:
+		b, ok := v.(bool)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch val.Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if rv.OverflowInt(val.Int()) {
+				return mismatch()
+			}
+			rv.SetInt(val.Int())
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if val.Uint() > math.MaxInt64 || rv.OverflowInt(int64(val.Uint())) {
+				return mismatch()
+			}
+			rv.SetInt(int64(val.Uint()))
+		default:
+			return mismatch()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch val.Kind() {
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if rv.OverflowUint(val.Uint()) {
+				return mismatch()
+			}
+			rv.SetUint(val.Uint())
+		default:
+			return mismatch()
+		}
+	case reflect.Float64:
+		f, ok := v.(float64)
+		if !ok {
+			return mismatch()
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		if b, ok := v.([]byte); ok && rv.Type().Elem().Kind() == reflect.Uint8 {
+			rv.SetBytes(append([]byte(nil), b...))
+			return nil
+		}
+		items, ok := v.([]any)
+		if !ok {
+			return mismatch()
+		}
+		s := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(s.Index(i), item, nsec); err != nil {
+				return err
+			}
+		}
+		rv.Set(s)
+	case reflect.Struct:
+		// Structs are decoded positionally from D-Bus structs.
+		fields, ok := v.(dbus.Struct)
+		if !ok || len(fields) != rv.NumField() {
+			return mismatch()
+		}
+		for i, field := range fields {
+			_, opts, _ := strings.Cut(rv.Type().Field(i).Tag.Get("property"), ",")
+			if err := setValue(rv.Field(i), field, opts == "nsec"); err != nil {
+				return err
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
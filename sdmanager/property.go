@@ -0,0 +1,321 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"fmt"
+	"net/netip"
+	"os"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// Property is a unit property, used when creating transient units or changing
+// the properties of a unit at runtime.
+//
+// The Prop* functions return properties for commonly used settings, other
+// properties may be set by constructing a Property directly. Property names are
+// the names used by D-Bus, which often differ from the unit file setting, such
+// as `RuntimeMaxUSec` for `RuntimeMaxSec=`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Properties2
+type Property struct {
+	// Name is the name of the property, such as `MemoryMax`.
+	Name string
+
+	// Value is the value of the property.
+	Value any
+
+	// Signature is the D-Bus type signature of Value. If empty, it is inferred
+	// from the type of Value, which works for strings, booleans, sized
+	// integers, and string slices.
+	Signature string
+}
+
+// variant returns the property value as a [dbus.Variant].
+func (p Property) variant() (dbus.Variant, error) {
+	if p.Signature != "" {
+		return dbus.Variant{Sig: dbus.Signature(p.Signature), Value: p.Value}, nil
+	}
+	v, err := dbus.VariantOf(p.Value)
+	if err != nil {
+		return dbus.Variant{}, fmt.Errorf("sdmanager: property %s: %w", p.Name, err)
+	}
+	return v, nil
+}
+
+// encodeProperties converts properties to the `a(sv)` format used by the
+// service manager.
+func encodeProperties(props []Property) ([]dbus.Struct, error) {
+	out := make([]dbus.Struct, len(props))
+	for i, p := range props {
+		v, err := p.variant()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = dbus.Struct{p.Name, v}
+	}
+	return out, nil
+}
+
+// PropDescription sets `Description=`.
+func PropDescription(description string) Property {
+	return Property{Name: "Description", Value: description, Signature: "s"}
+}
+
+// PropExecStart sets `ExecStart=` to run argv, the first element is used as
+// the path of the executable. If ignoreFailure is true, a non-zero exit status
+// is not treated as a failure.
+func PropExecStart(argv []string, ignoreFailure bool) Property {
+	var path string
+	if len(argv) > 0 {
+		path = argv[0]
+	}
+	return Property{
+		Name:      "ExecStart",
+		Value:     []dbus.Struct{{path, argv, ignoreFailure}},
+		Signature: "a(sasb)",
+	}
+}
+
+// PropType sets the `Type=` of a service, such as `oneshot` or `exec`.
+func PropType(typ string) Property {
+	return Property{Name: "Type", Value: typ, Signature: "s"}
+}
+
+// PropRemainAfterExit sets `RemainAfterExit=`.
+func PropRemainAfterExit(b bool) Property {
+	return Property{Name: "RemainAfterExit", Value: b, Signature: "b"}
+}
+
+// PropRestart sets `Restart=`, such as `on-failure`.
+func PropRestart(restart string) Property {
+	return Property{Name: "Restart", Value: restart, Signature: "s"}
+}
+
+// PropCollectMode sets `CollectMode=`, use `inactive-or-failed` to have
+// failed transient units unloaded automatically.
+func PropCollectMode(mode string) Property {
+	return Property{Name: "CollectMode", Value: mode, Signature: "s"}
+}
+
+// PropSlice sets `Slice=`, the slice the unit is placed in.
+func PropSlice(slice string) Property {
+	return Property{Name: "Slice", Value: slice, Signature: "s"}
+}
+
+// PropDelegate sets `Delegate=`, turning on delegation of all supported
+// cgroup controllers, allowing the unit to manage its own cgroup subtree.
+func PropDelegate(b bool) Property {
+	return Property{Name: "Delegate", Value: b, Signature: "b"}
+}
+
+// PropDelegateControllers sets `Delegate=` to a list of cgroup controllers,
+// such as `cpu` and `memory`.
+func PropDelegateControllers(controllers ...string) Property {
+	return Property{Name: "DelegateControllers", Value: controllers, Signature: "as"}
+}
+
+// PropEnvironment sets `Environment=`, each entry is in `KEY=VALUE` form.
+func PropEnvironment(env ...string) Property {
+	return Property{Name: "Environment", Value: env, Signature: "as"}
+}
+
+// PropUser sets `User=`.
+func PropUser(user string) Property {
+	return Property{Name: "User", Value: user, Signature: "s"}
+}
+
+// PropGroup sets `Group=`.
+func PropGroup(group string) Property {
+	return Property{Name: "Group", Value: group, Signature: "s"}
+}
+
+// PropWorkingDirectory sets `WorkingDirectory=`.
+func PropWorkingDirectory(dir string) Property {
+	return Property{Name: "WorkingDirectory", Value: dir, Signature: "s"}
+}
+
+// PropDynamicUser sets `DynamicUser=`.
+func PropDynamicUser(b bool) Property {
+	return Property{Name: "DynamicUser", Value: b, Signature: "b"}
+}
+
+// PropNoNewPrivileges sets `NoNewPrivileges=`.
+func PropNoNewPrivileges(b bool) Property {
+	return Property{Name: "NoNewPrivileges", Value: b, Signature: "b"}
+}
+
+// PropPrivateTmp sets `PrivateTmp=`.
+func PropPrivateTmp(b bool) Property {
+	return Property{Name: "PrivateTmp", Value: b, Signature: "b"}
+}
+
+// PropPrivateNetwork sets `PrivateNetwork=`.
+func PropPrivateNetwork(b bool) Property {
+	return Property{Name: "PrivateNetwork", Value: b, Signature: "b"}
+}
+
+// PropPrivateDevices sets `PrivateDevices=`.
+func PropPrivateDevices(b bool) Property {
+	return Property{Name: "PrivateDevices", Value: b, Signature: "b"}
+}
+
+// PropProtectSystem sets `ProtectSystem=`, such as `strict`.
+func PropProtectSystem(mode string) Property {
+	return Property{Name: "ProtectSystem", Value: mode, Signature: "s"}
+}
+
+// PropProtectHome sets `ProtectHome=`, such as `read-only`.
+func PropProtectHome(mode string) Property {
+	return Property{Name: "ProtectHome", Value: mode, Signature: "s"}
+}
+
+// PropReadWritePaths sets `ReadWritePaths=`.
+func PropReadWritePaths(paths ...string) Property {
+	return Property{Name: "ReadWritePaths", Value: paths, Signature: "as"}
+}
+
+// PropReadOnlyPaths sets `ReadOnlyPaths=`.
+func PropReadOnlyPaths(paths ...string) Property {
+	return Property{Name: "ReadOnlyPaths", Value: paths, Signature: "as"}
+}
+
+// PropInaccessiblePaths sets `InaccessiblePaths=`.
+func PropInaccessiblePaths(paths ...string) Property {
+	return Property{Name: "InaccessiblePaths", Value: paths, Signature: "as"}
+}
+
+// PropMemoryMax sets `MemoryMax=` in bytes, use [Unset] for no limit.
+func PropMemoryMax(bytes uint64) Property {
+	return Property{Name: "MemoryMax", Value: bytes, Signature: "t"}
+}
+
+// PropMemoryHigh sets `MemoryHigh=` in bytes, use [Unset] for no limit.
+func PropMemoryHigh(bytes uint64) Property {
+	return Property{Name: "MemoryHigh", Value: bytes, Signature: "t"}
+}
+
+// PropCPUQuota sets `CPUQuota=` as a percentage of a single CPU, such as 150
+// for one and a half CPUs.
+func PropCPUQuota(percent float64) Property {
+	return Property{Name: "CPUQuotaPerSecUSec", Value: uint64(percent * 10_000), Signature: "t"}
+}
+
+// PropCPUWeight sets `CPUWeight=`.
+func PropCPUWeight(weight uint64) Property {
+	return Property{Name: "CPUWeight", Value: weight, Signature: "t"}
+}
+
+// PropIOWeight sets `IOWeight=`.
+func PropIOWeight(weight uint64) Property {
+	return Property{Name: "IOWeight", Value: weight, Signature: "t"}
+}
+
+// PropTasksMax sets `TasksMax=`, use [Unset] for no limit.
+func PropTasksMax(n uint64) Property {
+	return Property{Name: "TasksMax", Value: n, Signature: "t"}
+}
+
+// PropManagedOOMPreference sets `ManagedOOMPreference=`, which is `none`,
+// `avoid`, or `omit`.
+func PropManagedOOMPreference(preference string) Property {
+	return Property{Name: "ManagedOOMPreference", Value: preference, Signature: "s"}
+}
+
+// PropIPAddressAllow sets `IPAddressAllow=`, the prefixes the unit may
+// communicate with, taking precedence over [PropIPAddressDeny].
+func PropIPAddressAllow(prefixes ...netip.Prefix) Property {
+	return Property{Name: "IPAddressAllow", Value: ipAddressList(prefixes), Signature: "a(iayu)"}
+}
+
+// PropIPAddressDeny sets `IPAddressDeny=`, the prefixes the unit may not
+// communicate with. Use `0.0.0.0/0` and `::/0` to deny everything not
+// explicitly allowed.
+func PropIPAddressDeny(prefixes ...netip.Prefix) Property {
+	return Property{Name: "IPAddressDeny", Value: ipAddressList(prefixes), Signature: "a(iayu)"}
+}
+
+// ipAddressList converts prefixes to the `a(iayu)` format of the service
+// manager, which is the address family, address, and prefix length.
+func ipAddressList(prefixes []netip.Prefix) []dbus.Struct {
+	// Address families as defined by Linux.
+	const (
+		afInet  = 2
+		afInet6 = 10
+	)
+	list := make([]dbus.Struct, len(prefixes))
+	for i, p := range prefixes {
+		family := int32(afInet6)
+		if p.Addr().Is4() {
+			family = afInet
+		}
+		list[i] = dbus.Struct{family, p.Addr().AsSlice(), uint32(p.Bits())}
+	}
+	return list
+}
+
+// PropRuntimeMax sets `RuntimeMaxSec=`.
+func PropRuntimeMax(d time.Duration) Property {
+	return Property{Name: "RuntimeMaxUSec", Value: usec(d), Signature: "t"}
+}
+
+// PropStandardOutput sets `StandardOutput=`, such as `journal` or `null`.
+// Use [PropStandardOutputFile] or [PropStandardOutputFileDescriptor] to
+// capture output.
+func PropStandardOutput(output string) Property {
+	return Property{Name: "StandardOutput", Value: output, Signature: "s"}
+}
+
+// PropStandardError sets `StandardError=`, such as `journal` or `null`.
+func PropStandardError(output string) Property {
+	return Property{Name: "StandardError", Value: output, Signature: "s"}
+}
+
+// PropStandardOutputFile sets `StandardOutput=file:path`, or
+// `StandardOutput=append:path` if appendFile is true.
+func PropStandardOutputFile(path string, appendFile bool) Property {
+	if appendFile {
+		return Property{Name: "StandardOutputFileToAppend", Value: path, Signature: "s"}
+	}
+	return Property{Name: "StandardOutputFile", Value: path, Signature: "s"}
+}
+
+// PropStandardErrorFile sets `StandardError=file:path`, or
+// `StandardError=append:path` if appendFile is true.
+func PropStandardErrorFile(path string, appendFile bool) Property {
+	if appendFile {
+		return Property{Name: "StandardErrorFileToAppend", Value: path, Signature: "s"}
+	}
+	return Property{Name: "StandardErrorFile", Value: path, Signature: "s"}
+}
+
+// PropStandardInputFileDescriptor connects standard input to f, which is
+// passed to the service manager over D-Bus.
+func PropStandardInputFileDescriptor(f *os.File) Property {
+	return Property{Name: "StandardInputFileDescriptor", Value: f, Signature: "h"}
+}
+
+// PropStandardOutputFileDescriptor connects standard output to f, which is
+// passed to the service manager over D-Bus. Use the write end of an
+// [os.Pipe] to capture the output of a unit.
+func PropStandardOutputFileDescriptor(f *os.File) Property {
+	return Property{Name: "StandardOutputFileDescriptor", Value: f, Signature: "h"}
+}
+
+// PropStandardErrorFileDescriptor connects standard error to f, which is
+// passed to the service manager over D-Bus.
+func PropStandardErrorFileDescriptor(f *os.File) Property {
+	return Property{Name: "StandardErrorFileDescriptor", Value: f, Signature: "h"}
+}
+
+// usec converts a duration to microseconds, mapping [Infinity] and negative
+// durations to the value used by the service manager for infinity.
+func usec(d time.Duration) uint64 {
+	if d == Infinity || d < 0 {
+		return Unset
+	}
+	return uint64(d / time.Microsecond)
+}
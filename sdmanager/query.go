@@ -0,0 +1,241 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"os"
+	"slices"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdunit"
+)
+
+const (
+	unitInterface    = "org.freedesktop.systemd1.Unit"
+	serviceInterface = "org.freedesktop.systemd1.Service"
+	socketInterface  = "org.freedesktop.systemd1.Socket"
+	timerInterface   = "org.freedesktop.systemd1.Timer"
+)
+
+// GetUnit returns the properties common to all units. If name does not have a
+// unit type suffix, `.service` is assumed.
+func (m *Conn) GetUnit(ctx context.Context, name string) (*Unit, error) {
+	var u Unit
+	if err := m.getProperties(ctx, name, ".service", &u, unitInterface); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// GetService returns the properties of a service unit. The `.service` suffix
+// may be omitted from name.
+func (m *Conn) GetService(ctx context.Context, name string) (*Service, error) {
+	var s Service
+	if err := m.getProperties(ctx, name, ".service", &s, unitInterface, serviceInterface); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetTimeouts returns the timeout and watchdog settings of a service unit. The
+// `.service` suffix may be omitted from name.
+//
+// This only reads the properties of the service, making it cheaper than
+// [Conn.GetService] for services checking their own settings, such as to pick
+// an interval for [sdnotify.Watchdog] or how long to extend a timeout by.
+//
+// [sdnotify.Watchdog]: https://pkg.go.dev/github.com/matthewpi/sd/sdnotify#Watchdog
+func (m *Conn) GetTimeouts(ctx context.Context, name string) (*Timeouts, error) {
+	var t Timeouts
+	if err := m.getProperties(ctx, name, ".service", &t, serviceInterface); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// GetFailureReport gathers the result of the last run of a service, such as
+// why it failed, into a [FailureReport]. The `.service` suffix may be omitted
+// from name.
+func (m *Conn) GetFailureReport(ctx context.Context, name string) (*FailureReport, error) {
+	s, err := m.GetService(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &FailureReport{
+		Unit:         s.Name,
+		Failed:       s.ActiveState == ActiveStateFailed,
+		ActiveState:  s.ActiveState,
+		SubState:     s.SubState,
+		Exit:         s.Exit(),
+		StatusText:   s.StatusText,
+		StatusErrno:  s.StatusErrno,
+		NRestarts:    s.NRestarts,
+		InvocationID: s.InvocationID,
+		StartedAt:    s.ExecMainStartTimestamp,
+		ExitedAt:     s.ExecMainExitTimestamp,
+		ChangedAt:    s.StateChangeTimestamp,
+	}, nil
+}
+
+// ListDependencies returns the graph of units the named unit depends on,
+// following `Requires=`, `Wants=`, `BindsTo=`, and `After=` recursively, similar
+// to `systemctl list-dependencies --all`. If reverse is true, the graph of
+// units that depend on the named unit is returned instead, following
+// `RequiredBy=`, `WantedBy=`, `BoundBy=`, and `Before=`.
+//
+// If name does not have a unit type suffix, `.service` is assumed.
+func (m *Conn) ListDependencies(ctx context.Context, name string, reverse bool) (*DependencyGraph, error) {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: %w", err)
+	}
+
+	g := &DependencyGraph{Root: name, Units: make(map[string]*Unit)}
+	queue := []string{name}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := g.Units[name]; ok {
+			continue
+		}
+		u, err := m.GetUnit(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		// Units are looked up by the name they are referenced by, which may be
+		// an alias of the unit.
+		u.Name = name
+		g.Units[name] = u
+		for _, d := range dependencies(u, reverse) {
+			g.Dependencies = append(g.Dependencies, d)
+			if _, ok := g.Units[d.To]; !ok {
+				queue = append(queue, d.To)
+			}
+		}
+	}
+	slices.SortFunc(g.Dependencies, func(a, b Dependency) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.Type, b.Type), cmp.Compare(a.To, b.To))
+	})
+	return g, nil
+}
+
+// GetSocket returns the properties of a socket unit. The `.socket` suffix may
+// be omitted from name.
+func (m *Conn) GetSocket(ctx context.Context, name string) (*Socket, error) {
+	var s Socket
+	if err := m.getProperties(ctx, name, ".socket", &s, unitInterface, socketInterface); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetTimer returns the properties of a timer unit. The `.timer` suffix may be
+// omitted from name.
+func (m *Conn) GetTimer(ctx context.Context, name string) (*Timer, error) {
+	var t Timer
+	if err := m.getProperties(ctx, name, ".timer", &t, unitInterface, timerInterface); err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// UnitForPID returns the name of the unit a process belongs to, such as a
+// process connected over a unix socket whose credentials were obtained using
+// `SO_PEERCRED`.
+//
+// If the process does not belong to a unit, an error matching
+// [ErrNoSuchUnit] is returned.
+func (m *Conn) UnitForPID(ctx context.Context, pid int) (string, error) {
+	if pid <= 0 || int64(pid) > math.MaxUint32 {
+		return "", fmt.Errorf("sdmanager: invalid pid %d", pid)
+	}
+	body, err := m.callBus(ctx, "GetUnitByPID", "u", uint32(pid))
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: unable to get unit of pid %d: %w", pid, err)
+	}
+	if len(body) != 1 {
+		return "", errors.New("sdmanager: invalid reply to GetUnitByPID")
+	}
+	path, ok := body[0].(dbus.ObjectPath)
+	if !ok {
+		return "", errors.New("sdmanager: invalid reply to GetUnitByPID")
+	}
+
+	c, err := m.bus()
+	if err != nil {
+		return "", err
+	}
+	v, err := c.GetProperty(ctx, destination, path, unitInterface, "Id")
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: unable to get unit of pid %d: %w", pid, convertError(err))
+	}
+	name, ok := v.Value.(string)
+	if !ok {
+		return "", errors.New("sdmanager: invalid unit id")
+	}
+	return name, nil
+}
+
+// OwnUnit returns the name of the unit the current process belongs to, as
+// known by the service manager.
+//
+// Unlike [sddaemon.CurrentUnit], this works for processes that are not in a
+// cgroup of their unit, such as in a container with a private cgroup
+// namespace, but requires a connection to the service manager.
+//
+// [sddaemon.CurrentUnit]: https://pkg.go.dev/github.com/matthewpi/sd/sddaemon#CurrentUnit
+func (m *Conn) OwnUnit(ctx context.Context) (string, error) {
+	return m.UnitForPID(ctx, os.Getpid())
+}
+
+// getProperties loads a unit and decodes the properties of the given
+// interfaces into dst.
+func (m *Conn) getProperties(ctx context.Context, name, suffix string, dst any, ifaces ...string) error {
+	name, err := sdunit.Mangle(name, suffix)
+	if err != nil {
+		return fmt.Errorf("sdmanager: %w", err)
+	}
+	path, err := m.loadUnit(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	c, err := m.bus()
+	if err != nil {
+		return fmt.Errorf("sdmanager: unable to get properties of %s: %w", name, err)
+	}
+	props := make(map[string]dbus.Variant)
+	for _, iface := range ifaces {
+		p, err := c.GetAllProperties(ctx, destination, path, iface)
+		if err != nil {
+			return fmt.Errorf("sdmanager: unable to get properties of %s: %w", name, convertError(err))
+		}
+		maps.Copy(props, p)
+	}
+	return decodeProperties(props, dst)
+}
+
+// loadUnit returns the object path of a unit, loading it if necessary.
+func (m *Conn) loadUnit(ctx context.Context, name string) (dbus.ObjectPath, error) {
+	body, err := m.callBus(ctx, "LoadUnit", "s", name)
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: unable to load unit %s: %w", name, err)
+	}
+	if len(body) != 1 {
+		return "", errors.New("sdmanager: invalid reply to LoadUnit")
+	}
+	path, ok := body[0].(dbus.ObjectPath)
+	if !ok {
+		return "", errors.New("sdmanager: invalid reply to LoadUnit")
+	}
+	return path, nil
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"errors"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+// TimerSpec describes when a transient timer elapses and what it activates.
+//
+// At least one of OnCalendar, OnActive, or OnUnitActive must be set, along
+// with exactly one of Unit or Exec.
+type TimerSpec struct {
+	// OnCalendar are calendar expressions the timer elapses at, such as
+	// `*-*-* 04:00:00`, see [systemd.time(7)].
+	//
+	// [systemd.time(7)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.time.html#Calendar%20Events
+	OnCalendar []string
+
+	// OnActive elapses the timer once, this long after the timer is started.
+	OnActive time.Duration
+
+	// OnUnitActive elapses the timer this long after the unit it activates was
+	// last activated, making the timer recurring.
+	OnUnitActive time.Duration
+
+	// Persistent records when the timer last elapsed, so a calendar timer
+	// that would have elapsed while the system was off elapses immediately
+	// when the timer is started.
+	Persistent bool
+
+	// Accuracy is the accuracy the timer elapses with, allowing wake-ups to
+	// be coalesced. Defaults to one minute if zero.
+	Accuracy time.Duration
+
+	// RandomizedDelay delays the timer by a random amount up to this value.
+	RandomizedDelay time.Duration
+
+	// WakeSystem resumes the system from suspend when the timer elapses.
+	WakeSystem bool
+
+	// Unit is the name of an existing unit to activate when the timer
+	// elapses.
+	Unit string
+
+	// Exec is a command to run as a transient service, created along with the
+	// timer, when the timer elapses.
+	Exec []string
+
+	// ServiceProperties are additional properties of the transient service
+	// created for Exec.
+	ServiceProperties []Property
+}
+
+// properties returns the properties of the timer unit.
+func (s TimerSpec) properties() ([]Property, error) {
+	if len(s.OnCalendar) == 0 && s.OnActive <= 0 && s.OnUnitActive <= 0 {
+		return nil, errors.New("sdmanager: timer requires OnCalendar, OnActive, or OnUnitActive")
+	}
+	if (s.Unit == "") == (len(s.Exec) == 0) {
+		return nil, errors.New("sdmanager: timer requires exactly one of Unit or Exec")
+	}
+
+	var (
+		calendar  []dbus.Struct
+		monotonic []dbus.Struct
+	)
+	for _, expr := range s.OnCalendar {
+		calendar = append(calendar, dbus.Struct{"OnCalendar", expr})
+	}
+	if s.OnActive > 0 {
+		monotonic = append(monotonic, dbus.Struct{"OnActiveUSec", usec(s.OnActive)})
+	}
+	if s.OnUnitActive > 0 {
+		monotonic = append(monotonic, dbus.Struct{"OnUnitActiveUSec", usec(s.OnUnitActive)})
+	}
+
+	props := []Property{
+		{Name: "TimersCalendar", Value: calendar, Signature: "a(ss)"},
+		{Name: "TimersMonotonic", Value: monotonic, Signature: "a(st)"},
+		{Name: "Persistent", Value: s.Persistent, Signature: "b"},
+		{Name: "WakeSystem", Value: s.WakeSystem, Signature: "b"},
+		// Allow timers that elapse once to be unloaded after they elapse.
+		{Name: "RemainAfterElapse", Value: false, Signature: "b"},
+	}
+	if s.Accuracy > 0 {
+		props = append(props, Property{Name: "AccuracyUSec", Value: usec(s.Accuracy), Signature: "t"})
+	}
+	if s.RandomizedDelay > 0 {
+		props = append(props, Property{Name: "RandomizedDelayUSec", Value: usec(s.RandomizedDelay), Signature: "t"})
+	}
+	if s.Unit != "" {
+		props = append(props, Property{Name: "Unit", Value: s.Unit, Signature: "s"})
+	}
+	return props, nil
+}
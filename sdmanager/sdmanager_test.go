@@ -0,0 +1,1370 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"net"
+	"net/netip"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+	"github.com/matthewpi/sd/sddaemon"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+// newTestConn returns a [Conn] connected to a fake bus.
+func newTestConn(t *testing.T) (*dbustest.Bus, *Conn) {
+	t.Helper()
+
+	bus, c := dbustest.New(t)
+	bus.Handle(managerInterface, "Subscribe", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "", nil, nil
+	})
+	m, err := newConn(context.Background(), c, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return bus, m
+}
+
+// handleJob handles method on the fake bus, completing each job with the
+// result returned by result. If early is true, the job is removed before the
+// reply is sent.
+func handleJob(bus *dbustest.Bus, method string, early bool, result func(unit string) JobResult) {
+	var next atomic.Uint32
+	bus.Handle(managerInterface, method, func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		unit := msg.Body[0].(string)
+		if unit == "missing.service" {
+			return "", nil, &dbus.Error{
+				Name: "org.freedesktop.systemd1.NoSuchUnit",
+				Body: []any{"Unit missing.service not found."},
+			}
+		}
+		id := next.Add(1)
+		job := dbus.ObjectPath("/org/freedesktop/systemd1/job/" + string(rune('0'+id)))
+		remove := func() {
+			_ = bus.Emit(managerPath, managerInterface, "JobRemoved", "uoss", id, job, unit, string(result(unit)))
+		}
+		if early {
+			remove()
+		} else {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				remove()
+			}()
+		}
+		return "o", []any{job}, nil
+	})
+}
+
+func TestNewUser(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	t.Setenv("XDG_RUNTIME_DIR", "")
+	if _, err := NewUser(context.Background()); err == nil {
+		t.Error("expected an error without a session bus address")
+	}
+
+	// The session bus is located using the runtime directory of the user.
+	dir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", dir)
+	_, err := NewUser(context.Background())
+	if err == nil || !strings.Contains(err.Error(), filepath.Join(dir, "bus")) {
+		t.Errorf("expected an error connecting to %s, but got %v", filepath.Join(dir, "bus"), err)
+	}
+}
+
+func TestStartUnit(t *testing.T) {
+	for _, early := range []bool{true, false} {
+		bus, m := newTestConn(t)
+		handleJob(bus, "StartUnit", early, func(unit string) JobResult {
+			if unit == "broken.service" {
+				return JobFailed
+			}
+			return JobDone
+		})
+
+		ctx := context.Background()
+		result, err := m.StartUnit(ctx, "example.service", ModeReplace)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		if expected, got := JobDone, result; expected != got {
+			t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+		}
+
+		result, err = m.StartUnit(ctx, "broken.service", ModeReplace)
+		var jobErr *JobError
+		if !errors.As(err, &jobErr) {
+			t.Fatalf("expected a *JobError, but got %v", err)
+			return
+		}
+		if jobErr.Result != JobFailed || result != JobFailed || jobErr.Unit != "broken.service" || jobErr.Job != 2 {
+			t.Errorf("unexpected job error %#v", jobErr)
+		}
+
+		_, err = m.StartUnit(ctx, "missing.service", ModeReplace)
+		if !errors.Is(err, ErrNoSuchUnit) {
+			t.Errorf("expected %v, but got %v", ErrNoSuchUnit, err)
+		}
+
+		m.mu.Lock()
+		if len(m.waiters) != 0 || len(m.removed) != 0 {
+			t.Errorf("expected no pending jobs, but got %d waiters and %d removed", len(m.waiters), len(m.removed))
+		}
+		m.mu.Unlock()
+	}
+}
+
+func TestJobMethods(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	for method, fn := range map[string]func(context.Context, string, Mode) (JobResult, error){
+		"StopUnit":    m.StopUnit,
+		"RestartUnit": m.RestartUnit,
+		"ReloadUnit":  m.ReloadUnit,
+	} {
+		handleJob(bus, method, false, func(string) JobResult { return JobSkipped })
+		result, err := fn(ctx, "example.service", "")
+		if err != nil {
+			t.Errorf("%s: %v", method, err)
+			continue
+		}
+		if expected, got := JobSkipped, result; expected != got {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", method, expected, got)
+		}
+	}
+
+	var modes []string
+	for _, call := range bus.Calls() {
+		if call.Member != "Subscribe" {
+			modes = append(modes, call.Body[1].(string))
+		}
+	}
+	for _, mode := range modes {
+		if mode != string(ModeReplace) {
+			t.Errorf("expected default mode \"%s\", but got \"%s\"", ModeReplace, mode)
+		}
+	}
+}
+
+func TestJobCanceled(t *testing.T) {
+	bus, m := newTestConn(t)
+	bus.Handle(managerInterface, "StartUnit", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "o", []any{dbus.ObjectPath("/org/freedesktop/systemd1/job/1")}, nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.StartUnit(ctx, "example.service", ModeFail); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, but got %v", context.DeadlineExceeded, err)
+	}
+	m.mu.Lock()
+	if len(m.waiters) != 0 {
+		t.Errorf("expected no waiters, but got %d", len(m.waiters))
+	}
+	m.mu.Unlock()
+}
+
+func TestJobWait(t *testing.T) {
+	bus, m := newTestConn(t)
+	handleJob(bus, "RestartUnit", false, func(unit string) JobResult {
+		if unit == "broken.service" {
+			return JobDependency
+		}
+		return JobDone
+	})
+
+	ctx := context.Background()
+	jobs := make([]*Job, 0, 2)
+	for _, name := range []string{"example.service", "broken.service"} {
+		job, err := m.RestartUnitJob(ctx, name, ModeReplace)
+		if err != nil {
+			t.Fatal(err)
+			return
+		}
+		jobs = append(jobs, job)
+	}
+	if expected, got := JobResult(""), jobs[0].Result(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	// A canceled wait does not stop tracking the job.
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := jobs[0].Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected %v, but got %v", context.Canceled, err)
+	}
+
+	result, err := jobs[0].Wait(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := JobDone, result; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if expected, got := uint32(1), jobs[0].ID(); expected != got {
+		t.Errorf("expected %d, but got %d", expected, got)
+	}
+
+	<-jobs[1].Done()
+	if expected, got := JobDependency, jobs[1].Result(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	var jobErr *JobError
+	if _, err := jobs[1].Wait(ctx); !errors.As(err, &jobErr) || jobErr.Unit != "broken.service" {
+		t.Errorf("expected a *JobError for broken.service, but got %v", err)
+	}
+}
+
+func TestGetService(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	const path = dbus.ObjectPath("/org/freedesktop/systemd1/unit/example_2eservice")
+	id := sdid128.MustParse("d3b07384d1134ec49f5d0d2e9a5c6e3c")
+	bus.Handle(managerInterface, "LoadUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if expected, got := "example.service", msg.Body[0]; expected != got {
+			t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+		}
+		return "o", []any{path}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if msg.Path != path {
+			t.Errorf("expected \"%s\", but got \"%s\"", path, msg.Path)
+		}
+		var props map[string]dbus.Variant
+		switch msg.Body[0] {
+		case unitInterface:
+			props = map[string]dbus.Variant{
+				"Id":                   dbus.MakeVariant("example.service"),
+				"Names":                dbus.MakeVariant([]string{"example.service", "alias.service"}),
+				"ActiveState":          dbus.MakeVariant("failed"),
+				"SubState":             dbus.MakeVariant("failed"),
+				"InvocationID":         dbus.MakeVariant(id.Bytes()),
+				"ActiveEnterTimestamp": dbus.MakeVariant(uint64(1700000000000000)),
+				"ActiveExitTimestamp":  dbus.MakeVariant(uint64(0)),
+				"CanStart":             dbus.MakeVariant(true),
+				"Unknown":              dbus.MakeVariant(int32(1)),
+			}
+		case serviceInterface:
+			props = map[string]dbus.Variant{
+				"MainPID":          dbus.MakeVariant(uint32(0)),
+				"Result":           dbus.MakeVariant("exit-code"),
+				"ExecMainCode":     dbus.MakeVariant(int32(1)),
+				"ExecMainStatus":   dbus.MakeVariant(int32(3)),
+				"MemoryCurrent":    dbus.MakeVariant(Unset),
+				"CPUUsageNSec":     dbus.MakeVariant(uint64(1500)),
+				"TimeoutStartUSec": dbus.MakeVariant(uint64(90_000_000)),
+				"RuntimeMaxUSec":   dbus.MakeVariant(uint64(math.MaxUint64)),
+				"WatchdogUSec":     dbus.MakeVariant(uint64(0)),
+			}
+		}
+		return "a{sv}", []any{props}, nil
+	})
+
+	s, err := m.GetService(context.Background(), "example")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if s.Name != "example.service" || len(s.Names) != 2 || s.ActiveState != ActiveStateFailed || !s.CanStart {
+		t.Errorf("unexpected unit properties %#v", s.Unit)
+	}
+	if s.InvocationID != id {
+		t.Errorf("expected \"%s\", but got \"%s\"", id, s.InvocationID)
+	}
+	if expected, got := time.UnixMicro(1700000000000000), s.ActiveEnterTimestamp; !expected.Equal(got) {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if !s.ActiveExitTimestamp.IsZero() {
+		t.Errorf("expected a zero timestamp, but got \"%s\"", s.ActiveExitTimestamp)
+	}
+	if s.MemoryCurrent != Unset || s.CPUUsage != 1500*time.Nanosecond {
+		t.Errorf("unexpected accounting properties %#v", s.Accounting)
+	}
+	if s.TimeoutStart != 90*time.Second || s.RuntimeMax != Infinity {
+		t.Errorf("unexpected timeouts %s, %s", s.TimeoutStart, s.RuntimeMax)
+	}
+	if expected, got := "exit-code (code=exited, status=3)", s.Exit().String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if s.Result != sddaemon.ResultExitCode {
+		t.Errorf("expected \"%s\", but got \"%s\"", sddaemon.ResultExitCode, s.Result)
+	}
+
+	report, err := m.GetFailureReport(context.Background(), "example")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !report.Failed || report.Unit != "example.service" || report.InvocationID != id || report.Exit != s.Exit() {
+		t.Errorf("unexpected failure report %#v", report)
+	}
+
+	timeouts, err := m.GetTimeouts(context.Background(), "example.service")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := (Timeouts{TimeoutStart: 90 * time.Second, RuntimeMax: Infinity}), *timeouts; expected != got {
+		t.Errorf("expected %+v, but got %+v", expected, got)
+	}
+}
+
+func TestDecodeProperties(t *testing.T) {
+	var timer Timer
+	err := decodeProperties(map[string]dbus.Variant{
+		"Unit": dbus.MakeVariant("example.service"),
+		"TimersCalendar": {Sig: "a(sst)", Value: []any{
+			dbus.Struct{"OnCalendar", "*-*-* 00:00:00", uint64(1700000000000000)},
+		}},
+		"TimersMonotonic": {Sig: "a(stt)", Value: []any{
+			dbus.Struct{"OnBootUSec", uint64(60_000_000), uint64(61_000_000)},
+		}},
+	}, &timer)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if timer.TriggerUnit != "example.service" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "example.service", timer.TriggerUnit)
+	}
+	if len(timer.TimersCalendar) != 1 || timer.TimersCalendar[0].Expression != "*-*-* 00:00:00" {
+		t.Errorf("unexpected calendar timers %#v", timer.TimersCalendar)
+	}
+	if len(timer.TimersMonotonic) != 1 || timer.TimersMonotonic[0].Value != time.Minute {
+		t.Errorf("unexpected monotonic timers %#v", timer.TimersMonotonic)
+	}
+
+	var socket Socket
+	err = decodeProperties(map[string]dbus.Variant{
+		"Listen":       {Sig: "a(ss)", Value: []any{dbus.Struct{"Stream", "[::]:80"}}},
+		"NConnections": dbus.MakeVariant(uint32(2)),
+	}, &socket)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(socket.Listen) != 1 || socket.Listen[0] != (Listen{Type: "Stream", Address: "[::]:80"}) || socket.NConnections != 2 {
+		t.Errorf("unexpected socket properties %#v", socket)
+	}
+
+	if err := decodeProperties(map[string]dbus.Variant{"MainPID": dbus.MakeVariant("1")}, &Service{}); err == nil {
+		t.Error("expected an error decoding a property with the wrong type")
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	all, err := m.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	watch, err := m.WatchUnit(ctx, "example")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	const path = dbus.ObjectPath("/org/freedesktop/systemd1/unit/example_2eservice")
+	changed := map[string]dbus.Variant{"ActiveState": dbus.MakeVariant("active")}
+	for _, emit := range []func() error{
+		func() error {
+			return bus.Emit(managerPath, managerInterface, "UnitNew", "so", "other.service", dbus.ObjectPath(unitPathPrefix+"other_2eservice"))
+		},
+		func() error {
+			return bus.Emit(path, "org.freedesktop.DBus.Properties", "PropertiesChanged", "sa{sv}as", unitInterface, changed, []string{"SubState"})
+		},
+		func() error {
+			return bus.Emit(managerPath, managerInterface, "JobRemoved", "uoss", uint32(7), dbus.ObjectPath("/org/freedesktop/systemd1/job/7"), "example.service", "done")
+		},
+	} {
+		if err := emit(); err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+
+	var types []EventType
+	for range 3 {
+		types = append(types, (<-all).Type)
+	}
+	if expected := []EventType{EventUnitNew, EventPropertiesChanged, EventJobRemoved}; !slices.Equal(expected, types) {
+		t.Errorf("expected %v, but got %v", expected, types)
+	}
+
+	ev := <-watch
+	if ev.Type != EventPropertiesChanged || ev.Unit != "example.service" {
+		t.Errorf("unexpected event %#v", ev)
+	}
+	if state, ok := ev.ActiveState(); !ok || state != ActiveStateActive {
+		t.Errorf("expected \"%s\", but got \"%s\"", ActiveStateActive, state)
+	}
+	if !slices.Equal(ev.Invalidated, []string{"SubState"}) {
+		t.Errorf("unexpected invalidated properties %v", ev.Invalidated)
+	}
+	ev = <-watch
+	if ev.Type != EventJobRemoved || ev.Job != 7 || ev.Result != JobDone {
+		t.Errorf("unexpected event %#v", ev)
+	}
+
+	cancel()
+	if _, ok := <-all; ok {
+		t.Error("expected the channel to be closed")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	buses := make(chan *dbustest.Bus, 2)
+	connect := func(context.Context) (*dbus.Conn, error) {
+		bus, c := dbustest.New(t)
+		bus.Handle(managerInterface, "Subscribe", func(*dbus.Message) (dbus.Signature, []any, error) {
+			return "", nil, nil
+		})
+		buses <- bus
+		return c, nil
+	}
+	m, err := dial(context.Background(), connect)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer m.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := m.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	_ = (<-buses).Close()
+	if ev := <-events; ev.Type != EventReconnected {
+		t.Errorf("expected \"%s\", but got \"%s\"", EventReconnected, ev.Type)
+	}
+
+	// Subscriptions and match rules must be restored on the new connection.
+	bus := <-buses
+	var members []string
+	for _, call := range bus.Calls() {
+		members = append(members, call.Member)
+	}
+	if !slices.Contains(members, "Subscribe") {
+		t.Errorf("expected Subscribe to be called after reconnecting, but got %v", members)
+	}
+	if err := bus.Emit(managerPath, managerInterface, "UnitRemoved", "so", "example.service", dbus.ObjectPath(unitPathPrefix+"example_2eservice")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if ev := <-events; ev.Type != EventUnitRemoved || ev.Unit != "example.service" {
+		t.Errorf("unexpected event %#v", ev)
+	}
+
+	_ = m.Close()
+	if _, ok := <-events; ok {
+		t.Error("expected the channel to be closed")
+	}
+}
+
+func TestUnitFromPath(t *testing.T) {
+	for path, expected := range map[dbus.ObjectPath]string{
+		"/org/freedesktop/systemd1/unit/nginx_2eservice":                "nginx.service",
+		"/org/freedesktop/systemd1/unit/getty_40tty1_2eservice":         "getty@tty1.service",
+		"/org/freedesktop/systemd1/unit/_2d_2eslice":                    "-.slice",
+		"/org/freedesktop/systemd1/unit/dev_2dsda1_2edevice":            "dev-sda1.device",
+		"/org/freedesktop/systemd1/unit/systemd_2djournald_2esocket":    "systemd-journald.socket",
+		"/org/freedesktop/systemd1/unit/sys_2dkernel_2dconfig_2emount":  "sys-kernel-config.mount",
+		"/org/freedesktop/systemd1/unit/user_5cx2dfoo_2eservice":        "user\\x2dfoo.service",
+		"/org/freedesktop/systemd1/unit/_31password_2dagents_2eservice": "1password-agents.service",
+	} {
+		got, ok := unitFromPath(path)
+		if !ok || got != expected {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", path, expected, got)
+		}
+	}
+	for _, path := range []dbus.ObjectPath{"/org/freedesktop/systemd1", "/org/freedesktop/systemd1/unit/a_2", "/org/freedesktop/systemd1/unit/a_zz"} {
+		if _, ok := unitFromPath(path); ok {
+			t.Errorf("%s: expected an invalid path", path)
+		}
+	}
+}
+
+func TestUnitFiles(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	change := dbus.Struct{"symlink", "/etc/systemd/system/example.service", "/opt/example/example.service"}
+	bus.Handle(managerInterface, "Reload", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "", nil, nil
+	})
+	bus.Handle(managerInterface, "LinkUnitFiles", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if runtime := msg.Body[1].(bool); runtime {
+			t.Error("expected runtime to be false")
+		}
+		if force := msg.Body[2].(bool); !force {
+			t.Error("expected force to be true")
+		}
+		return "a(sss)", []any{[]any{change}}, nil
+	})
+	bus.Handle(managerInterface, "PresetUnitFilesWithMode", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if expected, got := string(PresetFull), msg.Body[1]; expected != got {
+			t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+		}
+		return "ba(sss)", []any{true, []any{}}, nil
+	})
+
+	changes, err := m.LinkUnitFiles(ctx, []string{"/opt/example/example.service"}, UnitFileOptions{Force: true})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := UnitFileChange{Type: ChangeSymlink, Filename: change[1].(string), Destination: change[2].(string)}
+	if len(changes) != 1 || changes[0] != expected {
+		t.Errorf("expected %#v, but got %#v", expected, changes)
+	}
+	if err := m.Reload(ctx); err != nil {
+		t.Error(err)
+	}
+	installInfo, changes, err := m.PresetUnitFiles(ctx, []string{"example.service"}, "", UnitFileOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !installInfo || len(changes) != 0 {
+		t.Errorf("unexpected preset result %t, %#v", installInfo, changes)
+	}
+
+	bus.Handle(managerInterface, "EnableUnitFiles", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		return "ba(sss)", []any{true, []any{
+			dbus.Struct{"symlink", "/etc/systemd/system/multi-user.target.wants/example.service", "/etc/systemd/system/example.service"},
+		}}, nil
+	})
+	bus.Handle(managerInterface, "DisableUnitFiles", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if len(msg.Body) != 2 {
+			t.Errorf("expected 2 arguments, but got %d", len(msg.Body))
+		}
+		return "a(sss)", []any{[]any{
+			dbus.Struct{"unlink", "/etc/systemd/system/multi-user.target.wants/example.service", ""},
+		}}, nil
+	})
+	bus.Handle(managerInterface, "MaskUnitFiles", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "a(sss)", []any{[]any{}}, nil
+	})
+	bus.Handle(managerInterface, "GetUnitFileState", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if expected, got := "example.service", msg.Body[0]; expected != got {
+			t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+		}
+		return "s", []any{"enabled"}, nil
+	})
+
+	installInfo, changes, err = m.EnableUnitFiles(ctx, []string{"example.service"}, UnitFileOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !installInfo || len(changes) != 1 || changes[0].Type != ChangeSymlink || !ReloadRequired(changes) {
+		t.Errorf("unexpected enable result %t, %#v", installInfo, changes)
+	}
+	state, err := m.GetUnitFileState(ctx, "example")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := UnitFileEnabled, state; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	changes, err = m.DisableUnitFiles(ctx, []string{"example.service"}, UnitFileOptions{Runtime: true})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(changes) != 1 || changes[0].Type != ChangeUnlink || !ReloadRequired(changes) {
+		t.Errorf("unexpected disable result %#v", changes)
+	}
+	changes, err = m.MaskUnitFiles(ctx, []string{"example.service"}, UnitFileOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if ReloadRequired(changes) {
+		t.Errorf("expected no reload to be required for %#v", changes)
+	}
+	if ReloadRequired([]UnitFileChange{{Type: ChangeMasked, Filename: "/etc/systemd/system/example.service"}}) {
+		t.Error("expected no reload to be required for a masked unit")
+	}
+
+	bus.Handle(managerInterface, "Reload", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.AccessDenied", Body: []any{"Access denied"}}
+	})
+	var e *Error
+	if err := m.Reload(ctx); !errors.As(err, &e) || e.Name != "org.freedesktop.DBus.Error.AccessDenied" {
+		t.Errorf("expected an access denied error, but got %v", err)
+	}
+}
+
+// handleTransient handles StartTransientUnit on the fake bus, passing the
+// name and properties of each unit to fn which returns the result of the job.
+func handleTransient(bus *dbustest.Bus, fn func(name string, props map[string]dbus.Variant, aux []any) JobResult) {
+	var next atomic.Uint32
+	bus.Handle(managerInterface, "StartTransientUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		name := msg.Body[0].(string)
+		props := make(map[string]dbus.Variant)
+		for _, p := range msg.Body[2].([]any) {
+			p := p.(dbus.Struct)
+			props[p[0].(string)] = p[1].(dbus.Variant)
+		}
+		result := fn(name, props, msg.Body[3].([]any))
+
+		id := next.Add(1)
+		job := dbus.ObjectPath(fmt.Sprintf("/org/freedesktop/systemd1/job/%d", id))
+		_ = bus.Emit(managerPath, managerInterface, "JobRemoved", "uoss", id, job, name, string(result))
+		return "o", []any{job}, nil
+	})
+}
+
+func TestRunTransientService(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer r.Close()
+
+	units := make(chan map[string]dbus.Variant, 1)
+	handleTransient(bus, func(name string, props map[string]dbus.Variant, _ []any) JobResult {
+		units <- props
+		if f, ok := props["StandardOutputFileDescriptor"].Value.(*os.File); ok {
+			_, _ = f.WriteString("hello")
+			_ = f.Close()
+		}
+		return JobDone
+	})
+
+	name, err := m.RunTransientService(context.Background(), "", []string{"/bin/echo", "hello"},
+		PropEnvironment("A=1"),
+		PropMemoryMax(64<<20),
+		PropCPUQuota(50),
+		PropRuntimeMax(time.Minute),
+		PropStandardOutputFileDescriptor(w),
+		Property{Name: "Nice", Value: int32(5)},
+	)
+	_ = w.Close()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if !strings.HasPrefix(name, "run-r") || !strings.HasSuffix(name, ".service") {
+		t.Errorf("unexpected generated name \"%s\"", name)
+	}
+
+	got := <-units
+
+	if expected, got := "[sdmanager] /bin/echo hello", got["Description"].Value; expected != got {
+		t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+	}
+	execStart := got["ExecStart"]
+	if execStart.Sig != "a(sasb)" || !reflect.DeepEqual(execStart.Value, []any{dbus.Struct{"/bin/echo", []any{"/bin/echo", "hello"}, false}}) {
+		t.Errorf("unexpected ExecStart %#v", execStart)
+	}
+	for name, expected := range map[string]any{
+		"MemoryMax":          uint64(64 << 20),
+		"CPUQuotaPerSecUSec": uint64(500_000),
+		"RuntimeMaxUSec":     uint64(60_000_000),
+		"Nice":               int32(5),
+	} {
+		if got := got[name].Value; got != expected {
+			t.Errorf("%s: expected %v, but got %v", name, expected, got)
+		}
+	}
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "hello", string(b); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	if _, err := m.RunTransientService(context.Background(), "example", nil); err == nil {
+		t.Error("expected an error without a command")
+	}
+}
+
+func TestStartScope(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	units := make(chan map[string]dbus.Variant, 2)
+	handleTransient(bus, func(name string, props map[string]dbus.Variant, _ []any) JobResult {
+		units <- props
+		if name == "broken.scope" {
+			return JobFailed
+		}
+		return JobDone
+	})
+	stopped := make(chan string, 1)
+	bus.Handle(managerInterface, "StopUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		name := msg.Body[0].(string)
+		stopped <- name
+		job := dbus.ObjectPath("/org/freedesktop/systemd1/job/100")
+		_ = bus.Emit(managerPath, managerInterface, "JobRemoved", "uoss", uint32(100), job, name, "done")
+		return "o", []any{job}, nil
+	})
+
+	name, err := m.StartScope(ctx, "worker", []int{100, 101}, PropDelegate(true), PropTasksMax(16))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "worker.scope", name; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	props := <-units
+	if expected, got := []any{uint32(100), uint32(101)}, props["PIDs"].Value; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := "inactive-or-failed", props["CollectMode"].Value; expected != got {
+		t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+	}
+	if props["Delegate"].Value != true || props["TasksMax"].Value != uint64(16) {
+		t.Errorf("unexpected properties %v", props)
+	}
+
+	_, err = m.StartScope(ctx, "broken", []int{102})
+	var jobErr *JobError
+	if !errors.As(err, &jobErr) || jobErr.Result != JobFailed {
+		t.Errorf("expected a failed job, but got %v", err)
+	}
+	<-units
+	if expected, got := "broken.scope", <-stopped; expected != got {
+		t.Errorf("expected \"%s\" to be stopped, but got \"%s\"", expected, got)
+	}
+
+	for _, pids := range [][]int{nil, {0}, {-1}} {
+		if _, err := m.StartScope(ctx, "", pids); err == nil {
+			t.Errorf("%v: expected an error", pids)
+		}
+	}
+}
+
+func TestScheduleTransientTimer(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	type unit struct {
+		name  string
+		props map[string]dbus.Variant
+		aux   []any
+	}
+	units := make(chan unit, 2)
+	handleTransient(bus, func(name string, props map[string]dbus.Variant, aux []any) JobResult {
+		units <- unit{name, props, aux}
+		return JobDone
+	})
+
+	name, err := m.ScheduleTransientTimer(ctx, "backup", TimerSpec{
+		OnCalendar: []string{"*-*-* 04:00:00"},
+		Persistent: true,
+		Accuracy:   time.Second,
+		Exec:       []string{"/usr/bin/backup", "--all"},
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "backup.timer", name; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	u := <-units
+	if expected, got := []any{dbus.Struct{"OnCalendar", "*-*-* 04:00:00"}}, u.props["TimersCalendar"].Value; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if u.props["Persistent"].Value != true || u.props["AccuracyUSec"].Value != uint64(1_000_000) {
+		t.Errorf("unexpected properties %v", u.props)
+	}
+	if _, ok := u.props["Unit"]; ok {
+		t.Error("expected Unit to be unset when using Exec")
+	}
+	if len(u.aux) != 1 {
+		t.Fatalf("expected one auxiliary unit, but got %d", len(u.aux))
+		return
+	}
+	aux := u.aux[0].(dbus.Struct)
+	if expected, got := "backup.service", aux[0]; expected != got {
+		t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+	}
+
+	_, err = m.ScheduleTransientTimer(ctx, "", TimerSpec{OnActive: time.Hour, Unit: "cleanup.service"})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	u = <-units
+	if expected, got := []any{dbus.Struct{"OnActiveUSec", uint64(3_600_000_000)}}, u.props["TimersMonotonic"].Value; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := "cleanup.service", u.props["Unit"].Value; expected != got {
+		t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+	}
+	if len(u.aux) != 0 {
+		t.Errorf("expected no auxiliary units, but got %d", len(u.aux))
+	}
+
+	for _, spec := range []TimerSpec{
+		{Unit: "a.service"},
+		{OnActive: time.Second},
+		{OnActive: time.Second, Unit: "a.service", Exec: []string{"/bin/true"}},
+	} {
+		if _, err := m.ScheduleTransientTimer(ctx, "", spec); err == nil {
+			t.Errorf("%#v: expected an error", spec)
+		}
+	}
+}
+
+func TestStartTransientSocket(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	type unit struct {
+		props map[string]dbus.Variant
+		aux   []any
+	}
+	units := make(chan unit, 2)
+	handleTransient(bus, func(_ string, props map[string]dbus.Variant, aux []any) JobResult {
+		units <- unit{props, aux}
+		return JobDone
+	})
+
+	name, err := m.StartTransientSocket(ctx, "tenant-a", SocketSpec{
+		Listen:             []Listen{ListenStream("127.0.0.1:9000"), ListenDatagram("/run/tenant-a.sock")},
+		FileDescriptorName: "tenant-a",
+		Exec:               []string{"/usr/bin/tenant", "--id=a"},
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "tenant-a.socket", name; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	u := <-units
+	expected := []any{dbus.Struct{"Stream", "127.0.0.1:9000"}, dbus.Struct{"Datagram", "/run/tenant-a.sock"}}
+	if got := u.props["Listen"].Value; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := "tenant-a", u.props["FileDescriptorName"].Value; expected != got {
+		t.Errorf("expected \"%s\", but got \"%v\"", expected, got)
+	}
+	if len(u.aux) != 1 || u.aux[0].(dbus.Struct)[0] != "tenant-a.service" {
+		t.Errorf("unexpected auxiliary units %v", u.aux)
+	}
+
+	_, err = m.StartTransientSocket(ctx, "echo", SocketSpec{
+		Listen:  []Listen{ListenStream("[::]:7")},
+		Accept:  true,
+		Service: "echo@.service",
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	u = <-units
+	if expected, got := []any{"echo@.service"}, u.props["Triggers"].Value; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if u.props["Accept"].Value != true || len(u.aux) != 0 {
+		t.Errorf("unexpected socket %v", u)
+	}
+
+	for _, spec := range []SocketSpec{
+		{Service: "a.service"},
+		{Listen: []Listen{ListenStream(":80")}},
+		{Listen: []Listen{ListenStream(":80")}, Accept: true, Service: "a.service"},
+		{Listen: []Listen{ListenStream(":80")}, Accept: true, Exec: []string{"/bin/cat"}},
+	} {
+		if _, err := m.StartTransientSocket(ctx, "", spec); err == nil {
+			t.Errorf("%#v: expected an error", spec)
+		}
+	}
+}
+
+func TestListUnits(t *testing.T) {
+	bus, m := newTestConn(t)
+	ctx := context.Background()
+
+	row := func(name, active string) dbus.Struct {
+		path := dbus.ObjectPath(unitPathPrefix + strings.NewReplacer(".", "_2e", "-", "_2d").Replace(name))
+		return dbus.Struct{name, "Description of " + name, "loaded", active, "running", "", path, uint32(0), "", dbus.ObjectPath("/")}
+	}
+	bus.Handle(managerInterface, "ListUnitsByPatterns", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		patterns := msg.Body[1].([]any)
+		if len(patterns) == 1 && patterns[0] == "*.socket" {
+			return "a(ssssssouso)", []any{[]any{row("web.socket", "active"), row("dbus.socket", "active")}}, nil
+		}
+		return "a(ssssssouso)", []any{[]any{row("web.service", "active"), row("db.service", "failed")}}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		var props map[string]dbus.Variant
+		switch msg.Body[0] {
+		case socketInterface:
+			var listen []dbus.Struct
+			if msg.Path == unitPathPrefix+"web_2esocket" {
+				listen = []dbus.Struct{{"Stream", "[::]:80"}, {"Stream", "[::]:443"}}
+			} else {
+				listen = []dbus.Struct{{"Stream", "/run/dbus/system_bus_socket"}}
+			}
+			props = map[string]dbus.Variant{"Listen": {Sig: "a(ss)", Value: listen}}
+		case unitInterface:
+			unit, _ := unitFromPath(msg.Path)
+			props = map[string]dbus.Variant{"Triggers": dbus.MakeVariant([]string{strings.TrimSuffix(unit, ".socket") + ".service"})}
+		}
+		return "a{sv}", []any{props}, nil
+	})
+
+	units, err := m.ListUnits(ctx, []string{"*.service"}, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(units) != 2 || units[0].Name != "db.service" || units[0].ActiveState != ActiveStateFailed || units[1].Description != "Description of web.service" {
+		t.Errorf("unexpected units %#v", units)
+	}
+
+	sockets, err := m.ListSockets(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var got []string
+	for _, s := range sockets {
+		got = append(got, s.Listen.Address+" "+s.Unit+" "+strings.Join(s.Activates, ","))
+	}
+	expected := []string{
+		"/run/dbus/system_bus_socket dbus.socket dbus.service",
+		"[::]:443 web.socket web.service",
+		"[::]:80 web.socket web.service",
+	}
+	if !slices.Equal(expected, got) {
+		t.Errorf("expected %q, but got %q", expected, got)
+	}
+}
+
+func TestListInstances(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	var patterns []any
+	bus.Handle(managerInterface, "ListUnitsByPatterns", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		patterns = msg.Body[1].([]any)
+		row := func(name string) dbus.Struct {
+			return dbus.Struct{name, "", "loaded", "active", "running", "", dbus.ObjectPath("/"), uint32(0), "", dbus.ObjectPath("/")}
+		}
+		return "a(ssssssouso)", []any{[]any{row(`my\x2dapp@b.service`), row(`my\x2dapp@a.service`), row(`my\x2dapp@.service`)}}, nil
+	})
+
+	units, err := m.ListInstances(context.Background(), `my\x2dapp@.service`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []any{`my\\x2dapp@*.service`}; !slices.Equal(patterns, expected) {
+		t.Errorf("expected %q, but got %q", expected, patterns)
+	}
+	if len(units) != 2 || units[0].Name != `my\x2dapp@a.service` || units[1].Name != `my\x2dapp@b.service` {
+		t.Errorf("unexpected units %#v", units)
+	}
+
+	if _, err := m.ListInstances(context.Background(), "app.service"); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSetUnitProperties(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	calls := make(chan []any, 1)
+	bus.Handle(managerInterface, "SetUnitProperties", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		calls <- msg.Body
+		return "", nil, nil
+	})
+
+	err := m.SetUnitProperties(context.Background(), "web", true,
+		PropMemoryMax(1<<30),
+		PropCPUQuota(200),
+		PropTasksMax(Unset),
+		PropIPAddressDeny(netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")),
+		PropIPAddressAllow(netip.MustParsePrefix("10.0.0.0/8")),
+	)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	body := <-calls
+	if body[0] != "web.service" || body[1] != true {
+		t.Errorf("unexpected arguments %v", body[:2])
+	}
+	props := make(map[string]any)
+	for _, p := range body[2].([]any) {
+		p := p.(dbus.Struct)
+		props[p[0].(string)] = p[1].(dbus.Variant).Value
+	}
+	for name, expected := range map[string]any{
+		"MemoryMax":          uint64(1 << 30),
+		"CPUQuotaPerSecUSec": uint64(2_000_000),
+		"TasksMax":           uint64(math.MaxUint64),
+		"IPAddressDeny": []any{
+			dbus.Struct{int32(2), make([]byte, 4), uint32(0)},
+			dbus.Struct{int32(10), make([]byte, 16), uint32(0)},
+		},
+		"IPAddressAllow": []any{dbus.Struct{int32(2), []byte{10, 0, 0, 0}, uint32(8)}},
+	} {
+		if got := props[name]; !reflect.DeepEqual(expected, got) {
+			t.Errorf("%s: expected %v, but got %v", name, expected, got)
+		}
+	}
+}
+
+func TestKillUnit(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	calls := make(chan []any, 2)
+	bus.Handle(managerInterface, "KillUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		calls <- msg.Body
+		return "", nil, nil
+	})
+
+	ctx := context.Background()
+	if err := m.KillUnit(ctx, "web", KillMain, syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := []any{"web.service", "main", int32(syscall.SIGHUP)}, <-calls; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if err := m.KillUnit(ctx, "worker.scope", "", syscall.SIGKILL); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := []any{"worker.scope", "all", int32(syscall.SIGKILL)}, <-calls; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+// serveVarlink serves the Varlink interfaces of a fake service manager at a
+// unix socket, returning its path.
+func serveVarlink(t *testing.T, calls chan<- string) string {
+	t.Helper()
+
+	addr := filepath.Join(t.TempDir(), "io.systemd.Manager")
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+
+	units := []string{
+		`{"context":{"ID":"sshd.service","Description":"OpenSSH Daemon"},"runtime":{"LoadState":"loaded","ActiveState":"active","SubState":"running"}}`,
+		`{"context":{"ID":"broken.service","Description":"Broken"},"runtime":{"LoadState":"loaded","ActiveState":"failed","SubState":"failed"}}`,
+		`{"context":{"ID":"dbus.socket","Description":"D-Bus Socket"},"runtime":{"LoadState":"loaded","ActiveState":"active","SubState":"listening"}}`,
+	}
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer nc.Close()
+				r := bufio.NewReader(nc)
+				for {
+					line, err := r.ReadBytes(0)
+					if err != nil {
+						return
+					}
+					var req struct{ Method string }
+					_ = json.Unmarshal(line[:len(line)-1], &req)
+					calls <- req.Method
+
+					var replies []string
+					switch req.Method {
+					case "io.systemd.Unit.List":
+						for i, u := range units {
+							replies = append(replies, fmt.Sprintf(`{"parameters":%s,"continues":%t}`, u, i < len(units)-1))
+						}
+					case "io.systemd.Manager.Reload":
+						replies = []string{`{}`}
+					default:
+						replies = []string{`{"error":"org.varlink.service.MethodNotFound"}`}
+					}
+					for _, reply := range replies {
+						_, _ = nc.Write(append([]byte(reply), 0))
+					}
+				}
+			}()
+		}
+	}()
+	return addr
+}
+
+func TestVarlink(t *testing.T) {
+	calls := make(chan string, 16)
+	ctx := context.Background()
+	m, err := newVarlink(ctx, serveVarlink(t, calls))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer m.Close()
+	if expected, got := BackendVarlink, m.Backend(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	units, err := m.ListUnits(ctx, []string{"*.service"}, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var names []string
+	for _, u := range units {
+		names = append(names, u.Name)
+	}
+	if expected, got := []string{"broken.service", "sshd.service"}, names; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := ActiveStateActive, units[1].ActiveState; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+
+	units, err = m.ListUnits(ctx, nil, []string{"failed", "listening"})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := 2, len(units); expected != got {
+		t.Errorf("expected %d units, but got %d", expected, got)
+	}
+
+	if err := m.Reload(ctx); err != nil {
+		t.Error(err)
+	}
+	if expected, got := []string{"io.systemd.Unit.List", "io.systemd.Unit.List", "io.systemd.Manager.Reload"}, []string{<-calls, <-calls, <-calls}; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+
+	if _, err := m.StartUnit(ctx, "sshd.service", ModeReplace); !errors.Is(err, ErrUnsupportedBackend) {
+		t.Errorf("expected %v, but got %v", ErrUnsupportedBackend, err)
+	}
+	if _, err := m.GetService(ctx, "sshd.service"); !errors.Is(err, ErrUnsupportedBackend) {
+		t.Errorf("expected %v, but got %v", ErrUnsupportedBackend, err)
+	}
+	if _, err := m.Subscribe(ctx); !errors.Is(err, ErrUnsupportedBackend) {
+		t.Errorf("expected %v, but got %v", ErrUnsupportedBackend, err)
+	}
+}
+
+func TestPrivate(t *testing.T) {
+	bus, c := dbustest.NewPeer(t)
+	bus.Handle(managerInterface, "Subscribe", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "", nil, nil
+	})
+	handleJob(bus, "StartUnit", false, func(string) JobResult { return JobDone })
+
+	// Methods of the bus are not available on the private socket, so no match
+	// rules may be added.
+	ctx := context.Background()
+	m, err := newConn(ctx, c, nil)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	events, err := m.WatchUnit(ctx, "example")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	result, err := m.StartUnit(ctx, "example.service", ModeReplace)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := JobDone, result; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if ev := <-events; ev.Type != EventJobRemoved || ev.Unit != "example.service" {
+		t.Errorf("unexpected event %#v", ev)
+	}
+}
+
+func TestResetFailed(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	calls := make(chan string, 2)
+	bus.Handle(managerInterface, "ResetFailedUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		calls <- msg.Body[0].(string)
+		return "", nil, nil
+	})
+	bus.Handle(managerInterface, "ResetFailed", func(*dbus.Message) (dbus.Signature, []any, error) {
+		calls <- ""
+		return "", nil, nil
+	})
+
+	ctx := context.Background()
+	if err := m.ResetFailedUnit(ctx, "web"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "web.service", <-calls; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if err := m.ResetFailed(ctx); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "", <-calls; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestListDependencies(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	// app.service requires db.service and is ordered after it, db.service
+	// wants cache.service and both are ordered after each other.
+	deps := map[string]map[string][]string{
+		"app.service":   {"Requires": {"db.service"}, "After": {"db.service", "network.target"}},
+		"db.service":    {"Wants": {"cache.service"}, "After": {"cache.service"}, "RequiredBy": {"app.service"}},
+		"cache.service": {"After": {"db.service"}, "WantedBy": {"db.service"}},
+	}
+	var acyclic atomic.Bool
+	bus.Handle(managerInterface, "LoadUnit", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		return "o", []any{dbus.ObjectPath("/org/freedesktop/systemd1/unit/" + msg.Body[0].(string))}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		name := path.Base(string(msg.Path))
+		props := map[string]dbus.Variant{"Id": dbus.MakeVariant(name)}
+		for k, v := range deps[name] {
+			if acyclic.Load() && name == "cache.service" && k == "After" {
+				continue
+			}
+			props[k] = dbus.MakeVariant(v)
+		}
+		return "a{sv}", []any{props}, nil
+	})
+
+	ctx := context.Background()
+	g, err := m.ListDependencies(ctx, "app", false)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := []string{"app.service", "cache.service", "db.service", "network.target"}, slices.Sorted(maps.Keys(g.Units)); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	expected := []Dependency{
+		{From: "app.service", To: "db.service", Type: DependencyAfter},
+		{From: "app.service", To: "network.target", Type: DependencyAfter},
+		{From: "app.service", To: "db.service", Type: DependencyRequires},
+	}
+	if got := g.DependenciesOf("app.service"); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := [][]string{{"cache.service", "db.service"}}, g.Cycles(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if _, err := g.StartOrder(); !errors.Is(err, ErrOrderingCycle) {
+		t.Errorf("expected %v, but got %v", ErrOrderingCycle, err)
+	}
+
+	// Break the cycle.
+	acyclic.Store(true)
+	g, err = m.ListDependencies(ctx, "app.service", false)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	order, err := g.StartOrder()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := []string{"cache.service", "db.service", "network.target", "app.service"}, order; !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+
+	g, err = m.ListDependencies(ctx, "cache.service", true)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := []string{"app.service", "cache.service", "db.service"}, slices.Sorted(maps.Keys(g.Units)); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+}
+
+func TestUnitForPID(t *testing.T) {
+	bus, m := newTestConn(t)
+
+	const path = dbus.ObjectPath("/org/freedesktop/systemd1/unit/example_2eservice")
+	bus.Handle(managerInterface, "GetUnitByPID", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if pid := msg.Body[0].(uint32); pid != uint32(os.Getpid()) {
+			return "", nil, &dbus.Error{
+				Name: "org.freedesktop.systemd1.NoUnitForPID",
+				Body: []any{fmt.Sprintf("PID %d does not belong to any loaded unit.", pid)},
+			}
+		}
+		return "o", []any{path}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "Get", func(msg *dbus.Message) (dbus.Signature, []any, error) {
+		if msg.Path != path || msg.Body[0] != unitInterface || msg.Body[1] != "Id" {
+			t.Errorf("unexpected property %s %v", msg.Path, msg.Body)
+		}
+		return "v", []any{dbus.MakeVariant("example.service")}, nil
+	})
+
+	ctx := context.Background()
+	name, err := m.OwnUnit(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := "example.service", name; expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if _, err := m.UnitForPID(ctx, os.Getpid()+1); !errors.Is(err, ErrNoSuchUnit) {
+		t.Errorf("expected %v, but got %v", ErrNoSuchUnit, err)
+	}
+	if _, err := m.UnitForPID(ctx, 0); err == nil {
+		t.Error("expected an error for an invalid pid")
+	}
+}
@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdunit"
+)
+
+// unitPathPrefix is the prefix of the object paths of units.
+const unitPathPrefix = "/org/freedesktop/systemd1/unit/"
+
+// propertiesRule is the match rule for property changes of units.
+const propertiesRule = "type='signal',sender='" + destination + "',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged',path_namespace='/org/freedesktop/systemd1/unit'"
+
+// subscriber receives events from the service manager.
+type subscriber struct {
+	ch     chan Event
+	notify chan struct{}
+	// unit limits the events to a single unit, if set.
+	unit string
+
+	mu    sync.Mutex
+	queue []Event
+}
+
+// push queues an event for delivery without blocking.
+func (s *subscriber) push(ev Event) {
+	if s.unit != "" && ev.Type != EventReconnected && ev.Unit != s.unit {
+		return
+	}
+	s.mu.Lock()
+	s.queue = append(s.queue, ev)
+	s.mu.Unlock()
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers queued events until ctx is done or the connection is closed.
+func (s *subscriber) run(ctx context.Context, done <-chan struct{}) {
+	defer close(s.ch)
+	for {
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, ev := range queue {
+			select {
+			case s.ch <- ev:
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			}
+		}
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// broadcast sends an event to all subscribers.
+func (m *Conn) broadcast(ev Event) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for s := range m.subscribers {
+		s.push(ev)
+	}
+}
+
+// Subscribe returns a channel that receives events for all units until ctx is
+// done or the connection is closed, after which the channel is closed.
+//
+// Events are queued and never dropped, the channel should be drained
+// promptly to avoid unbounded memory growth.
+func (m *Conn) Subscribe(ctx context.Context) (<-chan Event, error) {
+	return m.subscribe(ctx, "")
+}
+
+// WatchUnit is like [Conn.Subscribe], but only receives events for the named
+// unit along with [EventReconnected]. If name does not have a unit type
+// suffix, `.service` is assumed.
+func (m *Conn) WatchUnit(ctx context.Context, name string) (<-chan Event, error) {
+	name, err := sdunit.Mangle(name, ".service")
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: %w", err)
+	}
+	return m.subscribe(ctx, name)
+}
+
+func (m *Conn) subscribe(ctx context.Context, unit string) (<-chan Event, error) {
+	if _, err := m.bus(); err != nil {
+		return nil, fmt.Errorf("sdmanager: unable to subscribe: %w", err)
+	}
+	m.mu.Lock()
+	watching, c := m.watching, m.conn
+	m.mu.Unlock()
+	if !watching && !isPeer(c) {
+		if err := c.AddMatch(ctx, propertiesRule); err != nil {
+			return nil, fmt.Errorf("sdmanager: unable to add match rule: %w", err)
+		}
+		m.mu.Lock()
+		m.watching = true
+		m.mu.Unlock()
+	}
+
+	s := &subscriber{
+		ch:     make(chan Event),
+		notify: make(chan struct{}, 1),
+		unit:   unit,
+	}
+	m.mu.Lock()
+	m.subscribers[s] = struct{}{}
+	m.mu.Unlock()
+
+	go func() {
+		s.run(ctx, m.done)
+		m.mu.Lock()
+		delete(m.subscribers, s)
+		m.mu.Unlock()
+	}()
+	return s.ch, nil
+}
+
+// parseEvent converts a signal from the service manager to an [Event].
+func parseEvent(msg *dbus.Message) (Event, bool) {
+	if msg.Interface == "org.freedesktop.DBus.Properties" && msg.Member == "PropertiesChanged" {
+		return parsePropertiesChanged(msg)
+	}
+	if msg.Path != managerPath || msg.Interface != managerInterface {
+		return Event{}, false
+	}
+
+	ev, ok := Event{}, true
+	switch msg.Member {
+	case "UnitNew", "UnitRemoved":
+		// UnitNew(s id, o unit)
+		if msg.Signature != "so" {
+			return Event{}, false
+		}
+		ev.Type = EventUnitNew
+		if msg.Member == "UnitRemoved" {
+			ev.Type = EventUnitRemoved
+		}
+		ev.Unit = msg.Body[0].(string)
+	case "JobNew":
+		// JobNew(u id, o job, s unit)
+		if msg.Signature != "uos" {
+			return Event{}, false
+		}
+		ev.Type = EventJobNew
+		ev.Job, ev.Unit = msg.Body[0].(uint32), msg.Body[2].(string)
+	case "JobRemoved":
+		// JobRemoved(u id, o job, s unit, s result)
+		if msg.Signature != "uoss" {
+			return Event{}, false
+		}
+		ev.Type = EventJobRemoved
+		ev.Job, ev.Unit = msg.Body[0].(uint32), msg.Body[2].(string)
+		ev.Result = JobResult(msg.Body[3].(string))
+	default:
+		ok = false
+	}
+	return ev, ok
+}
+
+// parsePropertiesChanged converts a `PropertiesChanged` signal emitted by a
+// unit to an [Event].
+func parsePropertiesChanged(msg *dbus.Message) (Event, bool) {
+	// PropertiesChanged(s interface, a{sv} changed, as invalidated)
+	if msg.Signature != "sa{sv}as" {
+		return Event{}, false
+	}
+	unit, ok := unitFromPath(msg.Path)
+	if !ok {
+		return Event{}, false
+	}
+	changed, ok := dbus.VariantMap(msg.Body[1])
+	if !ok {
+		return Event{}, false
+	}
+
+	ev := Event{
+		Type:    EventPropertiesChanged,
+		Unit:    unit,
+		Changed: make(map[string]any, len(changed)),
+	}
+	for k, v := range changed {
+		ev.Changed[k] = v.Value
+	}
+	for _, v := range msg.Body[2].([]any) {
+		ev.Invalidated = append(ev.Invalidated, v.(string))
+	}
+	return ev, true
+}
+
+// unitFromPath returns the name of a unit from its object path, reversing the
+// escaping systemd applies to object path labels, such as
+// `/org/freedesktop/systemd1/unit/nginx_2eservice`.
+func unitFromPath(path dbus.ObjectPath) (string, bool) {
+	label, ok := strings.CutPrefix(string(path), unitPathPrefix)
+	if !ok || label == "" || strings.Contains(label, "/") {
+		return "", false
+	}
+	var b strings.Builder
+	for i := 0; i < len(label); i++ {
+		if label[i] != '_' {
+			b.WriteByte(label[i])
+			continue
+		}
+		if i+2 >= len(label) {
+			return "", false
+		}
+		hi, ok1 := unhex(label[i+1])
+		lo, ok2 := unhex(label[i+2])
+		if !ok1 || !ok2 {
+			return "", false
+		}
+		b.WriteByte(hi<<4 | lo)
+		i += 2
+	}
+	return b.String(), true
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case '0' <= c && c <= '9':
+		return c - '0', true
+	case 'a' <= c && c <= 'f':
+		return c - 'a' + 10, true
+	case 'A' <= c && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
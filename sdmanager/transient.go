@@ -0,0 +1,222 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/sdid128"
+	"github.com/matthewpi/sd/sdunit"
+)
+
+// StartTransientUnit creates a transient unit with the given properties and
+// starts it, waiting for the start job to complete. The type of the unit is
+// determined by the suffix of name.
+//
+// Transient units only exist until they stop, unless they fail, in which case
+// they remain loaded until reset, see [PropCollectMode].
+func (m *Conn) StartTransientUnit(ctx context.Context, name string, mode Mode, props ...Property) (JobResult, error) {
+	return m.startTransient(ctx, name, mode, props, nil)
+}
+
+// auxUnit is an auxiliary transient unit, created along with the main unit
+// of a call to StartTransientUnit.
+type auxUnit struct {
+	name  string
+	props []Property
+}
+
+// startTransient creates and starts a transient unit, along with any
+// auxiliary units which are created but not started.
+func (m *Conn) startTransient(ctx context.Context, name string, mode Mode, props []Property, aux []auxUnit) (JobResult, error) {
+	p, err := encodeProperties(props)
+	if err != nil {
+		return "", err
+	}
+	a := make([]dbus.Struct, len(aux))
+	for i, u := range aux {
+		up, err := encodeProperties(u.props)
+		if err != nil {
+			return "", err
+		}
+		a[i] = dbus.Struct{u.name, up}
+	}
+	return m.runJob(ctx, "start transient unit", name, "StartTransientUnit", "ssa(sv)a(sa(sv))", name, string(defaultMode(mode)), p, a)
+}
+
+// RunTransientService starts a command as a transient service, equivalent to
+// `systemd-run`. It returns the name of the service once it has started.
+//
+// If name is empty, a unique name is generated, the `.service` suffix may be
+// omitted. The service defaults to `Type=simple`, so the start job completes
+// as soon as the command is executed, use [PropType] with `exec` or `oneshot`
+// to wait until the command was executed successfully or finished instead.
+func (m *Conn) RunTransientService(ctx context.Context, name string, execStart []string, props ...Property) (string, error) {
+	if len(execStart) == 0 {
+		return "", errors.New("sdmanager: transient service requires a command")
+	}
+	name, err := transientName(name, ".service")
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := m.StartTransientUnit(ctx, name, ModeFail, execProperties(execStart, props)...); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// execProperties returns the properties of a transient service running argv,
+// adding a default description if props does not include one.
+func execProperties(argv []string, props []Property) []Property {
+	all := make([]Property, 0, len(props)+2)
+	if !hasProperty(props, "Description") {
+		all = append(all, PropDescription("[sdmanager] "+strings.Join(argv, " ")))
+	}
+	all = append(all, PropExecStart(argv, false))
+	return append(all, props...)
+}
+
+// StartScope moves already running processes into a new transient scope unit,
+// placing them in their own cgroup where they are subject to the resource
+// limits of the scope. It returns the name of the scope once it has started.
+//
+// If name is empty, a unique name is generated, the `.scope` suffix may be
+// omitted. Scopes default to `CollectMode=inactive-or-failed`, so they are
+// unloaded once all of their processes exit even if the scope failed. If the
+// scope is created but fails to start, it is stopped, killing the processes
+// that were moved into it.
+//
+// Use [PropDelegate] to allow the processes to manage their own cgroup
+// subtree, such as a process manager that starts its own workers.
+func (m *Conn) StartScope(ctx context.Context, name string, pids []int, props ...Property) (string, error) {
+	if len(pids) == 0 {
+		return "", errors.New("sdmanager: transient scope requires at least one process")
+	}
+	p := make([]uint32, len(pids))
+	for i, pid := range pids {
+		if pid <= 0 || int64(pid) > math.MaxUint32 {
+			return "", fmt.Errorf("sdmanager: invalid pid %d", pid)
+		}
+		p[i] = uint32(pid)
+	}
+	name, err := transientName(name, ".scope")
+	if err != nil {
+		return "", err
+	}
+
+	all := make([]Property, 0, len(props)+2)
+	all = append(all, Property{Name: "PIDs", Value: p, Signature: "au"})
+	if !hasProperty(props, "CollectMode") {
+		all = append(all, PropCollectMode("inactive-or-failed"))
+	}
+	all = append(all, props...)
+	if _, err := m.StartTransientUnit(ctx, name, ModeFail, all...); err != nil {
+		var jobErr *JobError
+		if errors.As(err, &jobErr) {
+			_, _ = m.StopUnit(context.WithoutCancel(ctx), name, ModeReplace)
+		}
+		return name, err
+	}
+	return name, nil
+}
+
+// ScheduleTransientTimer creates and starts a transient timer, equivalent to
+// `systemd-run --on-calendar` or `systemd-run --on-active`. It returns the
+// name of the timer once it has started.
+//
+// If name is empty, a unique name is generated, the `.timer` suffix may be
+// omitted. If [TimerSpec.Exec] is set, a transient service with the same name
+// as the timer is created to run the command. Timers that only elapse once are
+// unloaded after elapsing.
+func (m *Conn) ScheduleTransientTimer(ctx context.Context, name string, spec TimerSpec, props ...Property) (string, error) {
+	timerProps, err := spec.properties()
+	if err != nil {
+		return "", err
+	}
+	name, err = transientName(name, ".timer")
+	if err != nil {
+		return "", err
+	}
+
+	var aux []auxUnit
+	if len(spec.Exec) > 0 {
+		aux = append(aux, auxUnit{
+			name:  strings.TrimSuffix(name, ".timer") + ".service",
+			props: execProperties(spec.Exec, spec.ServiceProperties),
+		})
+	}
+	if !hasProperty(props, "Description") {
+		timerProps = append(timerProps, PropDescription("[sdmanager] timer "+name))
+	}
+	if _, err := m.startTransient(ctx, name, ModeFail, append(timerProps, props...), aux); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// StartTransientSocket creates and starts a transient socket, equivalent to
+// `systemd-run --socket-property=Listen...`. It returns the name of the socket
+// once it is listening.
+//
+// If name is empty, a unique name is generated, the `.socket` suffix may be
+// omitted. If [SocketSpec.Exec] is set, a transient service with the same name
+// as the socket is created to run the command, otherwise the socket activates
+// [SocketSpec.Service]. Either way, the service receives the listening file
+// descriptors when it is activated.
+func (m *Conn) StartTransientSocket(ctx context.Context, name string, spec SocketSpec, props ...Property) (string, error) {
+	socketProps, err := spec.properties()
+	if err != nil {
+		return "", err
+	}
+	name, err = transientName(name, ".socket")
+	if err != nil {
+		return "", err
+	}
+
+	var aux []auxUnit
+	if len(spec.Exec) > 0 {
+		aux = append(aux, auxUnit{
+			name:  strings.TrimSuffix(name, ".socket") + ".service",
+			props: execProperties(spec.Exec, spec.ServiceProperties),
+		})
+	}
+	if !hasProperty(props, "Description") {
+		socketProps = append(socketProps, PropDescription("[sdmanager] socket "+name))
+	}
+	if _, err := m.startTransient(ctx, name, ModeFail, append(socketProps, props...), aux); err != nil {
+		return name, err
+	}
+	return name, nil
+}
+
+// transientName returns a mangled unit name for a transient unit, generating a
+// unique one if name is empty.
+func transientName(name, suffix string) (string, error) {
+	if name == "" {
+		id, err := sdid128.Random()
+		if err != nil {
+			return "", fmt.Errorf("sdmanager: unable to generate unit name: %w", err)
+		}
+		return "run-r" + id.String() + suffix, nil
+	}
+	name, err := sdunit.Mangle(name, suffix)
+	if err != nil {
+		return "", fmt.Errorf("sdmanager: %w", err)
+	}
+	return name, nil
+}
+
+// hasProperty reports whether props contains a property with the given name.
+func hasProperty(props []Property, name string) bool {
+	return slices.ContainsFunc(props, func(p Property) bool { return p.Name == name })
+}
@@ -0,0 +1,306 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+import (
+	"math"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/sddaemon"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+// Infinity is the value of a duration property that is unlimited, such as
+// `TimeoutStartSec=infinity`.
+const Infinity time.Duration = math.MaxInt64
+
+// Unset is the value of an accounting property that is unavailable, either
+// because accounting is disabled or the unit is not running.
+const Unset uint64 = math.MaxUint64
+
+// LoadState is the load state of a unit.
+type LoadState string
+
+const (
+	LoadStateStub       LoadState = "stub"
+	LoadStateLoaded     LoadState = "loaded"
+	LoadStateNotFound   LoadState = "not-found"
+	LoadStateBadSetting LoadState = "bad-setting"
+	LoadStateError      LoadState = "error"
+	LoadStateMerged     LoadState = "merged"
+	LoadStateMasked     LoadState = "masked"
+)
+
+// ActiveState is the high-level state of a unit.
+type ActiveState string
+
+const (
+	ActiveStateActive       ActiveState = "active"
+	ActiveStateReloading    ActiveState = "reloading"
+	ActiveStateInactive     ActiveState = "inactive"
+	ActiveStateFailed       ActiveState = "failed"
+	ActiveStateActivating   ActiveState = "activating"
+	ActiveStateDeactivating ActiveState = "deactivating"
+	ActiveStateMaintenance  ActiveState = "maintenance"
+	ActiveStateRefreshing   ActiveState = "refreshing"
+)
+
+// Unit holds the properties common to all units.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Properties1
+type Unit struct {
+	Name          string `property:"Id"`
+	Names         []string
+	Description   string
+	LoadState     LoadState
+	ActiveState   ActiveState
+	FreezerState  string
+	SubState      string
+	FragmentPath  string
+	SourcePath    string
+	DropInPaths   []string
+	UnitFileState UnitFileState
+	InvocationID  sdid128.ID128
+
+	Requires   []string
+	Wants      []string
+	BindsTo    []string
+	RequiredBy []string
+	WantedBy   []string
+	BoundBy    []string
+	Conflicts  []string
+	Before     []string
+	After      []string
+	Triggers   []string
+
+	StateChangeTimestamp   time.Time
+	ActiveEnterTimestamp   time.Time
+	ActiveExitTimestamp    time.Time
+	InactiveEnterTimestamp time.Time
+	InactiveExitTimestamp  time.Time
+
+	CanStart         bool
+	CanStop          bool
+	CanReload        bool
+	CanIsolate       bool
+	NeedDaemonReload bool
+}
+
+// Accounting holds the resource accounting properties of units that have a
+// control group, such as services, sockets, and scopes. Values are [Unset] if
+// the corresponding accounting is disabled or the unit is not running.
+type Accounting struct {
+	ControlGroup      string
+	MemoryCurrent     uint64
+	MemoryPeak        uint64
+	MemorySwapCurrent uint64
+	CPUUsage          time.Duration `property:"CPUUsageNSec,nsec"`
+	TasksCurrent      uint64
+	IPIngressBytes    uint64
+	IPEgressBytes     uint64
+	IOReadBytes       uint64
+	IOWriteBytes      uint64
+}
+
+// Timeouts holds the timeout and watchdog settings of a service unit. Limits
+// that are disabled are [Infinity], except for Watchdog which is zero if the
+// watchdog is disabled.
+//
+// Unlike `$WATCHDOG_USEC`, which is only set once when the service is
+// started, these reflect the current settings of the service, including
+// changes made using [Conn.SetUnitProperties] or `WATCHDOG_USEC=` and
+// `EXTEND_TIMEOUT_USEC=` notifications.
+type Timeouts struct {
+	// TimeoutStart is the time allowed for the service to start, as
+	// configured by `TimeoutStartSec=`.
+	TimeoutStart time.Duration `property:"TimeoutStartUSec"`
+	// TimeoutStop is the time allowed for the service to stop, as configured
+	// by `TimeoutStopSec=`.
+	TimeoutStop time.Duration `property:"TimeoutStopUSec"`
+	// RuntimeMax is the maximum time the service may run for, as configured
+	// by `RuntimeMaxSec=`.
+	RuntimeMax time.Duration `property:"RuntimeMaxUSec"`
+	// Watchdog is the watchdog timeout of the service, as configured by
+	// `WatchdogSec=`.
+	Watchdog time.Duration `property:"WatchdogUSec"`
+}
+
+// Service holds the properties of a service unit.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Service_Unit_Objects
+type Service struct {
+	Unit
+	Accounting
+	Timeouts
+
+	Type        string
+	Restart     string
+	Result      sddaemon.ServiceResult
+	StatusText  string
+	StatusErrno int32
+	NRestarts   uint32
+
+	MainPID    uint32
+	ControlPID uint32
+
+	ExecMainPID            uint32
+	ExecMainCode           int32
+	ExecMainStatus         int32
+	ExecMainStartTimestamp time.Time
+	ExecMainExitTimestamp  time.Time
+
+	WatchdogTimestamp time.Time
+}
+
+// Exit returns how the main process of the service last exited.
+func (s *Service) Exit() sddaemon.ExitInfo {
+	e := sddaemon.ExitInfo{Result: s.Result}
+	// ExecMainCode is a `CLD_*` code from waitid(2).
+	switch s.ExecMainCode {
+	case 1:
+		e.Code = sddaemon.ExitCodeExited
+		e.Status = int(s.ExecMainStatus)
+	case 2:
+		e.Code = sddaemon.ExitCodeKilled
+		e.Signal = syscall.Signal(s.ExecMainStatus)
+	case 3:
+		e.Code = sddaemon.ExitCodeDumped
+		e.Signal = syscall.Signal(s.ExecMainStatus)
+	}
+	return e
+}
+
+// FailureReport summarizes the last run of a service, such as why it failed,
+// as returned by [Conn.GetFailureReport].
+type FailureReport struct {
+	// Unit is the name of the service.
+	Unit string
+	// Failed reports whether the service is in the failed state.
+	Failed      bool
+	ActiveState ActiveState
+	SubState    string
+
+	// Exit is the result of the service and how its main process exited.
+	Exit sddaemon.ExitInfo
+	// StatusText and StatusErrno are the last status reported by the service
+	// using `STATUS=` and `ERRNO=` notifications.
+	StatusText  string
+	StatusErrno int32
+	// NRestarts is the number of times the service was restarted
+	// automatically.
+	NRestarts uint32
+
+	// InvocationID is the ID of the last invocation of the service, which
+	// may be used to find its log entries, such as with `journalctl
+	// _SYSTEMD_INVOCATION_ID=<id>`.
+	InvocationID sdid128.ID128
+	// StartedAt and ExitedAt are when the main process was started and
+	// exited, they are zero if it never ran or has not exited.
+	StartedAt time.Time
+	ExitedAt  time.Time
+	// ChangedAt is when the service last changed state, such as when it
+	// failed.
+	ChangedAt time.Time
+}
+
+// Listen is an address a socket unit listens on.
+type Listen struct {
+	// Type is the type of the address, such as `Stream` or `Datagram`.
+	Type string
+	// Address is the address, such as `[::]:80` or `/run/example.sock`.
+	Address string
+}
+
+// Socket holds the properties of a socket unit.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Socket_Unit_Objects
+type Socket struct {
+	Unit
+	Accounting
+
+	Listen             []Listen
+	Accept             bool
+	FileDescriptorName string
+	Result             string
+	NConnections       uint32
+	NAccepted          uint32
+	NRefused           uint32
+	ControlPID         uint32
+}
+
+// CalendarTimer is a realtime timer of a timer unit, such as `OnCalendar=`.
+type CalendarTimer struct {
+	// Base is the setting that defined the timer, such as `OnCalendar`.
+	Base string
+	// Expression is the calendar expression.
+	Expression string
+	// NextElapse is when the timer next elapses.
+	NextElapse time.Time
+}
+
+// MonotonicTimer is a monotonic timer of a timer unit, such as
+// `OnBootSec=`.
+type MonotonicTimer struct {
+	// Base is the setting that defined the timer, such as `OnBootUSec`.
+	Base string
+	// Value is the value of the setting.
+	Value time.Duration
+	// NextElapse is the monotonic time at which the timer next elapses.
+	NextElapse time.Duration
+}
+
+// Timer holds the properties of a timer unit.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.systemd1.html#Timer_Unit_Objects
+type Timer struct {
+	Unit
+
+	// TriggerUnit is the unit activated by the timer.
+	TriggerUnit string `property:"Unit"`
+
+	TimersCalendar  []CalendarTimer
+	TimersMonotonic []MonotonicTimer
+
+	NextElapseRealtime  time.Time     `property:"NextElapseUSecRealtime"`
+	NextElapseMonotonic time.Duration `property:"NextElapseUSecMonotonic"`
+	LastTrigger         time.Time     `property:"LastTriggerUSec"`
+
+	Result          string
+	Persistent      bool
+	WakeSystem      bool
+	Accuracy        time.Duration `property:"AccuracyUSec"`
+	RandomizedDelay time.Duration `property:"RandomizedDelayUSec"`
+}
+
+// UnitStatus is a summary of the state of a unit, as returned by
+// [Conn.ListUnits] and shown by `systemctl list-units`.
+type UnitStatus struct {
+	Name        string
+	Description string
+	LoadState   LoadState
+	ActiveState ActiveState
+	SubState    string
+
+	// Following is the unit this unit follows in state, if any, such as a
+	// device unit following another device unit.
+	Following string
+
+	// JobID is the ID of the job queued for the unit, or zero if there is
+	// none.
+	JobID uint32
+	// JobType is the type of the job queued for the unit, such as `start`.
+	JobType string
+}
+
+// SocketListing is an address a socket unit listens on, as returned by
+// [Conn.ListSockets] and shown by `systemctl list-sockets`.
+type SocketListing struct {
+	// Listen is the address the socket listens on.
+	Listen Listen
+	// Unit is the name of the socket unit.
+	Unit string
+	// Activates are the units activated by the socket.
+	Activates []string
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmanager
+
+// ChangeType is the type of a change made to the unit file configuration.
+type ChangeType string
+
+const (
+	// ChangeSymlink indicates a symlink was created.
+	ChangeSymlink ChangeType = "symlink"
+	// ChangeUnlink indicates a symlink was removed.
+	ChangeUnlink ChangeType = "unlink"
+	// ChangeMasked indicates the unit could not be enabled as it is masked,
+	// no changes were made.
+	ChangeMasked ChangeType = "is-masked"
+	// ChangeDangling indicates a symlink is dangling, no changes were made.
+	ChangeDangling ChangeType = "is-dangling"
+)
+
+// ReloadRequired reports whether any of the changes modified the unit file
+// configuration, in which case the service manager must be reloaded using
+// [Conn.Reload] for them to take effect.
+func ReloadRequired(changes []UnitFileChange) bool {
+	for _, c := range changes {
+		if c.Type == ChangeSymlink || c.Type == ChangeUnlink {
+			return true
+		}
+	}
+	return false
+}
+
+// UnitFileState is the enablement state of a unit file.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemctl.html#is-enabled%20UNIT%E2%80%A6
+type UnitFileState string
+
+const (
+	UnitFileEnabled        UnitFileState = "enabled"
+	UnitFileEnabledRuntime UnitFileState = "enabled-runtime"
+	UnitFileLinked         UnitFileState = "linked"
+	UnitFileLinkedRuntime  UnitFileState = "linked-runtime"
+	UnitFileAlias          UnitFileState = "alias"
+	UnitFileMasked         UnitFileState = "masked"
+	UnitFileMaskedRuntime  UnitFileState = "masked-runtime"
+	UnitFileStatic         UnitFileState = "static"
+	UnitFileIndirect       UnitFileState = "indirect"
+	UnitFileDisabled       UnitFileState = "disabled"
+	UnitFileGenerated      UnitFileState = "generated"
+	UnitFileTransient      UnitFileState = "transient"
+	UnitFileBad            UnitFileState = "bad"
+)
+
+// UnitFileChange is a change made to the unit file configuration, such as a
+// symlink created when linking or enabling a unit file.
+type UnitFileChange struct {
+	// Type is the type of the change.
+	Type ChangeType
+	// Filename is the path of the symlink that was created or removed.
+	Filename string
+	// Destination is the target of the symlink, it is empty for
+	// [ChangeUnlink].
+	Destination string
+}
+
+// UnitFileOptions controls how unit files are installed.
+type UnitFileOptions struct {
+	// Runtime makes the changes only until the next reboot, by placing them
+	// under `/run` instead of `/etc`.
+	Runtime bool
+	// Force replaces any conflicting symlinks.
+	Force bool
+}
+
+// PresetMode controls which parts of a preset policy are applied.
+type PresetMode string
+
+const (
+	// PresetFull enables and disables units according to the preset policy.
+	PresetFull PresetMode = "full"
+	// PresetEnableOnly only enables units according to the preset policy.
+	PresetEnableOnly PresetMode = "enable-only"
+	// PresetDisableOnly only disables units according to the preset policy.
+	PresetDisableOnly PresetMode = "disable-only"
+)
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdmanager
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+
+	"github.com/matthewpi/sd/internal/varlink"
+)
+
+// varlinkAddress is the path of the Varlink socket of the system service
+// manager.
+const varlinkAddress = "/run/systemd/io.systemd.Manager"
+
+// NewVarlink connects to the system service manager using its Varlink
+// interfaces rather than D-Bus, allowing it to be used without a bus daemon.
+// This requires systemd v258 or newer.
+//
+// Only [Conn.ListUnits] and [Conn.Reload] are supported over Varlink, all other
+// operations return [ErrUnsupportedBackend].
+func NewVarlink(ctx context.Context) (*Conn, error) {
+	return newVarlink(ctx, varlinkAddress)
+}
+
+// newVarlink returns a [Conn] using the Varlink socket at addr.
+func newVarlink(ctx context.Context, addr string) (*Conn, error) {
+	// Each operation uses its own connection, connect once to ensure the
+	// service manager is listening.
+	c, err := varlink.Dial(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("sdmanager: %w", err)
+	}
+	_ = c.Close()
+	return &Conn{
+		varlink:     addr,
+		done:        make(chan struct{}),
+		subscribers: make(map[*subscriber]struct{}),
+	}, nil
+}
+
+// callVarlink calls a method on the service manager using Varlink, fn is
+// called with the parameters of each reply.
+func (m *Conn) callVarlink(ctx context.Context, method string, params any, more bool, fn func(json.RawMessage) error) error {
+	select {
+	case <-m.done:
+		return errClosed
+	default:
+	}
+	c, err := varlink.Dial(ctx, m.varlink)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if more {
+		err = c.CallMore(ctx, method, params, fn)
+	} else {
+		err = c.Call(ctx, method, params, nil)
+	}
+	var e *varlink.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name}
+	}
+	return err
+}
+
+// varlinkUnit holds the fields of a unit returned by `io.systemd.Unit.List`,
+// they are spread across the `context` and `runtime` objects of the reply.
+type varlinkUnit struct {
+	ID          string
+	Description string
+	LoadState   string
+	ActiveState string
+	SubState    string
+	Following   string
+}
+
+// listUnitsVarlink implements [Conn.ListUnits] using Varlink.
+//
+// ref; https://github.com/systemd/systemd/blob/main/src/shared/varlink-io.systemd.Unit.c
+func (m *Conn) listUnitsVarlink(ctx context.Context, patterns, states []string) ([]UnitStatus, error) {
+	var units []UnitStatus
+	err := m.callVarlink(ctx, "io.systemd.Unit.List", nil, true, func(p json.RawMessage) error {
+		var reply struct {
+			Context json.RawMessage `json:"context"`
+			Runtime json.RawMessage `json:"runtime"`
+		}
+		if err := json.Unmarshal(p, &reply); err != nil {
+			return err
+		}
+		var u varlinkUnit
+		for _, obj := range []json.RawMessage{reply.Context, reply.Runtime} {
+			if len(obj) == 0 {
+				continue
+			}
+			if err := json.Unmarshal(obj, &u); err != nil {
+				return err
+			}
+		}
+		if !matchUnit(u, patterns, states) {
+			return nil
+		}
+		units = append(units, UnitStatus{
+			Name:        u.ID,
+			Description: u.Description,
+			LoadState:   LoadState(u.LoadState),
+			ActiveState: ActiveState(u.ActiveState),
+			SubState:    u.SubState,
+			Following:   u.Following,
+		})
+		return nil
+	})
+	if err != nil && !errors.Is(err, ErrNoSuchUnit) {
+		return nil, fmt.Errorf("sdmanager: unable to list units: %w", err)
+	}
+	slices.SortFunc(units, func(a, b UnitStatus) int { return cmp.Compare(a.Name, b.Name) })
+	return units, nil
+}
+
+// matchUnit reports whether u matches the patterns and states given to
+// [Conn.ListUnits], the same as `ListUnitsByPatterns` over D-Bus.
+func matchUnit(u varlinkUnit, patterns, states []string) bool {
+	if len(states) > 0 && !slices.Contains(states, u.LoadState) &&
+		!slices.Contains(states, u.ActiveState) && !slices.Contains(states, u.SubState) {
+		return false
+	}
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, u.ID); ok {
+			return true
+		}
+	}
+	return false
+}
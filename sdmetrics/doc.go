@@ -0,0 +1,24 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdmetrics exposes metrics about the systemd integration of an
+// application, such as the messages sent to `sd_notify`, keep-alives sent to
+// the watchdog, and connections accepted by [sd.Server], so operators can
+// monitor the integration layer itself.
+//
+// The metrics are counters kept by the other packages of this module, they
+// cost nothing if sdmetrics is not used. sdmetrics does not depend on a
+// metrics library, metrics are either served in the Prometheus text format
+// by [Handler], or registered on a registry of the caller using [Register]
+// with a small adapter, such as for the Prometheus client:
+//
+//	type registerer struct{ prometheus.Registerer }
+//
+//	func (r registerer) RegisterCounter(name, help string, value func() float64) error {
+//		return r.Register(prometheus.NewCounterFunc(prometheus.CounterOpts{Name: name, Help: help}, value))
+//	}
+//
+//	err := sdmetrics.Register(registerer{prometheus.DefaultRegisterer})
+//
+// [sd.Server]: https://pkg.go.dev/github.com/matthewpi/sd#Server
+package sdmetrics
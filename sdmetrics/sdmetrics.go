@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmetrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/matthewpi/sd/internal/metrics"
+)
+
+// Metric is a counter of the module.
+type Metric struct {
+	// Name of the metric, such as `sd_notify_messages_sent_total`.
+	Name string
+	// Help describes the metric.
+	Help string
+	// Value is the current value of the counter.
+	Value uint64
+}
+
+// counters are the counters of the module, in the order they are exposed.
+var counters = []struct {
+	name, help string
+	v          *atomic.Uint64
+}{
+	{"sd_notify_messages_sent_total", "Messages sent to the sd_notify socket.", &metrics.NotifySent},
+	{"sd_notify_messages_failed_total", "Messages that could not be sent to the sd_notify socket.", &metrics.NotifyFailed},
+	{"sd_watchdog_pings_total", "Keep-alives sent to the systemd watchdog.", &metrics.WatchdogPings},
+	{"sd_watchdog_misses_total", "Keep-alives to the systemd watchdog that were skipped or failed.", &metrics.WatchdogMisses},
+	{"sd_listener_accepts_total", "Connections accepted by listeners of sd.Server.", &metrics.ListenerAccepts},
+	{"sd_listener_errors_total", "Errors accepting connections on listeners of sd.Server.", &metrics.ListenerErrors},
+}
+
+// Metrics returns the current values of all the metrics.
+func Metrics() []Metric {
+	m := make([]Metric, len(counters))
+	for i, c := range counters {
+		m[i] = Metric{Name: c.name, Help: c.help, Value: c.v.Load()}
+	}
+	return m
+}
+
+// Registerer registers metrics on a metrics registry, such as an adapter for
+// the registry of the Prometheus client.
+type Registerer interface {
+	// RegisterCounter registers a counter whose current value is returned by
+	// value.
+	RegisterCounter(name, help string, value func() float64) error
+}
+
+// Register registers all the metrics on r, returning the first error.
+func Register(r Registerer) error {
+	for _, c := range counters {
+		v := c.v
+		if err := r.RegisterCounter(c.name, c.help, func() float64 { return float64(v.Load()) }); err != nil {
+			return fmt.Errorf("sdmetrics: unable to register %s: %w", c.name, err)
+		}
+	}
+	return nil
+}
+
+// WriteText writes all the metrics in the Prometheus text exposition format.
+//
+// ref; https://prometheus.io/docs/instrumenting/exposition_formats/#text-based-format
+func WriteText(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, m := range Metrics() {
+		bw.WriteString("# HELP " + m.Name + " " + strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(m.Help) + "\n")
+		bw.WriteString("# TYPE " + m.Name + " counter\n")
+		bw.WriteString(m.Name + " " + strconv.FormatUint(m.Value, 10) + "\n")
+	}
+	return bw.Flush()
+}
+
+// Handler returns a [http.Handler] serving all the metrics in the Prometheus
+// text exposition format, to be scraped directly or mounted next to the
+// handler of another metrics library.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = WriteText(w)
+	})
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdmetrics
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matthewpi/sd/internal/metrics"
+)
+
+type fakeRegisterer map[string]func() float64
+
+func (r fakeRegisterer) RegisterCounter(name, _ string, value func() float64) error {
+	if _, ok := r[name]; ok {
+		return errors.New("duplicate metric")
+	}
+	r[name] = value
+	return nil
+}
+
+func TestMetrics(t *testing.T) {
+	metrics.WatchdogPings.Add(3)
+
+	r := fakeRegisterer{}
+	if err := Register(r); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(r) != len(counters) {
+		t.Errorf("expected %d metrics, but got %d", len(counters), len(r))
+	}
+	before := r["sd_watchdog_pings_total"]()
+	metrics.WatchdogPings.Add(1)
+	if got := r["sd_watchdog_pings_total"](); got != before+1 {
+		t.Errorf("expected %v, but got %v", before+1, got)
+	}
+	if err := Register(r); err == nil {
+		t.Error("expected an error")
+	}
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, expected := range []string{
+		"# HELP sd_watchdog_pings_total Keep-alives sent to the systemd watchdog.\n",
+		"# TYPE sd_watchdog_pings_total counter\n",
+		"\nsd_watchdog_pings_total 4\n",
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("expected %q in %q", expected, body)
+		}
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type \"%s\"", ct)
+	}
+}
@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdnetwork
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of networkd.
+	destination = "org.freedesktop.network1"
+
+	// managerPath is the object path of networkd.
+	managerPath dbus.ObjectPath = "/org/freedesktop/network1"
+
+	// managerInterface is the interface of networkd.
+	managerInterface = "org.freedesktop.network1.Manager"
+)
+
+// Conn is a connection to systemd-networkd on the system bus, used for the
+// information that is not available in the state files of networkd.
+type Conn struct {
+	conn *dbus.Conn
+}
+
+// New connects to networkd on the system bus.
+func New(ctx context.Context) (*Conn, error) {
+	c, err := dbus.SystemBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdnetwork: unable to connect to bus: %w", err)
+	}
+	return newConn(c), nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection.
+func newConn(c *dbus.Conn) *Conn {
+	return &Conn{conn: c}
+}
+
+// Close closes the connection to networkd.
+func (n *Conn) Close() error {
+	return n.conn.Close()
+}
+
+// convertError converts a D-Bus error into an [Error].
+func convertError(err error) error {
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Message: e.Message()}
+	}
+	return err
+}
+
+// description is the subset of the JSON description of networkd used by this
+// package.
+type description struct {
+	Interfaces []struct {
+		Index     int
+		Name      string
+		Type      string
+		Addresses []struct {
+			Address      ipBytes
+			PrefixLength int
+			ScopeString  string
+			ConfigSource string
+			ConfigState  string
+		}
+		Routes []struct {
+			Destination             ipBytes
+			DestinationPrefixLength int
+			Gateway                 ipBytes
+			PreferredSource         ipBytes
+			Table                   uint32
+			Priority                uint32
+			ProtocolString          string
+			ScopeString             string
+			TypeString              string
+			ConfigSource            string
+		}
+	}
+}
+
+// ipBytes is an IP address encoded by networkd as an array of bytes.
+type ipBytes netip.Addr
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (a *ipBytes) UnmarshalJSON(b []byte) error {
+	var octets []byte
+	if err := json.Unmarshal(b, &octets); err != nil {
+		return err
+	}
+	if len(octets) == 0 {
+		*a = ipBytes{}
+		return nil
+	}
+	addr, ok := netip.AddrFromSlice(octets)
+	if !ok {
+		return fmt.Errorf("invalid address of %d bytes", len(octets))
+	}
+	*a = ipBytes(addr)
+	return nil
+}
+
+// Describe returns the addresses and routes of all links, equivalent to
+// `networkctl status --json=short`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.network1.html
+func (n *Conn) Describe(ctx context.Context) ([]LinkDetails, error) {
+	body, err := n.conn.Call(ctx, destination, managerPath, managerInterface, "Describe", "")
+	if err != nil {
+		return nil, fmt.Errorf("sdnetwork: unable to describe links: %w", convertError(err))
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdnetwork: unable to describe links: unexpected reply of %d values", len(body))
+	}
+	s, ok := body[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("sdnetwork: unable to describe links: unexpected reply of type %T", body[0])
+	}
+	var d description
+	if err := json.Unmarshal([]byte(s), &d); err != nil {
+		return nil, fmt.Errorf("sdnetwork: unable to describe links: %w", err)
+	}
+
+	links := make([]LinkDetails, len(d.Interfaces))
+	for i, iface := range d.Interfaces {
+		l := LinkDetails{
+			Index:     iface.Index,
+			Name:      iface.Name,
+			Type:      iface.Type,
+			Addresses: make([]Address, len(iface.Addresses)),
+			Routes:    make([]Route, len(iface.Routes)),
+		}
+		for j, a := range iface.Addresses {
+			l.Addresses[j] = Address{
+				Prefix: netip.PrefixFrom(netip.Addr(a.Address), a.PrefixLength),
+				Scope:  a.ScopeString,
+				Source: a.ConfigSource,
+				State:  a.ConfigState,
+			}
+		}
+		for j, r := range iface.Routes {
+			l.Routes[j] = Route{
+				Destination:     netip.PrefixFrom(netip.Addr(r.Destination), r.DestinationPrefixLength),
+				Gateway:         netip.Addr(r.Gateway),
+				PreferredSource: netip.Addr(r.PreferredSource),
+				Table:           r.Table,
+				Priority:        r.Priority,
+				Protocol:        r.ProtocolString,
+				Scope:           r.ScopeString,
+				Type:            r.TypeString,
+				Source:          r.ConfigSource,
+			}
+		}
+		links[i] = l
+	}
+	return links, nil
+}
+
+// DescribeLink returns the addresses and routes of a link by the index of its
+// network interface.
+//
+// If the link does not exist or is not known to networkd, an error matching
+// [ErrNotFound] is returned.
+func (n *Conn) DescribeLink(ctx context.Context, ifindex int) (*LinkDetails, error) {
+	links, err := n.Describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range links {
+		if links[i].Index == ifindex {
+			return &links[i], nil
+		}
+	}
+	return nil, fmt.Errorf("sdnetwork: unable to describe link %d: %w", ifindex, ErrNotFound)
+}
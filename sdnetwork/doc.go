@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdnetwork provides access to the state of network links managed by
+// systemd-networkd, similar to the [sd-network(3)] APIs of libsystemd, along
+// with [WaitOnline] to wait for links to be online without depending on
+// `systemd-networkd-wait-online`.
+//
+// Like libsystemd, the state of links is read from the state files networkd
+// maintains under `/run/systemd/netif`, no connection to networkd is required.
+// The addresses and routes of links are read from networkd on the system bus
+// using a [Conn].
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// [sd-network(3)]: https://github.com/systemd/systemd/blob/main/src/systemd/sd-network.h
+package sdnetwork
@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdnetwork
+
+import (
+	"errors"
+	"net/netip"
+	"slices"
+)
+
+// ErrNotFound is returned when a link does not exist or is not known to
+// networkd, or when networkd is not running.
+var ErrNotFound = errors.New("sdnetwork: not found")
+
+// Error is an error returned by networkd.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.DBus.Error.ServiceUnknown`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdnetwork: " + e.Name
+	}
+	return "sdnetwork: " + e.Message
+}
+
+// Is reports whether the error matches target, [ErrNotFound] matches errors
+// about networkd not running.
+func (e *Error) Is(target error) bool {
+	if target != ErrNotFound {
+		return false
+	}
+	switch e.Name {
+	case "org.freedesktop.DBus.Error.ServiceUnknown", "org.freedesktop.DBus.Error.NameHasNoOwner":
+		return true
+	default:
+		return false
+	}
+}
+
+// AdminState is the state of the configuration of a link by networkd.
+type AdminState string
+
+const (
+	AdminPending     AdminState = "pending"
+	AdminInitialized AdminState = "initialized"
+	AdminConfiguring AdminState = "configuring"
+	AdminConfigured  AdminState = "configured"
+	AdminUnmanaged   AdminState = "unmanaged"
+	AdminFailed      AdminState = "failed"
+	AdminLinger      AdminState = "linger"
+)
+
+// OperState is the operational state of a link, or of the system as a whole.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/networkctl.html#Description
+type OperState string
+
+const (
+	OperMissing         OperState = "missing"
+	OperOff             OperState = "off"
+	OperNoCarrier       OperState = "no-carrier"
+	OperDormant         OperState = "dormant"
+	OperDegradedCarrier OperState = "degraded-carrier"
+	OperCarrier         OperState = "carrier"
+	OperDegraded        OperState = "degraded"
+	OperEnslaved        OperState = "enslaved"
+	OperRoutable        OperState = "routable"
+)
+
+// operStates are the operational states, ordered from least to most
+// connected.
+var operStates = []OperState{
+	OperMissing,
+	OperOff,
+	OperNoCarrier,
+	OperDormant,
+	OperDegradedCarrier,
+	OperCarrier,
+	OperDegraded,
+	OperEnslaved,
+	OperRoutable,
+}
+
+// AtLeast reports whether s is at least as connected as min, for example
+// [OperRoutable] is at least [OperDegraded]. Unknown states are not at least
+// any state.
+func (s OperState) AtLeast(min OperState) bool {
+	i, j := slices.Index(operStates, s), slices.Index(operStates, min)
+	return i >= 0 && j >= 0 && i >= j
+}
+
+// AddressState is the state of the addresses of a link, or of the system as a
+// whole.
+type AddressState string
+
+const (
+	AddressOff      AddressState = "off"
+	AddressDegraded AddressState = "degraded"
+	AddressRoutable AddressState = "routable"
+)
+
+// AtLeast reports whether s is at least min, for example [AddressRoutable]
+// is at least [AddressDegraded].
+func (s AddressState) AtLeast(min AddressState) bool {
+	states := []AddressState{AddressOff, AddressDegraded, AddressRoutable}
+	i, j := slices.Index(states, s), slices.Index(states, min)
+	return i >= 0 && j >= 0 && i >= j
+}
+
+// OnlineState is whether the links required for being online are online.
+type OnlineState string
+
+const (
+	OnlineOffline OnlineState = "offline"
+	OnlinePartial OnlineState = "partial"
+	OnlineOnline  OnlineState = "online"
+)
+
+// Family is the address family required for a link to be online.
+type Family string
+
+const (
+	FamilyAny  Family = "any"
+	FamilyIPv4 Family = "ipv4"
+	FamilyIPv6 Family = "ipv6"
+	FamilyBoth Family = "both"
+)
+
+// State is the state of the network of the system as a whole.
+type State struct {
+	OperState        OperState
+	CarrierState     OperState
+	AddressState     AddressState
+	IPv4AddressState AddressState
+	IPv6AddressState AddressState
+	OnlineState      OnlineState
+	// DNS, NTP, and Domains are the DNS servers, NTP servers, and search
+	// domains of all links.
+	DNS     []string
+	NTP     []string
+	Domains []string
+}
+
+// Link is the state of a network link.
+type Link struct {
+	// Index is the index of the network interface.
+	Index int
+	// Name is the name of the network interface.
+	Name string
+
+	AdminState       AdminState
+	OperState        OperState
+	CarrierState     OperState
+	AddressState     AddressState
+	IPv4AddressState AddressState
+	IPv6AddressState AddressState
+	OnlineState      OnlineState
+
+	// RequiredForOnline is true if the link must be online for the system to
+	// be online, `RequiredForOnline=`.
+	RequiredForOnline bool
+	// RequiredOperState is the minimum operational state for the link to be
+	// online.
+	RequiredOperState OperState
+	// RequiredFamily is the address family required for the link to be
+	// online.
+	RequiredFamily Family
+
+	// NetworkFile is the path of the `.network` file the link is configured
+	// by.
+	NetworkFile string
+	// DNS, NTP, and Domains are the DNS servers, NTP servers, and search
+	// domains of the link.
+	DNS     []string
+	NTP     []string
+	Domains []string
+}
+
+// Address is an address configured on a link.
+type Address struct {
+	// Prefix is the address along with the length of its subnet.
+	Prefix netip.Prefix
+	// Scope is the scope of the address, such as `global` or `link`.
+	Scope string
+	// Source is what configured the address, such as `static`, `DHCPv4`,
+	// `NDisc`, or `foreign` for addresses not configured by networkd.
+	Source string
+	// State is the state of the configuration of the address.
+	State string
+}
+
+// Route is a route configured on a link.
+type Route struct {
+	// Destination is the destination of the route, such as `0.0.0.0/0` for a
+	// default route.
+	Destination netip.Prefix
+	// Gateway is the gateway of the route, if any.
+	Gateway netip.Addr
+	// PreferredSource is the source address preferred for the route, if any.
+	PreferredSource netip.Addr
+	// Table is the routing table of the route.
+	Table uint32
+	// Priority is the metric of the route.
+	Priority uint32
+	// Protocol is the protocol of the route, such as `static` or `dhcp`.
+	Protocol string
+	// Scope is the scope of the route, such as `global` or `link`.
+	Scope string
+	// Type is the type of the route, such as `unicast`.
+	Type string
+	// Source is what configured the route, such as `static`, `DHCPv4`, or
+	// `foreign` for routes not configured by networkd.
+	Source string
+}
+
+// LinkDetails are the addresses and routes of a link, as returned by
+// [Conn.DescribeLink].
+type LinkDetails struct {
+	// Index is the index of the network interface.
+	Index int
+	// Name is the name of the network interface.
+	Name string
+	// Type is the type of the link, such as `ether` or `wlan`.
+	Type string
+	// Addresses are the addresses configured on the link.
+	Addresses []Address
+	// Routes are the routes configured on the link.
+	Routes []Route
+}
+
+// Matcher selects links for [WaitOnline].
+type Matcher struct {
+	// Name is the name of the network interface, which may contain shell
+	// globs such as `eth*`.
+	Name string
+	// MinOperState is the minimum operational state for the link to be
+	// online, [OperDegraded] if empty.
+	MinOperState OperState
+	// Family is the address family required for the link to be online,
+	// [FamilyAny] if empty.
+	Family Family
+}
@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdnetwork
+
+import (
+	"context"
+	"errors"
+)
+
+func GetState() (*State, error) { return nil, errors.ErrUnsupported }
+
+func GetLink(int) (*Link, error) { return nil, errors.ErrUnsupported }
+
+func ListLinks() ([]Link, error) { return nil, errors.ErrUnsupported }
+
+func WaitOnline(context.Context, ...Matcher) error { return errors.ErrUnsupported }
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) Describe(context.Context) ([]LinkDetails, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) DescribeLink(context.Context, int) (*LinkDetails, error) {
+	return nil, errors.ErrUnsupported
+}
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdnetwork
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+)
+
+// writeFiles writes files relative to dir, creating any parent directories.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// useRoot makes the package read files from a temporary directory containing
+// files for the duration of the test.
+func useRoot(t *testing.T, files map[string]string) {
+	t.Helper()
+	prev := rootDir
+	t.Cleanup(func() { rootDir = prev })
+	rootDir = t.TempDir()
+	writeFiles(t, rootDir, files)
+}
+
+const (
+	loState = `# This is private data. Do not parse.
+ADMIN_STATE=unmanaged
+OPER_STATE=carrier
+CARRIER_STATE=carrier
+ADDRESS_STATE=off
+IPV4_ADDRESS_STATE=off
+IPV6_ADDRESS_STATE=off
+ONLINE_STATE=
+ACTIVATION_POLICY=up
+REQUIRED_FOR_ONLINE=yes
+REQUIRED_OPER_STATE_FOR_ONLINE=degraded
+REQUIRED_FAMILY_FOR_ONLINE=any
+`
+	eth0State = `# This is private data. Do not parse.
+ADMIN_STATE=configured
+OPER_STATE=routable
+CARRIER_STATE=carrier
+ADDRESS_STATE=routable
+IPV4_ADDRESS_STATE=routable
+IPV6_ADDRESS_STATE=degraded
+ONLINE_STATE=online
+ACTIVATION_POLICY=up
+REQUIRED_FOR_ONLINE=yes
+REQUIRED_OPER_STATE_FOR_ONLINE=routable:routable
+REQUIRED_FAMILY_FOR_ONLINE=ipv4
+NETWORK_FILE=/etc/systemd/network/20-wired.network
+DNS=192.0.2.1 2001:db8::1
+NTP=
+DOMAINS=example.com
+`
+	eth1State = `# This is private data. Do not parse.
+ADMIN_STATE=configuring
+OPER_STATE=no-carrier
+CARRIER_STATE=no-carrier
+ADDRESS_STATE=off
+IPV4_ADDRESS_STATE=off
+IPV6_ADDRESS_STATE=off
+ONLINE_STATE=offline
+REQUIRED_FOR_ONLINE=no
+REQUIRED_OPER_STATE_FOR_ONLINE=degraded
+REQUIRED_FAMILY_FOR_ONLINE=any
+`
+)
+
+func useLinks(t *testing.T) {
+	t.Helper()
+	useRoot(t, map[string]string{
+		"sys/class/net/lo/ifindex":          "1\n",
+		"sys/class/net/eth0/ifindex":        "2\n",
+		"sys/class/net/eth1/ifindex":        "3\n",
+		"run/systemd/netif/links/1":         loState,
+		"run/systemd/netif/links/2":         eth0State,
+		"run/systemd/netif/links/3":         eth1State,
+		"run/systemd/netif/links/.#2abcdef": "",
+		"run/systemd/netif/state": `OPER_STATE=routable
+CARRIER_STATE=carrier
+ADDRESS_STATE=routable
+IPV4_ADDRESS_STATE=routable
+IPV6_ADDRESS_STATE=degraded
+ONLINE_STATE=partial
+DNS=192.0.2.1 2001:db8::1
+NTP=
+DOMAINS=example.com
+`,
+	})
+}
+
+func TestGetLink(t *testing.T) {
+	useLinks(t)
+
+	l, err := GetLink(2)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := &Link{
+		Index:             2,
+		Name:              "eth0",
+		AdminState:        AdminConfigured,
+		OperState:         OperRoutable,
+		CarrierState:      OperCarrier,
+		AddressState:      AddressRoutable,
+		IPv4AddressState:  AddressRoutable,
+		IPv6AddressState:  AddressDegraded,
+		OnlineState:       OnlineOnline,
+		RequiredForOnline: true,
+		RequiredOperState: OperRoutable,
+		RequiredFamily:    FamilyIPv4,
+		NetworkFile:       "/etc/systemd/network/20-wired.network",
+		DNS:               []string{"192.0.2.1", "2001:db8::1"},
+		NTP:               []string{},
+		Domains:           []string{"example.com"},
+	}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, l)
+	}
+
+	if _, err := GetLink(4); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNotFound, err)
+	}
+
+	links, err := ListLinks()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var names []string
+	for _, l := range links {
+		names = append(names, l.Name)
+	}
+	if v := strings.Join(names, ","); v != "lo,eth0,eth1" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "lo,eth0,eth1", v)
+	}
+
+	s, err := GetState()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if s.OperState != OperRoutable || s.OnlineState != OnlinePartial || len(s.DNS) != 2 {
+		t.Errorf("unexpected state %+v", s)
+	}
+}
+
+func TestOperState(t *testing.T) {
+	for _, tc := range []struct {
+		s, min OperState
+		ok     bool
+	}{
+		{OperRoutable, OperDegraded, true},
+		{OperDegraded, OperDegraded, true},
+		{OperEnslaved, OperDegraded, true},
+		{OperCarrier, OperDegraded, false},
+		{OperNoCarrier, OperMissing, true},
+		{"", OperMissing, false},
+	} {
+		if v := tc.s.AtLeast(tc.min); v != tc.ok {
+			t.Errorf("expected %s.AtLeast(%s) to be %t", tc.s, tc.min, tc.ok)
+		}
+	}
+}
+
+func TestWaitOnline(t *testing.T) {
+	useLinks(t)
+	prev := waitInterval
+	t.Cleanup(func() { waitInterval = prev })
+	waitInterval = 10 * time.Millisecond
+
+	ctx := context.Background()
+	if err := WaitOnline(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := WaitOnline(ctx, Matcher{Name: "eth*", MinOperState: OperNoCarrier}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := WaitOnline(ctx, Matcher{Name: "eth0", MinOperState: OperRoutable, Family: FamilyIPv4}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	err := WaitOnline(timeout, Matcher{Name: "eth0", MinOperState: OperRoutable, Family: FamilyBoth}, Matcher{Name: "wlan0"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected \"%v\", but got \"%v\"", context.DeadlineExceeded, err)
+	} else if !strings.Contains(err.Error(), "eth0, wlan0") {
+		t.Errorf("expected error to list eth0 and wlan0, but got \"%v\"", err)
+	}
+
+	// The link becoming online while waiting must end the wait.
+	done := make(chan error, 1)
+	go func() { done <- WaitOnline(ctx, Matcher{Name: "eth1"}) }()
+	time.Sleep(30 * time.Millisecond)
+	writeFiles(t, rootDir, map[string]string{
+		"run/systemd/netif/links/.#3tmp": strings.NewReplacer(
+			"OPER_STATE=no-carrier", "OPER_STATE=degraded",
+			"ADDRESS_STATE=off", "ADDRESS_STATE=degraded",
+		).Replace(eth1State),
+	})
+	if err := os.Rename(filepath.Join(rootDir, "run/systemd/netif/links/.#3tmp"), filepath.Join(rootDir, "run/systemd/netif/links/3")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for link to be online")
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	bus, c := dbustest.New(t)
+	n := newConn(c)
+	bus.Handle(managerInterface, "Describe", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "s", []any{`{"Interfaces":[{"Index":1,"Name":"lo","Type":"loopback"},{"Index":2,"Name":"eth0","Type":"ether",` +
+			`"Addresses":[{"Family":2,"Address":[192,0,2,10],"PrefixLength":24,"Scope":0,"ScopeString":"global","ConfigSource":"DHCPv4","ConfigState":"configured"},` +
+			`{"Family":10,"Address":[254,128,0,0,0,0,0,0,0,0,0,0,0,0,0,1],"PrefixLength":64,"ScopeString":"link","ConfigSource":"foreign","ConfigState":"configured"}],` +
+			`"Routes":[{"Family":2,"Destination":[0,0,0,0],"DestinationPrefixLength":0,"Gateway":[192,0,2,1],"PreferredSource":[192,0,2,10],` +
+			`"Table":254,"Priority":1024,"ProtocolString":"dhcp","ScopeString":"global","TypeString":"unicast","ConfigSource":"DHCPv4"}]}]}`}, nil
+	})
+
+	l, err := n.DescribeLink(context.Background(), 2)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := &LinkDetails{
+		Index: 2,
+		Name:  "eth0",
+		Type:  "ether",
+		Addresses: []Address{
+			{Prefix: netip.MustParsePrefix("192.0.2.10/24"), Scope: "global", Source: "DHCPv4", State: "configured"},
+			{Prefix: netip.MustParsePrefix("fe80::1/64"), Scope: "link", Source: "foreign", State: "configured"},
+		},
+		Routes: []Route{{
+			Destination:     netip.MustParsePrefix("0.0.0.0/0"),
+			Gateway:         netip.MustParseAddr("192.0.2.1"),
+			PreferredSource: netip.MustParseAddr("192.0.2.10"),
+			Table:           254,
+			Priority:        1024,
+			Protocol:        "dhcp",
+			Scope:           "global",
+			Type:            "unicast",
+			Source:          "DHCPv4",
+		}},
+	}
+	if !reflect.DeepEqual(l, expected) {
+		t.Errorf("expected %+v, but got %+v", expected, l)
+	}
+
+	if _, err := n.DescribeLink(context.Background(), 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNotFound, err)
+	}
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdnetwork
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// rootDir is the directory used to resolve all the paths read by this package,
+// it is only changed by tests.
+var rootDir = "/"
+
+// readFile reads a file relative to [rootDir].
+func readFile(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(rootDir, name))
+}
+
+// readEnvFile parses a state file written by networkd, which contains one
+// `KEY=value` assignment per line.
+func readEnvFile(name string) (map[string]string, error) {
+	b, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		k, v, ok := bytes.Cut(line, []byte{'='})
+		if !ok || len(k) == 0 || k[0] == '#' {
+			continue
+		}
+		env[string(k)] = string(v)
+	}
+	return env, nil
+}
+
+// GetState returns the state of the network of the system as a whole,
+// equivalent to `networkctl status` without any links.
+//
+// If networkd is not running, an error matching [ErrNotFound] is returned.
+func GetState() (*State, error) {
+	env, err := readEnvFile("run/systemd/netif/state")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdnetwork: unable to read state: %w", ErrNotFound)
+		}
+		return nil, fmt.Errorf("sdnetwork: unable to read state: %w", err)
+	}
+	return &State{
+		OperState:        OperState(env["OPER_STATE"]),
+		CarrierState:     OperState(env["CARRIER_STATE"]),
+		AddressState:     AddressState(env["ADDRESS_STATE"]),
+		IPv4AddressState: AddressState(env["IPV4_ADDRESS_STATE"]),
+		IPv6AddressState: AddressState(env["IPV6_ADDRESS_STATE"]),
+		OnlineState:      OnlineState(env["ONLINE_STATE"]),
+		DNS:              strings.Fields(env["DNS"]),
+		NTP:              strings.Fields(env["NTP"]),
+		Domains:          strings.Fields(env["DOMAINS"]),
+	}, nil
+}
+
+// GetLink returns the state of a link by the index of its network interface.
+//
+// If the link does not exist or is not known to networkd, an error matching
+// [ErrNotFound] is returned.
+func GetLink(ifindex int) (*Link, error) {
+	names, err := interfaceNames()
+	if err != nil {
+		return nil, err
+	}
+	return getLink(ifindex, names[ifindex])
+}
+
+// getLink reads the state file of a link.
+func getLink(ifindex int, name string) (*Link, error) {
+	env, err := readEnvFile(filepath.Join("run/systemd/netif/links", strconv.Itoa(ifindex)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdnetwork: unable to read link %d: %w", ifindex, ErrNotFound)
+		}
+		return nil, fmt.Errorf("sdnetwork: unable to read link %d: %w", ifindex, err)
+	}
+	l := &Link{
+		Index:             ifindex,
+		Name:              name,
+		AdminState:        AdminState(env["ADMIN_STATE"]),
+		OperState:         OperState(env["OPER_STATE"]),
+		CarrierState:      OperState(env["CARRIER_STATE"]),
+		AddressState:      AddressState(env["ADDRESS_STATE"]),
+		IPv4AddressState:  AddressState(env["IPV4_ADDRESS_STATE"]),
+		IPv6AddressState:  AddressState(env["IPV6_ADDRESS_STATE"]),
+		OnlineState:       OnlineState(env["ONLINE_STATE"]),
+		RequiredForOnline: env["REQUIRED_FOR_ONLINE"] == "yes",
+		RequiredFamily:    Family(env["REQUIRED_FAMILY_FOR_ONLINE"]),
+		NetworkFile:       env["NETWORK_FILE"],
+		DNS:               strings.Fields(env["DNS"]),
+		NTP:               strings.Fields(env["NTP"]),
+		Domains:           strings.Fields(env["DOMAINS"]),
+	}
+	// The required operational state may be a range, such as
+	// `degraded:routable`, only the minimum is relevant.
+	min, _, _ := strings.Cut(env["REQUIRED_OPER_STATE_FOR_ONLINE"], ":")
+	l.RequiredOperState = OperState(min)
+	return l, nil
+}
+
+// ListLinks returns the links known to networkd, sorted by index, equivalent
+// to `networkctl list`.
+func ListLinks() ([]Link, error) {
+	entries, err := os.ReadDir(filepath.Join(rootDir, "run/systemd/netif/links"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("sdnetwork: unable to read /run/systemd/netif/links: %w", err)
+	}
+	names, err := interfaceNames()
+	if err != nil {
+		return nil, err
+	}
+	links := make([]Link, 0, len(entries))
+	for _, e := range entries {
+		// networkd writes files atomically by renaming temporary files, which
+		// must be skipped.
+		ifindex, err := strconv.Atoi(e.Name())
+		if err != nil || !e.Type().IsRegular() {
+			continue
+		}
+		l, err := getLink(ifindex, names[ifindex])
+		if err != nil {
+			// The link may have been removed since listing them.
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	slices.SortFunc(links, func(a, b Link) int { return cmp.Compare(a.Index, b.Index) })
+	return links, nil
+}
+
+// interfaceNames returns the names of the network interfaces by their index,
+// which networkd does not record in its state files.
+func interfaceNames() (map[int]string, error) {
+	entries, err := os.ReadDir(filepath.Join(rootDir, "sys/class/net"))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("sdnetwork: unable to read /sys/class/net: %w", err)
+	}
+	names := make(map[int]string, len(entries))
+	for _, e := range entries {
+		b, err := readFile(filepath.Join("sys/class/net", e.Name(), "ifindex"))
+		if err != nil {
+			// The interface may have been removed since listing them.
+			continue
+		}
+		if ifindex, err := strconv.Atoi(string(bytes.TrimSpace(b))); err == nil {
+			names[ifindex] = e.Name()
+		}
+	}
+	return names, nil
+}
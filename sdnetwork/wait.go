@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdnetwork
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// waitInterval is how often the state of links is checked by [WaitOnline].
+var waitInterval = 250 * time.Millisecond
+
+// WaitOnline waits until the links selected by matchers are online, similar
+// to `systemd-networkd-wait-online`.
+//
+// If no matchers are given, WaitOnline waits until at least one link is
+// managed by networkd and all links configured with `RequiredForOnline=` are
+// online, as required by their `.network` files. Otherwise, each matcher must
+// select at least one link, and all the links it selects must be online.
+//
+// If ctx is done before the links are online, an error matching the error of
+// ctx is returned, listing the links that are not online.
+func WaitOnline(ctx context.Context, matchers ...Matcher) error {
+	t := time.NewTicker(waitInterval)
+	defer t.Stop()
+	for {
+		pending, err := pendingLinks(matchers)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("sdnetwork: timed out waiting for %s to be online: %w", strings.Join(pending, ", "), ctx.Err())
+		case <-t.C:
+		}
+	}
+}
+
+// pendingLinks returns the descriptions of the links or matchers that are not
+// online yet.
+func pendingLinks(matchers []Matcher) ([]string, error) {
+	links, err := ListLinks()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	if len(matchers) == 0 {
+		var managed bool
+		for _, l := range links {
+			if l.AdminState == AdminUnmanaged || !l.RequiredForOnline {
+				continue
+			}
+			managed = true
+			if l.AdminState != AdminConfigured || !online(l, l.RequiredOperState, l.RequiredFamily) {
+				pending = append(pending, linkName(l))
+			}
+		}
+		if !managed {
+			pending = append(pending, "any link")
+		}
+		return pending, nil
+	}
+
+	for _, m := range matchers {
+		var matched bool
+		for _, l := range links {
+			if ok, _ := path.Match(m.Name, l.Name); !ok {
+				continue
+			}
+			matched = true
+			if !online(l, m.MinOperState, m.Family) {
+				pending = append(pending, linkName(l))
+			}
+		}
+		if !matched {
+			pending = append(pending, m.Name)
+		}
+	}
+	return pending, nil
+}
+
+// online reports whether a link is at least in the given operational state,
+// with the addresses of the given family that state requires.
+func online(l Link, min OperState, family Family) bool {
+	if min == "" {
+		min = OperDegraded
+	}
+	if !l.OperState.AtLeast(min) {
+		return false
+	}
+	// Links enslaved to a bond or bridge have no addresses of their own.
+	if l.OperState == OperEnslaved {
+		return true
+	}
+
+	var addr AddressState
+	switch {
+	case min.AtLeast(OperRoutable):
+		addr = AddressRoutable
+	case min.AtLeast(OperDegraded):
+		addr = AddressDegraded
+	default:
+		return true
+	}
+	switch family {
+	case FamilyIPv4:
+		return l.IPv4AddressState.AtLeast(addr)
+	case FamilyIPv6:
+		return l.IPv6AddressState.AtLeast(addr)
+	case FamilyBoth:
+		return l.IPv4AddressState.AtLeast(addr) && l.IPv6AddressState.AtLeast(addr)
+	default:
+		return l.AddressState.AtLeast(addr)
+	}
+}
+
+// linkName returns the name of a link for errors, falling back to its index.
+func linkName(l Link) string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return fmt.Sprintf("link %d", l.Index)
+}
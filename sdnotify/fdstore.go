@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdnotify
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"syscall"
+
+	"github.com/matthewpi/sd/internal/metrics"
+)
+
+const (
+	// fdStoreMessage stores the file descriptors sent along with the message
+	// in the file descriptor store of the service.
+	//
+	// ref; https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html#FDSTORE=1
+	fdStoreMessage = "FDSTORE=1"
+
+	// fdStoreRemoveMessage removes file descriptors from the file descriptor
+	// store of the service.
+	//
+	// ref; https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html#FDSTOREREMOVE=1
+	fdStoreRemoveMessage = "FDSTOREREMOVE=1"
+
+	// fdNamePrefix is the prefix for the name of the file descriptors being
+	// stored or removed.
+	//
+	// ref; https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html#FDNAME=%E2%80%A6
+	fdNamePrefix = "FDNAME="
+
+	// mainPIDPrefix is the prefix for changing the main process of the
+	// service.
+	//
+	// ref; https://www.freedesktop.org/software/systemd/man/latest/sd_notify.html#MAINPID=%E2%80%A6
+	mainPIDPrefix = "MAINPID="
+)
+
+// isValidFDName reports whether name may be used as the name of a file
+// descriptor, at most 255 printable ASCII characters without `:`.
+func isValidFDName(name string) bool {
+	if name == "" || len(name) > 255 {
+		return false
+	}
+	for i := range len(name) {
+		if c := name[i]; c < ' ' || c > '~' || c == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// FDStore stores files in the file descriptor store of the service under the
+// given name, so systemd keeps them open while the application restarts and
+// passes them back with [sdlisten.Files], named after name.
+//
+// The service must set [FileDescriptorStoreMax=] for files to be stored.
+//
+// [sdlisten.Files]: https://pkg.go.dev/github.com/matthewpi/sd/sdlisten#Files
+// [FileDescriptorStoreMax=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#FileDescriptorStoreMax=
+func FDStore(name string, files ...*os.File) error {
+	if !isValidFDName(name) {
+		return fmt.Errorf("sdnotify: invalid file descriptor name (%s)", name)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	fds := make([]int, len(files))
+	for i, f := range files {
+		fds[i] = int(f.Fd())
+	}
+
+	c, err := openSocket()
+	if c == nil || err != nil {
+		if err != nil {
+			metrics.NotifyFailed.Add(1)
+		}
+		return err
+	}
+	defer c.Close()
+	payload := fdStoreMessage + "\n" + fdNamePrefix + name
+	if _, _, err := c.WriteMsgUnix([]byte(payload), syscall.UnixRights(fds...), nil); err != nil {
+		metrics.NotifyFailed.Add(1)
+		return fmt.Errorf("sdnotify: failed to send message: %w", err)
+	}
+	metrics.NotifySent.Add(1)
+	return nil
+}
+
+// FDStoreRemove removes the files stored under the given name from the file
+// descriptor store of the service.
+func FDStoreRemove(name string) error {
+	if !isValidFDName(name) {
+		return fmt.Errorf("sdnotify: invalid file descriptor name (%s)", name)
+	}
+	return sdnotify([]byte(fdStoreRemoveMessage + "\n" + fdNamePrefix + name))
+}
+
+// MainPID notifies systemd that the main process of the service is now pid,
+// such as when handing over to a new process of the application.
+//
+// Unless the service sets [NotifyAccess=all], only the current main process
+// may change it.
+//
+// [NotifyAccess=all]: https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#NotifyAccess=
+func MainPID(pid int) error {
+	if pid < 1 {
+		return fmt.Errorf("sdnotify: invalid main pid (%d)", pid)
+	}
+	return sdnotify([]byte(mainPIDPrefix + strconv.Itoa(pid)))
+}
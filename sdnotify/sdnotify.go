@@ -13,6 +13,7 @@ import (
 	"path/filepath"
 	"strconv"
 
+	"github.com/matthewpi/sd/internal/metrics"
 	"github.com/matthewpi/sd/internal/monotime"
 )
 
@@ -87,12 +88,17 @@ func openSocket() (*net.UnixConn, error) {
 func sdnotify(payload []byte) error {
 	c, err := openSocket()
 	if c == nil || err != nil {
+		if err != nil {
+			metrics.NotifyFailed.Add(1)
+		}
 		return err
 	}
 	defer c.Close()
 	if _, err = c.Write(payload); err != nil {
+		metrics.NotifyFailed.Add(1)
 		return fmt.Errorf("sdnotify: failed to send message: %w", err)
 	}
+	metrics.NotifySent.Add(1)
 	return nil
 }
 
@@ -5,12 +5,17 @@
 
 package sdnotify
 
-func Notify([]byte) error            { return nil }
-func Ready() error                   { return nil }
-func Reloading() error               { return nil }
-func Stopping() error                { return nil }
-func Status(string) error            { return nil }
-func StatusBytes([]byte) error       { return nil }
-func Error(error, int) error         { return nil }
-func ErrorMessage(string, int) error { return nil }
-func ErrorBytes([]byte, int) error   { return nil }
+import "os"
+
+func Notify([]byte) error               { return nil }
+func Ready() error                      { return nil }
+func Reloading() error                  { return nil }
+func Stopping() error                   { return nil }
+func Status(string) error               { return nil }
+func StatusBytes([]byte) error          { return nil }
+func Error(error, int) error            { return nil }
+func ErrorMessage(string, int) error    { return nil }
+func ErrorBytes([]byte, int) error      { return nil }
+func FDStore(string, ...*os.File) error { return nil }
+func FDStoreRemove(string) error        { return nil }
+func MainPID(int) error                 { return nil }
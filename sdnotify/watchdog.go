@@ -11,6 +11,8 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/matthewpi/sd/internal/metrics"
 )
 
 const (
@@ -43,7 +45,12 @@ const (
 // [systemd.service(5)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html
 // [WatchdogSec=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#WatchdogSec=
 func Watchdog() error {
-	return sdnotify([]byte(watchdogMessage))
+	if err := sdnotify([]byte(watchdogMessage)); err != nil {
+		metrics.WatchdogMisses.Add(1)
+		return err
+	}
+	metrics.WatchdogPings.Add(1)
+	return nil
 }
 
 // WatchdogTrigger informs systemd that an internal error occurred.
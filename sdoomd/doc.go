@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdoomd provides integration with systemd-oomd, the userspace OOM
+// killer of systemd, which kills the processes of control groups under
+// sustained memory pressure or when swap is exhausted, as configured by
+// `ManagedOOMMemoryPressure=` and `ManagedOOMSwap=`.
+//
+// Services may detect when oomd killed their processes, change how likely
+// they are to be chosen by oomd with `ManagedOOMPreference=`, and release
+// memory before the memory pressure reaches the limit of oomd.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd-oomd.service.html
+package sdoomd
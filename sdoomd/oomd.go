@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdoomd
+
+import "time"
+
+// Preference is how oomd treats a unit when choosing which control group to
+// kill, `ManagedOOMPreference=`.
+type Preference string
+
+const (
+	// PreferenceNone treats the unit like any other.
+	PreferenceNone Preference = "none"
+	// PreferenceAvoid only chooses the unit if there are no other candidates.
+	PreferenceAvoid Preference = "avoid"
+	// PreferenceOmit never chooses the unit.
+	PreferenceOmit Preference = "omit"
+)
+
+// KillEvent is an event of oomd killing the processes of a control group,
+// delivered by [WatchKills].
+type KillEvent struct {
+	// Time is when the kill was detected.
+	Time time.Time
+	// Cgroup is the path of the control group killed by oomd, which is either
+	// the control group of the process or one of its children.
+	Cgroup string
+	// Kills is the number of times oomd killed the control group.
+	Kills uint64
+}
+
+// DefaultPressureLimit is the memory pressure limit of oomd if
+// `ManagedOOMMemoryPressureLimit=` and `DefaultMemoryPressureLimit=` are not
+// set.
+const DefaultPressureLimit = 0.6
+
+// PressureOptions are the options of [OnPressure].
+type PressureOptions struct {
+	// Limit is the memory pressure limit of oomd for the unit, as a fraction
+	// such as 0.6 for `ManagedOOMMemoryPressureLimit=60%`,
+	// [DefaultPressureLimit] if zero.
+	Limit float64
+	// Margin is the fraction of Limit at which the callback is called, 0.8 if
+	// zero, so the callback is called at 48% memory pressure for the default
+	// limit.
+	Margin float64
+	// Interval is how often the memory pressure is checked, one second if
+	// zero.
+	Interval time.Duration
+}
@@ -0,0 +1,249 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdoomd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/sdcgroup"
+	"github.com/matthewpi/sd/sdmanager"
+)
+
+// rootDir is the directory used to resolve the paths of control groups, it is
+// only changed by tests.
+var rootDir = "/"
+
+// ownDir returns the path of the control group of the current process, along
+// with its directory.
+func ownDir() (string, string, error) {
+	cgroup, err := sdcgroup.Own()
+	if err != nil {
+		return "", "", err
+	}
+	return cgroup, filepath.Join(rootDir, "sys/fs/cgroup", cgroup), nil
+}
+
+// SetPreference changes `ManagedOOMPreference=` of the unit of the current
+// process until the next reboot, such as to avoid being killed by oomd while
+// performing work that cannot be interrupted.
+//
+// The preference is only honored for units owned by root, see
+// `systemd.resource-control(5)`.
+func SetPreference(ctx context.Context, m *sdmanager.Conn, preference Preference) error {
+	unit, err := m.OwnUnit(ctx)
+	if err != nil {
+		return fmt.Errorf("sdoomd: unable to set preference: %w", err)
+	}
+	if err := m.SetUnitProperties(ctx, unit, true, sdmanager.PropManagedOOMPreference(string(preference))); err != nil {
+		return fmt.Errorf("sdoomd: unable to set preference: %w", err)
+	}
+	return nil
+}
+
+// oomdKillsXattr is the extended attribute oomd increments on a control group
+// each time it kills it.
+const oomdKillsXattr = "user.oomd_ooms"
+
+// WatchKills watches the control group of the current process and its
+// children for being killed by oomd, checking every interval. oomd records
+// kills in extended attributes of the control groups it kills, which are not
+// reported by inotify. Events are delivered on the returned channel until ctx
+// is done, after which it is closed.
+//
+// The channel must be read from, events are not dropped.
+func WatchKills(ctx context.Context, interval time.Duration) (<-chan KillEvent, error) {
+	if interval <= 0 {
+		return nil, errors.New("sdoomd: unable to watch kills: interval must be positive")
+	}
+	cgroup, dir, err := ownDir()
+	if err != nil {
+		return nil, err
+	}
+	kills, err := scanKills(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	ch := make(chan KillEvent)
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := scanKills(dir)
+			if err != nil {
+				// The control group has been removed.
+				return
+			}
+			var events []KillEvent
+			for rel, n := range next {
+				if n > kills[rel] {
+					events = append(events, KillEvent{Time: time.Now(), Cgroup: path.Join(cgroup, rel), Kills: n - kills[rel]})
+				}
+			}
+			kills = next
+			slices.SortFunc(events, func(a, b KillEvent) int { return strings.Compare(a.Cgroup, b.Cgroup) })
+			for _, ev := range events {
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// scanKills returns the number of times oomd killed a control group and each
+// of its children, keyed by their path relative to dir.
+func scanKills(dir string) (map[string]uint64, error) {
+	kills := make(map[string]uint64)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Children may be removed while walking.
+			if p != dir && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		n, err := readKills(p)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			kills[filepath.ToSlash(rel)] = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sdoomd: unable to scan %s: %w", dir, err)
+	}
+	return kills, nil
+}
+
+// readKills reads the number of times oomd killed a control group, which is
+// zero if it has never been killed.
+func readKills(dir string) (uint64, error) {
+	buf := make([]byte, 32)
+	n, err := syscall.Getxattr(dir, oomdKillsXattr, buf)
+	switch {
+	case errors.Is(err, syscall.ENODATA), errors.Is(err, syscall.ENOTSUP), errors.Is(err, syscall.ENOENT):
+		return 0, nil
+	case err != nil:
+		return 0, err
+	}
+	v, err := strconv.ParseUint(string(bytes.TrimRight(buf[:n], "\x00\n")), 10, 64)
+	if err != nil {
+		return 0, nil
+	}
+	return v, nil
+}
+
+// OnPressure calls fn in the background each time the memory pressure of the
+// control group of the current process rises above a margin below the limit
+// at which oomd kills it, giving the service a chance to release memory such
+// as caches first. fn is called with the current pressure, the share of time
+// in the last 10 seconds all tasks of the control group were stalled on
+// memory, as used by oomd.
+//
+// fn is called again only after the pressure has dropped below the margin.
+// Checking stops once ctx is done.
+func OnPressure(ctx context.Context, opts PressureOptions, fn func(pressure float64)) error {
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPressureLimit
+	}
+	if opts.Margin == 0 {
+		opts.Margin = 0.8
+	}
+	if opts.Interval == 0 {
+		opts.Interval = time.Second
+	}
+	_, dir, err := ownDir()
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(dir, "memory.pressure")
+	if _, err := readPressure(name); err != nil {
+		return err
+	}
+
+	threshold := opts.Limit * opts.Margin
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		var above bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			pressure, err := readPressure(name)
+			if err != nil {
+				// The control group has been removed.
+				return
+			}
+			switch {
+			case pressure >= threshold && !above:
+				above = true
+				fn(pressure)
+			case pressure < threshold:
+				above = false
+			}
+		}
+	}()
+	return nil
+}
+
+// readPressure returns the `full avg10` memory pressure of a `memory.pressure`
+// file as a fraction.
+func readPressure(name string) (float64, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return 0, fmt.Errorf("sdoomd: unable to read memory pressure: %w", err)
+	}
+	for line := range strings.SplitSeq(string(b), "\n") {
+		rest, ok := strings.CutPrefix(line, "full ")
+		if !ok {
+			continue
+		}
+		for _, f := range strings.Fields(rest) {
+			if v, ok := strings.CutPrefix(f, "avg10="); ok {
+				avg, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					return 0, fmt.Errorf("sdoomd: unable to parse memory pressure: %w", err)
+				}
+				return avg / 100, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("sdoomd: unable to parse memory pressure of %s", name)
+}
@@ -0,0 +1,26 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdoomd
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/matthewpi/sd/sdmanager"
+)
+
+func SetPreference(context.Context, *sdmanager.Conn, Preference) error {
+	return errors.ErrUnsupported
+}
+
+func WatchKills(context.Context, time.Duration) (<-chan KillEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func OnPressure(context.Context, PressureOptions, func(float64)) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdoomd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/sdcgroup"
+)
+
+// useCgroup makes the package use a temporary directory as the control group
+// of the current process, returning its path and directory.
+func useCgroup(t *testing.T) (string, string) {
+	t.Helper()
+	cgroup, err := sdcgroup.Own()
+	if err != nil {
+		t.Skipf("unable to determine own cgroup: %v", err)
+	}
+	prev := rootDir
+	t.Cleanup(func() { rootDir = prev })
+	rootDir = t.TempDir()
+	dir := filepath.Join(rootDir, "sys/fs/cgroup", cgroup)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return cgroup, dir
+}
+
+// writeAtomic replaces the contents of a file that may be read concurrently.
+func writeAtomic(t *testing.T, name, data string) {
+	t.Helper()
+	if err := os.WriteFile(name+".tmp", []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(name+".tmp", name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnPressure(t *testing.T) {
+	_, dir := useCgroup(t)
+	name := filepath.Join(dir, "memory.pressure")
+	writeAtomic(t, name, "some avg10=10.00 avg60=5.00 avg300=1.00 total=1000\nfull avg10=5.00 avg60=2.00 avg300=0.50 total=500\n")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	calls := make(chan float64, 8)
+	if err := OnPressure(ctx, PressureOptions{Interval: 5 * time.Millisecond}, func(p float64) { calls <- p }); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	// The default threshold is 80% of the 60% limit of oomd.
+	writeAtomic(t, name, "some avg10=60.00 avg60=5.00 avg300=1.00 total=1000\nfull avg10=50.00 avg60=2.00 avg300=0.50 total=500\n")
+	select {
+	case p := <-calls:
+		if p != 0.5 {
+			t.Errorf("expected %v, but got %v", 0.5, p)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for callback")
+	}
+
+	// The callback is not called again while the pressure stays high.
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case p := <-calls:
+		t.Errorf("unexpected callback with %v", p)
+	default:
+	}
+}
+
+func TestWatchKills(t *testing.T) {
+	cgroup, dir := useCgroup(t)
+	child := filepath.Join(dir, "worker")
+	if err := os.Mkdir(child, 0o755); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := syscall.Setxattr(dir, oomdKillsXattr, []byte("1"), 0); err != nil {
+		t.Skipf("user extended attributes are not supported: %v", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchKills(ctx, 5*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := syscall.Setxattr(child, oomdKillsXattr, []byte("2"), 0); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	select {
+	case ev := <-events:
+		if expected := filepath.Join(cgroup, "worker"); ev.Cgroup != expected || ev.Kills != 2 {
+			t.Errorf("expected 2 kills of %s, but got %+v", expected, ev)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,18 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdpressure implements the memory pressure protocol of systemd, used
+// by services to be notified when the system or their own control group is
+// under memory pressure, so they can release memory such as caches before the
+// kernel has to reclaim it or the service is killed.
+//
+// The service manager configures the protocol with the
+// `$MEMORY_PRESSURE_WATCH` and `$MEMORY_PRESSURE_WRITE` environment variables,
+// controlled by `MemoryPressureWatch=` and `MemoryPressureThresholdSec=`.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://systemd.io/MEMORY_PRESSURE/
+package sdpressure
@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdpressure
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDisabled is returned when memory pressure monitoring has been disabled by
+// the service manager, `MemoryPressureWatch=off`.
+var ErrDisabled = errors.New("sdpressure: memory pressure monitoring disabled")
+
+// DefaultTrigger is the PSI trigger used when the service manager does not
+// provide one, which fires when any task is stalled on memory for more than
+// 200ms within a 2s window, the same default as `MemoryPressureThresholdSec=`.
+const DefaultTrigger = "some 200000 2000000"
+
+// Config is the configuration of the memory pressure protocol.
+type Config struct {
+	// Path is the path of the file to watch for memory pressure, which is
+	// either a PSI file such as `memory.pressure` of a control group, a FIFO,
+	// or a unix socket.
+	Path string
+	// Trigger is written to the file after it is opened, which for a PSI file
+	// is the PSI trigger, such as [DefaultTrigger]. PSI triggers are written
+	// with a trailing NUL, which is added if missing.
+	Trigger []byte
+}
+
+// Event is a memory pressure event.
+type Event struct {
+	// Time is the time the event was received.
+	Time time.Time
+}
@@ -0,0 +1,17 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdpressure
+
+import (
+	"context"
+	"errors"
+)
+
+func ConfigFromEnv() (Config, error) { return Config{}, errors.ErrUnsupported }
+
+func Watch(context.Context) (<-chan Event, error) { return nil, errors.ErrUnsupported }
+
+func WatchConfig(context.Context, Config) (<-chan Event, error) { return nil, errors.ErrUnsupported }
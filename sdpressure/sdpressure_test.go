@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdpressure
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// receive waits for an event on events.
+func receive(t *testing.T, events <-chan Event) {
+	t.Helper()
+	select {
+	case _, ok := <-events:
+		if !ok {
+			t.Fatal("expected an event, but the channel was closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for event")
+	}
+}
+
+// closed waits for events to be closed.
+func closed(t *testing.T, events <-chan Event) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for channel to be closed")
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	prev := rootDir
+	t.Cleanup(func() { rootDir = prev })
+	rootDir = t.TempDir()
+	if err := os.MkdirAll(filepath.Join(rootDir, "proc/self"), 0o755); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(rootDir, "proc/self/cgroup"), []byte("0::/system.slice/app.service\n"), 0o644); err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	t.Setenv("MEMORY_PRESSURE_WATCH", "")
+	t.Setenv("MEMORY_PRESSURE_WRITE", "")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := filepath.Join(rootDir, "sys/fs/cgroup/system.slice/app.service/memory.pressure"); cfg.Path != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, cfg.Path)
+	}
+	if string(cfg.Trigger) != DefaultTrigger {
+		t.Errorf("expected \"%s\", but got \"%s\"", DefaultTrigger, cfg.Trigger)
+	}
+
+	t.Setenv("MEMORY_PRESSURE_WATCH", "/sys/fs/cgroup/system.slice/app.service/memory.pressure")
+	t.Setenv("MEMORY_PRESSURE_WRITE", base64.StdEncoding.EncodeToString([]byte("some 150000 2000000")))
+	cfg, err = ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if string(cfg.Trigger) != "some 150000 2000000" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "some 150000 2000000", cfg.Trigger)
+	}
+
+	t.Setenv("MEMORY_PRESSURE_WRITE", "!")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected an error for an invalid $MEMORY_PRESSURE_WRITE")
+	}
+
+	t.Setenv("MEMORY_PRESSURE_WATCH", os.DevNull)
+	if _, err := Watch(context.Background()); !errors.Is(err, ErrDisabled) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrDisabled, err)
+	}
+}
+
+func TestWatchFIFO(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pressure")
+	if err := syscall.Mkfifo(path, 0o600); err != nil {
+		t.Fatal(err)
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchConfig(ctx, Config{Path: path})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	w, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer w.Close()
+	if _, err := w.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	receive(t, events)
+
+	cancel()
+	closed(t, events)
+}
+
+func TestWatchSocket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pressure.sock")
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchConfig(ctx, Config{Path: path, Trigger: []byte("some 150000 2000000")})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+
+	c, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	defer c.Close()
+	buf := make([]byte, 64)
+	n, err := c.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if v := string(buf[:n]); v != "some 150000 2000000" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "some 150000 2000000", v)
+	}
+	if _, err := c.Write([]byte{1}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	receive(t, events)
+
+	// The service manager closing the socket ends the watch.
+	_ = c.Close()
+	closed(t, events)
+}
+
+func TestWatchPSI(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events, err := WatchConfig(ctx, Config{Path: "/proc/pressure/memory", Trigger: []byte("some 500000 2000000")})
+	if err != nil {
+		// PSI may be unavailable, or require privileges to create a trigger.
+		t.Skipf("unable to watch PSI: %v", err)
+		return
+	}
+	cancel()
+	closed(t, events)
+}
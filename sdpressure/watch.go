@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdpressure
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// rootDir is the directory used to resolve the paths of control groups, it is
+// only changed by tests.
+var rootDir = "/"
+
+// ConfigFromEnv returns the configuration of the memory pressure protocol set
+// by the service manager in `$MEMORY_PRESSURE_WATCH` and
+// `$MEMORY_PRESSURE_WRITE`.
+//
+// If `$MEMORY_PRESSURE_WATCH` is unset, the `memory.pressure` file of the
+// control group of the process is used along with [DefaultTrigger], the same
+// as libsystemd. If it has been set to `/dev/null`, [ErrDisabled] is returned.
+func ConfigFromEnv() (Config, error) {
+	path := os.Getenv("MEMORY_PRESSURE_WATCH")
+	switch path {
+	case "":
+		cgroup, err := ownCgroup()
+		if err != nil {
+			return Config{}, err
+		}
+		return Config{
+			Path:    filepath.Join(rootDir, "sys/fs/cgroup", cgroup, "memory.pressure"),
+			Trigger: []byte(DefaultTrigger),
+		}, nil
+	case os.DevNull:
+		return Config{}, ErrDisabled
+	}
+
+	cfg := Config{Path: path}
+	if v := os.Getenv("MEMORY_PRESSURE_WRITE"); v != "" {
+		trigger, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return Config{}, fmt.Errorf("sdpressure: unable to decode $MEMORY_PRESSURE_WRITE: %w", err)
+		}
+		cfg.Trigger = trigger
+	}
+	return cfg, nil
+}
+
+// ownCgroup returns the control group of the current process on the unified
+// hierarchy.
+func ownCgroup() (string, error) {
+	b, err := os.ReadFile(filepath.Join(rootDir, "proc/self/cgroup"))
+	if err != nil {
+		return "", fmt.Errorf("sdpressure: unable to read /proc/self/cgroup: %w", err)
+	}
+	for line := range bytes.SplitSeq(b, []byte{'\n'}) {
+		if path, ok := bytes.CutPrefix(line, []byte("0::")); ok {
+			return string(path), nil
+		}
+	}
+	return "", errors.New("sdpressure: unable to find cgroup in /proc/self/cgroup, cgroup v2 is required")
+}
+
+// Watch watches for memory pressure using the configuration from
+// [ConfigFromEnv], see [WatchConfig].
+func Watch(ctx context.Context) (<-chan Event, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return WatchConfig(ctx, cfg)
+}
+
+// WatchConfig watches for memory pressure, sending an event on the returned
+// channel each time pressure is signaled. Events are coalesced, if an event
+// has not been received by the time the next one occurs, only one event is
+// delivered.
+//
+// The channel is closed once ctx is done or watching fails.
+func WatchConfig(ctx context.Context, cfg Config) (<-chan Event, error) {
+	if cfg.Path == os.DevNull {
+		return nil, ErrDisabled
+	}
+	info, err := os.Stat(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("sdpressure: unable to stat %s: %w", cfg.Path, err)
+	}
+
+	events := make(chan Event, 1)
+	notify := func() {
+		select {
+		case events <- Event{Time: time.Now()}:
+		default:
+		}
+	}
+
+	var run func()
+	switch mode := info.Mode(); {
+	case mode&fs.ModeSocket != 0:
+		c, err := dialSocket(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Trigger) > 0 {
+			if _, err := c.Write(cfg.Trigger); err != nil {
+				_ = c.Close()
+				return nil, fmt.Errorf("sdpressure: unable to write trigger to %s: %w", cfg.Path, err)
+			}
+		}
+		run = func() { readLoop(ctx, c, notify) }
+	case mode&fs.ModeNamedPipe != 0:
+		// Opening the FIFO for writing as well keeps reads from failing with
+		// EOF while the service manager has not opened it.
+		f, err := os.OpenFile(cfg.Path, os.O_RDWR, 0)
+		if err != nil {
+			return nil, fmt.Errorf("sdpressure: unable to open %s: %w", cfg.Path, err)
+		}
+		run = func() { readLoop(ctx, f, notify) }
+	default:
+		w, err := openPSI(cfg)
+		if err != nil {
+			return nil, err
+		}
+		run = func() { w.run(ctx, notify) }
+	}
+
+	go func() {
+		defer close(events)
+		run()
+	}()
+	return events, nil
+}
+
+// dialSocket connects to a unix socket, which may be a stream or a seqpacket
+// socket.
+func dialSocket(path string) (net.Conn, error) {
+	c, err := net.Dial("unix", path)
+	if err == nil {
+		return c, nil
+	}
+	if !errors.Is(err, syscall.EPROTOTYPE) {
+		return nil, fmt.Errorf("sdpressure: unable to connect to %s: %w", path, err)
+	}
+	c, err = net.Dial("unixpacket", path)
+	if err != nil {
+		return nil, fmt.Errorf("sdpressure: unable to connect to %s: %w", path, err)
+	}
+	return c, nil
+}
+
+// readLoop signals an event each time data is read from r, which is closed
+// once ctx is done.
+func readLoop(ctx context.Context, r io.ReadCloser, notify func()) {
+	stop := context.AfterFunc(ctx, func() { _ = r.Close() })
+	defer func() {
+		if stop() {
+			_ = r.Close()
+		}
+	}()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			notify()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// psiWatcher waits for a PSI trigger to fire, which is signaled by POLLPRI
+// and not supported by the runtime poller of Go.
+type psiWatcher struct {
+	f    *os.File
+	epfd int
+	// wake is a pipe used to interrupt waiting once the context is done.
+	wake [2]int
+}
+
+// openPSI opens a PSI file and arms its trigger.
+func openPSI(cfg Config) (*psiWatcher, error) {
+	trigger := cfg.Trigger
+	if len(trigger) == 0 {
+		trigger = []byte(DefaultTrigger)
+	}
+	// The kernel replaces the last byte written with a NUL terminator, which
+	// the service manager already includes in `$MEMORY_PRESSURE_WRITE`.
+	if trigger[len(trigger)-1] != 0 {
+		trigger = append(trigger[:len(trigger):len(trigger)], 0)
+	}
+	f, err := os.OpenFile(cfg.Path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("sdpressure: unable to open %s: %w", cfg.Path, err)
+	}
+	// The trigger must be written in a single write, and stays armed for as
+	// long as the file is open.
+	if _, err := f.Write(trigger); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("sdpressure: unable to write trigger to %s: %w", cfg.Path, err)
+	}
+
+	w := &psiWatcher{f: f, epfd: -1, wake: [2]int{-1, -1}}
+	if err := w.init(); err != nil {
+		w.close()
+		return nil, fmt.Errorf("sdpressure: unable to watch %s: %w", cfg.Path, err)
+	}
+	return w, nil
+}
+
+// init creates the epoll instance and the wake pipe.
+func (w *psiWatcher) init() error {
+	var err error
+	if w.epfd, err = syscall.EpollCreate1(syscall.EPOLL_CLOEXEC); err != nil {
+		return err
+	}
+	if err := syscall.Pipe2(w.wake[:], syscall.O_CLOEXEC|syscall.O_NONBLOCK); err != nil {
+		return err
+	}
+	fd := int(w.f.Fd())
+	if err := syscall.EpollCtl(w.epfd, syscall.EPOLL_CTL_ADD, fd, &syscall.EpollEvent{Events: syscall.EPOLLPRI, Fd: int32(fd)}); err != nil {
+		return err
+	}
+	return syscall.EpollCtl(w.epfd, syscall.EPOLL_CTL_ADD, w.wake[0], &syscall.EpollEvent{Events: syscall.EPOLLIN, Fd: int32(w.wake[0])})
+}
+
+// close releases all the file descriptors of the watcher.
+func (w *psiWatcher) close() {
+	for _, fd := range []int{w.epfd, w.wake[0], w.wake[1]} {
+		if fd >= 0 {
+			_ = syscall.Close(fd)
+		}
+	}
+	_ = w.f.Close()
+}
+
+// run waits for the trigger to fire until ctx is done.
+func (w *psiWatcher) run(ctx context.Context, notify func()) {
+	defer w.close()
+	stop := context.AfterFunc(ctx, func() { _, _ = syscall.Write(w.wake[1], []byte{0}) })
+	defer stop()
+
+	events := make([]syscall.EpollEvent, 2)
+	for {
+		n, err := syscall.EpollWait(w.epfd, events, -1)
+		if err != nil {
+			if errors.Is(err, syscall.EINTR) {
+				continue
+			}
+			return
+		}
+		for _, ev := range events[:n] {
+			switch {
+			case int(ev.Fd) == w.wake[0]:
+				return
+			case ev.Events&syscall.EPOLLERR != 0:
+				// The control group has been removed.
+				return
+			case ev.Events&syscall.EPOLLPRI != 0:
+				notify()
+			}
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdresolve provides a client for systemd-resolved, allowing names to
+// be resolved using its cache, per-link DNS routing, and DNSSEC validation
+// without cgo or NSS.
+//
+// The client uses the `io.systemd.Resolve` Varlink interface of resolved, no
+// D-Bus connection or third-party dependencies are required. Only the
+// configuration of links, which is not available over Varlink, uses the
+// system bus.
+//
+// NOTE: this package is only useful on `linux` operating systems. Connecting
+// to resolved returns [errors.ErrUnsupported] on other operating systems.
+//
+// See the [org.freedesktop.resolve1(5)] docs for more details.
+//
+// [org.freedesktop.resolve1(5)]: https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.resolve1.html
+package sdresolve
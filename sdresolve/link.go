@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdresolve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"syscall"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of resolved.
+	destination = "org.freedesktop.resolve1"
+
+	// managerPath is the object path of resolved.
+	managerPath dbus.ObjectPath = "/org/freedesktop/resolve1"
+
+	// managerInterface is the interface of resolved.
+	managerInterface = "org.freedesktop.resolve1.Manager"
+
+	// linkInterface is the interface of links.
+	linkInterface = "org.freedesktop.resolve1.Link"
+)
+
+// bus returns the connection to resolved on the system bus, connecting on
+// first use. The configuration of links is not available over Varlink.
+func (r *Conn) bus(ctx context.Context) (*dbus.Conn, error) {
+	if r.closed.Load() {
+		return nil, ErrClosed
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.busConn != nil {
+		select {
+		case <-r.busConn.Done():
+		default:
+			return r.busConn, nil
+		}
+	}
+	c, err := r.dialBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to connect to bus: %w", err)
+	}
+	r.busConn = c
+	return c, nil
+}
+
+// callManager calls a method of resolved over D-Bus.
+func (r *Conn) callManager(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	c, err := r.bus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.Call(ctx, destination, managerPath, managerInterface, method, sig, args...)
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return nil, &Error{Name: e.Name, Message: e.Message()}
+	}
+	return body, err
+}
+
+// GetLink returns the DNS configuration of a network interface, the same as
+// `resolvectl status`.
+func (r *Conn) GetLink(ctx context.Context, ifindex int) (*Link, error) {
+	body, err := r.callManager(ctx, "GetLink", "i", ifindex)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to get link %d: %w", ifindex, err)
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdresolve: unable to get link %d: invalid reply", ifindex)
+	}
+	path, _ := body[0].(dbus.ObjectPath)
+	c, err := r.bus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to get link %d: %w", ifindex, err)
+	}
+	props, err := c.GetAllProperties(ctx, destination, path, linkInterface)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to get link %d: %w", ifindex, err)
+	}
+
+	link := &Link{Interface: ifindex}
+	str := func(name string) string {
+		s, _ := props[name].Value.(string)
+		return s
+	}
+	link.LLMNR = Mode(str("LLMNR"))
+	link.MulticastDNS = Mode(str("MulticastDNS"))
+	link.DNSOverTLS = Mode(str("DNSOverTLS"))
+	link.DNSSEC = Mode(str("DNSSEC"))
+	link.DefaultRoute, _ = props["DefaultRoute"].Value.(bool)
+	link.DNSSECSupported, _ = props["DNSSECSupported"].Value.(bool)
+
+	servers, _ := props["DNSEx"].Value.([]any)
+	for _, v := range servers {
+		if s, ok := parseServer(v); ok {
+			link.Servers = append(link.Servers, s)
+		}
+	}
+	if s, ok := parseServer(props["CurrentDNSServerEx"].Value); ok {
+		link.CurrentServer = &s
+	}
+	domains, _ := props["Domains"].Value.([]any)
+	for _, v := range domains {
+		d, ok := v.(dbus.Struct)
+		if !ok || len(d) != 2 {
+			continue
+		}
+		name, _ := d[0].(string)
+		routing, _ := d[1].(bool)
+		link.Domains = append(link.Domains, Domain{Name: name, RoutingOnly: routing})
+	}
+	anchors, _ := props["DNSSECNegativeTrustAnchors"].Value.([]any)
+	for _, v := range anchors {
+		if s, ok := v.(string); ok {
+			link.NegativeTrustAnchors = append(link.NegativeTrustAnchors, s)
+		}
+	}
+	return link, nil
+}
+
+// parseServer parses a DNS server in the `(iayqs)` format.
+func parseServer(v any) (DNSServer, bool) {
+	s, ok := v.(dbus.Struct)
+	if !ok || len(s) != 4 {
+		return DNSServer{}, false
+	}
+	raw, _ := s[1].([]byte)
+	ip, ok := netip.AddrFromSlice(raw)
+	if !ok {
+		// No server is current.
+		return DNSServer{}, false
+	}
+	port, _ := s[2].(uint16)
+	name, _ := s[3].(string)
+	return DNSServer{Addr: netip.AddrPortFrom(ip.Unmap(), port), ServerName: name}, true
+}
+
+// SetLinkDNS sets the DNS servers of a network interface, the same as
+// `resolvectl dns`.
+func (r *Conn) SetLinkDNS(ctx context.Context, ifindex int, servers []DNSServer) error {
+	args := make([]any, len(servers))
+	for i, s := range servers {
+		ip := s.Addr.Addr().Unmap()
+		if !ip.IsValid() {
+			return fmt.Errorf("sdresolve: unable to set DNS servers of link %d: invalid address", ifindex)
+		}
+		family := syscall.AF_INET6
+		if ip.Is4() {
+			family = syscall.AF_INET
+		}
+		args[i] = dbus.Struct{int32(family), ip.AsSlice(), s.Addr.Port(), s.ServerName}
+	}
+	if _, err := r.callManager(ctx, "SetLinkDNSEx", "ia(iayqs)", ifindex, args); err != nil {
+		return fmt.Errorf("sdresolve: unable to set DNS servers of link %d: %w", ifindex, err)
+	}
+	return nil
+}
+
+// SetLinkDomains sets the search and routing domains of a network interface,
+// the same as `resolvectl domain`.
+func (r *Conn) SetLinkDomains(ctx context.Context, ifindex int, domains []Domain) error {
+	args := make([]any, len(domains))
+	for i, d := range domains {
+		args[i] = dbus.Struct{d.Name, d.RoutingOnly}
+	}
+	if _, err := r.callManager(ctx, "SetLinkDomains", "ia(sb)", ifindex, args); err != nil {
+		return fmt.Errorf("sdresolve: unable to set domains of link %d: %w", ifindex, err)
+	}
+	return nil
+}
+
+// SetLinkDefaultRoute sets whether a network interface is used for names not
+// matching the routing domains of any link, the same as `resolvectl
+// default-route`.
+func (r *Conn) SetLinkDefaultRoute(ctx context.Context, ifindex int, enable bool) error {
+	if _, err := r.callManager(ctx, "SetLinkDefaultRoute", "ib", ifindex, enable); err != nil {
+		return fmt.Errorf("sdresolve: unable to set default route of link %d: %w", ifindex, err)
+	}
+	return nil
+}
+
+// SetLinkLLMNR sets the LLMNR mode of a network interface, the same as
+// `resolvectl llmnr`.
+func (r *Conn) SetLinkLLMNR(ctx context.Context, ifindex int, mode Mode) error {
+	return r.setLinkMode(ctx, "SetLinkLLMNR", "LLMNR", ifindex, mode)
+}
+
+// SetLinkMulticastDNS sets the mDNS mode of a network interface, the same as
+// `resolvectl mdns`.
+func (r *Conn) SetLinkMulticastDNS(ctx context.Context, ifindex int, mode Mode) error {
+	return r.setLinkMode(ctx, "SetLinkMulticastDNS", "mDNS", ifindex, mode)
+}
+
+// SetLinkDNSOverTLS sets the DNS-over-TLS mode of a network interface, the
+// same as `resolvectl dnsovertls`.
+func (r *Conn) SetLinkDNSOverTLS(ctx context.Context, ifindex int, mode Mode) error {
+	return r.setLinkMode(ctx, "SetLinkDNSOverTLS", "DNS-over-TLS", ifindex, mode)
+}
+
+// SetLinkDNSSEC sets the DNSSEC mode of a network interface, the same as
+// `resolvectl dnssec`.
+func (r *Conn) SetLinkDNSSEC(ctx context.Context, ifindex int, mode Mode) error {
+	return r.setLinkMode(ctx, "SetLinkDNSSEC", "DNSSEC", ifindex, mode)
+}
+
+// setLinkMode calls a method setting a mode of a network interface, setting
+// describes the mode in error messages.
+func (r *Conn) setLinkMode(ctx context.Context, method, setting string, ifindex int, mode Mode) error {
+	if _, err := r.callManager(ctx, method, "is", ifindex, string(mode)); err != nil {
+		return fmt.Errorf("sdresolve: unable to set %s mode of link %d: %w", setting, ifindex, err)
+	}
+	return nil
+}
+
+// SetLinkNegativeTrustAnchors sets the domains DNSSEC validation is disabled
+// for on a network interface, the same as `resolvectl nta`.
+func (r *Conn) SetLinkNegativeTrustAnchors(ctx context.Context, ifindex int, domains []string) error {
+	if _, err := r.callManager(ctx, "SetLinkDNSSECNegativeTrustAnchors", "ias", ifindex, domains); err != nil {
+		return fmt.Errorf("sdresolve: unable to set negative trust anchors of link %d: %w", ifindex, err)
+	}
+	return nil
+}
+
+// RevertLink reverts all DNS configuration of a network interface made using
+// this package or `resolvectl`, the same as `resolvectl revert`.
+func (r *Conn) RevertLink(ctx context.Context, ifindex int) error {
+	if _, err := r.callManager(ctx, "RevertLink", "i", ifindex); err != nil {
+		return fmt.Errorf("sdresolve: unable to revert link %d: %w", ifindex, err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdresolve
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DNS response codes.
+//
+// ref; https://www.iana.org/assignments/dns-parameters/dns-parameters.xhtml#dns-parameters-6
+const (
+	rcodeSuccess       = 0
+	rcodeFormatError   = 1
+	rcodeServerFailure = 2
+)
+
+// Resolver returns a [net.Resolver] that resolves names using resolved, the
+// same as those resolved by [Conn.ResolveRecord] with opts. The resolver may
+// be used by a [net.Dialer] to make existing code, such as an
+// [http.Transport], resolve names using resolved:
+//
+//	dialer := &net.Dialer{Resolver: r.Resolver(sdresolve.QueryOptions{})}
+//	transport := &http.Transport{DialContext: dialer.DialContext}
+//
+// Queries made by the resolver are answered in-process, without any network
+// traffic other than to resolved. The hosts file and search domains are
+// handled by the resolver the same as [net.DefaultResolver] with the pure Go
+// resolver.
+//
+// [http.Transport]: https://pkg.go.dev/net/http#Transport
+func (r *Conn) Resolver(opts QueryOptions) *net.Resolver {
+	// Search domains are applied by the Go resolver.
+	opts.Flags |= FlagNoSearch
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			client, server := net.Pipe()
+			go r.serveDNS(ctx, server, opts)
+			return client, nil
+		},
+	}
+}
+
+// serveDNS answers DNS queries read from c until it is closed. As c is not a
+// [net.PacketConn], messages are prefixed by their length the same as DNS over
+// TCP.
+func (r *Conn) serveDNS(ctx context.Context, c net.Conn, opts QueryOptions) {
+	defer c.Close()
+	for {
+		var n uint16
+		if err := binary.Read(c, binary.BigEndian, &n); err != nil {
+			return
+		}
+		query := make([]byte, n)
+		if _, err := io.ReadFull(c, query); err != nil {
+			return
+		}
+		resp, err := r.answer(ctx, query, opts)
+		if err != nil {
+			return
+		}
+		if _, err := c.Write(binary.BigEndian.AppendUint16(nil, uint16(len(resp)))); err != nil {
+			return
+		}
+		if _, err := c.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+// question is the question of a DNS query.
+type question struct {
+	name  string
+	typ   RecordType
+	class uint16
+	// raw is the question in wire format.
+	raw []byte
+}
+
+// parseQuery parses a DNS query with a single question.
+func parseQuery(b []byte) (id, flags uint16, q question, err error) {
+	if len(b) < 12 {
+		return 0, 0, question{}, errors.New("short message")
+	}
+	id, flags = binary.BigEndian.Uint16(b), binary.BigEndian.Uint16(b[2:])
+	if binary.BigEndian.Uint16(b[4:]) != 1 {
+		return id, flags, question{}, errors.New("expected a single question")
+	}
+	var labels []string
+	i := 12
+	for {
+		if i >= len(b) {
+			return id, flags, question{}, errors.New("short message")
+		}
+		l := int(b[i])
+		i++
+		if l == 0 {
+			break
+		}
+		// Compression is not used by queries with a single question.
+		if l > 63 || i+l > len(b) {
+			return id, flags, question{}, errors.New("invalid name")
+		}
+		labels = append(labels, string(b[i:i+l]))
+		i += l
+	}
+	if i+4 > len(b) {
+		return id, flags, question{}, errors.New("short message")
+	}
+	q = question{
+		name:  strings.Join(labels, "."),
+		typ:   RecordType(binary.BigEndian.Uint16(b[i:])),
+		class: binary.BigEndian.Uint16(b[i+2:]),
+		raw:   b[12 : i+4],
+	}
+	if q.name == "" {
+		q.name = "."
+	}
+	return id, flags, q, nil
+}
+
+// answer returns the response to a DNS query.
+func (r *Conn) answer(ctx context.Context, query []byte, opts QueryOptions) ([]byte, error) {
+	id, flags, q, err := parseQuery(query)
+	if err != nil {
+		if len(query) < 12 {
+			return nil, err
+		}
+		return response(id, flags, nil, rcodeFormatError, false, nil), nil
+	}
+
+	res, err := r.ResolveRecord(ctx, q.name, q.class, q.typ, opts)
+	if err != nil {
+		return response(id, flags, q.raw, rcode(err), false, nil), nil
+	}
+	var answers [][]byte
+	for _, rec := range res.Records {
+		if rr, ok := encodeRecord(rec); ok {
+			answers = append(answers, rr)
+		}
+	}
+	return response(id, flags, q.raw, rcodeSuccess, res.Flags.Authenticated(), answers), nil
+}
+
+// rcode returns the DNS response code for an error returned by resolved.
+func rcode(err error) int {
+	var e *Error
+	if !errors.As(err, &e) {
+		return rcodeServerFailure
+	}
+	switch e.Name {
+	case "io.systemd.Resolve.NoSuchResourceRecord":
+		// The name exists, but has no records of the type.
+		return rcodeSuccess
+	case "io.systemd.Resolve.DNSError":
+		if rcode, err := strconv.Atoi(e.param("rcode")); err == nil {
+			return rcode
+		}
+	}
+	return rcodeServerFailure
+}
+
+// response builds a DNS response to the query with id and flags.
+func response(id, flags uint16, question []byte, rcode int, authenticated bool, answers [][]byte) []byte {
+	// QR, the opcode and RD of the query, and RA.
+	out := flags&0x7900 | 0x8000 | 0x0080 | uint16(rcode&0xf)
+	if authenticated {
+		// AD
+		out |= 0x0020
+	}
+	b := binary.BigEndian.AppendUint16(nil, id)
+	b = binary.BigEndian.AppendUint16(b, out)
+	qdcount := 0
+	if question != nil {
+		qdcount = 1
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(qdcount))
+	b = binary.BigEndian.AppendUint16(b, uint16(len(answers)))
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = append(b, question...)
+	for _, rr := range answers {
+		b = append(b, rr...)
+	}
+	return b
+}
+
+// appendName appends a domain name in wire format without compression.
+func appendName(b []byte, name string) ([]byte, bool) {
+	for label := range strings.SplitSeq(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		if len(label) > 63 {
+			return nil, false
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0), true
+}
+
+// encodeRecord encodes a record in wire format, using the fields decoded from
+// it. The raw record returned by resolved is not used as it may contain
+// compressed names, only the types used by [net.Resolver] are supported.
+func encodeRecord(rec Record) ([]byte, bool) {
+	var rdata []byte
+	ok := true
+	switch rec.Type {
+	case TypeA, TypeAAAA:
+		if !rec.IP.IsValid() {
+			return nil, false
+		}
+		rdata = rec.IP.AsSlice()
+	case TypeCNAME, TypeNS, TypePTR:
+		rdata, ok = appendName(nil, rec.Target)
+	case TypeMX:
+		rdata, ok = appendName(binary.BigEndian.AppendUint16(nil, rec.Priority), rec.Target)
+	case TypeSRV:
+		rdata = binary.BigEndian.AppendUint16(nil, rec.Priority)
+		rdata = binary.BigEndian.AppendUint16(rdata, rec.Weight)
+		rdata = binary.BigEndian.AppendUint16(rdata, rec.Port)
+		rdata, ok = appendName(rdata, rec.Target)
+	case TypeTXT:
+		for _, s := range rec.Text {
+			for {
+				chunk := s[:min(len(s), 255)]
+				rdata = append(rdata, byte(len(chunk)))
+				rdata = append(rdata, chunk...)
+				s = s[len(chunk):]
+				if s == "" {
+					break
+				}
+			}
+		}
+	default:
+		return nil, false
+	}
+	if !ok || len(rdata) > 0xffff {
+		return nil, false
+	}
+
+	b, ok := appendName(nil, rec.Name)
+	if !ok {
+		return nil, false
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(rec.Type))
+	b = binary.BigEndian.AppendUint16(b, rec.Class)
+	// The TTL is not returned by resolved, the Go resolver does not cache.
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(rdata)))
+	return append(b, rdata...), true
+}
@@ -0,0 +1,523 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdresolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ErrClosed is returned when using a closed [Conn].
+var ErrClosed = errors.New("sdresolve: connection closed")
+
+// ErrNotFound is returned when a name or record does not exist.
+var ErrNotFound = errors.New("sdresolve: not found")
+
+// ErrDNSSECFailed is returned when DNSSEC validation of a result fails, see
+// [Error.DNSSECResult] for the reason.
+var ErrDNSSECFailed = errors.New("sdresolve: DNSSEC validation failed")
+
+// ErrNotAuthenticated is returned when a result is not authenticated, but
+// [QueryOptions.RequireAuthenticated] is set.
+var ErrNotAuthenticated = errors.New("sdresolve: result not authenticated")
+
+// Error is an error returned by resolved.
+type Error struct {
+	// Name is the name of the error, such as
+	// `io.systemd.Resolve.NoSuchResourceRecord`.
+	Name string
+	// Parameters are the parameters of the error, if any, such as the
+	// response code of `io.systemd.Resolve.DNSError`.
+	Parameters json.RawMessage
+	// Message is the human-readable message of errors returned over D-Bus.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message != "" {
+		return "sdresolve: " + e.Message
+	}
+	if len(e.Parameters) == 0 || bytes.Equal(e.Parameters, []byte("{}")) {
+		return "sdresolve: " + e.Name
+	}
+	return "sdresolve: " + e.Name + ": " + string(e.Parameters)
+}
+
+// Is reports whether the error matches target, [ErrNotFound] matches
+// `io.systemd.Resolve.NoSuchResourceRecord` and `NXDOMAIN` responses, and
+// [ErrDNSSECFailed] matches `io.systemd.Resolve.DNSSECValidationFailed`.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		switch e.Name {
+		case "io.systemd.Resolve.NoSuchResourceRecord":
+			return true
+		case "io.systemd.Resolve.DNSError":
+			// NXDOMAIN
+			return e.param("rcode") == "3"
+		}
+	case ErrDNSSECFailed:
+		return e.Name == "io.systemd.Resolve.DNSSECValidationFailed"
+	}
+	return false
+}
+
+// DNSSECResult returns the reason DNSSEC validation failed, such as `bogus`,
+// `no-signature`, or `missing-key`. It is empty for other errors.
+func (e *Error) DNSSECResult() string {
+	if e.Name != "io.systemd.Resolve.DNSSECValidationFailed" {
+		return ""
+	}
+	return e.param("result")
+}
+
+// param returns a parameter of the error formatted as JSON, without quotes
+// for strings.
+func (e *Error) param(name string) string {
+	var p map[string]json.RawMessage
+	if json.Unmarshal(e.Parameters, &p) != nil {
+		return ""
+	}
+	var v string
+	if json.Unmarshal(p[name], &v) == nil {
+		return v
+	}
+	return string(p[name])
+}
+
+// Flags are the `SD_RESOLVED_*` flags, used to control how a query is
+// resolved and to describe how its result was obtained.
+//
+// ref; https://github.com/systemd/systemd/blob/main/src/shared/resolve-util.h
+type Flags uint64
+
+const (
+	// FlagDNS, FlagLLMNRIPv4, FlagLLMNRIPv6, FlagMDNSIPv4, and FlagMDNSIPv6
+	// limit a query to the given protocols, by default all protocols are
+	// used.
+	FlagDNS       Flags = 1 << 0
+	FlagLLMNRIPv4 Flags = 1 << 1
+	FlagLLMNRIPv6 Flags = 1 << 2
+	FlagMDNSIPv4  Flags = 1 << 3
+	FlagMDNSIPv6  Flags = 1 << 4
+
+	FlagNoCNAME       Flags = 1 << 5
+	FlagNoTXT         Flags = 1 << 6
+	FlagNoAddress     Flags = 1 << 7
+	FlagNoSearch      Flags = 1 << 8
+	FlagNoValidate    Flags = 1 << 10
+	FlagNoSynthesize  Flags = 1 << 11
+	FlagNoCache       Flags = 1 << 12
+	FlagNoZone        Flags = 1 << 13
+	FlagNoTrustAnchor Flags = 1 << 14
+	FlagNoNetwork     Flags = 1 << 15
+	FlagNoStale       Flags = 1 << 24
+
+	// FlagAuthenticated is set on results that were validated using DNSSEC,
+	// or that are trusted for another reason, such as being synthesized
+	// locally.
+	FlagAuthenticated Flags = 1 << 9
+	// FlagConfidential is set on results that were only transmitted over
+	// encrypted channels, such as DNS-over-TLS.
+	FlagConfidential    Flags = 1 << 18
+	FlagSynthetic       Flags = 1 << 19
+	FlagFromCache       Flags = 1 << 20
+	FlagFromZone        Flags = 1 << 21
+	FlagFromTrustAnchor Flags = 1 << 22
+	FlagFromNetwork     Flags = 1 << 23
+)
+
+// flagNames are the names of the flags, as used by [Flags.String].
+var flagNames = []struct {
+	flag Flags
+	name string
+}{
+	{FlagDNS, "dns"},
+	{FlagLLMNRIPv4, "llmnr-ipv4"},
+	{FlagLLMNRIPv6, "llmnr-ipv6"},
+	{FlagMDNSIPv4, "mdns-ipv4"},
+	{FlagMDNSIPv6, "mdns-ipv6"},
+	{FlagNoCNAME, "no-cname"},
+	{FlagNoTXT, "no-txt"},
+	{FlagNoAddress, "no-address"},
+	{FlagNoSearch, "no-search"},
+	{FlagAuthenticated, "authenticated"},
+	{FlagNoValidate, "no-validate"},
+	{FlagNoSynthesize, "no-synthesize"},
+	{FlagNoCache, "no-cache"},
+	{FlagNoZone, "no-zone"},
+	{FlagNoTrustAnchor, "no-trust-anchor"},
+	{FlagNoNetwork, "no-network"},
+	{FlagConfidential, "confidential"},
+	{FlagSynthetic, "synthetic"},
+	{FlagFromCache, "cache"},
+	{FlagFromZone, "zone"},
+	{FlagFromTrustAnchor, "trust-anchor"},
+	{FlagFromNetwork, "network"},
+	{FlagNoStale, "no-stale"},
+}
+
+// String returns the names of the flags that are set separated by commas,
+// such as `dns,authenticated,network`, for logging where a result came from.
+func (f Flags) String() string {
+	var names []string
+	for _, n := range flagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+			f &^= n.flag
+		}
+	}
+	if f != 0 {
+		names = append(names, "0x"+strconv.FormatUint(uint64(f), 16))
+	}
+	return strings.Join(names, ",")
+}
+
+// Authenticated reports whether the result was validated using DNSSEC, or is
+// trusted for another reason, such as being synthesized locally.
+func (f Flags) Authenticated() bool {
+	return f&FlagAuthenticated != 0
+}
+
+// Confidential reports whether the result was only transmitted over encrypted
+// channels, such as DNS-over-TLS.
+func (f Flags) Confidential() bool {
+	return f&FlagConfidential != 0
+}
+
+// Protocol is a protocol used to resolve names.
+type Protocol string
+
+const (
+	ProtocolDNS   Protocol = "dns"
+	ProtocolLLMNR Protocol = "llmnr"
+	ProtocolMDNS  Protocol = "mdns"
+)
+
+// Protocols returns the protocols a result was obtained using.
+func (f Flags) Protocols() []Protocol {
+	var protocols []Protocol
+	if f&FlagDNS != 0 {
+		protocols = append(protocols, ProtocolDNS)
+	}
+	if f&(FlagLLMNRIPv4|FlagLLMNRIPv6) != 0 {
+		protocols = append(protocols, ProtocolLLMNR)
+	}
+	if f&(FlagMDNSIPv4|FlagMDNSIPv6) != 0 {
+		protocols = append(protocols, ProtocolMDNS)
+	}
+	return protocols
+}
+
+// Source is where the data of a result came from.
+type Source string
+
+const (
+	// SourceSynthetic is data synthesized locally, such as for `localhost`
+	// or the hostname of the system.
+	SourceSynthetic Source = "synthetic"
+	// SourceZone is data from a locally registered zone, such as the hosts
+	// file or services published using mDNS.
+	SourceZone Source = "zone"
+	// SourceTrustAnchor is data from the local DNSSEC trust anchor.
+	SourceTrustAnchor Source = "trust-anchor"
+	// SourceCache is data from the cache of resolved.
+	SourceCache Source = "cache"
+	// SourceNetwork is data received from the network.
+	SourceNetwork Source = "network"
+)
+
+// Sources returns where the data of a result came from, a result may combine
+// data from multiple sources, such as a CNAME from the cache and an address
+// from the network.
+func (f Flags) Sources() []Source {
+	var sources []Source
+	for _, s := range []struct {
+		flag   Flags
+		source Source
+	}{
+		{FlagSynthetic, SourceSynthetic},
+		{FlagFromZone, SourceZone},
+		{FlagFromTrustAnchor, SourceTrustAnchor},
+		{FlagFromCache, SourceCache},
+		{FlagFromNetwork, SourceNetwork},
+	} {
+		if f&s.flag != 0 {
+			sources = append(sources, s.source)
+		}
+	}
+	return sources
+}
+
+// QueryOptions controls how a query is resolved.
+type QueryOptions struct {
+	// Interface limits the query to the network interface with the given
+	// index, zero allows any interface.
+	Interface int
+	// Flags controls how the query is resolved, such as [FlagNoCache].
+	Flags Flags
+	// RequireAuthenticated rejects results that are not authenticated, see
+	// [Flags.Authenticated], with an error wrapping [ErrNotAuthenticated].
+	RequireAuthenticated bool
+}
+
+// Address is an address a hostname resolved to.
+type Address struct {
+	// Interface is the index of the network interface the address was
+	// resolved on, which is zero if the address is not scoped to an
+	// interface. This should be used as the zone of link-local addresses.
+	Interface int
+	// IP is the address.
+	IP netip.Addr
+}
+
+// Zoned returns the address with the name of its interface as the zone if it
+// is link-local, as required to connect to it.
+func (a Address) Zoned() netip.Addr {
+	if a.Interface <= 0 || !a.IP.Is6() || !(a.IP.IsLinkLocalUnicast() || a.IP.IsLinkLocalMulticast()) {
+		return a.IP
+	}
+	if ifi, err := net.InterfaceByIndex(a.Interface); err == nil {
+		return a.IP.WithZone(ifi.Name)
+	}
+	return a.IP.WithZone(strconv.Itoa(a.Interface))
+}
+
+// HostnameResult is the result of [Conn.ResolveHostname].
+type HostnameResult struct {
+	// Name is the canonical name of the hostname, after following any CNAME
+	// records.
+	Name string
+	// Addresses are the addresses the hostname resolved to, in order of
+	// preference.
+	Addresses []Address
+	// Flags describe how the result was obtained, such as whether it was
+	// authenticated.
+	Flags Flags
+}
+
+// Name is a name an address resolved to.
+type Name struct {
+	// Interface is the index of the network interface the name was resolved
+	// on, or zero.
+	Interface int
+	// Name is the name.
+	Name string
+}
+
+// AddressResult is the result of [Conn.ResolveAddress].
+type AddressResult struct {
+	// Names are the names the address resolved to.
+	Names []Name
+	// Flags describe how the result was obtained, such as whether it was
+	// authenticated.
+	Flags Flags
+}
+
+// RecordType is the type of a DNS resource record.
+//
+// ref; https://www.iana.org/assignments/dns-parameters/dns-parameters.xhtml#dns-parameters-4
+type RecordType uint16
+
+const (
+	TypeA     RecordType = 1
+	TypeNS    RecordType = 2
+	TypeCNAME RecordType = 5
+	TypeSOA   RecordType = 6
+	TypePTR   RecordType = 12
+	TypeMX    RecordType = 15
+	TypeTXT   RecordType = 16
+	TypeAAAA  RecordType = 28
+	TypeSRV   RecordType = 33
+	TypeSSHFP RecordType = 44
+	TypeTLSA  RecordType = 52
+	TypeSVCB  RecordType = 64
+	TypeHTTPS RecordType = 65
+	TypeCAA   RecordType = 257
+)
+
+// ClassIN is the Internet class of DNS resource records, used for almost all
+// records.
+const ClassIN uint16 = 1
+
+// Record is a DNS resource record.
+type Record struct {
+	// Interface is the index of the network interface the record was resolved
+	// on, or zero.
+	Interface int
+	// Name, Class, and Type are the key of the record.
+	Name  string
+	Class uint16
+	Type  RecordType
+
+	// IP is the address of A and AAAA records.
+	IP netip.Addr
+	// Target is the name referenced by CNAME, NS, PTR, MX, and SRV records.
+	Target string
+	// Priority is the priority of MX and SRV records.
+	Priority uint16
+	// Weight and Port are the weight and port of SRV records.
+	Weight uint16
+	Port   uint16
+	// Text are the strings of TXT records.
+	Text []string
+
+	// Data is the record as decoded by resolved, a JSON object whose fields
+	// depend on the type of the record.
+	Data json.RawMessage
+	// Raw is the record in DNS wire format.
+	Raw []byte
+}
+
+// RecordResult is the result of [Conn.ResolveRecord].
+type RecordResult struct {
+	// Records are the records found.
+	Records []Record
+	// Flags describe how the result was obtained, such as whether it was
+	// authenticated.
+	Flags Flags
+}
+
+// Service is an instance of a service, as described by an SRV record.
+type Service struct {
+	// Priority and Weight determine the order instances should be tried in.
+	Priority uint16
+	Weight   uint16
+	// Port is the port the service is listening on.
+	Port uint16
+	// Hostname is the host the service is running on.
+	Hostname string
+	// Addresses are the addresses of the host, unless [FlagNoAddress] is set.
+	Addresses []Address
+}
+
+// ServiceResult is the result of [Conn.ResolveService].
+type ServiceResult struct {
+	// Name, Type, and Domain are the canonical name of the service, such as
+	// `Office Printer`, `_ipp._tcp`, and `local`. Name is empty for services
+	// resolved without an instance name.
+	Name   string
+	Type   string
+	Domain string
+	// Services are the instances of the service.
+	Services []Service
+	// TXT are the strings of the TXT record of the service, unless
+	// [FlagNoTXT] is set.
+	TXT []string
+	// Flags describe how the result was obtained, such as whether it was
+	// authenticated.
+	Flags Flags
+}
+
+// Attributes returns the `key=value` attributes of the TXT record of a
+// DNS-SD service. Attributes without a value are present with an empty value,
+// only the first occurrence of a key is used.
+//
+// ref; https://www.rfc-editor.org/rfc/rfc6763#section-6
+func (r *ServiceResult) Attributes() map[string]string {
+	attrs := make(map[string]string, len(r.TXT))
+	for _, s := range r.TXT {
+		k, v, _ := strings.Cut(s, "=")
+		if k == "" {
+			continue
+		}
+		k = strings.ToLower(k)
+		if _, ok := attrs[k]; !ok {
+			attrs[k] = v
+		}
+	}
+	return attrs
+}
+
+// ServiceEventType is the type of a [ServiceEvent].
+type ServiceEventType string
+
+const (
+	ServiceAdded   ServiceEventType = "added"
+	ServiceRemoved ServiceEventType = "removed"
+)
+
+// ServiceEvent is a service instance appearing or disappearing, as delivered
+// by [Conn.BrowseServices].
+type ServiceEvent struct {
+	Type ServiceEventType
+	// Interface is the index of the network interface the instance was found
+	// on.
+	Interface int
+	// Name, ServiceType, and Domain are the name of the instance, which may
+	// be resolved using [Conn.ResolveService].
+	Name        string
+	ServiceType string
+	Domain      string
+}
+
+// Mode is the mode of a per-link protocol setting, such as LLMNR or DNSSEC.
+// Not all modes are valid for every setting, see [resolved.conf(5)].
+//
+// [resolved.conf(5)]: https://www.freedesktop.org/software/systemd/man/latest/resolved.conf.html
+type Mode string
+
+const (
+	// ModeDefault reverts a setting to the global default.
+	ModeDefault Mode = ""
+	ModeYes     Mode = "yes"
+	ModeNo      Mode = "no"
+	// ModeResolve only resolves names using LLMNR or mDNS, without
+	// announcing the hostname of the system.
+	ModeResolve Mode = "resolve"
+	// ModeOpportunistic uses DNS-over-TLS if the server supports it.
+	ModeOpportunistic Mode = "opportunistic"
+	// ModeAllowDowngrade uses DNSSEC if the server supports it.
+	ModeAllowDowngrade Mode = "allow-downgrade"
+)
+
+// DNSServer is a DNS server configured on a link.
+type DNSServer struct {
+	// Addr is the address of the server, a zero port uses the default port.
+	Addr netip.AddrPort
+	// ServerName is the name used to authenticate the server when using
+	// DNS-over-TLS, if any.
+	ServerName string
+}
+
+// Domain is a search or routing domain configured on a link.
+type Domain struct {
+	// Name is the domain, `.` routes all queries to the link.
+	Name string
+	// RoutingOnly is true for domains that only route queries for names in
+	// the domain to the link, and are not used as search domains, the same
+	// as domains prefixed by `~` in `resolvectl domain`.
+	RoutingOnly bool
+}
+
+// Link is the DNS configuration of a network interface, as returned by
+// [Conn.GetLink].
+type Link struct {
+	// Interface is the index of the network interface.
+	Interface int
+	// Servers are the DNS servers of the link.
+	Servers []DNSServer
+	// CurrentServer is the server currently used, if any.
+	CurrentServer *DNSServer
+	// Domains are the search and routing domains of the link.
+	Domains []Domain
+	// DefaultRoute is true if the link is used for names not matching the
+	// routing domains of any link.
+	DefaultRoute bool
+
+	LLMNR        Mode
+	MulticastDNS Mode
+	DNSOverTLS   Mode
+	DNSSEC       Mode
+	// NegativeTrustAnchors are the domains DNSSEC validation is disabled for.
+	NegativeTrustAnchors []string
+	// DNSSECSupported is true if the DNS servers of the link support DNSSEC.
+	DNSSECSupported bool
+}
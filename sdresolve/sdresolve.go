@@ -0,0 +1,444 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdresolve
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/varlink"
+)
+
+// varlinkAddress is the path of the Varlink socket of resolved.
+const varlinkAddress = "/run/systemd/resolve/io.systemd.Resolve"
+
+// Conn is a connection to systemd-resolved. It may be used by multiple
+// goroutines, queries are resolved concurrently.
+type Conn struct {
+	addr   string
+	closed atomic.Bool
+
+	// dialBus connects to the system bus, which is used for the operations
+	// not available over Varlink.
+	dialBus func(context.Context) (*dbus.Conn, error)
+
+	mu      sync.Mutex
+	busConn *dbus.Conn
+}
+
+// New connects to resolved.
+func New(ctx context.Context) (*Conn, error) {
+	return newConn(ctx, varlinkAddress)
+}
+
+// newConn returns a [Conn] using the Varlink socket at addr.
+func newConn(ctx context.Context, addr string) (*Conn, error) {
+	// Each query uses its own connection so queries are not serialized,
+	// connect once to ensure resolved is listening.
+	c, err := varlink.Dial(ctx, addr)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: %w", err)
+	}
+	_ = c.Close()
+	return &Conn{addr: addr, dialBus: dbus.SystemBus}, nil
+}
+
+// Close closes the connection to resolved.
+func (r *Conn) Close() error {
+	r.closed.Store(true)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.busConn != nil {
+		return r.busConn.Close()
+	}
+	return nil
+}
+
+// checkFlags returns an error if a result with flags is not allowed by opts.
+func checkFlags(flags Flags, opts QueryOptions) error {
+	if opts.RequireAuthenticated && !flags.Authenticated() {
+		return ErrNotAuthenticated
+	}
+	return nil
+}
+
+// call calls a method of resolved, decoding the parameters of the reply into
+// out.
+func (r *Conn) call(ctx context.Context, method string, params, out any) error {
+	if r.closed.Load() {
+		return ErrClosed
+	}
+	c, err := varlink.Dial(ctx, r.addr)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	err = c.Call(ctx, method, params, out)
+	var e *varlink.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Parameters: e.Parameters}
+	}
+	return err
+}
+
+// family returns the address family for network, which is one of `ip`, `ip4`,
+// or `ip6`.
+func family(network string) (int, error) {
+	switch network {
+	case "ip", "":
+		return syscall.AF_UNSPEC, nil
+	case "ip4":
+		return syscall.AF_INET, nil
+	case "ip6":
+		return syscall.AF_INET6, nil
+	default:
+		return 0, fmt.Errorf("sdresolve: unsupported network %q", network)
+	}
+}
+
+// resolvedAddress is an address returned by resolved.
+type resolvedAddress struct {
+	Interface int    `json:"ifindex"`
+	Address   octets `json:"address"`
+}
+
+// parseAddresses parses addresses returned by resolved.
+func parseAddresses(addrs []resolvedAddress) ([]Address, error) {
+	var out []Address
+	for _, a := range addrs {
+		ip, ok := parseIP(a.Address)
+		if !ok {
+			return nil, fmt.Errorf("invalid address %v", []byte(a.Address))
+		}
+		out = append(out, Address{Interface: a.Interface, IP: ip})
+	}
+	return out, nil
+}
+
+// parseIP parses an address returned by resolved, which is an array of bytes.
+func parseIP(b []byte) (netip.Addr, bool) {
+	ip, ok := netip.AddrFromSlice(b)
+	return ip.Unmap(), ok
+}
+
+// octets is an array of bytes encoded as an array of numbers, rather than as
+// base64.
+type octets []byte
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (o *octets) UnmarshalJSON(b []byte) error {
+	var n []int
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	v := make([]byte, len(n))
+	for i, x := range n {
+		if x < 0 || x > 255 {
+			return fmt.Errorf("invalid byte %d", x)
+		}
+		v[i] = byte(x)
+	}
+	*o = v
+	return nil
+}
+
+// ResolveHostname resolves a hostname to its addresses, the same as
+// `resolvectl query`. network is one of `ip`, `ip4`, or `ip6`.
+//
+// If the hostname does not exist, an error matching [ErrNotFound] is returned.
+//
+// ref; https://github.com/systemd/systemd/blob/main/src/shared/varlink-io.systemd.Resolve.c
+func (r *Conn) ResolveHostname(ctx context.Context, network, name string, opts QueryOptions) (*HostnameResult, error) {
+	af, err := family(network)
+	if err != nil {
+		return nil, err
+	}
+	params := struct {
+		Interface int    `json:"ifindex,omitempty"`
+		Name      string `json:"name"`
+		Family    int    `json:"family,omitempty"`
+		Flags     Flags  `json:"flags,omitempty"`
+	}{opts.Interface, name, af, opts.Flags}
+	var reply struct {
+		Addresses []resolvedAddress `json:"addresses"`
+		Name      string            `json:"name"`
+		Flags     Flags             `json:"flags"`
+	}
+	if err := r.call(ctx, "io.systemd.Resolve.ResolveHostname", params, &reply); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", name, err)
+	}
+	if err := checkFlags(reply.Flags, opts); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", name, err)
+	}
+	addrs, err := parseAddresses(reply.Addresses)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", name, err)
+	}
+	return &HostnameResult{Name: reply.Name, Addresses: addrs, Flags: reply.Flags}, nil
+}
+
+// ResolveAddress resolves an address to its names, the same as `resolvectl
+// query` with an address.
+//
+// If the address has no names, an error matching [ErrNotFound] is returned.
+func (r *Conn) ResolveAddress(ctx context.Context, addr netip.Addr, opts QueryOptions) (*AddressResult, error) {
+	if !addr.IsValid() {
+		return nil, errors.New("sdresolve: unable to resolve invalid address")
+	}
+	addr = addr.Unmap()
+	af := syscall.AF_INET6
+	if addr.Is4() {
+		af = syscall.AF_INET
+	}
+	raw := addr.AsSlice()
+	bs := make([]int, len(raw))
+	for i, b := range raw {
+		bs[i] = int(b)
+	}
+	params := struct {
+		Interface int   `json:"ifindex,omitempty"`
+		Family    int   `json:"family"`
+		Address   []int `json:"address"`
+		Flags     Flags `json:"flags,omitempty"`
+	}{opts.Interface, af, bs, opts.Flags}
+	var reply struct {
+		Names []struct {
+			Interface int    `json:"ifindex"`
+			Name      string `json:"name"`
+		} `json:"names"`
+		Flags Flags `json:"flags"`
+	}
+	if err := r.call(ctx, "io.systemd.Resolve.ResolveAddress", params, &reply); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", addr, err)
+	}
+	if err := checkFlags(reply.Flags, opts); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", addr, err)
+	}
+
+	res := &AddressResult{Flags: reply.Flags}
+	for _, n := range reply.Names {
+		res.Names = append(res.Names, Name{Interface: n.Interface, Name: n.Name})
+	}
+	return res, nil
+}
+
+// ResolveRecord resolves the DNS resource records of a name, the same as
+// `resolvectl query --type=`. A zero class is [ClassIN].
+//
+// If no records exist, an error matching [ErrNotFound] is returned.
+func (r *Conn) ResolveRecord(ctx context.Context, name string, class uint16, typ RecordType, opts QueryOptions) (*RecordResult, error) {
+	if class == 0 {
+		class = ClassIN
+	}
+	params := struct {
+		Interface int        `json:"ifindex,omitempty"`
+		Name      string     `json:"name"`
+		Class     uint16     `json:"class"`
+		Type      RecordType `json:"type"`
+		Flags     Flags      `json:"flags,omitempty"`
+	}{opts.Interface, name, class, typ, opts.Flags}
+	var reply struct {
+		Records []struct {
+			Interface int             `json:"ifindex"`
+			RR        json.RawMessage `json:"rr"`
+			Raw       string          `json:"raw"`
+		} `json:"rrs"`
+		Flags Flags `json:"flags"`
+	}
+	if err := r.call(ctx, "io.systemd.Resolve.ResolveRecord", params, &reply); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", name, err)
+	}
+	if err := checkFlags(reply.Flags, opts); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve %s: %w", name, err)
+	}
+
+	res := &RecordResult{Flags: reply.Flags}
+	for _, rr := range reply.Records {
+		rec, err := parseRecord(rr.RR)
+		if err != nil {
+			return nil, fmt.Errorf("sdresolve: unable to resolve %s: invalid record: %w", name, err)
+		}
+		rec.Interface = rr.Interface
+		if rec.Raw, err = base64.StdEncoding.DecodeString(rr.Raw); err != nil {
+			return nil, fmt.Errorf("sdresolve: unable to resolve %s: invalid record: %w", name, err)
+		}
+		res.Records = append(res.Records, rec)
+	}
+	return res, nil
+}
+
+// parseRecord parses a resource record as decoded by resolved.
+func parseRecord(data json.RawMessage) (Record, error) {
+	var rr struct {
+		Key struct {
+			Class uint16     `json:"class"`
+			Type  RecordType `json:"type"`
+			Name  string     `json:"name"`
+		} `json:"key"`
+		Address  octets   `json:"address"`
+		Name     string   `json:"name"`
+		Exchange string   `json:"exchange"`
+		Priority uint16   `json:"priority"`
+		Weight   uint16   `json:"weight"`
+		Port     uint16   `json:"port"`
+		Items    []string `json:"items"`
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &rr); err != nil {
+			return Record{}, err
+		}
+	}
+	rec := Record{
+		Name:     rr.Key.Name,
+		Class:    rr.Key.Class,
+		Type:     rr.Key.Type,
+		Target:   rr.Name,
+		Priority: rr.Priority,
+		Weight:   rr.Weight,
+		Port:     rr.Port,
+		Text:     rr.Items,
+		Data:     data,
+	}
+	if rr.Key.Type == TypeMX {
+		rec.Target = rr.Exchange
+	}
+	if len(rr.Address) > 0 {
+		ip, ok := parseIP(rr.Address)
+		if !ok {
+			return Record{}, fmt.Errorf("invalid address %v", []byte(rr.Address))
+		}
+		rec.IP = ip
+	}
+	return rec, nil
+}
+
+// ResolveService resolves a DNS-SD service, or a plain SRV service if name is
+// empty, the same as `resolvectl service`. typ is the type of the service,
+// such as `_ipp._tcp`. The SRV records of the service are resolved along with
+// the addresses of their targets and the TXT record of the service.
+//
+// Services advertised using mDNS are found using the `local` domain.
+//
+// ref; https://www.rfc-editor.org/rfc/rfc6763
+func (r *Conn) ResolveService(ctx context.Context, name, typ, domain string, opts QueryOptions) (*ServiceResult, error) {
+	params := struct {
+		Interface int    `json:"ifindex,omitempty"`
+		Name      string `json:"name,omitempty"`
+		Type      string `json:"type,omitempty"`
+		Domain    string `json:"domain"`
+		Flags     Flags  `json:"flags,omitempty"`
+	}{opts.Interface, name, typ, domain, opts.Flags}
+	var reply struct {
+		Services []struct {
+			Priority  uint16            `json:"priority"`
+			Weight    uint16            `json:"weight"`
+			Port      uint16            `json:"port"`
+			Hostname  string            `json:"hostname"`
+			Addresses []resolvedAddress `json:"addresses"`
+		} `json:"services"`
+		TXT       []string `json:"txt"`
+		Canonical struct {
+			Name   string `json:"name"`
+			Type   string `json:"type"`
+			Domain string `json:"domain"`
+		} `json:"canonical"`
+		Flags Flags `json:"flags"`
+	}
+	service := strings.Join(slices.DeleteFunc([]string{name, typ, domain}, func(s string) bool { return s == "" }), ".")
+	if err := r.call(ctx, "io.systemd.Resolve.ResolveService", params, &reply); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve service %s: %w", service, err)
+	}
+	if err := checkFlags(reply.Flags, opts); err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to resolve service %s: %w", service, err)
+	}
+
+	res := &ServiceResult{
+		Name:   reply.Canonical.Name,
+		Type:   reply.Canonical.Type,
+		Domain: reply.Canonical.Domain,
+		TXT:    reply.TXT,
+		Flags:  reply.Flags,
+	}
+	for _, s := range reply.Services {
+		addrs, err := parseAddresses(s.Addresses)
+		if err != nil {
+			return nil, fmt.Errorf("sdresolve: unable to resolve service %s: %w", service, err)
+		}
+		res.Services = append(res.Services, Service{
+			Priority:  s.Priority,
+			Weight:    s.Weight,
+			Port:      s.Port,
+			Hostname:  s.Hostname,
+			Addresses: addrs,
+		})
+	}
+	return res, nil
+}
+
+// BrowseServices browses for instances of a DNS-SD service, such as
+// `_ipp._tcp` in the `local` domain, using mDNS. Events are delivered on the
+// returned channel as instances appear and disappear, until ctx is canceled
+// or resolved stops browsing, such as when it does not support browsing,
+// after which it is closed. This requires systemd v257 or newer.
+//
+// Instances may be resolved using [Conn.ResolveService].
+func (r *Conn) BrowseServices(ctx context.Context, typ, domain string, opts QueryOptions) (<-chan ServiceEvent, error) {
+	if r.closed.Load() {
+		return nil, ErrClosed
+	}
+	c, err := varlink.Dial(ctx, r.addr)
+	if err != nil {
+		return nil, fmt.Errorf("sdresolve: unable to browse %s.%s: %w", typ, domain, err)
+	}
+	params := struct {
+		Interface int    `json:"ifindex,omitempty"`
+		Type      string `json:"type"`
+		Domain    string `json:"domain"`
+		Flags     Flags  `json:"flags,omitempty"`
+	}{opts.Interface, typ, domain, opts.Flags}
+
+	ch := make(chan ServiceEvent)
+	go func() {
+		defer close(ch)
+		defer c.Close()
+		// Replies are only sent once instances are found, the call lasts until
+		// ctx is canceled.
+		_ = c.CallMore(ctx, "io.systemd.Resolve.BrowseServices", params, func(p json.RawMessage) error {
+			var reply struct {
+				Services []struct {
+					UpdateFlag ServiceEventType `json:"updateFlag"`
+					Interface  int              `json:"ifindex"`
+					Name       string           `json:"name"`
+					Type       string           `json:"type"`
+					Domain     string           `json:"domain"`
+				} `json:"browserServiceData"`
+			}
+			if err := json.Unmarshal(p, &reply); err != nil {
+				return err
+			}
+			for _, s := range reply.Services {
+				ev := ServiceEvent{Type: s.UpdateFlag, Interface: s.Interface, Name: s.Name, ServiceType: s.Type, Domain: s.Domain}
+				select {
+				case ch <- ev:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			return nil
+		})
+	}()
+	return ch, nil
+}
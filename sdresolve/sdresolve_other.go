@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdresolve
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+)
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) ResolveHostname(context.Context, string, string, QueryOptions) (*HostnameResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) ResolveAddress(context.Context, netip.Addr, QueryOptions) (*AddressResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) ResolveRecord(context.Context, string, uint16, RecordType, QueryOptions) (*RecordResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) Resolver(QueryOptions) *net.Resolver { return net.DefaultResolver }
+
+func (*Conn) ResolveService(context.Context, string, string, string, QueryOptions) (*ServiceResult, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) BrowseServices(context.Context, string, string, QueryOptions) (<-chan ServiceEvent, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (*Conn) GetLink(context.Context, int) (*Link, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) SetLinkDNS(context.Context, int, []DNSServer) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkDomains(context.Context, int, []Domain) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkDefaultRoute(context.Context, int, bool) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkLLMNR(context.Context, int, Mode) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkMulticastDNS(context.Context, int, Mode) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkDNSOverTLS(context.Context, int, Mode) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkDNSSEC(context.Context, int, Mode) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLinkNegativeTrustAnchors(context.Context, int, []string) error {
+	return errors.ErrUnsupported
+}
+
+func (*Conn) RevertLink(context.Context, int) error { return errors.ErrUnsupported }
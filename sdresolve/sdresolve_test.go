@@ -0,0 +1,462 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdresolve
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/netip"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+)
+
+// serve serves a fake resolved at a unix socket, returning a [Conn] connected
+// to it. fn returns the reply to each call.
+func serve(t *testing.T, fn func(method string, params json.RawMessage) string) *Conn {
+	t.Helper()
+
+	addr := filepath.Join(t.TempDir(), "io.systemd.Resolve")
+	l, err := net.Listen("unix", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			nc, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer nc.Close()
+				r := bufio.NewReader(nc)
+				for {
+					line, err := r.ReadBytes(0)
+					if err != nil {
+						return
+					}
+					var req struct {
+						Method     string
+						Parameters json.RawMessage
+					}
+					_ = json.Unmarshal(line[:len(line)-1], &req)
+					if _, err := nc.Write(append([]byte(fn(req.Method, req.Parameters)), 0)); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	c, err := newConn(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestResolveHostname(t *testing.T) {
+	r := serve(t, func(method string, params json.RawMessage) string {
+		var p struct {
+			Name   string
+			Family int
+		}
+		_ = json.Unmarshal(params, &p)
+		switch {
+		case method != "io.systemd.Resolve.ResolveHostname":
+			return `{"error":"org.varlink.service.MethodNotFound"}`
+		case p.Name == "missing.example":
+			return `{"error":"io.systemd.Resolve.DNSError","parameters":{"rcode":3}}`
+		case p.Name == "bogus.example":
+			return `{"error":"io.systemd.Resolve.DNSSECValidationFailed","parameters":{"result":"bogus"}}`
+		case p.Family == 10:
+			return `{"parameters":{"addresses":[{"ifindex":2,"family":10,"address":[254,128,0,0,0,0,0,0,0,0,0,0,0,0,0,1]}],"name":"host.example","flags":8388609}}`
+		}
+		return `{"parameters":{"addresses":[{"family":2,"address":[192,0,2,1]},{"ifindex":2,"family":10,"address":[254,128,0,0,0,0,0,0,0,0,0,0,0,0,0,1]}],"name":"host.example","flags":8389121}}`
+	})
+	ctx := context.Background()
+
+	res, err := r.ResolveHostname(ctx, "ip", "www.example", QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := &HostnameResult{
+		Name: "host.example",
+		Addresses: []Address{
+			{IP: netip.MustParseAddr("192.0.2.1")},
+			{Interface: 2, IP: netip.MustParseAddr("fe80::1")},
+		},
+		Flags: FlagDNS | FlagAuthenticated | FlagFromNetwork,
+	}
+	if !reflect.DeepEqual(expected, res) {
+		t.Errorf("expected %#v, but got %#v", expected, res)
+	}
+	if !res.Flags.Authenticated() {
+		t.Error("expected the result to be authenticated")
+	}
+
+	res, err = r.ResolveHostname(ctx, "ip6", "www.example", QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(res.Addresses) != 1 || res.Flags.Authenticated() {
+		t.Errorf("unexpected result %#v", res)
+	}
+
+	if _, err := r.ResolveHostname(ctx, "ip", "missing.example", QueryOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+	if _, err := r.ResolveHostname(ctx, "ip6", "www.example", QueryOptions{RequireAuthenticated: true}); !errors.Is(err, ErrNotAuthenticated) {
+		t.Errorf("expected %v, but got %v", ErrNotAuthenticated, err)
+	}
+	_, err = r.ResolveHostname(ctx, "ip", "bogus.example", QueryOptions{})
+	var e *Error
+	if !errors.Is(err, ErrDNSSECFailed) || !errors.As(err, &e) || e.DNSSECResult() != "bogus" {
+		t.Errorf("expected %v, but got %v", ErrDNSSECFailed, err)
+	}
+	if _, err := r.ResolveHostname(ctx, "tcp", "www.example", QueryOptions{}); err == nil {
+		t.Error("expected an error, but got nil")
+	}
+
+	_ = r.Close()
+	if _, err := r.ResolveHostname(ctx, "ip", "www.example", QueryOptions{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected %v, but got %v", ErrClosed, err)
+	}
+}
+
+func TestResolveAddress(t *testing.T) {
+	r := serve(t, func(method string, params json.RawMessage) string {
+		var p struct {
+			Family  int
+			Address []int
+		}
+		_ = json.Unmarshal(params, &p)
+		if p.Family != 2 || !reflect.DeepEqual(p.Address, []int{192, 0, 2, 1}) {
+			return `{"error":"io.systemd.Resolve.NoSuchResourceRecord"}`
+		}
+		return `{"parameters":{"names":[{"ifindex":0,"name":"host.example"}],"flags":1}}`
+	})
+	ctx := context.Background()
+
+	res, err := r.ResolveAddress(ctx, netip.MustParseAddr("::ffff:192.0.2.1"), QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []Name{{Name: "host.example"}}; !reflect.DeepEqual(expected, res.Names) {
+		t.Errorf("expected %v, but got %v", expected, res.Names)
+	}
+	if _, err := r.ResolveAddress(ctx, netip.MustParseAddr("2001:db8::1"), QueryOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+}
+
+func TestResolveRecord(t *testing.T) {
+	r := serve(t, func(method string, params json.RawMessage) string {
+		var p struct{ Type RecordType }
+		_ = json.Unmarshal(params, &p)
+		switch p.Type {
+		case TypeMX:
+			return `{"parameters":{"rrs":[{"ifindex":2,"rr":{"key":{"class":1,"type":15,"name":"example"},"priority":10,"exchange":"mail.example"},"raw":"AQID"}],"flags":1}}`
+		case TypeSRV:
+			return `{"parameters":{"rrs":[{"rr":{"key":{"class":1,"type":33,"name":"_ldap._tcp.example"},"priority":0,"weight":5,"port":389,"name":"ldap.example"},"raw":""}],"flags":1}}`
+		case TypeA:
+			return `{"parameters":{"rrs":[{"rr":{"key":{"class":1,"type":1,"name":"example"},"address":[192,0,2,1]},"raw":""}],"flags":1}}`
+		}
+		return `{"error":"io.systemd.Resolve.NoSuchResourceRecord"}`
+	})
+	ctx := context.Background()
+
+	res, err := r.ResolveRecord(ctx, "example", 0, TypeMX, QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(res.Records) != 1 {
+		t.Fatalf("expected 1 record, but got %d", len(res.Records))
+		return
+	}
+	rec := res.Records[0]
+	if rec.Interface != 2 || rec.Name != "example" || rec.Type != TypeMX || rec.Class != ClassIN ||
+		rec.Target != "mail.example" || rec.Priority != 10 || !reflect.DeepEqual(rec.Raw, []byte{1, 2, 3}) {
+		t.Errorf("unexpected record %#v", rec)
+	}
+
+	res, err = r.ResolveRecord(ctx, "_ldap._tcp.example", 0, TypeSRV, QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if rec := res.Records[0]; rec.Target != "ldap.example" || rec.Port != 389 || rec.Weight != 5 {
+		t.Errorf("unexpected record %#v", rec)
+	}
+
+	res, err = r.ResolveRecord(ctx, "example", 0, TypeA, QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected, got := netip.MustParseAddr("192.0.2.1"), res.Records[0].IP; expected != got {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+
+	if _, err := r.ResolveRecord(ctx, "example", 0, TypeTXT, QueryOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	r := serve(t, func(method string, params json.RawMessage) string {
+		var p struct {
+			Name  string
+			Type  RecordType
+			Flags Flags
+		}
+		_ = json.Unmarshal(params, &p)
+		switch {
+		case p.Flags&FlagNoSearch == 0:
+			return `{"error":"io.systemd.Resolve.InvalidReply"}`
+		case p.Name == "missing.example":
+			return `{"error":"io.systemd.Resolve.DNSError","parameters":{"rcode":3}}`
+		case p.Type == TypeA:
+			return `{"parameters":{"rrs":[{"rr":{"key":{"class":1,"type":5,"name":"www.example"},"name":"host.example"},"raw":""},{"rr":{"key":{"class":1,"type":1,"name":"host.example"},"address":[192,0,2,1]},"raw":""}],"flags":513}}`
+		case p.Type == TypeMX:
+			return `{"parameters":{"rrs":[{"rr":{"key":{"class":1,"type":15,"name":"www.example"},"priority":10,"exchange":"mail.example"},"raw":""}],"flags":1}}`
+		case p.Type == TypeTXT:
+			return `{"parameters":{"rrs":[{"rr":{"key":{"class":1,"type":16,"name":"www.example"},"items":["v=spf1 -all"]},"raw":""}],"flags":1}}`
+		}
+		return `{"error":"io.systemd.Resolve.NoSuchResourceRecord"}`
+	})
+	res := r.Resolver(QueryOptions{})
+	ctx := context.Background()
+
+	ips, err := res.LookupNetIP(ctx, "ip", "www.example.")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []netip.Addr{netip.MustParseAddr("192.0.2.1")}; !reflect.DeepEqual(expected, ips) {
+		t.Errorf("expected %v, but got %v", expected, ips)
+	}
+
+	mx, err := res.LookupMX(ctx, "www.example.")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(mx) != 1 || mx[0].Host != "mail.example." || mx[0].Pref != 10 {
+		t.Errorf("unexpected MX records %v", mx)
+	}
+
+	txt, err := res.LookupTXT(ctx, "www.example.")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []string{"v=spf1 -all"}; !reflect.DeepEqual(expected, txt) {
+		t.Errorf("expected %v, but got %v", expected, txt)
+	}
+
+	_, err = res.LookupNetIP(ctx, "ip", "missing.example.")
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("expected a not found error, but got %v", err)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	f := FlagDNS | FlagMDNSIPv6 | FlagAuthenticated | FlagConfidential | FlagFromCache | FlagFromNetwork | 1<<40
+	if expected, got := "dns,mdns-ipv6,authenticated,confidential,cache,network,0x10000000000", f.String(); expected != got {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+	if !f.Authenticated() || !f.Confidential() {
+		t.Errorf("expected %s to be authenticated and confidential", f)
+	}
+	if expected, got := []Protocol{ProtocolDNS, ProtocolMDNS}, f.Protocols(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if expected, got := []Source{SourceCache, SourceNetwork}, f.Sources(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+
+	lo, err := net.InterfaceByIndex(1)
+	if err != nil {
+		t.Skip(err)
+		return
+	}
+	a := Address{Interface: 1, IP: netip.MustParseAddr("fe80::1")}
+	if expected, got := netip.MustParseAddr("fe80::1%"+lo.Name), a.Zoned(); expected != got {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+	a.IP = netip.MustParseAddr("192.0.2.1")
+	if expected, got := a.IP, a.Zoned(); expected != got {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+}
+
+func TestResolveService(t *testing.T) {
+	r := serve(t, func(method string, params json.RawMessage) string {
+		switch method {
+		case "io.systemd.Resolve.ResolveService":
+			var p struct{ Name, Type, Domain string }
+			_ = json.Unmarshal(params, &p)
+			if p.Name != "Office Printer" || p.Type != "_ipp._tcp" || p.Domain != "local" {
+				return `{"error":"io.systemd.Resolve.NoSuchResourceRecord"}`
+			}
+			return `{"parameters":{"services":[{"priority":0,"weight":0,"port":631,"hostname":"printer.local","addresses":[{"ifindex":2,"family":2,"address":[192,168,1,20]}]}],"txt":["txtvers=1","RP=ipp/print","Color=T","rp=ignored"],"canonical":{"name":"Office Printer","type":"_ipp._tcp","domain":"local"},"flags":16}}`
+		case "io.systemd.Resolve.BrowseServices":
+			// Each message is terminated by the server, the call continues
+			// until the client hangs up.
+			return `{"parameters":{"browserServiceData":[{"updateFlag":"added","family":2,"name":"Office Printer","type":"_ipp._tcp","domain":"local","ifindex":2}]},"continues":true}` + "\x00" +
+				`{"parameters":{"browserServiceData":[{"updateFlag":"removed","family":2,"name":"Office Printer","type":"_ipp._tcp","domain":"local","ifindex":2}]},"continues":true}`
+		}
+		return `{"error":"org.varlink.service.MethodNotFound"}`
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := r.ResolveService(ctx, "Office Printer", "_ipp._tcp", "local", QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := []Service{{Port: 631, Hostname: "printer.local", Addresses: []Address{{Interface: 2, IP: netip.MustParseAddr("192.168.1.20")}}}}
+	if !reflect.DeepEqual(expected, res.Services) {
+		t.Errorf("expected %#v, but got %#v", expected, res.Services)
+	}
+	if res.Name != "Office Printer" || res.Type != "_ipp._tcp" || res.Domain != "local" {
+		t.Errorf("unexpected canonical name %q %q %q", res.Name, res.Type, res.Domain)
+	}
+	if expected, got := map[string]string{"txtvers": "1", "rp": "ipp/print", "color": "T"}, res.Attributes(); !reflect.DeepEqual(expected, got) {
+		t.Errorf("expected %v, but got %v", expected, got)
+	}
+	if _, err := r.ResolveService(ctx, "", "_ipp._tcp", "example", QueryOptions{}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected %v, but got %v", ErrNotFound, err)
+	}
+
+	events, err := r.BrowseServices(ctx, "_ipp._tcp", "local", QueryOptions{})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	for _, typ := range []ServiceEventType{ServiceAdded, ServiceRemoved} {
+		select {
+		case ev := <-events:
+			if expected := (ServiceEvent{Type: typ, Interface: 2, Name: "Office Printer", ServiceType: "_ipp._tcp", Domain: "local"}); ev != expected {
+				t.Errorf("expected %#v, but got %#v", expected, ev)
+			}
+		case <-ctx.Done():
+			t.Fatal(ctx.Err())
+			return
+		}
+	}
+	cancel()
+	for range events {
+	}
+}
+
+func TestLink(t *testing.T) {
+	r := serve(t, func(string, json.RawMessage) string { return `{}` })
+	bus, c := dbustest.New(t)
+	r.dialBus = func(context.Context) (*dbus.Conn, error) { return c, nil }
+
+	const path dbus.ObjectPath = "/org/freedesktop/resolve1/link/_32"
+	bus.Handle(managerInterface, "GetLink", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] != int32(2) {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.resolve1.NoSuchLink", Body: []any{"Link 3 not known"}}
+		}
+		return "o", []any{path}, nil
+	})
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		return "a{sv}", []any{map[string]dbus.Variant{
+			"DNSEx": {Sig: "a(iayqs)", Value: []any{
+				dbus.Struct{int32(2), []byte{192, 0, 2, 53}, uint16(0), ""},
+				dbus.Struct{int32(10), netip.MustParseAddr("2001:db8::53").AsSlice(), uint16(853), "dns.example"},
+			}},
+			"CurrentDNSServerEx":         {Sig: "(iayqs)", Value: dbus.Struct{int32(2), []byte{192, 0, 2, 53}, uint16(0), ""}},
+			"Domains":                    {Sig: "a(sb)", Value: []any{dbus.Struct{"corp.example", false}, dbus.Struct{"vpn.example", true}}},
+			"DefaultRoute":               dbus.MakeVariant(false),
+			"LLMNR":                      dbus.MakeVariant("no"),
+			"MulticastDNS":               dbus.MakeVariant("resolve"),
+			"DNSOverTLS":                 dbus.MakeVariant("opportunistic"),
+			"DNSSEC":                     dbus.MakeVariant("allow-downgrade"),
+			"DNSSECNegativeTrustAnchors": {Sig: "as", Value: []string{"internal.example"}},
+			"DNSSECSupported":            dbus.MakeVariant(true),
+		}}, nil
+	})
+	for _, method := range []string{"SetLinkDNSEx", "SetLinkDomains", "SetLinkDNSSEC", "RevertLink"} {
+		bus.Handle(managerInterface, method, func(*dbus.Message) (dbus.Signature, []any, error) { return "", nil, nil })
+	}
+	ctx := context.Background()
+
+	link, err := r.GetLink(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	current := DNSServer{Addr: netip.MustParseAddrPort("192.0.2.53:0")}
+	expected := &Link{
+		Interface: 2,
+		Servers: []DNSServer{
+			current,
+			{Addr: netip.MustParseAddrPort("[2001:db8::53]:853"), ServerName: "dns.example"},
+		},
+		CurrentServer:        &current,
+		Domains:              []Domain{{Name: "corp.example"}, {Name: "vpn.example", RoutingOnly: true}},
+		LLMNR:                ModeNo,
+		MulticastDNS:         ModeResolve,
+		DNSOverTLS:           ModeOpportunistic,
+		DNSSEC:               ModeAllowDowngrade,
+		NegativeTrustAnchors: []string{"internal.example"},
+		DNSSECSupported:      true,
+	}
+	if !reflect.DeepEqual(expected, link) {
+		t.Errorf("expected %#v, but got %#v", expected, link)
+	}
+	var e *Error
+	if _, err := r.GetLink(ctx, 3); !errors.As(err, &e) || e.Name != "org.freedesktop.resolve1.NoSuchLink" {
+		t.Errorf("expected a NoSuchLink error, but got %v", err)
+	}
+
+	if err := r.SetLinkDNS(ctx, 2, expected.Servers); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := r.SetLinkDomains(ctx, 2, []Domain{{Name: ".", RoutingOnly: true}}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := r.SetLinkDNSSEC(ctx, 2, ModeYes); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := r.RevertLink(ctx, 2); err != nil {
+		t.Fatal(err)
+		return
+	}
+	var calls []string
+	for _, m := range bus.Calls() {
+		calls = append(calls, m.Member+" "+string(m.Signature))
+	}
+	if expected := []string{"GetLink i", "GetAll s", "GetLink i", "SetLinkDNSEx ia(iayqs)", "SetLinkDomains ia(sb)", "SetLinkDNSSEC is", "RevertLink i"}; !reflect.DeepEqual(expected, calls) {
+		t.Errorf("expected %v, but got %v", expected, calls)
+	}
+	dns := bus.Calls()[3].Body[1].([]any)
+	if expected := (dbus.Struct{int32(10), netip.MustParseAddr("2001:db8::53").AsSlice(), uint16(853), "dns.example"}); !reflect.DeepEqual(expected, dns[1]) {
+		t.Errorf("expected %v, but got %v", expected, dns[1])
+	}
+}
@@ -0,0 +1,670 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdtime
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCalendar is returned when parsing a calendar event that is not
+// valid.
+var ErrInvalidCalendar = errors.New("sdtime: invalid calendar event")
+
+// maxYear is the last year a calendar event may elapse in, the same as
+// systemd.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/shared/calendarspec.c
+const maxYear = 2199
+
+// weekdayNames are the names of the days of the week, starting with Monday as
+// done by systemd.
+var weekdayNames = [7]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// calendarShorthands are the shorthands of calendar events.
+var calendarShorthands = map[string]string{
+	"minutely":     "*-*-* *:*:00",
+	"hourly":       "*-*-* *:00:00",
+	"daily":        "*-*-* 00:00:00",
+	"monthly":      "*-*-01 00:00:00",
+	"weekly":       "Mon *-*-* 00:00:00",
+	"yearly":       "*-01-01 00:00:00",
+	"annually":     "*-01-01 00:00:00",
+	"quarterly":    "*-01,04,07,10-01 00:00:00",
+	"semiannually": "*-01,07-01 00:00:00",
+}
+
+// Calendar is a calendar event, such as `Mon..Fri *-*-* 09:00`, describing
+// the times a timer with `OnCalendar=` elapses.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.time.html#Calendar%20Events
+type Calendar struct {
+	// weekdays is a bitmask of the allowed days of the week, starting with
+	// Monday as bit 0, or zero to allow all days.
+	weekdays uint8
+
+	year   component
+	month  component
+	day    component
+	hour   component
+	minute component
+	// second is in microseconds.
+	second component
+
+	// endOfMonth is true if days count backwards from the end of the month,
+	// written as `~`.
+	endOfMonth bool
+
+	// Location is the timezone of the calendar event, or nil to use the
+	// location of the time passed to [Calendar.Next].
+	Location *time.Location
+}
+
+// component is a field of a calendar event, such as the hour, matching any
+// value if it has no items.
+type component []item
+
+// item is a value, range, or repetition of a component, `start..stop/repeat`.
+type item struct {
+	start int
+	// stop is the end of a range, or -1.
+	stop int
+	// repeat is the step of a repetition, or zero.
+	repeat int
+}
+
+// next returns the smallest value of the component that is at least v, and
+// whether there is one.
+func (c component) next(v int) (int, bool) {
+	if len(c) == 0 {
+		return v, true
+	}
+	best, ok := 0, false
+	for _, it := range c {
+		n, found := it.next(v)
+		if found && (!ok || n < best) {
+			best, ok = n, true
+		}
+	}
+	return best, ok
+}
+
+func (it item) next(v int) (int, bool) {
+	if v <= it.start {
+		return it.start, true
+	}
+	switch {
+	case it.repeat > 0:
+		n := it.start + it.repeat*((v-it.start+it.repeat-1)/it.repeat)
+		return n, it.stop < 0 || n <= it.stop
+	case it.stop >= 0:
+		return v, v <= it.stop
+	default:
+		return 0, false
+	}
+}
+
+// ParseCalendar parses a calendar event in the syntax of systemd, such as
+// `Mon..Fri *-*-* 09:00`, `*-*-01 00:00:00 Europe/Berlin`, or `weekly`.
+//
+// The event consists of optional days of the week, an optional date, an
+// optional time, and an optional timezone. If the date is omitted, it matches
+// every day, and if the time is omitted, it matches midnight.
+func ParseCalendar(s string) (*Calendar, error) {
+	c, err := parseCalendar(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s): %w", ErrInvalidCalendar, s, err)
+	}
+	return c, nil
+}
+
+func parseCalendar(s string) (*Calendar, error) {
+	c := &Calendar{}
+	if v, ok := calendarShorthands[strings.ToLower(s)]; ok {
+		s = v
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, errors.New("empty calendar event")
+	}
+
+	// The timezone is the last field, if it is not part of the date or time.
+	if last := fields[len(fields)-1]; len(fields) > 1 || !startsWithDigitOrStar(last) {
+		if loc, ok := parseLocation(last); ok {
+			c.Location = loc
+			fields = fields[:len(fields)-1]
+		}
+	}
+
+	if len(fields) > 0 && isLetter(fields[0][0]) {
+		w, err := parseWeekdays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		c.weekdays = w
+		fields = fields[1:]
+	}
+
+	var date, clock string
+	switch len(fields) {
+	case 0:
+		if c.weekdays == 0 {
+			return nil, errors.New("missing date and time")
+		}
+	case 1:
+		if strings.Contains(fields[0], ":") {
+			clock = fields[0]
+		} else {
+			date = fields[0]
+		}
+	case 2:
+		date, clock = fields[0], fields[1]
+	default:
+		return nil, fmt.Errorf("unexpected %q", fields[2])
+	}
+
+	if date != "" {
+		if err := c.parseDate(date); err != nil {
+			return nil, err
+		}
+	}
+	if clock == "" {
+		clock = "00:00:00"
+	}
+	if err := c.parseTime(clock); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseLocation parses a timezone, `UTC` or the name of a timezone such as
+// `Europe/Berlin`.
+func parseLocation(s string) (*time.Location, bool) {
+	if s == "UTC" || s == "Z" {
+		return time.UTC, true
+	}
+	if !isLetter(s[0]) || s == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(s)
+	return loc, err == nil
+}
+
+func startsWithDigitOrStar(s string) bool {
+	return s[0] == '*' || s[0] >= '0' && s[0] <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// parseWeekdays parses a list of days of the week, such as `Mon,Wed..Fri`.
+func parseWeekdays(s string) (uint8, error) {
+	var mask uint8
+	for part := range strings.SplitSeq(s, ",") {
+		from, to, isRange := strings.Cut(part, "..")
+		if !isRange {
+			from, to, isRange = strings.Cut(part, "-")
+		}
+		start, err := parseWeekday(from)
+		if err != nil {
+			return 0, err
+		}
+		stop := start
+		if isRange {
+			if stop, err = parseWeekday(to); err != nil {
+				return 0, err
+			}
+		}
+		// Ranges wrap around the end of the week, such as `Sat..Mon`.
+		for d := start; ; d = (d + 1) % 7 {
+			mask |= 1 << d
+			if d == stop {
+				break
+			}
+		}
+	}
+	return mask, nil
+}
+
+// parseWeekday parses the name of a day of the week, either its full name or
+// its first three letters, returning its index starting with Monday.
+func parseWeekday(s string) (int, error) {
+	for i, name := range weekdayNames {
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day of the week %q", s)
+}
+
+// parseDate parses a date, `[YEAR-]MONTH-DAY` or `[YEAR-]MONTH~DAY` for days
+// counting backwards from the end of the month.
+func (c *Calendar) parseDate(s string) error {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '~' })
+	if i := strings.IndexByte(s, '~'); i >= 0 {
+		// Only the day may count backwards, so `~` must be the last separator.
+		if strings.ContainsAny(s[i+1:], "-~") {
+			return fmt.Errorf("invalid date %q", s)
+		}
+		c.endOfMonth = true
+	}
+	if strings.Count(s, "-")+strings.Count(s, "~") != len(parts)-1 {
+		return fmt.Errorf("invalid date %q", s)
+	}
+
+	year := "*"
+	switch len(parts) {
+	case 2:
+	case 3:
+		year, parts = parts[0], parts[1:]
+	default:
+		return fmt.Errorf("invalid date %q", s)
+	}
+
+	var err error
+	if c.year, err = parseComponent(year, 1970, maxYear, true); err != nil {
+		return fmt.Errorf("invalid year: %w", err)
+	}
+	if c.month, err = parseComponent(parts[0], 1, 12, false); err != nil {
+		return fmt.Errorf("invalid month: %w", err)
+	}
+	if c.day, err = parseComponent(parts[1], 1, 31, false); err != nil {
+		return fmt.Errorf("invalid day: %w", err)
+	}
+	return nil
+}
+
+// parseTime parses a time, `HOUR:MINUTE[:SECOND]`.
+func (c *Calendar) parseTime(s string) error {
+	parts := strings.Split(s, ":")
+	switch len(parts) {
+	case 2:
+		parts = append(parts, "00")
+	case 3:
+	default:
+		return fmt.Errorf("invalid time %q", s)
+	}
+
+	var err error
+	if c.hour, err = parseComponent(parts[0], 0, 23, false); err != nil {
+		return fmt.Errorf("invalid hour: %w", err)
+	}
+	if c.minute, err = parseComponent(parts[1], 0, 59, false); err != nil {
+		return fmt.Errorf("invalid minute: %w", err)
+	}
+	if c.second, err = parseSeconds(parts[2]); err != nil {
+		return fmt.Errorf("invalid second: %w", err)
+	}
+	return nil
+}
+
+// parseComponent parses a component of a calendar event, a comma-separated
+// list of values, ranges (`1..5`), and repetitions (`1/2` or `1..9/2`), or `*`
+// to match any value. Two digit years are in 1970 to 2069.
+func parseComponent(s string, lo, hi int, year bool) (component, error) {
+	return parseItems(s, lo, parseRepeat, func(v string) (int, error) {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || strings.HasPrefix(v, "+") {
+			return 0, fmt.Errorf("invalid value %q", v)
+		}
+		if year && len(v) == 2 {
+			if n < 70 {
+				n += 2000
+			} else {
+				n += 1900
+			}
+		}
+		if n < lo || n > hi {
+			return 0, fmt.Errorf("%d is out of range", n)
+		}
+		return n, nil
+	})
+}
+
+// parseSeconds parses the seconds component, which may have up to six
+// decimal places, returning the values in microseconds.
+func parseSeconds(s string) (component, error) {
+	return parseItems(s, 0, parseMicroseconds, func(v string) (int, error) {
+		us, err := parseMicroseconds(v)
+		if err != nil {
+			return 0, err
+		}
+		if us >= 60*1e6 {
+			return 0, fmt.Errorf("%s is out of range", v)
+		}
+		return us, nil
+	})
+}
+
+// parseRepeat parses the step of a repetition.
+func parseRepeat(v string) (int, error) {
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 || strings.HasPrefix(v, "+") {
+		return 0, fmt.Errorf("invalid repetition %q", v)
+	}
+	return n, nil
+}
+
+// parseMicroseconds parses a number of seconds with up to six decimal places,
+// returning it in microseconds.
+func parseMicroseconds(v string) (int, error) {
+	whole, frac, _ := strings.Cut(v, ".")
+	n, err := strconv.Atoi(whole)
+	if err != nil || n < 0 || n > 1e6 || strings.HasPrefix(whole, "+") || len(frac) > 6 {
+		return 0, fmt.Errorf("invalid value %q", v)
+	}
+	us := n * 1e6
+	if frac != "" {
+		f, err := strconv.Atoi(frac + strings.Repeat("0", 6-len(frac)))
+		if err != nil || f < 0 || strings.HasPrefix(frac, "+") {
+			return 0, fmt.Errorf("invalid value %q", v)
+		}
+		us += f
+	}
+	return us, nil
+}
+
+// parseItems parses the items of a component, using parse for each value and
+// parseRepeat for the steps of repetitions.
+func parseItems(s string, lo int, parseRepeat, parse func(string) (int, error)) (component, error) {
+	if s == "*" {
+		return nil, nil
+	}
+	var c component
+	for part := range strings.SplitSeq(s, ",") {
+		it := item{stop: -1}
+		value, rep, hasRepeat := strings.Cut(part, "/")
+		from, to, isRange := strings.Cut(value, "..")
+
+		var err error
+		if from == "*" && !isRange && hasRepeat {
+			it.start = lo
+		} else if it.start, err = parse(from); err != nil {
+			return nil, err
+		}
+		if isRange {
+			if it.stop, err = parse(to); err != nil {
+				return nil, err
+			}
+			if it.stop < it.start {
+				return nil, fmt.Errorf("range %q is reversed", value)
+			}
+		}
+		if hasRepeat {
+			if it.repeat, err = parseRepeat(rep); err != nil || it.repeat == 0 {
+				return nil, fmt.Errorf("invalid repetition %q", rep)
+			}
+		}
+		c = append(c, it)
+	}
+	slices.SortFunc(c, func(a, b item) int { return a.start - b.start })
+	return c, nil
+}
+
+// Next returns the first time after the given time the calendar event
+// elapses, or the zero time if it never elapses again.
+//
+// If the calendar event has no timezone, it is evaluated in the location of
+// after, use a time in [time.Local] to match the service manager.
+func (c *Calendar) Next(after time.Time) time.Time {
+	loc := c.Location
+	if loc == nil {
+		loc = after.Location()
+	}
+	// Calendar events have a resolution of a microsecond.
+	after = after.In(loc).Truncate(time.Microsecond).Add(time.Microsecond)
+	y, mo, d := after.Date()
+	h, mi, us := after.Hour(), after.Minute(), after.Second()*1e6+after.Nanosecond()/1e3
+
+	// carry normalizes the fields after one of them is incremented past its
+	// end, resetting the smaller fields.
+	carry := func(ny, nmo, nd, nh, nmi int) {
+		t := time.Date(ny, time.Month(nmo), nd, nh, nmi, 0, 0, time.UTC)
+		y, mo, d = t.Date()
+		h, mi, us = t.Hour(), t.Minute(), 0
+	}
+
+	for y <= maxYear {
+		ny, ok := c.year.next(y)
+		if !ok || ny > maxYear {
+			break
+		}
+		if ny != y {
+			carry(ny, 1, 1, 0, 0)
+		}
+
+		nmo, ok := c.month.next(int(mo))
+		if !ok || nmo > 12 {
+			carry(y+1, 1, 1, 0, 0)
+			continue
+		}
+		if nmo != int(mo) {
+			carry(y, nmo, 1, 0, 0)
+		}
+
+		nd, ok := c.nextDay(y, mo, d)
+		if !ok {
+			carry(y, int(mo)+1, 1, 0, 0)
+			continue
+		}
+		if nd != d {
+			carry(y, int(mo), nd, 0, 0)
+		}
+
+		nh, ok := c.hour.next(h)
+		if !ok || nh > 23 {
+			carry(y, int(mo), d+1, 0, 0)
+			continue
+		}
+		if nh != h {
+			carry(y, int(mo), d, nh, 0)
+		}
+
+		nmi, ok := c.minute.next(mi)
+		if !ok || nmi > 59 {
+			carry(y, int(mo), d, h+1, 0)
+			continue
+		}
+		if nmi != mi {
+			carry(y, int(mo), d, h, nmi)
+		}
+
+		nus, ok := c.second.next(us)
+		if !ok || nus >= 60*1e6 {
+			carry(y, int(mo), d, h, mi+1)
+			continue
+		}
+
+		t := time.Date(y, mo, d, h, mi, 0, nus*1e3, loc)
+		if t.Before(after) {
+			// The time does not exist in the location, such as when the
+			// clocks are moved forward, try again after it.
+			us = nus + 1
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// nextDay returns the first day of the month that is at least d and matches
+// both the day and the days of the week of the calendar event.
+func (c *Calendar) nextDay(y int, mo time.Month, d int) (int, bool) {
+	days := time.Date(y, mo+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for ; d <= days; d++ {
+		if !c.matchDay(d, days) {
+			continue
+		}
+		if c.weekdays != 0 {
+			// time.Weekday starts with Sunday, the weekdays start with Monday.
+			wd := (int(time.Date(y, mo, d, 0, 0, 0, 0, time.UTC).Weekday()) + 6) % 7
+			if c.weekdays&(1<<wd) == 0 {
+				continue
+			}
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// matchDay reports whether day d of a month with the given number of days
+// matches the day of the calendar event.
+func (c *Calendar) matchDay(d, days int) bool {
+	if len(c.day) == 0 {
+		return true
+	}
+	if !c.endOfMonth {
+		n, ok := c.day.next(d)
+		return ok && n == d
+	}
+	for _, it := range c.day {
+		// Days count backwards from the end of the month, `~01` is the last
+		// day, and a repetition continues until the end of the month.
+		start := days - it.start + 1
+		lo, hi := start, start
+		switch {
+		case it.stop >= 0:
+			lo = days - it.stop + 1
+		case it.repeat > 0:
+			hi = days
+		}
+		if lo > hi {
+			lo, hi = hi, lo
+		}
+		if d < lo || d > hi {
+			continue
+		}
+		if it.repeat == 0 || (d-lo)%it.repeat == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// All returns an iterator over the times the calendar event elapses after the
+// given time, see [Calendar.Next].
+func (c *Calendar) All(after time.Time) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		for {
+			t := c.Next(after)
+			if t.IsZero() || !yield(t) {
+				return
+			}
+			after = t
+		}
+	}
+}
+
+// String returns the calendar event in its normalized form, the same as
+// `systemd-analyze calendar`, such as `Mon..Fri *-*-* 09:00:00`.
+func (c *Calendar) String() string {
+	var b strings.Builder
+	if c.weekdays != 0 {
+		formatWeekdays(&b, c.weekdays)
+		b.WriteByte(' ')
+	}
+	formatComponent(&b, c.year, 4)
+	b.WriteByte('-')
+	formatComponent(&b, c.month, 2)
+	if c.endOfMonth {
+		b.WriteByte('~')
+	} else {
+		b.WriteByte('-')
+	}
+	formatComponent(&b, c.day, 2)
+	b.WriteByte(' ')
+	formatComponent(&b, c.hour, 2)
+	b.WriteByte(':')
+	formatComponent(&b, c.minute, 2)
+	b.WriteByte(':')
+	formatSeconds(&b, c.second)
+	if c.Location != nil {
+		b.WriteByte(' ')
+		b.WriteString(c.Location.String())
+	}
+	return b.String()
+}
+
+// formatWeekdays writes the days of the week, using ranges for three or more
+// consecutive days.
+func formatWeekdays(b *strings.Builder, mask uint8) {
+	first := true
+	for d := 0; d < 7; d++ {
+		if mask&(1<<d) == 0 {
+			continue
+		}
+		end := d
+		for end+1 < 7 && mask&(1<<(end+1)) != 0 {
+			end++
+		}
+		if !first {
+			b.WriteByte(',')
+		}
+		first = false
+		b.WriteString(weekdayNames[d][:3])
+		switch {
+		case end-d >= 2:
+			b.WriteString("..")
+			b.WriteString(weekdayNames[end][:3])
+			d = end
+		case end-d == 1:
+			b.WriteByte(',')
+			b.WriteString(weekdayNames[end][:3])
+			d = end
+		}
+	}
+}
+
+func formatComponent(b *strings.Builder, c component, width int) {
+	formatItems(b, c, strconv.Itoa, func(v int) string {
+		s := strconv.Itoa(v)
+		return strings.Repeat("0", max(0, width-len(s))) + s
+	})
+}
+
+func formatSeconds(b *strings.Builder, c component) {
+	formatItems(b, c, func(us int) string {
+		s := strconv.Itoa(us / 1e6)
+		if us%1e6 != 0 {
+			s += strings.TrimRight(fmt.Sprintf(".%06d", us%1e6), "0")
+		}
+		return s
+	}, func(us int) string {
+		s := fmt.Sprintf("%02d", us/1e6)
+		if us%1e6 != 0 {
+			s += fmt.Sprintf(".%06d", us%1e6)
+		}
+		return s
+	})
+}
+
+// formatItems writes the items of a component, using formatRepeat for the
+// steps of repetitions and format for the values.
+func formatItems(b *strings.Builder, c component, formatRepeat, format func(int) string) {
+	if len(c) == 0 {
+		b.WriteByte('*')
+		return
+	}
+	for i, it := range c {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(format(it.start))
+		if it.stop >= 0 {
+			b.WriteString("..")
+			b.WriteString(format(it.stop))
+		}
+		if it.repeat > 0 {
+			b.WriteByte('/')
+			b.WriteString(formatRepeat(it.repeat))
+		}
+	}
+}
@@ -0,0 +1,12 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdtime implements the time and date syntax of systemd, such as the
+// calendar events used by `OnCalendar=` in timer units.
+//
+// Like sdunit, sdtime is pure Go and works the same on all operating systems.
+//
+// See [systemd.time(7)] for details.
+//
+// [systemd.time(7)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.time.html
+package sdtime
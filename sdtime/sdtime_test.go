@@ -0,0 +1,219 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseCalendar(t *testing.T) {
+	for s, expected := range map[string]string{
+		"Mon..Fri *-*-* 09:00":         "Mon..Fri *-*-* 09:00:00",
+		"weekly":                       "Mon *-*-* 00:00:00",
+		"quarterly":                    "*-01,04,07,10-01 00:00:00",
+		"Sat,Sun":                      "Sat,Sun *-*-* 00:00:00",
+		"Wed..Sat,Tue 12-10-15 1:2:3":  "Tue..Sat 2012-10-15 01:02:03",
+		"*:0/15":                       "*-*-* *:00/15:00",
+		"*-02~01":                      "*-02~01 00:00:00",
+		"03-05":                        "*-03-05 00:00:00",
+		"12:34:56.789":                 "*-*-* 12:34:56.789000",
+		"*-*-* *:*:0/2.5":              "*-*-* *:*:00/2.5",
+		"2024..2026/2-1,6-1..7 0:0":    "2024..2026/2-01,06-01..07 00:00:00",
+		"Mon *-05~07/1":                "Mon *-05~07/1 00:00:00",
+		"*-*-* 00:00:00 UTC":           "*-*-* 00:00:00 UTC",
+		"fri..mon *-*-* 23:59:59 UTC":  "Mon,Fri..Sun *-*-* 23:59:59 UTC",
+		"  daily  ":                    "*-*-* 00:00:00",
+		"Mon-Wed 2025-*-* 08:00:00":    "Mon..Wed 2025-*-* 08:00:00",
+		"*-*-01/7 *:00":                "*-*-01/7 *:00:00",
+		"Thu,Fri 2012-*-1,5 11:12:13":  "Thu,Fri 2012-*-01,05 11:12:13",
+		"Tuesday,Wednesday *-*-* 4:00": "Tue,Wed *-*-* 04:00:00",
+	} {
+		c, err := ParseCalendar(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if got := c.String(); got != expected {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", s, expected, got)
+		}
+	}
+
+	for _, s := range []string{
+		"",
+		"Funday",
+		"Mon..Fri 25:00",
+		"*-13-01",
+		"*-*-32",
+		"*-*-1~2-3",
+		"*-*-* 12:00 Nowhere/Zone",
+		"*-*-* *:*:60",
+		"*-*-5..1",
+		"*:0/0",
+		"1-2-3-4",
+		"*-*-* 1:2:3:4",
+	} {
+		if _, err := ParseCalendar(s); !errors.Is(err, ErrInvalidCalendar) {
+			t.Errorf("%s: expected %v, but got %v", s, ErrInvalidCalendar, err)
+		}
+	}
+}
+
+func TestCalendarNext(t *testing.T) {
+	date := func(y int, mo time.Month, d, h, mi, s, us int) time.Time {
+		return time.Date(y, mo, d, h, mi, s, us*1e3, time.UTC)
+	}
+	for _, tc := range []struct {
+		spec     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"Mon..Fri *-*-* 09:00", date(2025, time.January, 3, 10, 0, 0, 0), date(2025, time.January, 6, 9, 0, 0, 0)},
+		{"Mon..Fri *-*-* 09:00", date(2025, time.January, 6, 8, 59, 59, 999999), date(2025, time.January, 6, 9, 0, 0, 0)},
+		// The next elapse is strictly after the given time.
+		{"*:00", date(2025, time.January, 1, 10, 0, 0, 0), date(2025, time.January, 1, 11, 0, 0, 0)},
+		{"*:0/15", date(2025, time.January, 1, 10, 7, 30, 0), date(2025, time.January, 1, 10, 15, 0, 0)},
+		{"*:0/15", date(2025, time.January, 1, 23, 50, 0, 0), date(2025, time.January, 2, 0, 0, 0, 0)},
+		{"*-02~01", date(2024, time.January, 15, 0, 0, 0, 0), date(2024, time.February, 29, 0, 0, 0, 0)},
+		{"*-02~01", date(2025, time.January, 15, 0, 0, 0, 0), date(2025, time.February, 28, 0, 0, 0, 0)},
+		// The last Monday of May.
+		{"Mon *-05~07/1", date(2025, time.January, 1, 0, 0, 0, 0), date(2025, time.May, 26, 0, 0, 0, 0)},
+		{"*-*-31", date(2025, time.February, 1, 0, 0, 0, 0), date(2025, time.March, 31, 0, 0, 0, 0)},
+		{"*-02-29", date(2025, time.March, 1, 0, 0, 0, 0), date(2028, time.February, 29, 0, 0, 0, 0)},
+		{"Fri *-*-13", date(2025, time.January, 1, 0, 0, 0, 0), date(2025, time.June, 13, 0, 0, 0, 0)},
+		{"yearly", date(2025, time.December, 31, 23, 59, 59, 0), date(2026, time.January, 1, 0, 0, 0, 0)},
+		{"*-*-* *:*:0/2.5", date(2025, time.January, 1, 0, 0, 56, 0), date(2025, time.January, 1, 0, 0, 57, 500000)},
+		{"2020-01-01", date(2021, time.January, 1, 0, 0, 0, 0), time.Time{}},
+	} {
+		c, err := ParseCalendar(tc.spec)
+		if err != nil {
+			t.Errorf("%s: %v", tc.spec, err)
+			continue
+		}
+		if got := c.Next(tc.after); !got.Equal(tc.expected) {
+			t.Errorf("%s after %s: expected %s, but got %s", tc.spec, tc.after, tc.expected, got)
+		}
+	}
+
+	// Calendar events with a timezone are evaluated in it.
+	c, err := ParseCalendar("*-*-* 12:00 UTC")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	after := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.FixedZone("UTC+14", 14*60*60))
+	if got, expected := c.Next(after), date(2024, time.December, 31, 12, 0, 0, 0); !got.Equal(expected) {
+		t.Errorf("expected %s, but got %s", expected, got)
+	}
+
+	c, err = ParseCalendar("hourly")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	var got []time.Time
+	for next := range c.All(date(2025, time.January, 1, 22, 30, 0, 0)) {
+		got = append(got, next)
+		if len(got) == 3 {
+			break
+		}
+	}
+	expected := []time.Time{
+		date(2025, time.January, 1, 23, 0, 0, 0),
+		date(2025, time.January, 2, 0, 0, 0, 0),
+		date(2025, time.January, 2, 1, 0, 0, 0),
+	}
+	for i := range expected {
+		if i >= len(got) || !got[i].Equal(expected[i]) {
+			t.Errorf("expected %v, but got %v", expected, got)
+			break
+		}
+	}
+}
+
+func TestParseTimespan(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"90":          90 * time.Second,
+		"1min 30s":    90 * time.Second,
+		"1h30min":     90 * time.Minute,
+		"2h 30min":    150 * time.Minute,
+		"1w3d":        10 * 24 * time.Hour,
+		"250ms":       250 * time.Millisecond,
+		"1.5s":        1500 * time.Millisecond,
+		"0.1s":        100 * time.Millisecond,
+		".5min":       30 * time.Second,
+		"2 days 3 h":  51 * time.Hour,
+		"5 minutes":   5 * time.Minute,
+		"10us":        10 * time.Microsecond,
+		"10µs":        10 * time.Microsecond,
+		"3 sec 5msec": 3005 * time.Millisecond,
+		"1y":          31557600 * time.Second,
+		"1M":          2629800 * time.Second,
+		"infinity":    Infinity,
+		" 5s ":        5 * time.Second,
+	} {
+		got, err := ParseTimespan(s)
+		if err != nil {
+			t.Errorf("%s: %v", s, err)
+			continue
+		}
+		if got != expected {
+			t.Errorf("%s: expected %v, but got %v", s, expected, got)
+		}
+	}
+
+	if got, err := ParseTimespanUnit("500", time.Microsecond); err != nil || got != 500*time.Microsecond {
+		t.Errorf("expected %v, but got %v (%v)", 500*time.Microsecond, got, err)
+	}
+
+	for _, s := range []string{"", "abc", "5 parsecs", "1..2s", "-5s", "999999999999y"} {
+		if _, err := ParseTimespan(s); !errors.Is(err, ErrInvalidTimespan) {
+			t.Errorf("%s: expected %v, but got %v", s, ErrInvalidTimespan, err)
+		}
+	}
+}
+
+func TestFormatTimespan(t *testing.T) {
+	for _, tc := range []struct {
+		d        time.Duration
+		accuracy time.Duration
+		expected string
+	}{
+		{0, 0, "0"},
+		{Infinity, 0, "infinity"},
+		{5 * time.Second, 0, "5s"},
+		{90 * time.Second, 0, "1min 30s"},
+		{150 * time.Minute, 0, "2h 30min"},
+		{10 * 24 * time.Hour, 0, "1w 3d"},
+		{30*time.Second + 500*time.Millisecond, 0, "30.500000s"},
+		{30*time.Second + 500*time.Millisecond, time.Millisecond, "30.500s"},
+		{30*time.Second + 500*time.Millisecond, time.Second, "30s"},
+		{90*time.Second + 500*time.Millisecond, time.Millisecond, "1min 30.500s"},
+		{250 * time.Millisecond, 0, "250ms"},
+		{1500 * time.Microsecond, 0, "1.500ms"},
+		{999 * time.Millisecond, time.Second, "0"},
+		{31557600*time.Second + 2629800*time.Second, 0, "1y 1month"},
+	} {
+		if got := FormatTimespan(tc.d, tc.accuracy); got != tc.expected {
+			t.Errorf("%v: expected \"%s\", but got \"%s\"", tc.d, tc.expected, got)
+		}
+	}
+
+	// Formatted time spans parse back to the same duration.
+	for _, d := range []time.Duration{time.Microsecond, 90 * time.Second, 36*time.Hour + 1500*time.Millisecond, 400 * 24 * time.Hour} {
+		got, err := ParseTimespan(FormatTimespan(d, 0))
+		if err != nil || got != d {
+			t.Errorf("%v: expected %v, but got %v (%v)", d, d, got, err)
+		}
+	}
+
+	var ts Timespan
+	if err := ts.UnmarshalText([]byte("1h 5min")); err != nil || time.Duration(ts) != 65*time.Minute {
+		t.Errorf("expected %v, but got %v (%v)", 65*time.Minute, time.Duration(ts), err)
+	}
+	if b, _ := ts.MarshalText(); string(b) != "1h 5min" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "1h 5min", b)
+	}
+}
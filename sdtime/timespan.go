@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdtime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTimespan is returned when parsing a time span that is not valid.
+var ErrInvalidTimespan = errors.New("sdtime: invalid time span")
+
+// Infinity is the time span systemd writes as `infinity`, such as to disable
+// a timeout.
+const Infinity time.Duration = math.MaxInt64
+
+const (
+	day   = 24 * time.Hour
+	week  = 7 * day
+	month = 2629800 * time.Second
+	year  = 31557600 * time.Second
+)
+
+// timespanUnits are the units of a time span, longest suffixes first so they
+// are matched before their prefixes.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/time-util.c
+var timespanUnits = []struct {
+	suffix string
+	d      time.Duration
+}{
+	{"seconds", time.Second},
+	{"second", time.Second},
+	{"minutes", time.Minute},
+	{"minute", time.Minute},
+	{"months", month},
+	{"month", month},
+	{"hours", time.Hour},
+	{"hour", time.Hour},
+	{"weeks", week},
+	{"week", week},
+	{"years", year},
+	{"year", year},
+	{"days", day},
+	{"day", day},
+	{"msec", time.Millisecond},
+	{"usec", time.Microsecond},
+	{"nsec", time.Nanosecond},
+	{"sec", time.Second},
+	{"min", time.Minute},
+	{"hr", time.Hour},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+	{"µs", time.Microsecond},
+	{"μs", time.Microsecond},
+	{"ns", time.Nanosecond},
+	{"s", time.Second},
+	{"m", time.Minute},
+	{"h", time.Hour},
+	{"d", day},
+	{"w", week},
+	{"M", month},
+	{"y", year},
+}
+
+// formatUnits are the units used by [FormatTimespan], largest first.
+var formatUnits = []struct {
+	suffix string
+	d      time.Duration
+}{
+	{"y", year},
+	{"month", month},
+	{"w", week},
+	{"d", day},
+	{"h", time.Hour},
+	{"min", time.Minute},
+	{"s", time.Second},
+	{"ms", time.Millisecond},
+	{"us", time.Microsecond},
+}
+
+// ParseTimespan parses a time span in the syntax of systemd, such as
+// `2h 30min`, `1w3d`, or `infinity`, as used by settings such as
+// `TimeoutStartSec=`. Numbers without a unit are in seconds.
+//
+// A time span of `infinity` is returned as [Infinity].
+func ParseTimespan(s string) (time.Duration, error) {
+	return ParseTimespanUnit(s, time.Second)
+}
+
+// ParseTimespanUnit is the same as [ParseTimespan] except that numbers without
+// a unit are in the given unit, such as [time.Microsecond] for settings like
+// `RuntimeMaxUSec=` of the D-Bus API.
+func ParseTimespanUnit(s string, unit time.Duration) (time.Duration, error) {
+	d, err := parseTimespan(strings.TrimSpace(s), unit)
+	if err != nil {
+		return 0, fmt.Errorf("%w (%s): %w", ErrInvalidTimespan, s, err)
+	}
+	return d, nil
+}
+
+func parseTimespan(s string, unit time.Duration) (time.Duration, error) {
+	if s == "infinity" {
+		return Infinity, nil
+	}
+	if s == "" {
+		return 0, errors.New("empty time span")
+	}
+
+	var total time.Duration
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i < 0 {
+			i = len(s)
+		}
+		whole, frac, _ := strings.Cut(s[:i], ".")
+		if whole == "" && frac == "" || strings.Contains(frac, ".") {
+			return 0, fmt.Errorf("unexpected %q", s)
+		}
+		s = strings.TrimLeft(s[i:], " ")
+
+		d := unit
+		for _, u := range timespanUnits {
+			rest, ok := strings.CutPrefix(s, u.suffix)
+			if ok && (rest == "" || rest[0] == ' ' || rest[0] >= '0' && rest[0] <= '9' || rest[0] == '.') {
+				d, s = u.d, rest
+				break
+			}
+		}
+
+		var n time.Duration
+		if whole != "" {
+			w, err := strconv.ParseInt(whole, 10, 64)
+			if err != nil || w > int64(Infinity/d) {
+				return 0, errors.New("time span is too large")
+			}
+			n = time.Duration(w) * d
+		}
+		// Fractions are exact up to the resolution of the unit.
+		f := d
+		for _, c := range frac {
+			f /= 10
+			n += time.Duration(c-'0') * f
+		}
+		if n > Infinity-total {
+			return 0, errors.New("time span is too large")
+		}
+		total += n
+		s = strings.TrimLeft(s, " ")
+	}
+	return total, nil
+}
+
+// FormatTimespan formats a time span in the syntax of systemd, the same as
+// `systemd-analyze timespan`, such as `2h 30min` or `1min 30.500s`. The time
+// span is rounded down to the given accuracy, such as [time.Second] to leave
+// out fractions of seconds, zero shows the time span with the resolution of a
+// microsecond.
+//
+// [Infinity] is formatted as `infinity`, and time spans of zero or less as
+// `0`.
+func FormatTimespan(d, accuracy time.Duration) string {
+	if d == Infinity {
+		return "infinity"
+	}
+	if accuracy < time.Microsecond {
+		accuracy = time.Microsecond
+	}
+	if d < accuracy {
+		return "0"
+	}
+
+	var b strings.Builder
+	for _, u := range formatUnits {
+		if d < accuracy {
+			break
+		}
+		if d < u.d {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		a, rest := d/u.d, d%u.d
+		b.WriteString(strconv.FormatInt(int64(a), 10))
+
+		// Time spans below a minute show the rest as a fraction, such as
+		// `30.500s` rather than `30s 500ms`.
+		if d < time.Minute && rest >= accuracy && u.d > accuracy {
+			digits := 0
+			for p := u.d; p > accuracy; p /= 10 {
+				digits++
+			}
+			frac := rest / (u.d / pow10(digits))
+			b.WriteByte('.')
+			fs := strconv.FormatInt(int64(frac), 10)
+			b.WriteString(strings.Repeat("0", digits-len(fs)) + fs)
+			b.WriteString(u.suffix)
+			break
+		}
+		b.WriteString(u.suffix)
+		d = rest
+	}
+	return b.String()
+}
+
+func pow10(n int) time.Duration {
+	p := time.Duration(1)
+	for range n {
+		p *= 10
+	}
+	return p
+}
+
+// Timespan is a [time.Duration] that is marshaled as a time span in the syntax
+// of systemd, such as for configuration files mirroring unit settings.
+type Timespan time.Duration
+
+// String implements [fmt.Stringer] using [FormatTimespan].
+func (t Timespan) String() string {
+	return FormatTimespan(time.Duration(t), 0)
+}
+
+// MarshalText implements [encoding.TextMarshaler].
+func (t Timespan) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler] using [ParseTimespan].
+func (t *Timespan) UnmarshalText(b []byte) error {
+	d, err := ParseTimespan(string(b))
+	if err != nil {
+		return err
+	}
+	*t = Timespan(d)
+	return nil
+}
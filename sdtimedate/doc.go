@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdtimedate provides a client for systemd-timedated, which manages
+// the system clock, timezone, and time synchronization, the same as
+// `timedatectl`.
+//
+// NOTE: this package is only useful on `linux` operating systems. Calling any
+// functions exposed by this package on other operating systems returns
+// [errors.ErrUnsupported].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/org.freedesktop.timedate1.html
+package sdtimedate
@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build !linux
+
+package sdtimedate
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type Conn struct{}
+
+func New(context.Context) (*Conn, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) Close() error { return errors.ErrUnsupported }
+
+func (*Conn) Status(context.Context) (*Status, error) { return nil, errors.ErrUnsupported }
+
+func (*Conn) SetTimezone(context.Context, string) error { return errors.ErrUnsupported }
+
+func (*Conn) SetLocalRTC(context.Context, bool, bool) error { return errors.ErrUnsupported }
+
+func (*Conn) SetNTP(context.Context, bool) error { return errors.ErrUnsupported }
+
+func (*Conn) SetTime(context.Context, time.Time) error { return errors.ErrUnsupported }
+
+func (*Conn) ListTimezones(context.Context) ([]string, error) { return nil, errors.ErrUnsupported }
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdtimedate
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+	"github.com/matthewpi/sd/internal/dbus/dbustest"
+)
+
+func newTestConn(t *testing.T) (*dbustest.Bus, *Conn) {
+	t.Helper()
+	bus, c := dbustest.New(t)
+	return bus, newConn(c)
+}
+
+func TestTimedate(t *testing.T) {
+	bus, td := newTestConn(t)
+
+	var (
+		mu    sync.Mutex
+		props = map[string]dbus.Variant{
+			"Timezone":        dbus.MakeVariant("UTC"),
+			"LocalRTC":        dbus.MakeVariant(false),
+			"CanNTP":          dbus.MakeVariant(true),
+			"NTP":             dbus.MakeVariant(false),
+			"NTPSynchronized": dbus.MakeVariant(false),
+			"TimeUSec":        dbus.MakeVariant(uint64(1700000000000000)),
+			"RTCTimeUSec":     dbus.MakeVariant(uint64(0)),
+		}
+	)
+	bus.Handle("org.freedesktop.DBus.Properties", "GetAll", func(*dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return "a{sv}", []any{props}, nil
+	})
+	bus.Handle(iface, "SetTimezone", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		if m.Body[0] != "Europe/Berlin" {
+			return "", nil, &dbus.Error{Name: "org.freedesktop.DBus.Error.InvalidArgs", Body: []any{"Invalid or not installed time zone"}}
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		props["Timezone"] = dbus.MakeVariant(m.Body[0])
+		return "", nil, nil
+	})
+	bus.Handle(iface, "SetNTP", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		props["NTP"] = dbus.MakeVariant(m.Body[0])
+		return "", nil, nil
+	})
+	bus.Handle(iface, "SetLocalRTC", func(m *dbus.Message) (dbus.Signature, []any, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		props["LocalRTC"] = dbus.MakeVariant(m.Body[0])
+		return "", nil, nil
+	})
+	bus.Handle(iface, "SetTime", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "", nil, &dbus.Error{Name: "org.freedesktop.timedate1.AutomaticTimeSyncEnabled", Body: []any{"Automatic time synchronization is enabled"}}
+	})
+	bus.Handle(iface, "ListTimezones", func(*dbus.Message) (dbus.Signature, []any, error) {
+		return "as", []any{[]any{"Europe/Berlin", "UTC"}}, nil
+	})
+
+	ctx := context.Background()
+	if err := td.SetTimezone(ctx, "Mars/Olympus_Mons"); !errors.Is(err, ErrInvalid) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrInvalid, err)
+	}
+	if err := td.SetTimezone(ctx, "Europe/Berlin"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := td.SetNTP(ctx, true); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := td.SetLocalRTC(ctx, true, false); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := td.SetTime(ctx, time.Now()); !errors.Is(err, ErrNTPEnabled) {
+		t.Errorf("expected \"%v\", but got \"%v\"", ErrNTPEnabled, err)
+	}
+
+	s, err := td.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := Status{
+		Timezone: "Europe/Berlin",
+		LocalRTC: true,
+		CanNTP:   true,
+		NTP:      true,
+		Time:     time.UnixMicro(1700000000000000),
+	}
+	if *s != expected {
+		t.Errorf("expected %+v, but got %+v", expected, *s)
+	}
+
+	zones, err := td.ListTimezones(ctx)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []string{"Europe/Berlin", "UTC"}; !reflect.DeepEqual(expected, zones) {
+		t.Errorf("expected %v, but got %v", expected, zones)
+	}
+
+	for _, m := range bus.Calls() {
+		if m.Member == "SetLocalRTC" && !reflect.DeepEqual(m.Body, []any{true, false, false}) {
+			t.Errorf("unexpected arguments to SetLocalRTC %v", m.Body)
+		}
+	}
+}
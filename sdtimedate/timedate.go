@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdtimedate
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAccessDenied is returned when the caller is not authorized to change the
+// time configuration.
+var ErrAccessDenied = errors.New("sdtimedate: access denied")
+
+// ErrInvalid is returned when a value is rejected by timedated, such as an
+// unknown timezone.
+var ErrInvalid = errors.New("sdtimedate: invalid value")
+
+// ErrNTPUnsupported is returned when enabling NTP while no time
+// synchronization service is installed.
+var ErrNTPUnsupported = errors.New("sdtimedate: NTP not supported")
+
+// ErrNTPEnabled is returned when setting the time while NTP is enabled.
+var ErrNTPEnabled = errors.New("sdtimedate: automatic time synchronization is enabled")
+
+// Error is an error returned by timedated.
+type Error struct {
+	// Name is the name of the error, such as
+	// `org.freedesktop.timedate1.NoNTPSupport`.
+	Name string
+	// Message is the human-readable message of the error.
+	Message string
+}
+
+// Error implements [error].
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return "sdtimedate: " + e.Name
+	}
+	return "sdtimedate: " + e.Message
+}
+
+// Is allows the error to be matched against the sentinel errors of this
+// package using [errors.Is].
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrAccessDenied:
+		switch e.Name {
+		case "org.freedesktop.DBus.Error.AccessDenied", "org.freedesktop.DBus.Error.InteractiveAuthorizationRequired":
+			return true
+		}
+		return false
+	case ErrInvalid:
+		return e.Name == "org.freedesktop.DBus.Error.InvalidArgs"
+	case ErrNTPUnsupported:
+		return e.Name == "org.freedesktop.timedate1.NoNTPSupport"
+	case ErrNTPEnabled:
+		return e.Name == "org.freedesktop.timedate1.AutomaticTimeSyncEnabled"
+	default:
+		return false
+	}
+}
+
+// Status is the time configuration and synchronization status of the system,
+// as returned by [Conn.Status].
+type Status struct {
+	// Timezone is the name of the system timezone, such as `Europe/Berlin`.
+	Timezone string
+	// LocalRTC is true if the real-time clock is kept in local time instead of
+	// UTC.
+	LocalRTC bool
+	// CanNTP is true if a time synchronization service is available.
+	CanNTP bool
+	// NTP is true if the time synchronization service is enabled.
+	NTP bool
+	// NTPSynchronized is true if the kernel considers the system clock to be
+	// synchronized.
+	NTPSynchronized bool
+	// Time is the time of the system clock.
+	Time time.Time
+	// RTCTime is the time of the real-time clock, or the zero time if the
+	// system has no real-time clock.
+	RTCTime time.Time
+}
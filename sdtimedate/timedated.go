@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+//go:build linux
+
+package sdtimedate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/matthewpi/sd/internal/dbus"
+)
+
+const (
+	// destination is the bus name of timedated.
+	destination = "org.freedesktop.timedate1"
+
+	// objectPath is the object path of timedated.
+	objectPath dbus.ObjectPath = "/org/freedesktop/timedate1"
+
+	// iface is the interface of timedated.
+	iface = "org.freedesktop.timedate1"
+)
+
+// Conn is a connection to systemd-timedated on the system bus.
+type Conn struct {
+	conn *dbus.Conn
+}
+
+// New connects to timedated on the system bus.
+func New(ctx context.Context) (*Conn, error) {
+	c, err := dbus.SystemBus(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("sdtimedate: unable to connect to bus: %w", err)
+	}
+	return newConn(c), nil
+}
+
+// newConn returns a new [Conn] using an established D-Bus connection.
+func newConn(c *dbus.Conn) *Conn {
+	return &Conn{conn: c}
+}
+
+// Close closes the connection to timedated.
+func (t *Conn) Close() error {
+	return t.conn.Close()
+}
+
+// convertError converts a D-Bus error into an [Error].
+func convertError(err error) error {
+	var e *dbus.Error
+	if errors.As(err, &e) {
+		return &Error{Name: e.Name, Message: e.Message()}
+	}
+	return err
+}
+
+// call calls a method on timedated, returning the body of the reply.
+func (t *Conn) call(ctx context.Context, method string, sig dbus.Signature, args ...any) ([]any, error) {
+	body, err := t.conn.Call(ctx, destination, objectPath, iface, method, sig, args...)
+	return body, convertError(err)
+}
+
+// Status returns the time configuration and synchronization status of the
+// system, the same as `timedatectl status`.
+func (t *Conn) Status(ctx context.Context) (*Status, error) {
+	props, err := t.conn.GetAllProperties(ctx, destination, objectPath, iface)
+	if err != nil {
+		return nil, fmt.Errorf("sdtimedate: unable to get properties: %w", convertError(err))
+	}
+	s := &Status{}
+	s.Timezone, _ = props["Timezone"].Value.(string)
+	s.LocalRTC, _ = props["LocalRTC"].Value.(bool)
+	s.CanNTP, _ = props["CanNTP"].Value.(bool)
+	s.NTP, _ = props["NTP"].Value.(bool)
+	s.NTPSynchronized, _ = props["NTPSynchronized"].Value.(bool)
+	if usec, ok := props["TimeUSec"].Value.(uint64); ok && usec > 0 {
+		s.Time = time.UnixMicro(int64(usec))
+	}
+	if usec, ok := props["RTCTimeUSec"].Value.(uint64); ok && usec > 0 {
+		s.RTCTime = time.UnixMicro(int64(usec))
+	}
+	return s, nil
+}
+
+// SetTimezone sets the system timezone, such as `Europe/Berlin`, see
+// [Conn.ListTimezones] for the valid names.
+func (t *Conn) SetTimezone(ctx context.Context, timezone string) error {
+	if _, err := t.call(ctx, "SetTimezone", "sb", timezone, false); err != nil {
+		return fmt.Errorf("sdtimedate: unable to set timezone: %w", err)
+	}
+	return nil
+}
+
+// SetLocalRTC sets whether the real-time clock is kept in local time instead
+// of UTC. If fixSystem is true, the system clock is set from the real-time
+// clock, otherwise the real-time clock is set from the system clock.
+func (t *Conn) SetLocalRTC(ctx context.Context, local, fixSystem bool) error {
+	if _, err := t.call(ctx, "SetLocalRTC", "bbb", local, fixSystem, false); err != nil {
+		return fmt.Errorf("sdtimedate: unable to set local RTC: %w", err)
+	}
+	return nil
+}
+
+// SetNTP enables or disables the time synchronization service.
+//
+// If no time synchronization service is available, an error matching
+// [ErrNTPUnsupported] is returned.
+func (t *Conn) SetNTP(ctx context.Context, enabled bool) error {
+	if _, err := t.call(ctx, "SetNTP", "bb", enabled, false); err != nil {
+		return fmt.Errorf("sdtimedate: unable to set NTP: %w", err)
+	}
+	return nil
+}
+
+// SetTime sets the system clock, along with the real-time clock.
+//
+// If the time synchronization service is enabled, an error matching
+// [ErrNTPEnabled] is returned.
+func (t *Conn) SetTime(ctx context.Context, tm time.Time) error {
+	if _, err := t.call(ctx, "SetTime", "xbb", tm.UnixMicro(), false, false); err != nil {
+		return fmt.Errorf("sdtimedate: unable to set time: %w", err)
+	}
+	return nil
+}
+
+// ListTimezones returns the names of the timezones known to the system, the
+// same as `timedatectl list-timezones`.
+func (t *Conn) ListTimezones(ctx context.Context) ([]string, error) {
+	body, err := t.call(ctx, "ListTimezones", "")
+	if err != nil {
+		return nil, fmt.Errorf("sdtimedate: unable to list timezones: %w", err)
+	}
+	if len(body) != 1 {
+		return nil, fmt.Errorf("sdtimedate: unable to list timezones: unexpected reply of %d values", len(body))
+	}
+	values, _ := body[0].([]any)
+	zones := make([]string, 0, len(values))
+	for _, v := range values {
+		if s, ok := v.(string); ok {
+			zones = append(zones, s)
+		}
+	}
+	return zones, nil
+}
@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+// Package sdunit provides utilities for working with systemd units, such as
+// escaping strings for use in unit names and rendering and parsing unit files.
+//
+// Unlike the other packages in this module, sdunit is pure Go and works the
+// same on all operating systems.
+//
+// See [systemd.unit(5)] for details.
+//
+// [systemd.unit(5)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html
+package sdunit
@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// DropIn is a drop-in of a unit, a `.conf` file in the `<unit>.d/` directory
+// overriding or adding to the settings of the unit.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html#Description
+type DropIn struct {
+	// Path is the path of the drop-in, such as
+	// `/etc/systemd/system/app.service.d/override.conf`.
+	Path string
+	File *File
+}
+
+// dropInPath returns the path of a drop-in of a unit in dir.
+func dropInPath(dir, unit, name string) (string, error) {
+	if !IsValidName(unit) {
+		return "", fmt.Errorf("sdunit: invalid unit name (%s)", unit)
+	}
+	if name == "" || strings.ContainsRune(name, '/') || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("sdunit: invalid drop-in name (%s)", name)
+	}
+	if !strings.HasSuffix(name, ".conf") {
+		name += ".conf"
+	}
+	return filepath.Join(dir, unit+".d", name), nil
+}
+
+// ReadDropIn reads a drop-in of a unit in dir, such as `override` for
+// `<dir>/<unit>.d/override.conf`. An empty file is returned if the drop-in
+// does not exist.
+func ReadDropIn(dir, unit, name string) (*File, error) {
+	p, err := dropInPath(dir, unit, name)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &File{}, nil
+		}
+		return nil, fmt.Errorf("sdunit: unable to read drop-in: %w", err)
+	}
+	f, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return nil, fmt.Errorf("sdunit: unable to parse drop-in (%s): %w", p, err)
+	}
+	return f, nil
+}
+
+// WriteDropIn writes a drop-in of a unit in dir, creating the drop-in
+// directory if needed. The drop-in is replaced atomically if it exists.
+//
+// The service manager must be reloaded for the drop-in to take effect.
+func WriteDropIn(dir, unit, name string, f *File) error {
+	p, err := dropInPath(dir, unit, name)
+	if err != nil {
+		return err
+	}
+	b, err := f.MarshalText()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("sdunit: unable to create drop-in directory: %w", err)
+	}
+	return writeFileAtomic(p, b)
+}
+
+// EditDropIn reads a drop-in of a unit in dir, passes it to edit, and writes
+// it back, similar to `systemctl edit`. Settings already in the drop-in are
+// kept unless edit changes them, use [Section.Set] to override a setting.
+//
+// If the drop-in has no settings after being edited, it is removed using
+// [RemoveDropIn].
+func EditDropIn(dir, unit, name string, edit func(f *File) error) error {
+	f, err := ReadDropIn(dir, unit, name)
+	if err != nil {
+		return err
+	}
+	if err := edit(f); err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(f.Sections, func(s *Section) bool {
+		return slices.ContainsFunc(s.Entries, func(e Entry) bool { return e.Key != "" })
+	}) {
+		return RemoveDropIn(dir, unit, name)
+	}
+	return WriteDropIn(dir, unit, name, f)
+}
+
+// RemoveDropIn removes a drop-in of a unit in dir, along with the drop-in
+// directory if it is left empty. Removing a drop-in that does not exist is not
+// an error.
+func RemoveDropIn(dir, unit, name string) error {
+	p, err := dropInPath(dir, unit, name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("sdunit: unable to remove drop-in: %w", err)
+	}
+	// Fails if the directory is not empty, which is expected.
+	_ = os.Remove(filepath.Dir(p))
+	return nil
+}
+
+// ReadDropIns reads all the drop-ins of a unit in the given directories, in the
+// order the service manager applies them.
+//
+// Directories are in order of priority, such as `/etc/systemd/system` before
+// `/usr/lib/systemd/system`. Drop-ins are ordered by their file name, a
+// drop-in hides drop-ins with the same file name in directories of a lower
+// priority. For instances of templates, the drop-ins of the template, such as
+// `app@.service.d/`, are also read.
+func ReadDropIns(unit string, dirs ...string) ([]DropIn, error) {
+	if !IsValidName(unit) {
+		return nil, fmt.Errorf("sdunit: invalid unit name (%s)", unit)
+	}
+	names := []string{unit}
+	if prefix, suffix, ok := strings.Cut(unit, "@"); ok {
+		if i := strings.LastIndexByte(suffix, '.'); i > 0 {
+			names = append(names, prefix+"@"+suffix[i:])
+		}
+	}
+
+	found := make(map[string]string)
+	for _, dir := range slices.Backward(dirs) {
+		for _, name := range slices.Backward(names) {
+			entries, err := os.ReadDir(filepath.Join(dir, name+".d"))
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return nil, fmt.Errorf("sdunit: unable to read drop-in directory: %w", err)
+			}
+			for _, e := range entries {
+				if e.IsDir() || !strings.HasSuffix(e.Name(), ".conf") || strings.HasPrefix(e.Name(), ".") {
+					continue
+				}
+				// Directories of a higher priority are read last, replacing
+				// drop-ins with the same name.
+				found[e.Name()] = filepath.Join(dir, name+".d", e.Name())
+			}
+		}
+	}
+
+	dropIns := make([]DropIn, 0, len(found))
+	for _, name := range slices.Sorted(maps.Keys(found)) {
+		p := found[name]
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("sdunit: unable to read drop-in: %w", err)
+		}
+		f, err := Parse(bytes.NewReader(b))
+		if err != nil {
+			return nil, fmt.Errorf("sdunit: unable to parse drop-in (%s): %w", p, err)
+		}
+		dropIns = append(dropIns, DropIn{Path: p, File: f})
+	}
+	return dropIns, nil
+}
+
+// MergeDropIns returns the unit with the settings of the drop-ins applied, as
+// done by the service manager. The base unit and drop-ins are not modified.
+//
+// Settings of drop-ins are appended to the same section of the unit, so a
+// setting with a single value is overridden, and a setting with a list of
+// values is added to unless it is reset with an empty assignment first. See
+// [Section.Value] and [Section.Values].
+func MergeDropIns(base *File, dropIns ...*File) *File {
+	merged := &File{Comments: slices.Clone(base.Comments)}
+	for _, f := range append([]*File{base}, dropIns...) {
+		for _, s := range f.Sections {
+			m := merged.AddSection(s.Name)
+			for _, e := range s.Entries {
+				if e.Key != "" {
+					m.Entries = append(m.Entries, e)
+				}
+			}
+		}
+	}
+	return merged
+}
+
+// CheckDropIn checks a drop-in against the unit it applies to, the result of
+// [MergeDropIns] if the unit has other drop-ins, for settings that conflict
+// with the unit or have no effect.
+//
+// Mistakes that are errors are sections the unit does not have, other than
+// `[Unit]` and `[Install]`, and `ExecStart=` being added to a service that
+// already has one without resetting it first. Settings assigned the value
+// they already have in the unit are reported as warnings.
+func CheckDropIn(base, dropIn *File) []Diagnostic {
+	var diags []Diagnostic
+	report := func(sev Severity, section, key, format string, args ...any) {
+		diags = append(diags, Diagnostic{Severity: sev, Section: section, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, s := range dropIn.Sections {
+		bs := base.Section(s.Name)
+		if bs == nil {
+			if s.Name != "Unit" && s.Name != "Install" && !strings.HasPrefix(s.Name, "X-") {
+				report(SeverityError, s.Name, "", "section does not exist in the unit")
+			}
+			bs = &Section{Name: s.Name}
+		}
+
+		reset := make(map[string]bool)
+		for _, e := range s.Entries {
+			if e.Key == "" {
+				continue
+			}
+			if e.Value == "" {
+				reset[e.Key] = true
+				continue
+			}
+			if s.Name == "Service" && e.Key == "ExecStart" && !reset[e.Key] && len(bs.Values(e.Key)) > 0 {
+				typ, _ := MergeDropIns(base, dropIn).Value("Service", "Type")
+				if typ != string(ServiceOneshot) {
+					report(SeverityError, s.Name, e.Key, "adds a second command to the unit, reset it with an empty ExecStart= first")
+					continue
+				}
+			}
+			if v, ok := bs.Value(e.Key); ok && v == e.Value && !reset[e.Key] {
+				report(SeverityWarning, s.Name, e.Key, "already set to %q in the unit", v)
+			}
+		}
+	}
+	return diags
+}
@@ -0,0 +1,253 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// unitNameMax is the maximum length of a unit name, including the suffix.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/unit-name.h#L9
+const unitNameMax = 255
+
+// ErrInvalidEscape is returned when unescaping a string that contains an
+// invalid escape sequence.
+var ErrInvalidEscape = errors.New("sdunit: invalid escape sequence")
+
+// unitTypes are the suffixes of all the unit types supported by systemd.
+var unitTypes = []string{
+	".service",
+	".socket",
+	".target",
+	".device",
+	".mount",
+	".automount",
+	".swap",
+	".timer",
+	".path",
+	".slice",
+	".scope",
+}
+
+// isValidChar reports whether c may be used in a unit name without being
+// escaped.
+func isValidChar(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		c == ':' || c == '_' || c == '.'
+}
+
+// escapeChar writes the `\xNN` escape sequence for c to b.
+func escapeChar(b *strings.Builder, c byte) {
+	const hex = "0123456789abcdef"
+	b.WriteString(`\x`)
+	b.WriteByte(hex[c>>4])
+	b.WriteByte(hex[c&0xf])
+}
+
+// Escape escapes a string for use in a unit name, equivalent to
+// `systemd-escape`.
+//
+// Forward slashes are replaced with dashes, any characters other than ASCII
+// letters, digits, `:`, `_` and `.` are replaced with a C-style `\xNN` escape
+// sequence. A leading `.` is also escaped, to prevent hidden unit names.
+func Escape(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := range len(s) {
+		c := s[i]
+		switch {
+		case c == '/':
+			b.WriteByte('-')
+		case c == '.' && i == 0, !isValidChar(c):
+			escapeChar(&b, c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// Unescape reverses [Escape], equivalent to `systemd-escape --unescape`.
+func Unescape(s string) (string, error) {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '-':
+			b.WriteByte('/')
+		case '\\':
+			if i+3 >= len(s) || s[i+1] != 'x' {
+				return "", ErrInvalidEscape
+			}
+			hi, ok1 := unhex(s[i+2])
+			lo, ok2 := unhex(s[i+3])
+			if !ok1 || !ok2 {
+				return "", ErrInvalidEscape
+			}
+			b.WriteByte(hi<<4 | lo)
+			i += 3
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
+
+// unhex returns the value of a single hexadecimal digit.
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+// EscapePath escapes a path for use in a unit name, equivalent to
+// `systemd-escape --path`.
+//
+// The path is simplified first, then leading and trailing slashes are removed
+// before being escaped with [Escape]. The root directory is escaped as `-`.
+//
+// An error is returned if the path contains `.` or `..` components after being
+// simplified, such as a relative path that traverses upwards.
+func EscapePath(p string) (string, error) {
+	p = path.Clean("/" + p)
+	if p == "/" {
+		return "-", nil
+	}
+	p = strings.Trim(p, "/")
+	for elem := range strings.SplitSeq(p, "/") {
+		if elem == "." || elem == ".." {
+			return "", fmt.Errorf("sdunit: path is not normalized (%s)", p)
+		}
+	}
+	return Escape(p), nil
+}
+
+// UnescapePath reverses [EscapePath], equivalent to
+// `systemd-escape --unescape --path`. The returned path is always absolute.
+func UnescapePath(s string) (string, error) {
+	if s == "" {
+		return "", ErrInvalidEscape
+	}
+	if s == "-" {
+		return "/", nil
+	}
+	p, err := Unescape(s)
+	if err != nil {
+		return "", err
+	}
+	return path.Clean("/" + p), nil
+}
+
+// Instance returns the name of an instance of a template unit, equivalent to
+// `systemd-escape --template=<template> <instance>`. The instance is escaped
+// using [Escape].
+//
+// The template must be a valid template unit name, such as `getty@.service`.
+func Instance(template, instance string) (string, error) {
+	prefix, suffix, ok := strings.Cut(template, "@")
+	if !ok || prefix == "" || !strings.HasPrefix(suffix, ".") || !IsValidName(template) {
+		return "", fmt.Errorf("sdunit: invalid template unit name (%s)", template)
+	}
+	name := prefix + "@" + Escape(instance) + suffix
+	if len(name) > unitNameMax {
+		return "", fmt.Errorf("sdunit: unit name is too long (%s)", name)
+	}
+	return name, nil
+}
+
+// IsValidName reports whether name is a valid unit name, a plain unit name such
+// as `foo.service`, a template such as `foo@.service`, or an instance of a
+// template such as `foo@bar.service`.
+func IsValidName(name string) bool {
+	if name == "" || len(name) > unitNameMax {
+		return false
+	}
+	prefix, ok := cutUnitType(name)
+	if !ok || prefix == "" {
+		return false
+	}
+	if strings.Count(prefix, "@") > 1 || prefix[0] == '@' {
+		return false
+	}
+	for i := range len(prefix) {
+		c := prefix[i]
+		if !isValidChar(c) && c != '-' && c != '\\' && c != '@' {
+			return false
+		}
+	}
+	return true
+}
+
+// cutUnitType returns name without its unit type suffix, and whether it had a
+// valid unit type suffix.
+func cutUnitType(name string) (string, bool) {
+	for _, suffix := range unitTypes {
+		if prefix, ok := strings.CutSuffix(name, suffix); ok {
+			return prefix, true
+		}
+	}
+	return name, false
+}
+
+// Mangle turns an arbitrary string into a valid unit name, equivalent to
+// `systemd-escape --mangle`.
+//
+// If name is already a valid unit name, it is returned as-is. Absolute paths
+// are escaped with [EscapePath] and given a `.device` suffix if they are under
+// `/dev`, or `.mount` otherwise. Anything else has its invalid characters
+// escaped and is given the provided suffix (such as `.service`) if it does not
+// already have a valid unit type suffix.
+func Mangle(name, suffix string) (string, error) {
+	if !strings.HasPrefix(suffix, ".") {
+		return "", fmt.Errorf("sdunit: invalid unit suffix (%s)", suffix)
+	}
+	if IsValidName(name) {
+		return name, nil
+	}
+
+	if path.IsAbs(name) {
+		p, err := EscapePath(name)
+		if err != nil {
+			return "", err
+		}
+		if name == "/dev" || strings.HasPrefix(name, "/dev/") {
+			return p + ".device", nil
+		}
+		return p + ".mount", nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(name) + len(suffix))
+	for i := range len(name) {
+		c := name[i]
+		if isValidChar(c) || c == '-' || c == '\\' || c == '@' {
+			b.WriteByte(c)
+			continue
+		}
+		escapeChar(&b, c)
+	}
+	if _, ok := cutUnitType(name); !ok {
+		b.WriteString(suffix)
+	}
+
+	mangled := b.String()
+	if !IsValidName(mangled) {
+		return "", fmt.Errorf("sdunit: unable to mangle unit name (%s)", name)
+	}
+	return mangled, nil
+}
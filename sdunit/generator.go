@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GeneratorDir is one of the output directories of a generator, which differ
+// in the priority of the units written to them.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.generator.html#Output%20directories
+type GeneratorDir int
+
+const (
+	// GeneratorNormal is the directory for units overridden by units in
+	// `/etc`, but overriding units in `/usr`.
+	GeneratorNormal GeneratorDir = iota
+	// GeneratorEarly is the directory for units overriding all other units,
+	// including units in `/etc`.
+	GeneratorEarly
+	// GeneratorLate is the directory for units overridden by all other units,
+	// including units in `/usr`.
+	GeneratorLate
+)
+
+// Generator is a [systemd.generator(7)], a program run by the service manager
+// early during boot and on every reload to generate units dynamically, such as
+// from a configuration file.
+//
+// Generators must not start units, talk to D-Bus, or block on anything other
+// than local files, as they are run before most of the system is available.
+//
+// [systemd.generator(7)]: https://www.freedesktop.org/software/systemd/man/latest/systemd.generator.html
+type Generator struct {
+	// Dirs are the output directories, indexed by [GeneratorDir].
+	Dirs [3]string
+
+	// Scope is the scope of the service manager running the generator,
+	// either `system` or `user`, from `SYSTEMD_SCOPE`.
+	Scope string
+	// InInitrd is true if the generator is run in the initrd, from
+	// `SYSTEMD_IN_INITRD`.
+	InInitrd bool
+	// FirstBoot is true if this is the first boot of the system, from
+	// `SYSTEMD_FIRST_BOOT`.
+	FirstBoot bool
+	// Virtualization is the detected virtualization, such as `vm:kvm` or
+	// `container:docker`, from `SYSTEMD_VIRTUALIZATION`.
+	Virtualization string
+	// Architecture is the architecture of the system, such as `x86-64`, from
+	// `SYSTEMD_ARCHITECTURE`.
+	Architecture string
+
+	// Logger writes to the kernel log buffer, as the journal is not running
+	// yet when generators are run, falling back to stderr.
+	Logger *slog.Logger
+}
+
+// NewGenerator returns a [Generator] using the output directories passed as
+// arguments by the service manager, such as `os.Args[1:]`.
+//
+// The service manager always passes three directories, if none are passed the
+// directories of the system service manager in `/run/systemd` are used, which
+// is useful for testing a generator manually.
+func NewGenerator(args []string) (*Generator, error) {
+	g := &Generator{
+		Scope:          os.Getenv("SYSTEMD_SCOPE"),
+		InInitrd:       isTrue(os.Getenv("SYSTEMD_IN_INITRD")),
+		FirstBoot:      isTrue(os.Getenv("SYSTEMD_FIRST_BOOT")),
+		Virtualization: os.Getenv("SYSTEMD_VIRTUALIZATION"),
+		Architecture:   os.Getenv("SYSTEMD_ARCHITECTURE"),
+	}
+	switch len(args) {
+	case 0:
+		g.Dirs = [3]string{"/run/systemd/generator", "/run/systemd/generator.early", "/run/systemd/generator.late"}
+	case 3:
+		copy(g.Dirs[:], args)
+	default:
+		return nil, fmt.Errorf("sdunit: generator expects 0 or 3 arguments, got %d", len(args))
+	}
+	for _, d := range g.Dirs {
+		if !filepath.IsAbs(d) {
+			return nil, fmt.Errorf("sdunit: generator output directory is not absolute (%s)", d)
+		}
+	}
+
+	var w io.Writer = os.Stderr
+	if f, err := os.OpenFile("/dev/kmsg", os.O_WRONLY|os.O_APPEND, 0); err == nil {
+		w = f
+	}
+	g.Logger = slog.New(&kmsgHandler{
+		w:     w,
+		mu:    &sync.Mutex{},
+		ident: filepath.Base(os.Args[0]) + "[" + strconv.Itoa(os.Getpid()) + "]",
+	})
+	return g, nil
+}
+
+// RunGenerator runs fn as a generator using the arguments of the process,
+// logging the returned error and exiting with a non-zero status on failure.
+// It is intended to be called from `main`.
+func RunGenerator(fn func(g *Generator) error) {
+	g, err := NewGenerator(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+		return
+	}
+	if err := fn(g); err != nil {
+		g.Logger.LogAttrs(context.Background(), slog.LevelError, "generator failed", slog.Any("err", err))
+		os.Exit(1)
+		return
+	}
+}
+
+// WriteUnit writes a unit file to an output directory, such as the result of
+// [Service.MarshalText]. The file is replaced atomically if it exists.
+func (g *Generator) WriteUnit(dir GeneratorDir, name string, data []byte) error {
+	if !IsValidName(name) {
+		return fmt.Errorf("sdunit: invalid unit name (%s)", name)
+	}
+	if err := os.MkdirAll(g.Dirs[dir], 0o755); err != nil {
+		return fmt.Errorf("sdunit: unable to create output directory: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(g.Dirs[dir], name), data)
+}
+
+// WriteDropIn writes a drop-in of a unit to an output directory, such as
+// `<unit>.d/<name>.conf`, adding the `.conf` suffix to the name if missing.
+func (g *Generator) WriteDropIn(dir GeneratorDir, unit, name string, data []byte) error {
+	p, err := dropInPath(g.Dirs[dir], unit, name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return fmt.Errorf("sdunit: unable to create drop-in directory: %w", err)
+	}
+	return writeFileAtomic(p, data)
+}
+
+// Wants adds a `Wants=` dependency from target to unit, such as to start a
+// generated unit during boot, by linking it in the `.wants/` directory of the
+// target. The unit must be in the same output directory.
+func (g *Generator) Wants(dir GeneratorDir, target, unit string) error {
+	return g.link(dir, target+".wants", unit)
+}
+
+// Requires adds a `Requires=` dependency from target to unit, see
+// [Generator.Wants].
+func (g *Generator) Requires(dir GeneratorDir, target, unit string) error {
+	return g.link(dir, target+".requires", unit)
+}
+
+// link links a unit in a dependency directory of the output directory.
+func (g *Generator) link(dir GeneratorDir, depDir, unit string) error {
+	if !IsValidName(unit) {
+		return fmt.Errorf("sdunit: invalid unit name (%s)", unit)
+	}
+	if !IsValidName(strings.TrimSuffix(strings.TrimSuffix(depDir, ".wants"), ".requires")) {
+		return fmt.Errorf("sdunit: invalid unit name (%s)", depDir)
+	}
+	d := filepath.Join(g.Dirs[dir], depDir)
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return fmt.Errorf("sdunit: unable to create dependency directory: %w", err)
+	}
+	return symlinkAtomic(filepath.Join("..", unit), filepath.Join(d, unit))
+}
+
+// writeFileAtomic writes a file by writing to a temporary file in the same
+// directory and renaming it, so readers never see a partially written file.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".*")
+	if err != nil {
+		return fmt.Errorf("sdunit: unable to create file: %w", err)
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("sdunit: unable to write file (%s): %w", name, err)
+	}
+	return nil
+}
+
+// symlinkAtomic creates a symlink, replacing any existing file at name.
+func symlinkAtomic(target, name string) error {
+	tmp := filepath.Join(filepath.Dir(name), "."+filepath.Base(name)+".tmp")
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("sdunit: unable to create symlink (%s): %w", name, err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("sdunit: unable to create symlink (%s): %w", name, err)
+	}
+	return nil
+}
+
+// kmsgHandler is a [slog.Handler] writing records in the format expected by
+// `/dev/kmsg`, `<priority>ident: message key=value`.
+type kmsgHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex
+	ident string
+	// attrs are the formatted attributes added with WithAttrs.
+	attrs string
+	group string
+}
+
+// Enabled implements [slog.Handler]. Debug records are only written if
+// `SYSTEMD_LOG_LEVEL=debug` is set, as done by systemd.
+func (h *kmsgHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level > slog.LevelDebug || os.Getenv("SYSTEMD_LOG_LEVEL") == "debug"
+}
+
+// Handle implements [slog.Handler].
+func (h *kmsgHandler) Handle(_ context.Context, r slog.Record) error {
+	var prio int
+	switch {
+	case r.Level >= slog.LevelError:
+		prio = 3
+	case r.Level >= slog.LevelWarn:
+		prio = 4
+	case r.Level >= slog.LevelInfo:
+		prio = 6
+	default:
+		prio = 7
+	}
+
+	var b strings.Builder
+	b.WriteString("<" + strconv.Itoa(prio) + ">" + h.ident + ": ")
+	// Each write is a single record, newlines would be written as-is.
+	b.WriteString(strings.ReplaceAll(r.Message, "\n", " "))
+	b.WriteString(h.attrs)
+	r.Attrs(func(a slog.Attr) bool {
+		b.WriteString(formatAttr(h.group, a))
+		return true
+	})
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+// WithAttrs implements [slog.Handler].
+func (h *kmsgHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	for _, a := range attrs {
+		h2.attrs += formatAttr(h.group, a)
+	}
+	return &h2
+}
+
+// WithGroup implements [slog.Handler].
+func (h *kmsgHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.group += name + "."
+	return &h2
+}
+
+// formatAttr formats an attribute as ` key=value`, quoting the value if needed.
+func formatAttr(group string, a slog.Attr) string {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return ""
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if a.Key != "" {
+			group += a.Key + "."
+		}
+		var s string
+		for _, ga := range attrs {
+			s += formatAttr(group, ga)
+		}
+		return s
+	}
+	v := a.Value.String()
+	if v == "" || strings.ContainsFunc(v, func(r rune) bool { return r <= ' ' || r == '"' || r == '=' }) {
+		v = strconv.Quote(v)
+	}
+	return " " + group + a.Key + "=" + v
+}
@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Needs are what a service needs from the system, used by [Service.Harden] to
+// recommend the strictest sandboxing the service still works with.
+type Needs struct {
+	// AddressFamilies are the socket address families the service uses, such
+	// as `AF_INET`, `AF_INET6`, and `AF_UNIX`. A service that uses none of
+	// them is not given network access, sockets passed to it with socket
+	// activation keep working.
+	AddressFamilies []string
+	// ReadPaths are paths the service reads outside of the OS, such as
+	// under `/home`. Everything else is readable unless it is hidden by the
+	// sandboxing.
+	ReadPaths []string
+	// WritePaths are the paths the service writes to, other than the
+	// directories of [Service.StateDirectory] and friends which are always
+	// writable.
+	WritePaths []string
+	// Capabilities are the capabilities the service needs, such as
+	// `CAP_NET_BIND_SERVICE`. All other capabilities are dropped.
+	Capabilities []string
+	// SystemCalls are system calls or groups the service needs in addition
+	// to `@system-service`, such as `@mount`.
+	SystemCalls []string
+	// Devices is whether the service needs access to physical devices.
+	Devices bool
+	// WriteExecute is whether the service needs memory that is both writable
+	// and executable, such as for a JIT compiler. Go programs do not.
+	WriteExecute bool
+
+	// Exempt are sandboxing settings [Service.Harden] must not change, such as
+	// `ProtectClock` for a service that sets the time. The names are the
+	// same as the fields of [Sandboxing] and the settings of systemd.
+	Exempt []string
+}
+
+// homePaths are the directories hidden by `ProtectHome=yes`.
+var homePaths = []string{"/home", "/root", "/run/user"}
+
+// recommend returns the recommended sandboxing for a service with the given
+// needs, and whether the service runs as a user other than root.
+func (n *Needs) recommend(unprivileged bool) Sandboxing {
+	sb := Sandboxing{
+		NoNewPrivileges:         true,
+		ProtectSystem:           "strict",
+		ProtectHome:             "yes",
+		ReadWritePaths:          slices.Clone(n.WritePaths),
+		PrivateTmp:              true,
+		PrivateDevices:          !n.Devices,
+		ProtectKernelTunables:   true,
+		ProtectKernelModules:    true,
+		ProtectKernelLogs:       true,
+		ProtectControlGroups:    true,
+		ProtectClock:            true,
+		ProtectHostname:         true,
+		RestrictNamespaces:      true,
+		RestrictRealtime:        true,
+		RestrictSUIDSGID:        true,
+		LockPersonality:         true,
+		MemoryDenyWriteExecute:  !n.WriteExecute,
+		CapabilityBoundingSet:   slices.Clone(n.Capabilities),
+		SystemCallFilter:        append([]string{"@system-service"}, n.SystemCalls...),
+		SystemCallArchitectures: []string{"native"},
+	}
+	for _, p := range slices.Concat(n.ReadPaths, n.WritePaths) {
+		if slices.ContainsFunc(homePaths, func(home string) bool { return p == home || strings.HasPrefix(p, home+"/") }) {
+			sb.ProtectHome = "read-only"
+			break
+		}
+	}
+	if len(n.AddressFamilies) == 0 {
+		sb.PrivateNetwork = true
+		sb.RestrictAddressFamilies = []string{"none"}
+	} else {
+		sb.RestrictAddressFamilies = slices.Sorted(slices.Values(n.AddressFamilies))
+	}
+	if unprivileged {
+		sb.AmbientCapabilities = slices.Clone(n.Capabilities)
+	}
+	return sb
+}
+
+// Harden sets the sandboxing of the service to the strictest settings the
+// service still works with given its needs, an alternative to going through
+// the output of `systemd-analyze security` by hand.
+//
+// All the settings of [Service.Sandboxing] are replaced except for
+// [Sandboxing.DynamicUser] and the settings in [Needs.Exempt]. If the service
+// needs no capabilities, an empty `CapabilityBoundingSet=` is added to
+// [Service.Extra] to drop all of them.
+//
+// Capabilities are only made ambient if the service runs as another user, with
+// [Service.User] or [Sandboxing.DynamicUser].
+func (s *Service) Harden(needs Needs) error {
+	sb := reflect.ValueOf(&s.Sandboxing).Elem()
+	for _, key := range needs.Exempt {
+		if key == "DynamicUser" || !sb.FieldByName(key).IsValid() {
+			return fmt.Errorf("sdunit: unknown sandboxing setting (%s)", key)
+		}
+	}
+
+	rec := needs.recommend(s.User != "" || s.Sandboxing.DynamicUser)
+	rec.DynamicUser = s.Sandboxing.DynamicUser
+	recValue := reflect.ValueOf(&rec).Elem()
+	for i := range sb.NumField() {
+		if !slices.Contains(needs.Exempt, sb.Type().Field(i).Name) {
+			sb.Field(i).Set(recValue.Field(i))
+		}
+	}
+
+	if len(needs.Capabilities) == 0 && !slices.Contains(needs.Exempt, "CapabilityBoundingSet") &&
+		!slices.ContainsFunc(s.Extra, func(d Directive) bool { return d.Section == "Service" && d.Key == "CapabilityBoundingSet" }) {
+		s.Extra = append(s.Extra, Directive{Section: "Service", Key: "CapabilityBoundingSet"})
+	}
+	return nil
+}
+
+// CheckHardening checks the sandboxing of an existing service unit against
+// the settings recommended by [Service.Harden] for the given needs, reporting
+// each setting that is missing or differs from the recommendation as a
+// warning.
+func CheckHardening(f *File, needs Needs) ([]Diagnostic, error) {
+	s := &Service{}
+	s.User, _ = f.Value("Service", "User")
+	if v, ok := f.Value("Service", "DynamicUser"); ok {
+		s.Sandboxing.DynamicUser = isTrue(v)
+	}
+	if err := s.Harden(needs); err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	report := func(key, format string, args ...any) {
+		diags = append(diags, Diagnostic{Severity: SeverityWarning, Section: "Service", Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	sb := reflect.ValueOf(&s.Sandboxing).Elem()
+	for i := range sb.NumField() {
+		key, field := sb.Type().Field(i).Name, sb.Field(i)
+		if key == "DynamicUser" || slices.Contains(needs.Exempt, key) {
+			continue
+		}
+		v, ok := f.Value("Service", key)
+		switch field.Kind() {
+		case reflect.Bool:
+			if field.Bool() && !isTrue(v) {
+				report(key, "recommended to be enabled")
+			}
+		case reflect.String:
+			if expected := field.String(); expected != "" && v != expected {
+				report(key, "recommended to be set to %q", expected)
+			}
+		case reflect.Slice:
+			expected := field.Interface().([]string)
+			if len(expected) == 0 {
+				if key == "CapabilityBoundingSet" && (!ok || v != "") {
+					report(key, "recommended to be empty to drop all capabilities")
+				}
+				continue
+			}
+			var got []string
+			for _, v := range f.Values("Service", key) {
+				words, err := SplitWords(v)
+				if err != nil {
+					continue
+				}
+				got = append(got, words...)
+			}
+			slices.Sort(got)
+			if !slices.Equal(slices.Compact(got), slices.Sorted(slices.Values(expected))) {
+				report(key, "recommended to be set to %q", strings.Join(expected, " "))
+			}
+		}
+	}
+	return diags, nil
+}
@@ -0,0 +1,453 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// lineMax is the maximum length of a line in a unit file, including any
+// continuation lines.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/def.h
+const lineMax = 1024 * 1024
+
+// ErrSyntax is returned when parsing a unit file or a value that is not valid.
+var ErrSyntax = errors.New("sdunit: syntax error")
+
+// File is a parsed unit file.
+//
+// Sections that appear more than once are merged, and settings keep the order
+// they were written in, including repeated settings. Comments are kept so a
+// modified file can be written back with [File.MarshalText].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.syntax.html
+type File struct {
+	// Comments are the comments before the first section.
+	Comments []string
+	Sections []*Section
+}
+
+// Section is a section of a unit file, such as `[Service]`.
+type Section struct {
+	Name string
+	// Entries are the settings and comments of the section, in order.
+	Entries []Entry
+}
+
+// Entry is a setting or a comment in a section.
+type Entry struct {
+	// Key is the name of the setting, or empty for a comment.
+	Key string
+	// Value is the value of the setting as written, with continuation lines
+	// joined and surrounding whitespace removed, or the comment including its
+	// leading `#` or `;`. Values are not unquoted, use [SplitWords] or
+	// [SplitCommands] for settings that take a list of words.
+	Value string
+}
+
+// Parse parses a unit file.
+func Parse(r io.Reader) (*File, error) {
+	f := &File{}
+	var (
+		cur  *Section
+		cont strings.Builder
+		n    int
+	)
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), lineMax)
+	for s.Scan() {
+		n++
+		line := s.Text()
+		if n == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if cont.Len() > 0 {
+			// Comments within continued lines are ignored.
+			if t := strings.TrimLeft(line, " \t"); strings.HasPrefix(t, "#") || strings.HasPrefix(t, ";") {
+				continue
+			}
+		} else {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "":
+				continue
+			case line[0] == '#' || line[0] == ';':
+				if cur == nil {
+					f.Comments = append(f.Comments, line)
+				} else {
+					cur.Entries = append(cur.Entries, Entry{Value: line})
+				}
+				continue
+			}
+		}
+
+		if continues(line) {
+			cont.WriteString(line[:len(line)-1])
+			cont.WriteByte(' ')
+			if cont.Len() > lineMax {
+				return nil, fmt.Errorf("%w: line %d: line is too long", ErrSyntax, n)
+			}
+			continue
+		}
+		if cont.Len() > 0 {
+			cont.WriteString(line)
+			line = strings.TrimSpace(cont.String())
+			cont.Reset()
+		}
+
+		if line[0] == '[' {
+			name, ok := strings.CutSuffix(line[1:], "]")
+			if !ok || name == "" || strings.ContainsAny(name, "[]") {
+				return nil, fmt.Errorf("%w: line %d: invalid section header %q", ErrSyntax, n, line)
+			}
+			cur = f.AddSection(name)
+			continue
+		}
+		if cur == nil {
+			return nil, fmt.Errorf("%w: line %d: setting outside of a section", ErrSyntax, n)
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: line %d: missing '='", ErrSyntax, n)
+		}
+		cur.Entries = append(cur.Entries, Entry{Key: key, Value: strings.TrimSpace(value)})
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("sdunit: unable to read unit file: %w", err)
+	}
+	if cont.Len() > 0 {
+		return nil, fmt.Errorf("%w: line %d: unterminated line continuation", ErrSyntax, n)
+	}
+	return f, nil
+}
+
+// continues reports whether a line ends with an unescaped backslash, continuing
+// on the next line.
+func continues(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], parsing a unit file
+// using [Parse].
+func (f *File) UnmarshalText(b []byte) error {
+	parsed, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	*f = *parsed
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler], rendering the unit file.
+//
+// Values are written as-is, they must be quoted and escaped as needed.
+func (f *File) MarshalText() ([]byte, error) {
+	w := &unitWriter{}
+	for _, c := range f.Comments {
+		w.comment(c)
+	}
+	for _, s := range f.Sections {
+		if s.Name == "" || strings.ContainsAny(s.Name, "[]\n\r") {
+			w.fail(fmt.Errorf("%w: section name %q", ErrInvalidValue, s.Name))
+		}
+		w.section(s.Name)
+		for _, e := range s.Entries {
+			if e.Key == "" {
+				w.comment(e.Value)
+				continue
+			}
+			if strings.ContainsAny(e.Key, "=[#; \t") {
+				w.fail(fmt.Errorf("%w: setting name %q", ErrInvalidValue, e.Key))
+			}
+			w.line(e.Key, e.Value)
+		}
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// comment writes a comment, adding a leading `#` if it has none.
+func (w *unitWriter) comment(c string) {
+	if strings.ContainsAny(c, "\n\r") {
+		w.fail(fmt.Errorf("%w: comment %q", ErrInvalidValue, c))
+		return
+	}
+	if !strings.HasPrefix(c, "#") && !strings.HasPrefix(c, ";") {
+		c = "# " + c
+	}
+	w.buf.WriteString(c)
+	w.buf.WriteByte('\n')
+}
+
+// Section returns the section with the given name, or nil if there is none.
+func (f *File) Section(name string) *Section {
+	for _, s := range f.Sections {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// AddSection returns the section with the given name, adding it to the end of
+// the file if there is none.
+func (f *File) AddSection(name string) *Section {
+	if s := f.Section(name); s != nil {
+		return s
+	}
+	s := &Section{Name: name}
+	f.Sections = append(f.Sections, s)
+	return s
+}
+
+// Value returns the value of a setting in a section, see [Section.Value].
+func (f *File) Value(section, key string) (string, bool) {
+	s := f.Section(section)
+	if s == nil {
+		return "", false
+	}
+	return s.Value(key)
+}
+
+// Values returns the values of a setting in a section, see [Section.Values].
+func (f *File) Values(section, key string) []string {
+	s := f.Section(section)
+	if s == nil {
+		return nil
+	}
+	return s.Values(key)
+}
+
+// Value returns the last value of a setting, for settings where each
+// assignment replaces the previous one, such as `Type=`.
+func (s *Section) Value(key string) (string, bool) {
+	for i := len(s.Entries) - 1; i >= 0; i-- {
+		if s.Entries[i].Key == key {
+			return s.Entries[i].Value, true
+		}
+	}
+	return "", false
+}
+
+// Values returns the values of a setting, for settings where each assignment
+// adds to the list of values, such as `After=` or `ExecStart=`.
+//
+// Assigning an empty value resets the list, only the values assigned after
+// the last empty assignment are returned.
+func (s *Section) Values(key string) []string {
+	var values []string
+	for _, e := range s.Entries {
+		if e.Key != key {
+			continue
+		}
+		if e.Value == "" {
+			values = values[:0]
+			continue
+		}
+		values = append(values, e.Value)
+	}
+	return values
+}
+
+// Set replaces all the assignments of a setting with a single value, in place
+// of the first assignment or at the end of the section if there is none.
+func (s *Section) Set(key, value string) {
+	i := slices.IndexFunc(s.Entries, func(e Entry) bool { return e.Key == key })
+	if i < 0 {
+		s.Add(key, value)
+		return
+	}
+	s.Entries[i].Value = value
+	s.Entries = slices.Concat(s.Entries[:i+1], slices.DeleteFunc(s.Entries[i+1:], func(e Entry) bool { return e.Key == key }))
+}
+
+// Add adds an assignment of a setting after its last assignment, or at the end
+// of the section if there is none.
+func (s *Section) Add(key, value string) {
+	i := len(s.Entries)
+	for j := len(s.Entries) - 1; j >= 0; j-- {
+		if s.Entries[j].Key == key {
+			i = j + 1
+			break
+		}
+	}
+	s.Entries = slices.Insert(s.Entries, i, Entry{Key: key, Value: value})
+}
+
+// Del removes all the assignments of a setting.
+func (s *Section) Del(key string) {
+	s.Entries = slices.DeleteFunc(s.Entries, func(e Entry) bool { return e.Key == key })
+}
+
+// SplitWords splits a value into words, removing quotes and resolving escape
+// sequences, as done by systemd for settings that take a list, such as
+// `After=` or `Environment=`.
+//
+// Specifiers and environment variable references are left as-is, use
+// [Expander.Expand] to expand specifiers.
+func SplitWords(value string) ([]string, error) {
+	words, _, err := splitWords(value)
+	return words, err
+}
+
+// SplitCommands splits the value of a command line setting, such as
+// `ExecStart=`, into its commands. Commands are separated by a lone `;`, use
+// `\;` to pass a literal `;` as an argument.
+//
+// Prefixes of the command, such as `-` or `+`, are kept in the first word of
+// each command. Specifiers and environment variable references are left as-is.
+func SplitCommands(value string) ([]Command, error) {
+	words, bare, err := splitWords(value)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		cmds []Command
+		cmd  Command
+	)
+	for i, w := range words {
+		if w == ";" && bare[i] {
+			if len(cmd) == 0 {
+				return nil, fmt.Errorf("%w: empty command in %q", ErrSyntax, value)
+			}
+			cmds = append(cmds, cmd)
+			cmd = nil
+			continue
+		}
+		cmd = append(cmd, w)
+	}
+	if len(cmd) > 0 {
+		cmds = append(cmds, cmd)
+	}
+	return cmds, nil
+}
+
+// splitWords splits a value into words, also returning whether each word was
+// written without quotes or escapes.
+func splitWords(value string) ([]string, []bool, error) {
+	var (
+		words []string
+		bare  []bool
+	)
+	s := value
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if s == "" {
+			return words, bare, nil
+		}
+
+		var (
+			b     strings.Builder
+			quote byte
+			plain = true
+		)
+	word:
+		for len(s) > 0 {
+			c := s[0]
+			switch {
+			case quote == 0 && (c == ' ' || c == '\t' || c == '\n' || c == '\r'):
+				break word
+			case c == quote:
+				quote = 0
+				s = s[1:]
+			case quote == 0 && (c == '"' || c == '\''):
+				quote = c
+				plain = false
+				s = s[1:]
+			case c == '\\':
+				r, n, err := unescape(s)
+				if err != nil {
+					return nil, nil, fmt.Errorf("%w: %w in %q", ErrSyntax, err, value)
+				}
+				b.WriteString(r)
+				plain = false
+				s = s[n:]
+			default:
+				b.WriteByte(c)
+				s = s[1:]
+			}
+		}
+		if quote != 0 {
+			return nil, nil, fmt.Errorf("%w: unterminated quote in %q", ErrSyntax, value)
+		}
+		words = append(words, b.String())
+		bare = append(bare, plain)
+	}
+}
+
+// unescape resolves the escape sequence at the start of s, returning the
+// resulting string and the length of the escape sequence.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/escape.c
+func unescape(s string) (string, int, error) {
+	if len(s) < 2 {
+		return "", 0, errors.New("trailing backslash")
+	}
+	switch c := s[1]; c {
+	case 'a':
+		return "\a", 2, nil
+	case 'b':
+		return "\b", 2, nil
+	case 'f':
+		return "\f", 2, nil
+	case 'n':
+		return "\n", 2, nil
+	case 'r':
+		return "\r", 2, nil
+	case 't':
+		return "\t", 2, nil
+	case 'v':
+		return "\v", 2, nil
+	case 's':
+		return " ", 2, nil
+	case '\\', '"', '\'', ' ', ';':
+		return string(c), 2, nil
+	case 'x':
+		return unescapeNumber(s, 2, 2, 16)
+	case 'u':
+		return unescapeNumber(s, 2, 4, 16)
+	case 'U':
+		return unescapeNumber(s, 2, 8, 16)
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		return unescapeNumber(s, 1, 3, 8)
+	default:
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s[:2])
+	}
+}
+
+// unescapeNumber resolves an escape sequence of a character code with n digits
+// in the given base, starting at offset.
+func unescapeNumber(s string, offset, n, base int) (string, int, error) {
+	if len(s) < offset+n {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s)
+	}
+	v, err := strconv.ParseUint(s[offset:offset+n], base, 32)
+	if err != nil || v == 0 {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s[:offset+n])
+	}
+	if n <= 3 {
+		if v > 0xff {
+			return "", 0, fmt.Errorf("invalid escape sequence %q", s[:offset+n])
+		}
+		return string([]byte{byte(v)}), offset + n, nil
+	}
+	if !utf8.ValidRune(rune(v)) {
+		return "", 0, fmt.Errorf("invalid escape sequence %q", s[:offset+n])
+	}
+	return string(rune(v)), offset + n, nil
+}
@@ -0,0 +1,758 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matthewpi/sd/sdlisten"
+)
+
+func TestEscape(t *testing.T) {
+	for _, tc := range []struct {
+		value, expect string
+	}{
+		{value: "foo", expect: "foo"},
+		{value: "foo/bar", expect: "foo-bar"},
+		{value: "foo-bar", expect: `foo\x2dbar`},
+		{value: "Hallöchen, Meister", expect: `Hall\xc3\xb6chen\x2c\x20Meister`},
+		{value: ".hidden", expect: `\x2ehidden`},
+		{value: "a.b:c_d", expect: "a.b:c_d"},
+	} {
+		if got := Escape(tc.value); got != tc.expect {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.value, tc.expect, got)
+			continue
+		}
+		v, err := Unescape(tc.expect)
+		if err != nil {
+			t.Errorf("%q: %v", tc.expect, err)
+			continue
+		}
+		if v != tc.value {
+			t.Errorf("%q: expected unescape to return \"%s\", but got \"%s\"", tc.expect, tc.value, v)
+		}
+	}
+
+	for _, v := range []string{`\x`, `\x2`, `\y20`, `\xzz`} {
+		if _, err := Unescape(v); !errors.Is(err, ErrInvalidEscape) {
+			t.Errorf("%q: expected %v, but got %v", v, ErrInvalidEscape, err)
+		}
+	}
+}
+
+func TestEscapePath(t *testing.T) {
+	for _, tc := range []struct {
+		value, expect string
+	}{
+		{value: "/", expect: "-"},
+		{value: "/dev/sda", expect: "dev-sda"},
+		{value: "//var//lib/", expect: "var-lib"},
+		{value: "/mnt/my-disk", expect: `mnt-my\x2ddisk`},
+		{value: "/a/./b", expect: "a-b"},
+	} {
+		got, err := EscapePath(tc.value)
+		if err != nil {
+			t.Errorf("%q: %v", tc.value, err)
+			continue
+		}
+		if got != tc.expect {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.value, tc.expect, got)
+		}
+	}
+
+	if p, err := UnescapePath(`mnt-my\x2ddisk`); err != nil || p != "/mnt/my-disk" {
+		t.Errorf("expected \"/mnt/my-disk\", but got \"%s\" (%v)", p, err)
+	}
+	if p, err := UnescapePath("-"); err != nil || p != "/" {
+		t.Errorf("expected \"/\", but got \"%s\" (%v)", p, err)
+	}
+}
+
+func TestInstance(t *testing.T) {
+	name, err := Instance("getty@.service", "tty/1")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := "getty@tty-1.service"; name != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, name)
+	}
+
+	for _, template := range []string{"getty.service", "@.service", "getty@"} {
+		if _, err := Instance(template, "x"); err == nil {
+			t.Errorf("%q: expected an error", template)
+		}
+	}
+}
+
+func TestSplitInstance(t *testing.T) {
+	template, instance, err := SplitInstance("app@tenant\\x2done.service")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if template != "app@.service" || instance != "tenant-one" {
+		t.Errorf("expected \"%s\" and \"%s\", but got \"%s\" and \"%s\"", "app@.service", "tenant-one", template, instance)
+	}
+	for _, name := range []string{"app.service", "app@.service", "@x.service", "app@x"} {
+		if _, _, err := SplitInstance(name); err == nil {
+			t.Errorf("%q: expected an error", name)
+		}
+	}
+
+	names := []string{"app@b.service", "app@.service", "app@a.service", "app@a.socket", "other@c.service", "app@b.service"}
+	if got, expected := InstancesOf("app@.service", names), []string{"a", "b"}; !slices.Equal(got, expected) {
+		t.Errorf("expected %q, but got %q", expected, got)
+	}
+
+	etc, usr := t.TempDir(), t.TempDir()
+	for _, p := range []string{etc + "/multi-user.target.wants", usr + "/app@c.service.d"} {
+		if err := os.MkdirAll(p, 0o755); err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+	if err := os.Symlink(usr+"/app@.service", etc+"/multi-user.target.wants/app@a.service"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := WriteInstanceDropIn(etc, "app@.service", "tenant/b", "limits", &File{Sections: []*Section{{Name: "Service", Entries: []Entry{{Key: "MemoryMax", Value: "1G"}}}}}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if _, err := os.Stat(etc + "/app@tenant-b.service.d/limits.conf"); err != nil {
+		t.Error(err)
+	}
+	instances, err := ReadInstances("app@.service", etc, usr)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := []string{"a", "c", "tenant/b"}; !slices.Equal(instances, expected) {
+		t.Errorf("expected %q, but got %q", expected, instances)
+	}
+}
+
+func TestMangle(t *testing.T) {
+	for _, tc := range []struct {
+		value, expect string
+	}{
+		{value: "foo.service", expect: "foo.service"},
+		{value: "foo", expect: "foo.service"},
+		{value: "foo bar", expect: `foo\x20bar.service`},
+		{value: "/dev/sda", expect: "dev-sda.device"},
+		{value: "/home", expect: "home.mount"},
+		{value: "foo.timer", expect: "foo.timer"},
+	} {
+		got, err := Mangle(tc.value, ".service")
+		if err != nil {
+			t.Errorf("%q: %v", tc.value, err)
+			continue
+		}
+		if got != tc.expect {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.value, tc.expect, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	for _, tc := range []struct {
+		unit, value, expect string
+	}{
+		{unit: "getty@tty1.service", value: "%n %N %p %i", expect: "getty@tty1.service getty@tty1 getty tty1"},
+		{unit: "foo-bar@var-lib-data.mount", value: "%j %J %I %f", expect: "bar bar var/lib/data /var/lib/data"},
+		{unit: "app.service", value: "/run/%p.sock 100%%", expect: "/run/app.sock 100%"},
+		{unit: "app.service", value: "%t/%N %S %C %L %E %V", expect: "/run/app /var/lib /var/cache /var/log /etc /var/tmp"},
+		{unit: "app.service", value: "%u:%U %h", expect: "root:0 /root"},
+	} {
+		got, err := Expand(tc.unit, tc.value)
+		if err != nil {
+			t.Errorf("%q: %v", tc.value, err)
+			continue
+		}
+		if got != tc.expect {
+			t.Errorf("%q: expected \"%s\", but got \"%s\"", tc.value, tc.expect, got)
+		}
+	}
+
+	if _, err := Expand("app.service", "%z"); !errors.Is(err, ErrUnknownSpecifier) {
+		t.Errorf("expected %v, but got %v", ErrUnknownSpecifier, err)
+	}
+
+	e := Expander{Extra: map[byte]string{'y': "/etc/systemd/system/app.service"}}
+	got, err := e.Expand("%z %n %y")
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if expected := "%z %n /etc/systemd/system/app.service"; got != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, got)
+	}
+}
+
+func TestService(t *testing.T) {
+	s := &Service{
+		Unit: Unit{
+			Description:   "My App (100% Go)",
+			Documentation: []string{"https://example.com/docs"},
+			Wants:         []string{"network-online.target"},
+			After:         []string{"network-online.target"},
+		},
+		Type: ServiceNotify,
+		ExecStart: []Command{
+			{"/usr/bin/my app", "--config", "/etc/my app/config.yaml", "--greeting", `say "hi"`, "$HOME", ";"},
+		},
+		ExecReload: []Command{{"/bin/kill", "-HUP", "$MAINPID"}},
+		Restart:    RestartOnFailure,
+		RestartSec: 5 * time.Second,
+		Environment: map[string]string{
+			"LOG_LEVEL": "debug",
+			"MOTD":      "hello world",
+		},
+		StateDirectory: []string{"app"},
+		Sandboxing: Sandboxing{
+			DynamicUser:         true,
+			NoNewPrivileges:     true,
+			ProtectSystem:       "strict",
+			ReadWritePaths:      []string{"/srv/my data"},
+			AmbientCapabilities: []string{"CAP_NET_BIND_SERVICE"},
+		},
+		Resources: Resources{MemoryMax: 1 << 30, CPUQuota: 0.1},
+		Install:   Install{WantedBy: []string{"multi-user.target"}},
+		Extra:     []Directive{{Section: "Service", Key: "OOMScoreAdjust", Value: "-100"}},
+	}
+	b, err := s.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := `[Unit]
+Description=My App (100%% Go)
+Documentation=https://example.com/docs
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+ExecStart="/usr/bin/my app" --config "/etc/my app/config.yaml" --greeting "say \"hi\"" $$HOME \;
+ExecReload=/bin/kill -HUP $$MAINPID
+Restart=on-failure
+RestartSec=5s
+Environment=LOG_LEVEL=debug
+Environment="MOTD=hello world"
+StateDirectory=app
+DynamicUser=yes
+NoNewPrivileges=yes
+ProtectSystem=strict
+ReadWritePaths="/srv/my data"
+AmbientCapabilities=CAP_NET_BIND_SERVICE
+MemoryMax=1073741824
+CPUQuota=10%
+OOMScoreAdjust=-100
+
+[Install]
+WantedBy=multi-user.target
+`
+	if string(b) != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, string(b))
+	}
+
+	for _, s := range []*Service{
+		{Unit: Unit{Description: "line\nbreak"}},
+		{ExecStart: []Command{{}}},
+		{Environment: map[string]string{"A=B": "c"}},
+		{Extra: []Directive{{Section: "Socket", Key: "ListenStream", Value: "80"}}},
+	} {
+		if _, err := s.MarshalText(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("expected %v, but got %v", ErrInvalidValue, err)
+		}
+	}
+}
+
+func TestHarden(t *testing.T) {
+	s := &Service{
+		Unit:      Unit{Description: "App"},
+		ExecStart: []Command{{"/usr/bin/app"}},
+		User:      "app",
+	}
+	err := s.Harden(Needs{
+		AddressFamilies: []string{"AF_INET6", "AF_INET"},
+		WritePaths:      []string{"/home/app/data"},
+		Capabilities:    []string{"CAP_NET_BIND_SERVICE"},
+		Exempt:          []string{"ProtectClock"},
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	b, err := s.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	f, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	for key, expected := range map[string]string{
+		"ProtectSystem":           "strict",
+		"ProtectHome":             "read-only",
+		"ReadWritePaths":          "/home/app/data",
+		"MemoryDenyWriteExecute":  "yes",
+		"CapabilityBoundingSet":   "CAP_NET_BIND_SERVICE",
+		"AmbientCapabilities":     "CAP_NET_BIND_SERVICE",
+		"RestrictAddressFamilies": "AF_INET AF_INET6",
+		"SystemCallFilter":        "@system-service",
+	} {
+		if v, _ := f.Value("Service", key); v != expected {
+			t.Errorf("%s: expected \"%s\", but got \"%s\"", key, expected, v)
+		}
+	}
+	for _, key := range []string{"ProtectClock", "PrivateNetwork"} {
+		if v, ok := f.Value("Service", key); ok {
+			t.Errorf("%s: expected no value, but got \"%s\"", key, v)
+		}
+	}
+
+	diags, err := CheckHardening(f, Needs{AddressFamilies: []string{"AF_INET", "AF_INET6"}, WritePaths: []string{"/home/app/data"}, Capabilities: []string{"CAP_NET_BIND_SERVICE"}})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(diags) != 1 || diags[0].Key != "ProtectClock" {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+
+	// Services that need nothing are cut off from the network and drop all
+	// capabilities.
+	s = &Service{ExecStart: []Command{{"/usr/bin/app"}}}
+	if err := s.Harden(Needs{}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	f = &File{}
+	if b, err = s.MarshalText(); err == nil {
+		err = f.UnmarshalText(b)
+	}
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if diags, err := CheckHardening(f, Needs{}); err != nil || len(diags) != 0 {
+		t.Errorf("unexpected diagnostics %v (%v)", diags, err)
+	}
+	if v, ok := f.Value("Service", "CapabilityBoundingSet"); !ok || v != "" {
+		t.Errorf("expected an empty CapabilityBoundingSet=, but got \"%s\"", v)
+	}
+	if v, _ := f.Value("Service", "PrivateNetwork"); v != "yes" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "yes", v)
+	}
+
+	if err := s.Harden(Needs{Exempt: []string{"ProtectEverything"}}); err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestSocketFor(t *testing.T) {
+	s, err := SocketFor(
+		sdlisten.Socket{Name: "http", Network: "tcp", Address: ":80"},
+		sdlisten.Socket{Name: "http", Network: "tcp4", Address: "127.0.0.1:8080"},
+		sdlisten.Socket{Name: "http", Network: "tcp6", Address: ":8443"},
+		sdlisten.Socket{Name: "http", Network: "unix", Address: "/run/app/%i.sock"},
+		sdlisten.Socket{Name: "http", Network: "udp", Address: ":53"},
+	)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	s.Unit.Description = "App Socket"
+	s.SocketMode = 0o660
+	s.Install.WantedBy = []string{"sockets.target"}
+	b, err := s.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := `[Unit]
+Description=App Socket
+
+[Socket]
+ListenStream=80
+ListenStream=127.0.0.1:8080
+ListenStream=[::]:8443
+ListenStream=/run/app/%%i.sock
+ListenDatagram=53
+FileDescriptorName=http
+BindIPv6Only=ipv6-only
+SocketMode=0660
+
+[Install]
+WantedBy=sockets.target
+`
+	if string(b) != expected {
+		t.Errorf("expected \"%s\", but got \"%s\"", expected, string(b))
+	}
+
+	for _, sockets := range [][]sdlisten.Socket{
+		{},
+		{{Name: "a", Network: "tcp", Address: ":80"}, {Name: "b", Network: "tcp", Address: ":81"}},
+		{{Name: "a", Network: "tcp", Address: "80"}},
+		{{Name: "a", Network: "ip", Address: "127.0.0.1"}},
+	} {
+		if _, err := SocketFor(sockets...); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("expected %v, but got %v", ErrInvalidValue, err)
+		}
+	}
+
+	for _, s := range []*Socket{
+		{ListenStream: []string{"80"}, FileDescriptorName: "a:b"},
+		{ListenDatagram: []string{"53"}, Accept: true},
+	} {
+		if _, err := s.MarshalText(); !errors.Is(err, ErrInvalidValue) {
+			t.Errorf("expected %v, but got %v", ErrInvalidValue, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader(`# Generated by app
+[Unit]
+Description=My App
+After=network.target
+After=
+After=network-online.target \
+	# ignored comment
+remote-fs.target
+
+[Service]
+; start the app
+ExecStart=-/usr/bin/app "--name=my app" \; ; /usr/bin/app --check
+Type=simple
+Type=notify
+
+[Unit]
+Wants=network-online.target
+`))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if v, _ := f.Value("Service", "Type"); v != "notify" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "notify", v)
+	}
+	if v := f.Values("Unit", "After"); !slices.Equal(v, []string{"network-online.target  remote-fs.target"}) {
+		t.Errorf("expected \"%v\", but got \"%v\"", []string{"network-online.target  remote-fs.target"}, v)
+	}
+	if v := f.Values("Unit", "Wants"); !slices.Equal(v, []string{"network-online.target"}) {
+		t.Errorf("expected \"%v\", but got \"%v\"", []string{"network-online.target"}, v)
+	}
+
+	v, _ := f.Value("Service", "ExecStart")
+	cmds, err := SplitCommands(v)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := []Command{{"-/usr/bin/app", "--name=my app", ";"}, {"/usr/bin/app", "--check"}}
+	if !slices.EqualFunc(cmds, expected, slices.Equal) {
+		t.Errorf("expected \"%v\", but got \"%v\"", expected, cmds)
+	}
+
+	f.Section("Service").Set("Type", "exec")
+	f.Section("Unit").Add("After", "time-sync.target")
+	f.AddSection("Install").Add("WantedBy", "multi-user.target")
+	b, err := f.MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expectedText := `# Generated by app
+
+[Unit]
+Description=My App
+After=network.target
+After=
+After=network-online.target  remote-fs.target
+After=time-sync.target
+Wants=network-online.target
+
+[Service]
+; start the app
+ExecStart=-/usr/bin/app "--name=my app" \; ; /usr/bin/app --check
+Type=exec
+
+[Install]
+WantedBy=multi-user.target
+`
+	if string(b) != expectedText {
+		t.Errorf("expected \"%s\", but got \"%s\"", expectedText, string(b))
+	}
+
+	for _, s := range []string{
+		"Description=outside",
+		"[Unit\nDescription=x",
+		"[Unit]\nDescription",
+		"[Unit]\nDescription=x \\",
+	} {
+		if _, err := Parse(strings.NewReader(s)); !errors.Is(err, ErrSyntax) {
+			t.Errorf("expected %v, but got %v", ErrSyntax, err)
+		}
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	words, err := SplitWords(`a "b c" 'd "e"' f\x41g "é" \s`)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	expected := []string{"a", "b c", `d "e"`, "fAg", "é", " "}
+	if !slices.Equal(words, expected) {
+		t.Errorf("expected \"%v\", but got \"%v\"", expected, words)
+	}
+
+	for _, s := range []string{`"unterminated`, `trailing\`, `\q`, `\x0`} {
+		if _, err := SplitWords(s); !errors.Is(err, ErrSyntax) {
+			t.Errorf("expected %v, but got %v", ErrSyntax, err)
+		}
+	}
+
+	// Commands rendered by Service must split back into the same arguments.
+	cmd := Command{"/usr/bin/my app", `say "hi"`, ";", "", "tab\there"}
+	b, err := (&Service{ExecStart: []Command{cmd}}).MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	f, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	v, _ := f.Value("Service", "ExecStart")
+	cmds, err := SplitCommands(v)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if len(cmds) != 1 || !slices.Equal(cmds[0], cmd) {
+		t.Errorf("expected \"%v\", but got \"%v\"", cmd, cmds)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f, err := Parse(strings.NewReader(`[Unit]
+Description=App
+Requires=db.service
+After=network.target
+
+[Service]
+Type=exec
+ExecStart=/usr/bin/app
+ExecStart=/usr/bin/app --again
+Restart=sometimes
+RestartSec=1min 30s
+WatchdogSec=30s
+MemoryMax=512M
+CPUQuota=150%
+ProtectClock=yes
+Frobnicate=yes
+X-Custom=ignored
+
+[Timer]
+OnCalendar=Mon..Fri 25:00
+
+[X-Tool]
+Anything=goes
+`))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	diags := (&Validator{Version: 239}).Validate(f)
+	expected := []string{
+		`error: [Service] Type: Type=exec requires systemd 240 or newer`,
+		`error: [Service] Restart: invalid value "sometimes": must be one of always, no, on-abnormal, on-abort, on-failure, on-success, on-watchdog`,
+		`error: [Service] ProtectClock: requires systemd 245 or newer`,
+		`warning: [Service] Frobnicate: unknown setting`,
+		`error: [Timer] OnCalendar: invalid value "Mon..Fri 25:00": sdtime: invalid calendar event (Mon..Fri 25:00): invalid hour: 25 is out of range`,
+		`warning: [Unit] Requires: db.service is not also in After=, both units are started at the same time`,
+		`error: [Service] ExecStart: more than one command, only allowed with Type=oneshot`,
+		`warning: [Service] WatchdogSec: the watchdog requires the service to send notifications, use Type=notify or set NotifyAccess=`,
+	}
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = d.String()
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected \"%q\", but got \"%q\"", expected, got)
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SYSTEMD_SCOPE", "system")
+	t.Setenv("SYSTEMD_FIRST_BOOT", "1")
+	g, err := NewGenerator([]string{dir + "/normal", dir + "/early", dir + "/late"})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if g.Scope != "system" || !g.FirstBoot || g.InInitrd {
+		t.Errorf("unexpected generator environment: %+v", g)
+	}
+
+	b, err := (&Service{ExecStart: []Command{{"/usr/bin/app"}}}).MarshalText()
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := g.WriteUnit(GeneratorLate, "app.service", b); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := g.WriteDropIn(GeneratorLate, "app.service", "50-env", []byte("[Service]\nEnvironment=A=1\n")); err != nil {
+		t.Fatal(err)
+		return
+	}
+	// Linking twice replaces the existing link.
+	for range 2 {
+		if err := g.Wants(GeneratorLate, "multi-user.target", "app.service"); err != nil {
+			t.Fatal(err)
+			return
+		}
+	}
+
+	got, err := os.ReadFile(dir + "/late/app.service")
+	if err != nil || string(got) != string(b) {
+		t.Errorf("expected \"%s\", but got \"%s\" (%v)", b, got, err)
+	}
+	if _, err := os.Stat(dir + "/late/app.service.d/50-env.conf"); err != nil {
+		t.Error(err)
+	}
+	target, err := os.Readlink(dir + "/late/multi-user.target.wants/app.service")
+	if err != nil || target != "../app.service" {
+		t.Errorf("expected \"%s\", but got \"%s\" (%v)", "../app.service", target, err)
+	}
+	// The unit must resolve through the link.
+	if _, err := os.Stat(dir + "/late/multi-user.target.wants/app.service"); err != nil {
+		t.Error(err)
+	}
+
+	if err := g.WriteUnit(GeneratorNormal, "../escape.service", b); err == nil {
+		t.Error("expected an error for an invalid unit name")
+	}
+	if _, err := NewGenerator([]string{dir}); err == nil {
+		t.Error("expected an error for a single argument")
+	}
+}
+
+func TestDropIn(t *testing.T) {
+	etc, usr := t.TempDir(), t.TempDir()
+	err := EditDropIn(etc, "app.service", "override", func(f *File) error {
+		s := f.AddSection("Service")
+		s.Set("MemoryMax", "1G")
+		s.Add("Environment", "LOG_LEVEL=debug")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	err = EditDropIn(etc, "app.service", "override", func(f *File) error {
+		f.Section("Service").Set("MemoryMax", "2G")
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	b, err := os.ReadFile(etc + "/app.service.d/override.conf")
+	if expected := "[Service]\nMemoryMax=2G\nEnvironment=LOG_LEVEL=debug\n"; err != nil || string(b) != expected {
+		t.Errorf("expected \"%s\", but got \"%s\" (%v)", expected, b, err)
+	}
+
+	// Drop-ins of the template apply to instances, and drop-ins in /etc hide
+	// drop-ins with the same name in /usr.
+	if err := WriteDropIn(usr, "app@.service", "override", &File{Sections: []*Section{{Name: "Service", Entries: []Entry{{Key: "MemoryMax", Value: "512M"}}}}}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := WriteDropIn(usr, "app@.service", "10-limits", &File{Sections: []*Section{{Name: "Service", Entries: []Entry{{Key: "TasksMax", Value: "64"}}}}}); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if err := os.Rename(etc+"/app.service.d", etc+"/app@web.service.d"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	dropIns, err := ReadDropIns("app@web.service", etc, usr)
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	paths := make([]string, len(dropIns))
+	files := make([]*File, len(dropIns))
+	for i, d := range dropIns {
+		paths[i], files[i] = d.Path, d.File
+	}
+	expectedPaths := []string{usr + "/app@.service.d/10-limits.conf", etc + "/app@web.service.d/override.conf"}
+	if !slices.Equal(paths, expectedPaths) {
+		t.Errorf("expected \"%v\", but got \"%v\"", expectedPaths, paths)
+	}
+
+	base, err := Parse(strings.NewReader("[Service]\nExecStart=/usr/bin/app\nMemoryMax=1G\n"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	merged := MergeDropIns(base, files...)
+	if v, _ := merged.Value("Service", "MemoryMax"); v != "2G" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "2G", v)
+	}
+	if v, _ := merged.Value("Service", "TasksMax"); v != "64" {
+		t.Errorf("expected \"%s\", but got \"%s\"", "64", v)
+	}
+
+	if err := RemoveDropIn(etc, "app@web.service", "override"); err != nil {
+		t.Fatal(err)
+		return
+	}
+	if _, err := os.Stat(etc + "/app@web.service.d"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("expected the drop-in directory to be removed, but got %v", err)
+	}
+
+	dropIn, err := Parse(strings.NewReader("[Service]\nExecStart=/usr/bin/app --debug\nMemoryMax=1G\n\n[Socket]\nListenStream=80\n"))
+	if err != nil {
+		t.Fatal(err)
+		return
+	}
+	diags := CheckDropIn(base, dropIn)
+	got := make([]string, len(diags))
+	for i, d := range diags {
+		got[i] = d.String()
+	}
+	expected := []string{
+		`error: [Service] ExecStart: adds a second command to the unit, reset it with an empty ExecStart= first`,
+		`warning: [Service] MemoryMax: already set to "1G" in the unit`,
+		`error: [Socket]: section does not exist in the unit`,
+	}
+	if !slices.Equal(got, expected) {
+		t.Errorf("expected \"%q\", but got \"%q\"", expected, got)
+	}
+
+	dropIn.Section("Service").Entries = slices.Insert(dropIn.Section("Service").Entries, 0, Entry{Key: "ExecStart"})
+	dropIn.Sections = dropIn.Sections[:1]
+	dropIn.Section("Service").Del("MemoryMax")
+	if diags := CheckDropIn(base, dropIn); len(diags) > 0 {
+		t.Errorf("expected no diagnostics, but got %v", diags)
+	}
+}
@@ -0,0 +1,495 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/matthewpi/sd/sdtime"
+)
+
+// ErrInvalidValue is returned when rendering a unit file with a value that
+// cannot be represented in a unit file, such as one containing a newline.
+var ErrInvalidValue = errors.New("sdunit: invalid value")
+
+// ServiceType is the type of a service, `Type=`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#Type=
+type ServiceType string
+
+const (
+	ServiceSimple       ServiceType = "simple"
+	ServiceExec         ServiceType = "exec"
+	ServiceForking      ServiceType = "forking"
+	ServiceOneshot      ServiceType = "oneshot"
+	ServiceDBus         ServiceType = "dbus"
+	ServiceNotify       ServiceType = "notify"
+	ServiceNotifyReload ServiceType = "notify-reload"
+	ServiceIdle         ServiceType = "idle"
+)
+
+// RestartPolicy controls when a service is restarted, `Restart=`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html#Restart=
+type RestartPolicy string
+
+const (
+	RestartNo         RestartPolicy = "no"
+	RestartOnSuccess  RestartPolicy = "on-success"
+	RestartOnFailure  RestartPolicy = "on-failure"
+	RestartOnAbnormal RestartPolicy = "on-abnormal"
+	RestartOnWatchdog RestartPolicy = "on-watchdog"
+	RestartOnAbort    RestartPolicy = "on-abort"
+	RestartAlways     RestartPolicy = "always"
+)
+
+// Command is a command line of a service, such as `ExecStart=`, with the path
+// or name of the program first followed by its arguments.
+//
+// Arguments are quoted as needed when rendered, and `%` and `$` are escaped so
+// they are passed to the program as-is rather than expanded by systemd.
+type Command []string
+
+// Unit are the generic settings of a unit, written to the `[Unit]` section.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html#%5BUnit%5D%20Section%20Options
+type Unit struct {
+	Description   string
+	Documentation []string
+
+	Wants     []string
+	Requires  []string
+	BindsTo   []string
+	PartOf    []string
+	Conflicts []string
+	After     []string
+	Before    []string
+
+	// ConditionPathExists are paths that must exist for the unit to start,
+	// prefixed with `!` for paths that must not exist.
+	ConditionPathExists []string
+}
+
+// Install are the installation settings of a unit, written to the `[Install]`
+// section and used by `systemctl enable`.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html#%5BInstall%5D%20Section%20Options
+type Install struct {
+	WantedBy   []string
+	RequiredBy []string
+	Alias      []string
+	Also       []string
+}
+
+// Sandboxing are the security settings of a service.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html#Security
+type Sandboxing struct {
+	// DynamicUser runs the service as a user allocated when it is started.
+	DynamicUser bool
+	// NoNewPrivileges prevents the service from gaining privileges, such as
+	// through setuid binaries.
+	NoNewPrivileges bool
+
+	// ProtectSystem mounts the OS read-only, such as `strict` or `full`.
+	ProtectSystem string
+	// ProtectHome hides or mounts home directories read-only, such as `yes`
+	// or `read-only`.
+	ProtectHome string
+	// ReadWritePaths, ReadOnlyPaths, and InaccessiblePaths change the access
+	// of the service to the given paths.
+	ReadWritePaths    []string
+	ReadOnlyPaths     []string
+	InaccessiblePaths []string
+
+	PrivateTmp             bool
+	PrivateDevices         bool
+	PrivateNetwork         bool
+	ProtectKernelTunables  bool
+	ProtectKernelModules   bool
+	ProtectKernelLogs      bool
+	ProtectControlGroups   bool
+	ProtectClock           bool
+	ProtectHostname        bool
+	RestrictNamespaces     bool
+	RestrictRealtime       bool
+	RestrictSUIDSGID       bool
+	LockPersonality        bool
+	MemoryDenyWriteExecute bool
+
+	// CapabilityBoundingSet and AmbientCapabilities are capabilities, such as
+	// `CAP_NET_BIND_SERVICE`. An empty bounding set is not written, use
+	// [Service.Extra] to drop all capabilities.
+	CapabilityBoundingSet []string
+	AmbientCapabilities   []string
+	// RestrictAddressFamilies are the socket address families the service
+	// may use, such as `AF_UNIX` and `AF_INET`.
+	RestrictAddressFamilies []string
+	// SystemCallFilter are the system calls or groups the service may use,
+	// such as `@system-service`, prefixed with `~` to deny them instead.
+	SystemCallFilter []string
+	// SystemCallArchitectures are the architectures of system calls the
+	// service may use, such as `native`.
+	SystemCallArchitectures []string
+}
+
+// Resources are the resource limits of a service, zero values are not
+// written.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.resource-control.html
+type Resources struct {
+	// MemoryHigh and MemoryMax are memory limits in bytes.
+	MemoryHigh uint64
+	MemoryMax  uint64
+	// CPUQuota is the number of CPUs the service may use, such as 1.5 for
+	// `CPUQuota=150%`.
+	CPUQuota  float64
+	CPUWeight uint64
+	IOWeight  uint64
+	TasksMax  uint64
+	// LimitNOFILE is the number of file descriptors the service may open.
+	LimitNOFILE uint64
+}
+
+// Directive is a setting of a unit file without a dedicated field.
+type Directive struct {
+	// Section is the section of the setting, such as `Service`.
+	Section string
+	// Key is the name of the setting, such as `OOMScoreAdjust`.
+	Key string
+	// Value is written as-is, it must be quoted and escaped as needed.
+	Value string
+}
+
+// Service is a service unit file, rendered with [Service.MarshalText].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.service.html
+type Service struct {
+	Unit Unit
+
+	Type ServiceType
+	// ExecStartPre, ExecStart, ExecStartPost, ExecReload, and ExecStop are
+	// the commands run by the service. Only services of [ServiceOneshot] may
+	// have more than one command in ExecStart.
+	ExecStartPre  []Command
+	ExecStart     []Command
+	ExecStartPost []Command
+	ExecReload    []Command
+	ExecStop      []Command
+
+	Restart    RestartPolicy
+	RestartSec time.Duration
+	// TimeoutStartSec and TimeoutStopSec are not written if zero.
+	TimeoutStartSec time.Duration
+	TimeoutStopSec  time.Duration
+	WatchdogSec     time.Duration
+
+	User             string
+	Group            string
+	WorkingDirectory string
+	// Environment are environment variables of the service, written sorted
+	// by name.
+	Environment     map[string]string
+	EnvironmentFile []string
+
+	// StateDirectory, RuntimeDirectory, CacheDirectory, LogsDirectory, and
+	// ConfigurationDirectory are directories created for the service, such as
+	// `app` for `/var/lib/app`.
+	StateDirectory         []string
+	RuntimeDirectory       []string
+	CacheDirectory         []string
+	LogsDirectory          []string
+	ConfigurationDirectory []string
+
+	Sandboxing Sandboxing
+	Resources  Resources
+
+	Install Install
+
+	// Extra are additional settings, written at the end of their section.
+	Extra []Directive
+}
+
+// MarshalText implements [encoding.TextMarshaler], rendering the service as a
+// unit file.
+func (s *Service) MarshalText() ([]byte, error) {
+	w := &unitWriter{}
+
+	w.unit(&s.Unit, s.Extra)
+
+	w.section("Service")
+	w.value("Type", string(s.Type))
+	w.commands("ExecStartPre", s.ExecStartPre)
+	w.commands("ExecStart", s.ExecStart)
+	w.commands("ExecStartPost", s.ExecStartPost)
+	w.commands("ExecReload", s.ExecReload)
+	w.commands("ExecStop", s.ExecStop)
+	w.value("Restart", string(s.Restart))
+	w.duration("RestartSec", s.RestartSec)
+	w.duration("TimeoutStartSec", s.TimeoutStartSec)
+	w.duration("TimeoutStopSec", s.TimeoutStopSec)
+	w.duration("WatchdogSec", s.WatchdogSec)
+	w.value("User", s.User)
+	w.value("Group", s.Group)
+	w.value("WorkingDirectory", s.WorkingDirectory)
+	for _, k := range slices.Sorted(maps.Keys(s.Environment)) {
+		if k == "" || strings.ContainsAny(k, "=") {
+			w.fail(fmt.Errorf("%w: environment variable name %q", ErrInvalidValue, k))
+			continue
+		}
+		w.line("Environment", quote(k+"="+s.Environment[k], false))
+	}
+	for _, f := range s.EnvironmentFile {
+		w.value("EnvironmentFile", f)
+	}
+	w.list("StateDirectory", s.StateDirectory)
+	w.list("RuntimeDirectory", s.RuntimeDirectory)
+	w.list("CacheDirectory", s.CacheDirectory)
+	w.list("LogsDirectory", s.LogsDirectory)
+	w.list("ConfigurationDirectory", s.ConfigurationDirectory)
+
+	sb := &s.Sandboxing
+	w.bool("DynamicUser", sb.DynamicUser)
+	w.bool("NoNewPrivileges", sb.NoNewPrivileges)
+	w.value("ProtectSystem", sb.ProtectSystem)
+	w.value("ProtectHome", sb.ProtectHome)
+	w.list("ReadWritePaths", sb.ReadWritePaths)
+	w.list("ReadOnlyPaths", sb.ReadOnlyPaths)
+	w.list("InaccessiblePaths", sb.InaccessiblePaths)
+	w.bool("PrivateTmp", sb.PrivateTmp)
+	w.bool("PrivateDevices", sb.PrivateDevices)
+	w.bool("PrivateNetwork", sb.PrivateNetwork)
+	w.bool("ProtectKernelTunables", sb.ProtectKernelTunables)
+	w.bool("ProtectKernelModules", sb.ProtectKernelModules)
+	w.bool("ProtectKernelLogs", sb.ProtectKernelLogs)
+	w.bool("ProtectControlGroups", sb.ProtectControlGroups)
+	w.bool("ProtectClock", sb.ProtectClock)
+	w.bool("ProtectHostname", sb.ProtectHostname)
+	w.bool("RestrictNamespaces", sb.RestrictNamespaces)
+	w.bool("RestrictRealtime", sb.RestrictRealtime)
+	w.bool("RestrictSUIDSGID", sb.RestrictSUIDSGID)
+	w.bool("LockPersonality", sb.LockPersonality)
+	w.bool("MemoryDenyWriteExecute", sb.MemoryDenyWriteExecute)
+	w.list("CapabilityBoundingSet", sb.CapabilityBoundingSet)
+	w.list("AmbientCapabilities", sb.AmbientCapabilities)
+	w.list("RestrictAddressFamilies", sb.RestrictAddressFamilies)
+	w.list("SystemCallFilter", sb.SystemCallFilter)
+	w.list("SystemCallArchitectures", sb.SystemCallArchitectures)
+
+	r := &s.Resources
+	w.uint("MemoryHigh", r.MemoryHigh)
+	w.uint("MemoryMax", r.MemoryMax)
+	if r.CPUQuota > 0 {
+		w.line("CPUQuota", strconv.FormatFloat(math.Round(r.CPUQuota*10000)/100, 'f', -1, 64)+"%")
+	}
+	w.uint("CPUWeight", r.CPUWeight)
+	w.uint("IOWeight", r.IOWeight)
+	w.uint("TasksMax", r.TasksMax)
+	w.uint("LimitNOFILE", r.LimitNOFILE)
+	w.extra("Service", s.Extra)
+
+	w.install(&s.Install, s.Extra)
+	return w.finish(s.Extra, "Service")
+}
+
+// hasSection reports whether any of the directives are in a section.
+func hasSection(extra []Directive, section string) bool {
+	return slices.ContainsFunc(extra, func(d Directive) bool { return d.Section == section })
+}
+
+// unit writes the `[Unit]` section.
+func (w *unitWriter) unit(u *Unit, extra []Directive) {
+	w.section("Unit")
+	w.value("Description", u.Description)
+	w.list("Documentation", u.Documentation)
+	w.list("Wants", u.Wants)
+	w.list("Requires", u.Requires)
+	w.list("BindsTo", u.BindsTo)
+	w.list("PartOf", u.PartOf)
+	w.list("Conflicts", u.Conflicts)
+	w.list("After", u.After)
+	w.list("Before", u.Before)
+	for _, p := range u.ConditionPathExists {
+		w.value("ConditionPathExists", p)
+	}
+	w.extra("Unit", extra)
+}
+
+// install writes the `[Install]` section, unless it is empty.
+func (w *unitWriter) install(i *Install, extra []Directive) {
+	if len(i.WantedBy) == 0 && len(i.RequiredBy) == 0 && len(i.Alias) == 0 && len(i.Also) == 0 && !hasSection(extra, "Install") {
+		return
+	}
+	w.section("Install")
+	w.list("WantedBy", i.WantedBy)
+	w.list("RequiredBy", i.RequiredBy)
+	w.list("Alias", i.Alias)
+	w.list("Also", i.Also)
+	w.extra("Install", extra)
+}
+
+// finish checks that all the directives are in one of the written sections,
+// returning the unit file or the first error.
+func (w *unitWriter) finish(extra []Directive, section string) ([]byte, error) {
+	for _, d := range extra {
+		switch d.Section {
+		case "Unit", section, "Install":
+		default:
+			w.fail(fmt.Errorf("%w: unknown section %q", ErrInvalidValue, d.Section))
+		}
+	}
+	if w.err != nil {
+		return nil, w.err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// unitWriter writes the lines of a unit file, keeping the first error.
+type unitWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *unitWriter) fail(err error) {
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *unitWriter) section(name string) {
+	if w.buf.Len() > 0 {
+		w.buf.WriteByte('\n')
+	}
+	w.buf.WriteString("[" + name + "]\n")
+}
+
+// line writes a setting with a value that is already quoted and escaped.
+func (w *unitWriter) line(key, value string) {
+	// A trailing backslash would continue the value on the next line.
+	if strings.ContainsAny(value, "\n\r") || strings.HasSuffix(value, `\`) {
+		w.fail(fmt.Errorf("%w: %s=%q", ErrInvalidValue, key, value))
+		return
+	}
+	w.buf.WriteString(key)
+	w.buf.WriteByte('=')
+	w.buf.WriteString(value)
+	w.buf.WriteByte('\n')
+}
+
+// value writes a setting with a single value, escaping specifiers, unless it
+// is empty.
+func (w *unitWriter) value(key, value string) {
+	if value == "" {
+		return
+	}
+	w.line(key, strings.ReplaceAll(value, "%", "%%"))
+}
+
+// list writes a setting with a space-separated list of values, quoting them as
+// needed, unless it is empty.
+func (w *unitWriter) list(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	words := make([]string, len(values))
+	for i, v := range values {
+		words[i] = quote(v, false)
+	}
+	w.line(key, strings.Join(words, " "))
+}
+
+func (w *unitWriter) commands(key string, cmds []Command) {
+	for _, cmd := range cmds {
+		if len(cmd) == 0 || cmd[0] == "" {
+			w.fail(fmt.Errorf("%w: empty %s command", ErrInvalidValue, key))
+			continue
+		}
+		words := make([]string, len(cmd))
+		for i, arg := range cmd {
+			words[i] = quote(arg, true)
+		}
+		w.line(key, strings.Join(words, " "))
+	}
+}
+
+func (w *unitWriter) bool(key string, v bool) {
+	if v {
+		w.line(key, "yes")
+	}
+}
+
+func (w *unitWriter) uint(key string, v uint64) {
+	if v > 0 {
+		w.line(key, strconv.FormatUint(v, 10))
+	}
+}
+
+// duration writes a time span, such as `1min 30s`, unless it is zero.
+func (w *unitWriter) duration(key string, d time.Duration) {
+	if d > 0 {
+		w.line(key, sdtime.FormatTimespan(d, 0))
+	}
+}
+
+func (w *unitWriter) extra(section string, extra []Directive) {
+	for _, d := range extra {
+		if d.Section == section {
+			w.line(d.Key, d.Value)
+		}
+	}
+}
+
+// quote quotes and escapes a word of a setting, escaping specifiers, and
+// environment variable references if exec is true, as done by systemd for
+// command lines.
+func quote(s string, exec bool) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	if exec {
+		s = strings.ReplaceAll(s, "$", "$$")
+		// A lone semicolon separates commands.
+		if s == ";" {
+			return `\;`
+		}
+	}
+	if s != "" && !strings.ContainsFunc(s, needsQuote) {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if c < ' ' || c == 0x7f {
+				escapeChar(&b, c)
+				continue
+			}
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// needsQuote reports whether a word containing r must be quoted.
+func needsQuote(r rune) bool {
+	return r <= ' ' || r == 0x7f || r == '"' || r == '\'' || r == '\\'
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/matthewpi/sd/sdlisten"
+)
+
+// fdNameMax is the maximum length of a file descriptor name.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/fd-util.h
+const fdNameMax = 255
+
+// Socket is a socket unit file, rendered with [Socket.MarshalText].
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.socket.html
+type Socket struct {
+	Unit Unit
+
+	// ListenStream, ListenDatagram, and ListenSequentialPacket are the
+	// addresses to listen on, such as `8080`, `127.0.0.1:8080`, or
+	// `/run/app/app.sock`.
+	ListenStream           []string
+	ListenDatagram         []string
+	ListenSequentialPacket []string
+
+	// FileDescriptorName is the name of all the sockets of the unit, see
+	// [sdlisten.Listener.Name].
+	FileDescriptorName string
+	// Accept spawns an instance of the service for each connection, see
+	// [sdlisten.Connections].
+	Accept bool
+	// Service is the service activated by the socket, defaults to the service
+	// with the same name as the socket.
+	Service string
+
+	// BindIPv6Only controls whether IPv6 sockets also accept IPv4 connections,
+	// such as `ipv6-only` or `both`.
+	BindIPv6Only string
+	Backlog      uint64
+	// SocketUser, SocketGroup, SocketMode, and DirectoryMode are the owner and
+	// permissions of unix sockets and their parent directories.
+	SocketUser     string
+	SocketGroup    string
+	SocketMode     fs.FileMode
+	DirectoryMode  fs.FileMode
+	RemoveOnStop   bool
+	MaxConnections uint64
+
+	ReusePort   bool
+	FreeBind    bool
+	Transparent bool
+	KeepAlive   bool
+	NoDelay     bool
+	// ReceiveBuffer and SendBuffer are the sizes of the socket buffers in
+	// bytes.
+	ReceiveBuffer uint64
+	SendBuffer    uint64
+
+	Install Install
+
+	// Extra are additional settings, written at the end of their section.
+	Extra []Directive
+}
+
+// SocketFor returns a socket unit listening on the given sockets, with their
+// name as the [Socket.FileDescriptorName], so the listeners returned by
+// [sdlisten.ListenersByName] match the sockets the application declares.
+//
+// All the sockets must have the same name, as the name applies to all the
+// sockets of a unit. Use a socket unit per name to pass sockets with different
+// names to the same service.
+func SocketFor(sockets ...sdlisten.Socket) (*Socket, error) {
+	if len(sockets) == 0 {
+		return nil, fmt.Errorf("%w: no sockets", ErrInvalidValue)
+	}
+	s := &Socket{FileDescriptorName: sockets[0].Name}
+	for _, ls := range sockets {
+		if ls.Name != s.FileDescriptorName {
+			return nil, fmt.Errorf("%w: socket names differ (%s, %s)", ErrInvalidValue, s.FileDescriptorName, ls.Name)
+		}
+		switch ls.Network {
+		case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+			host, port, err := net.SplitHostPort(ls.Address)
+			if err != nil {
+				return nil, fmt.Errorf("%w: socket address %q: %w", ErrInvalidValue, ls.Address, err)
+			}
+			if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+				return nil, fmt.Errorf("%w: socket port %q", ErrInvalidValue, port)
+			}
+			// A port on its own listens on both IPv4 and IPv6.
+			addr := port
+			switch {
+			case host != "":
+				addr = net.JoinHostPort(host, port)
+			case strings.HasSuffix(ls.Network, "4"):
+				addr = "0.0.0.0:" + port
+			case strings.HasSuffix(ls.Network, "6"):
+				addr = "[::]:" + port
+				s.BindIPv6Only = "ipv6-only"
+			}
+			if strings.HasPrefix(ls.Network, "tcp") {
+				s.ListenStream = append(s.ListenStream, addr)
+			} else {
+				s.ListenDatagram = append(s.ListenDatagram, addr)
+			}
+		case "unix":
+			s.ListenStream = append(s.ListenStream, ls.Address)
+		case "unixgram":
+			s.ListenDatagram = append(s.ListenDatagram, ls.Address)
+		case "unixpacket":
+			s.ListenSequentialPacket = append(s.ListenSequentialPacket, ls.Address)
+		default:
+			return nil, fmt.Errorf("%w: socket network %q", ErrInvalidValue, ls.Network)
+		}
+	}
+	return s, nil
+}
+
+// MarshalText implements [encoding.TextMarshaler], rendering the socket as a
+// unit file.
+func (s *Socket) MarshalText() ([]byte, error) {
+	w := &unitWriter{}
+
+	w.unit(&s.Unit, s.Extra)
+
+	w.section("Socket")
+	for _, a := range s.ListenStream {
+		w.value("ListenStream", a)
+	}
+	for _, a := range s.ListenDatagram {
+		w.value("ListenDatagram", a)
+	}
+	for _, a := range s.ListenSequentialPacket {
+		w.value("ListenSequentialPacket", a)
+	}
+	if s.FileDescriptorName != "" && !isValidFDName(s.FileDescriptorName) {
+		w.fail(fmt.Errorf("%w: file descriptor name %q", ErrInvalidValue, s.FileDescriptorName))
+	}
+	w.value("FileDescriptorName", s.FileDescriptorName)
+	if s.Accept && len(s.ListenStream) == 0 && len(s.ListenSequentialPacket) == 0 {
+		w.fail(fmt.Errorf("%w: Accept=yes requires a stream socket", ErrInvalidValue))
+	}
+	w.bool("Accept", s.Accept)
+	w.value("Service", s.Service)
+	w.value("BindIPv6Only", s.BindIPv6Only)
+	w.uint("Backlog", s.Backlog)
+	w.value("SocketUser", s.SocketUser)
+	w.value("SocketGroup", s.SocketGroup)
+	w.mode("SocketMode", s.SocketMode)
+	w.mode("DirectoryMode", s.DirectoryMode)
+	w.bool("RemoveOnStop", s.RemoveOnStop)
+	w.uint("MaxConnections", s.MaxConnections)
+	w.bool("ReusePort", s.ReusePort)
+	w.bool("FreeBind", s.FreeBind)
+	w.bool("Transparent", s.Transparent)
+	w.bool("KeepAlive", s.KeepAlive)
+	w.bool("NoDelay", s.NoDelay)
+	w.uint("ReceiveBuffer", s.ReceiveBuffer)
+	w.uint("SendBuffer", s.SendBuffer)
+	w.extra("Socket", s.Extra)
+
+	w.install(&s.Install, s.Extra)
+	return w.finish(s.Extra, "Socket")
+}
+
+// isValidFDName reports whether name is a valid file descriptor name, printable
+// ASCII characters other than `:`.
+//
+// ref; https://github.com/systemd/systemd/blob/v257.5/src/basic/fd-util.c
+func isValidFDName(name string) bool {
+	if name == "" || len(name) > fdNameMax {
+		return false
+	}
+	for i := range len(name) {
+		if c := name[i]; c < ' ' || c >= 0x7f || c == ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// mode writes file permissions in octal, unless they are zero.
+func (w *unitWriter) mode(key string, m fs.FileMode) {
+	if m.Perm() != 0 {
+		w.line(key, fmt.Sprintf("%04o", m.Perm()))
+	}
+}
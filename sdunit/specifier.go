@@ -0,0 +1,383 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ErrUnknownSpecifier is returned by [Expander.Expand] in strict mode when
+// an unknown specifier is encountered.
+var ErrUnknownSpecifier = errors.New("sdunit: unknown specifier")
+
+// Expander expands specifiers, such as `%i` and `%n`, in strings used in unit
+// files.
+//
+// See the [Specifiers] section of systemd.unit(5) for the list of specifiers
+// and their meaning.
+//
+// [Specifiers]: https://www.freedesktop.org/software/systemd/man/latest/systemd.unit.html#Specifiers
+type Expander struct {
+	// Unit is the full name of the unit, such as `foo@bar.service`. It is used
+	// to expand the unit name specifiers (`%n`, `%N`, `%p`, `%P`, `%i`, `%I`,
+	// `%j`, `%J` and `%f`), if it is empty these specifiers are unknown.
+	Unit string
+
+	// User controls whether specifiers are expanded as they would be for a
+	// unit run by a user manager, instead of the system manager. This affects
+	// the user specifiers (`%u`, `%U`, `%g`, `%G`, `%h` and `%s`) and the
+	// directory specifiers (`%t`, `%S`, `%C`, `%L`, `%E` and `%T`).
+	User bool
+
+	// Strict controls whether unknown specifiers cause an error. If false,
+	// unknown specifiers are left as-is.
+	Strict bool
+
+	// Extra holds additional specifiers, or overrides for the default ones.
+	// For example, `%y` and `%Y` (the path of the unit file) are only known if
+	// provided here.
+	Extra map[byte]string
+}
+
+// Expand expands all the specifiers in s using the system manager's values and
+// the unit name, unknown specifiers cause an error.
+func Expand(unit, s string) (string, error) {
+	e := Expander{Unit: unit, Strict: true}
+	return e.Expand(s)
+}
+
+// Expand expands all the specifiers in s.
+func (e *Expander) Expand(s string) (string, error) {
+	if !strings.Contains(s, "%") {
+		return s, nil
+	}
+
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '%' {
+			b.WriteByte(c)
+			continue
+		}
+		if i+1 >= len(s) {
+			if e.Strict {
+				return "", fmt.Errorf("%w: trailing %%", ErrUnknownSpecifier)
+			}
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		spec := s[i]
+		v, ok, err := e.lookup(spec)
+		if err != nil {
+			return "", fmt.Errorf("sdunit: unable to expand %%%c: %w", spec, err)
+		}
+		if !ok {
+			if e.Strict {
+				return "", fmt.Errorf("%w: %%%c", ErrUnknownSpecifier, spec)
+			}
+			b.WriteByte('%')
+			b.WriteByte(spec)
+			continue
+		}
+		b.WriteString(v)
+	}
+	return b.String(), nil
+}
+
+// lookup returns the value of a specifier.
+func (e *Expander) lookup(spec byte) (string, bool, error) {
+	if v, ok := e.Extra[spec]; ok {
+		return v, true, nil
+	}
+
+	switch spec {
+	case '%':
+		return "%", true, nil
+	case 'n', 'N', 'p', 'P', 'i', 'I', 'j', 'J', 'f':
+		if e.Unit == "" {
+			return "", false, nil
+		}
+		v, err := unitSpecifier(e.Unit, spec)
+		return v, err == nil, err
+	case 'H':
+		v, err := os.Hostname()
+		return v, err == nil, err
+	case 'l':
+		v, err := os.Hostname()
+		v, _, _ = strings.Cut(v, ".")
+		return v, err == nil, err
+	case 'q':
+		v, err := prettyHostname()
+		return v, err == nil, err
+	case 'm':
+		v, err := readID("/etc/machine-id")
+		return v, err == nil, err
+	case 'b':
+		v, err := readID("/proc/sys/kernel/random/boot_id")
+		return v, err == nil, err
+	case 'o', 'w', 'B', 'W', 'M', 'A':
+		v, err := osRelease(osReleaseKeys[spec])
+		return v, err == nil, err
+	case 'v':
+		b, err := os.ReadFile("/proc/sys/kernel/osrelease")
+		return string(bytes.TrimSpace(b)), err == nil, err
+	case 'a':
+		return architecture(), true, nil
+	case 'u', 'U', 'g', 'G', 'h', 's':
+		v, err := e.userSpecifier(spec)
+		return v, err == nil, err
+	case 't', 'S', 'C', 'L', 'E', 'T':
+		v, err := e.dirSpecifier(spec)
+		return v, err == nil, err
+	case 'V':
+		return "/var/tmp", true, nil
+	case 'd':
+		v := os.Getenv("CREDENTIALS_DIRECTORY")
+		return v, v != "", nil
+	default:
+		return "", false, nil
+	}
+}
+
+// unitSpecifier returns the value of a unit name specifier.
+func unitSpecifier(unit string, spec byte) (string, error) {
+	name, ok := cutUnitType(unit)
+	if !ok {
+		return "", fmt.Errorf("invalid unit name (%s)", unit)
+	}
+	prefix, instance, _ := strings.Cut(name, "@")
+
+	switch spec {
+	case 'n':
+		return unit, nil
+	case 'N':
+		return name, nil
+	case 'p':
+		return prefix, nil
+	case 'P':
+		return Unescape(prefix)
+	case 'i':
+		return instance, nil
+	case 'I':
+		return Unescape(instance)
+	case 'j':
+		if i := strings.LastIndexByte(prefix, '-'); i >= 0 {
+			return prefix[i+1:], nil
+		}
+		return prefix, nil
+	case 'J':
+		j := prefix
+		if i := strings.LastIndexByte(prefix, '-'); i >= 0 {
+			j = prefix[i+1:]
+		}
+		return Unescape(j)
+	case 'f':
+		v := instance
+		if v == "" {
+			v = prefix
+		}
+		return UnescapePath(v)
+	default:
+		return "", fmt.Errorf("unknown unit specifier (%c)", spec)
+	}
+}
+
+// userSpecifier returns the value of a user specifier. For the system manager
+// these always refer to root.
+func (e *Expander) userSpecifier(spec byte) (string, error) {
+	if !e.User {
+		switch spec {
+		case 'u', 'g':
+			return "root", nil
+		case 'U', 'G':
+			return "0", nil
+		case 'h':
+			return "/root", nil
+		default:
+			return "/bin/sh", nil
+		}
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return "", err
+	}
+	switch spec {
+	case 'u':
+		return u.Username, nil
+	case 'U':
+		return u.Uid, nil
+	case 'g':
+		g, err := user.LookupGroupId(u.Gid)
+		if err != nil {
+			return "", err
+		}
+		return g.Name, nil
+	case 'G':
+		return u.Gid, nil
+	case 'h':
+		return u.HomeDir, nil
+	default:
+		if v := os.Getenv("SHELL"); v != "" {
+			return v, nil
+		}
+		return "/bin/sh", nil
+	}
+}
+
+// dirSpecifier returns the value of a directory specifier.
+func (e *Expander) dirSpecifier(spec byte) (string, error) {
+	if !e.User {
+		switch spec {
+		case 't':
+			return "/run", nil
+		case 'S':
+			return "/var/lib", nil
+		case 'C':
+			return "/var/cache", nil
+		case 'L':
+			return "/var/log", nil
+		case 'E':
+			return "/etc", nil
+		default:
+			return "/tmp", nil
+		}
+	}
+
+	switch spec {
+	case 't':
+		return xdgDir("XDG_RUNTIME_DIR", "")
+	case 'S':
+		return xdgDir("XDG_STATE_HOME", ".local/state")
+	case 'C':
+		return xdgDir("XDG_CACHE_HOME", ".cache")
+	case 'L':
+		dir, err := xdgDir("XDG_STATE_HOME", ".local/state")
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, "log"), nil
+	case 'E':
+		return xdgDir("XDG_CONFIG_HOME", ".config")
+	default:
+		if v := os.Getenv("TMPDIR"); filepath.IsAbs(v) {
+			return v, nil
+		}
+		return "/tmp", nil
+	}
+}
+
+// xdgDir returns the value of the XDG environment variable named by key, or the
+// fallback relative to the user's home directory if it is unset.
+func xdgDir(key, fallback string) (string, error) {
+	if v := os.Getenv(key); filepath.IsAbs(v) {
+		return v, nil
+	}
+	if fallback == "" {
+		return "", fmt.Errorf("%s is not set", key)
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, fallback), nil
+}
+
+// readID reads a 128-bit ID from a file, formatted without dashes.
+func readID(name string) (string, error) {
+	b, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(string(bytes.TrimSpace(b)), "-", ""), nil
+}
+
+// prettyHostname returns `PRETTY_HOSTNAME` from `/etc/machine-info`, falling
+// back to the hostname if it is unset.
+func prettyHostname() (string, error) {
+	if v, err := readEnvFile("/etc/machine-info", "PRETTY_HOSTNAME"); err == nil && v != "" {
+		return v, nil
+	}
+	return os.Hostname()
+}
+
+// osReleaseKeys maps specifiers to their key in os-release(5).
+var osReleaseKeys = map[byte]string{
+	'o': "ID",
+	'w': "VERSION_ID",
+	'B': "BUILD_ID",
+	'W': "VARIANT_ID",
+	'M': "IMAGE_ID",
+	'A': "IMAGE_VERSION",
+}
+
+// osRelease returns the value of key from os-release(5).
+func osRelease(key string) (string, error) {
+	v, err := readEnvFile("/etc/os-release", key)
+	if err == nil {
+		return v, nil
+	}
+	return readEnvFile("/usr/lib/os-release", key)
+}
+
+// readEnvFile reads the value of key from an environment-like file, such as
+// os-release(5) or machine-info(5). Missing keys are returned as an empty
+// string.
+func readEnvFile(name, key string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(s.Text()), "=")
+		if !ok || k != key {
+			continue
+		}
+		if len(v) >= 2 && (v[0] == '"' || v[0] == '\'') && v[len(v)-1] == v[0] {
+			v = v[1 : len(v)-1]
+		}
+		return v, nil
+	}
+	return "", s.Err()
+}
+
+// architectures maps [runtime.GOARCH] values to the architecture names used by
+// systemd.
+var architectures = map[string]string{
+	"386":      "x86",
+	"amd64":    "x86-64",
+	"arm":      "arm",
+	"arm64":    "arm64",
+	"loong64":  "loongarch64",
+	"mips":     "mips",
+	"mipsle":   "mips-le",
+	"mips64":   "mips64",
+	"mips64le": "mips64-le",
+	"ppc64":    "ppc64",
+	"ppc64le":  "ppc64-le",
+	"riscv64":  "riscv64",
+	"s390x":    "s390x",
+}
+
+// architecture returns the architecture name used by systemd for the current
+// architecture.
+func architecture() string {
+	if v, ok := architectures[runtime.GOARCH]; ok {
+		return v
+	}
+	return runtime.GOARCH
+}
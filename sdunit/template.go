@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// IsTemplate reports whether name is the name of a template unit, such as
+// `getty@.service`.
+func IsTemplate(name string) bool {
+	prefix, suffix, ok := strings.Cut(name, "@")
+	return ok && prefix != "" && strings.HasPrefix(suffix, ".") && IsValidName(name)
+}
+
+// SplitInstance splits the name of an instance of a template unit into the
+// name of the template and the unescaped instance, such as `getty@tty1.service`
+// into `getty@.service` and `tty1`. It reverses [Instance].
+func SplitInstance(name string) (template, instance string, err error) {
+	prefix, suffix, ok := strings.Cut(name, "@")
+	i := strings.LastIndexByte(suffix, '.')
+	if !ok || prefix == "" || i < 1 || !IsValidName(name) {
+		return "", "", fmt.Errorf("sdunit: not an instance of a template unit (%s)", name)
+	}
+	instance, err = Unescape(suffix[:i])
+	if err != nil {
+		return "", "", fmt.Errorf("sdunit: invalid instance name (%s): %w", name, err)
+	}
+	return prefix + "@" + suffix[i:], instance, nil
+}
+
+// InstancesOf returns the unescaped instances of a template unit among the
+// given unit names, such as the names of the units returned by
+// `sdmanager.Conn.ListUnits`. Names that are not instances of the template
+// are ignored. The instances are sorted and do not contain duplicates.
+func InstancesOf(template string, names []string) []string {
+	var instances []string
+	for _, name := range names {
+		t, instance, err := SplitInstance(name)
+		if err != nil || t != template {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	slices.Sort(instances)
+	return slices.Compact(instances)
+}
+
+// ReadInstances returns the unescaped instances of a template unit that exist
+// in the given unit directories, such as `/etc/systemd/system`, whether or not
+// they are loaded by the service manager.
+//
+// Instances exist if they have been enabled, with a symlink in a `.wants/` or
+// `.requires/` directory, have a unit file or symlink of their own, or have a
+// drop-in directory. The instances are sorted and do not contain duplicates.
+func ReadInstances(template string, dirs ...string) ([]string, error) {
+	if !IsTemplate(template) {
+		return nil, fmt.Errorf("sdunit: invalid template unit name (%s)", template)
+	}
+
+	var names []string
+	read := func(dir string) ([]fs.DirEntry, error) {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("sdunit: unable to read unit directory: %w", err)
+		}
+		return entries, nil
+	}
+	for _, dir := range dirs {
+		entries, err := read(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() && (strings.HasSuffix(name, ".wants") || strings.HasSuffix(name, ".requires")) {
+				deps, err := read(filepath.Join(dir, name))
+				if err != nil {
+					return nil, err
+				}
+				for _, d := range deps {
+					names = append(names, d.Name())
+				}
+				continue
+			}
+			names = append(names, strings.TrimSuffix(name, ".d"))
+		}
+	}
+	return InstancesOf(template, names), nil
+}
+
+// WriteInstanceDropIn writes a drop-in for a single instance of a template
+// unit in dir, such as `<dir>/app@tenant.service.d/<name>.conf`, using
+// [WriteDropIn]. Settings in the drop-in only apply to the given instance,
+// unlike drop-ins of the template.
+//
+// The service manager must be reloaded for the drop-in to take effect.
+func WriteInstanceDropIn(dir, template, instance, name string, f *File) error {
+	unit, err := Instance(template, instance)
+	if err != nil {
+		return err
+	}
+	return WriteDropIn(dir, unit, name, f)
+}
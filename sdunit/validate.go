@@ -0,0 +1,669 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sdunit
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/matthewpi/sd/sdtime"
+)
+
+// Severity is the severity of a [Diagnostic].
+type Severity int
+
+const (
+	// SeverityWarning is a mistake systemd ignores or accepts, such as an
+	// unknown setting.
+	SeverityWarning Severity = iota
+	// SeverityError is a mistake causing systemd to ignore a setting or to
+	// refuse to load the unit.
+	SeverityError
+)
+
+// String implements [fmt.Stringer].
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "Severity(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Diagnostic is a problem found by [Validator.Validate].
+type Diagnostic struct {
+	Severity Severity
+	// Section and Key are the setting the problem was found in, Key is empty
+	// for problems with a whole section and both are empty for problems with
+	// the whole unit.
+	Section string
+	Key     string
+	Message string
+}
+
+// String implements [fmt.Stringer].
+func (d Diagnostic) String() string {
+	var loc string
+	switch {
+	case d.Key != "":
+		loc = "[" + d.Section + "] " + d.Key + ": "
+	case d.Section != "":
+		loc = "[" + d.Section + "]: "
+	}
+	return d.Severity.String() + ": " + loc + d.Message
+}
+
+// Validator checks unit files for unknown settings, settings unsupported by a
+// version of systemd, invalid values, and common mistakes.
+//
+// Only the settings of the `[Unit]`, `[Install]`, `[Service]`, `[Socket]`, and
+// `[Timer]` sections are known, settings and sections prefixed with `X-` are
+// ignored. Values containing specifiers are not checked.
+type Validator struct {
+	// Version is the oldest version of systemd the unit must work with, such
+	// as 252, or zero to allow all the settings known to this package.
+	Version int
+}
+
+// Validate checks a unit file using a [Validator] allowing all the settings
+// known to this package.
+func Validate(f *File) []Diagnostic {
+	return (&Validator{}).Validate(f)
+}
+
+// Validate checks a unit file, returning the problems found in the order of
+// the settings, followed by problems with the unit as a whole.
+func (v *Validator) Validate(f *File) []Diagnostic {
+	var diags []Diagnostic
+	report := func(sev Severity, section, key, format string, args ...any) {
+		diags = append(diags, Diagnostic{Severity: sev, Section: section, Key: key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, s := range f.Sections {
+		if strings.HasPrefix(s.Name, "X-") {
+			continue
+		}
+		known, ok := directives[s.Name]
+		if !ok {
+			report(SeverityWarning, s.Name, "", "unknown section")
+			continue
+		}
+		for _, e := range s.Entries {
+			if e.Key == "" || strings.HasPrefix(e.Key, "X-") {
+				continue
+			}
+			d, ok := known[e.Key]
+			if !ok {
+				d, ok = execDirectives[e.Key]
+				ok = ok && (s.Name == "Service" || s.Name == "Socket")
+			}
+			if !ok {
+				report(SeverityWarning, s.Name, e.Key, "unknown setting")
+				continue
+			}
+			if v.Version > 0 && d.since > v.Version {
+				report(SeverityError, s.Name, e.Key, "requires systemd %d or newer", d.since)
+			}
+			if e.Value == "" || strings.Contains(e.Value, "%") {
+				continue
+			}
+			if err := d.check(e.Value); err != nil {
+				report(SeverityError, s.Name, e.Key, "invalid value %q: %v", e.Value, err)
+				continue
+			}
+			if since := d.values[e.Value]; v.Version > 0 && since > v.Version {
+				report(SeverityError, s.Name, e.Key, "%s=%s requires systemd %d or newer", e.Key, e.Value, since)
+			}
+		}
+	}
+
+	v.checkUnit(f, report)
+	return diags
+}
+
+// checkUnit checks for common mistakes involving multiple settings.
+func (v *Validator) checkUnit(f *File, report func(sev Severity, section, key, format string, args ...any)) {
+	after := words(f.Values("Unit", "After"))
+	for _, dep := range []string{"Requires", "BindsTo"} {
+		for _, u := range words(f.Values("Unit", dep)) {
+			if !slices.Contains(after, u) {
+				report(SeverityWarning, "Unit", dep, "%s is not also in After=, both units are started at the same time", u)
+			}
+		}
+	}
+
+	if s := f.Section("Service"); s != nil {
+		typ, ok := s.Value("Type")
+		if !ok || typ == "" {
+			typ = string(ServiceSimple)
+		}
+		start := s.Values("ExecStart")
+		switch {
+		case len(start) == 0 && typ != string(ServiceOneshot):
+			report(SeverityError, "Service", "ExecStart", "missing, required unless Type=oneshot")
+		case len(start) > 1 && typ != string(ServiceOneshot):
+			report(SeverityError, "Service", "ExecStart", "more than one command, only allowed with Type=oneshot")
+		}
+		if restart, _ := s.Value("Restart"); typ == string(ServiceOneshot) && (restart == string(RestartAlways) || restart == string(RestartOnSuccess)) {
+			report(SeverityError, "Service", "Restart", "Restart=%s is not allowed with Type=oneshot", restart)
+		}
+		notify := typ == string(ServiceNotify) || typ == string(ServiceNotifyReload)
+		if access, _ := s.Value("NotifyAccess"); notify && access == "none" {
+			report(SeverityError, "Service", "NotifyAccess", "NotifyAccess=none with Type=%s, the service can never become ready", typ)
+		}
+		if w, ok := s.Value("WatchdogSec"); ok && !notify {
+			if d, err := sdtime.ParseTimespan(w); err == nil && d > 0 {
+				if access, _ := s.Value("NotifyAccess"); access == "" || access == "none" {
+					report(SeverityWarning, "Service", "WatchdogSec", "the watchdog requires the service to send notifications, use Type=notify or set NotifyAccess=")
+				}
+			}
+		}
+		if pid, _ := s.Value("PIDFile"); pid != "" && typ != string(ServiceForking) {
+			report(SeverityWarning, "Service", "PIDFile", "only used with Type=forking")
+		}
+	}
+
+	if s := f.Section("Socket"); s != nil {
+		if accept, _ := s.Value("Accept"); isTrue(accept) {
+			if svc, _ := s.Value("Service"); svc != "" {
+				report(SeverityError, "Socket", "Service", "not allowed with Accept=yes")
+			}
+		}
+		if !slices.ContainsFunc(s.Entries, func(e Entry) bool { return strings.HasPrefix(e.Key, "Listen") && e.Value != "" }) {
+			report(SeverityError, "Socket", "", "no Listen setting, the socket does not listen on anything")
+		}
+	}
+
+	if s := f.Section("Timer"); s != nil {
+		if !slices.ContainsFunc(s.Entries, func(e Entry) bool { return strings.HasPrefix(e.Key, "On") && e.Value != "" }) {
+			report(SeverityError, "Timer", "", "no On setting, the timer never elapses")
+		}
+	}
+}
+
+// words splits the values of a list setting into words, ignoring values that
+// fail to split.
+func words(values []string) []string {
+	var out []string
+	for _, v := range values {
+		w, err := SplitWords(v)
+		if err == nil {
+			out = append(out, w...)
+		}
+	}
+	return out
+}
+
+// directive is a known setting.
+type directive struct {
+	// since is the version of systemd that added the setting, zero for
+	// settings older than the oldest version tracked.
+	since int
+	// check validates a value of the setting.
+	check func(string) error
+	// values are the versions of systemd that added some of the values of an
+	// enum setting.
+	values map[string]int
+}
+
+// Constructors for directives, keeping the tables below readable.
+func text(since int) directive     { return directive{since: since, check: checkAny} }
+func boolean(since int) directive  { return directive{since: since, check: checkBool} }
+func timespan(since int) directive { return directive{since: since, check: checkTimespan} }
+func size(since int) directive     { return directive{since: since, check: checkSize} }
+func number(since int) directive   { return directive{since: since, check: checkNumber} }
+func command(since int) directive  { return directive{since: since, check: checkCommand} }
+func calendar(since int) directive { return directive{since: since, check: checkCalendar} }
+func enum(since int, values map[string]int) directive {
+	return directive{since: since, check: checkEnum(values), values: values}
+}
+
+// directives are the known settings of each section.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.directives.html
+var directives = map[string]map[string]directive{
+	"Unit": {
+		"Description":                     text(0),
+		"Documentation":                   text(0),
+		"Wants":                           text(0),
+		"Requires":                        text(0),
+		"Requisite":                       text(0),
+		"BindsTo":                         text(0),
+		"PartOf":                          text(0),
+		"Upholds":                         text(249),
+		"Conflicts":                       text(0),
+		"Before":                          text(0),
+		"After":                           text(0),
+		"OnFailure":                       text(0),
+		"OnSuccess":                       text(249),
+		"PropagatesReloadTo":              text(0),
+		"ReloadPropagatedFrom":            text(0),
+		"PropagatesStopTo":                text(249),
+		"StopPropagatedFrom":              text(249),
+		"JoinsNamespaceOf":                text(0),
+		"RequiresMountsFor":               text(0),
+		"WantsMountsFor":                  text(256),
+		"DefaultDependencies":             boolean(0),
+		"StopWhenUnneeded":                boolean(0),
+		"RefuseManualStart":               boolean(0),
+		"RefuseManualStop":                boolean(0),
+		"AllowIsolate":                    boolean(0),
+		"IgnoreOnIsolate":                 boolean(0),
+		"CollectMode":                     enum(236, map[string]int{"inactive": 0, "inactive-or-failed": 0}),
+		"FailureAction":                   text(236),
+		"SuccessAction":                   text(236),
+		"JobTimeoutSec":                   timespan(0),
+		"StartLimitIntervalSec":           timespan(230),
+		"StartLimitBurst":                 number(230),
+		"StartLimitAction":                text(0),
+		"SourcePath":                      text(0),
+		"ConditionPathExists":             text(0),
+		"ConditionPathIsDirectory":        text(0),
+		"ConditionFileNotEmpty":           text(0),
+		"ConditionVirtualization":         text(0),
+		"ConditionHost":                   text(0),
+		"ConditionKernelCommandLine":      text(0),
+		"ConditionEnvironment":            text(246),
+		"ConditionUser":                   text(237),
+		"ConditionGroup":                  text(237),
+		"ConditionControlGroupController": text(246),
+		"ConditionMemoryPressure":         text(250),
+		"ConditionCPUPressure":            text(250),
+		"ConditionIOPressure":             text(250),
+		"AssertPathExists":                text(218),
+	},
+	"Install": {
+		"Alias":           text(0),
+		"WantedBy":        text(0),
+		"RequiredBy":      text(0),
+		"UpheldBy":        text(254),
+		"Also":            text(0),
+		"DefaultInstance": text(0),
+	},
+	"Service": {
+		"Type": enum(0, map[string]int{
+			"simple": 0, "exec": 240, "forking": 0, "oneshot": 0, "dbus": 0,
+			"notify": 0, "notify-reload": 253, "idle": 0,
+		}),
+		"ExitType":                  enum(250, map[string]int{"main": 0, "cgroup": 0}),
+		"RemainAfterExit":           boolean(0),
+		"GuessMainPID":              boolean(0),
+		"PIDFile":                   text(0),
+		"BusName":                   text(0),
+		"ExecStart":                 command(0),
+		"ExecStartPre":              command(0),
+		"ExecStartPost":             command(0),
+		"ExecCondition":             command(243),
+		"ExecReload":                command(0),
+		"ExecStop":                  command(0),
+		"ExecStopPost":              command(0),
+		"RestartSec":                timespan(0),
+		"RestartSteps":              number(254),
+		"RestartMaxDelaySec":        timespan(254),
+		"TimeoutStartSec":           timespan(0),
+		"TimeoutStopSec":            timespan(0),
+		"TimeoutAbortSec":           timespan(243),
+		"TimeoutSec":                timespan(0),
+		"TimeoutStartFailureMode":   enum(246, map[string]int{"terminate": 0, "abort": 0, "kill": 0}),
+		"TimeoutStopFailureMode":    enum(246, map[string]int{"terminate": 0, "abort": 0, "kill": 0}),
+		"RuntimeMaxSec":             timespan(229),
+		"RuntimeRandomizedExtraSec": timespan(250),
+		"WatchdogSec":               timespan(0),
+		"Restart": enum(0, map[string]int{
+			"no": 0, "on-success": 0, "on-failure": 0, "on-abnormal": 0,
+			"on-watchdog": 0, "on-abort": 0, "always": 0,
+		}),
+		"RestartMode":                 enum(254, map[string]int{"normal": 0, "direct": 0, "debug": 257}),
+		"SuccessExitStatus":           text(0),
+		"RestartPreventExitStatus":    text(0),
+		"RestartForceExitStatus":      text(0),
+		"RootDirectoryStartOnly":      boolean(0),
+		"NonBlocking":                 boolean(0),
+		"NotifyAccess":                enum(0, map[string]int{"none": 0, "main": 0, "exec": 246, "all": 0}),
+		"Sockets":                     text(0),
+		"FileDescriptorStoreMax":      number(219),
+		"FileDescriptorStorePreserve": enum(254, map[string]int{"no": 0, "yes": 0, "restart": 0}),
+		"USBFunctionDescriptors":      text(227),
+		"USBFunctionStrings":          text(227),
+		"OOMPolicy":                   enum(243, map[string]int{"continue": 0, "stop": 0, "kill": 0}),
+		"OpenFile":                    text(253),
+		"ReloadSignal":                text(253),
+	},
+	"Socket": {
+		"ListenStream":            text(0),
+		"ListenDatagram":          text(0),
+		"ListenSequentialPacket":  text(0),
+		"ListenFIFO":              text(0),
+		"ListenSpecial":           text(0),
+		"ListenNetlink":           text(0),
+		"ListenMessageQueue":      text(0),
+		"ListenUSBFunction":       text(227),
+		"SocketProtocol":          text(229),
+		"BindIPv6Only":            enum(0, map[string]int{"default": 0, "both": 0, "ipv6-only": 0}),
+		"Backlog":                 number(0),
+		"BindToDevice":            text(0),
+		"SocketUser":              text(214),
+		"SocketGroup":             text(214),
+		"SocketMode":              text(0),
+		"DirectoryMode":           text(0),
+		"Accept":                  boolean(0),
+		"Writable":                boolean(0),
+		"FlushPending":            boolean(247),
+		"MaxConnections":          number(0),
+		"MaxConnectionsPerSource": number(232),
+		"KeepAlive":               boolean(0),
+		"KeepAliveTimeSec":        timespan(0),
+		"KeepAliveIntervalSec":    timespan(0),
+		"KeepAliveProbes":         number(0),
+		"NoDelay":                 boolean(0),
+		"Priority":                number(0),
+		"DeferAcceptSec":          timespan(0),
+		"ReceiveBuffer":           size(0),
+		"SendBuffer":              size(0),
+		"IPTOS":                   text(0),
+		"IPTTL":                   number(0),
+		"Mark":                    number(0),
+		"ReusePort":               boolean(0),
+		"SmackLabel":              text(0),
+		"SELinuxContextFromNet":   boolean(0),
+		"PipeSize":                size(0),
+		"FreeBind":                boolean(0),
+		"Transparent":             boolean(0),
+		"Broadcast":               boolean(0),
+		"PassCredentials":         boolean(0),
+		"PassSecurity":            boolean(0),
+		"PassPacketInfo":          boolean(247),
+		"Timestamping":            enum(247, map[string]int{"off": 0, "us": 0, "usec": 0, "µs": 0, "ns": 0, "nsec": 0}),
+		"TCPCongestion":           text(0),
+		"ExecStartPre":            command(0),
+		"ExecStartPost":           command(0),
+		"ExecStopPre":             command(0),
+		"ExecStopPost":            command(0),
+		"TimeoutSec":              timespan(0),
+		"Service":                 text(0),
+		"RemoveOnStop":            boolean(0),
+		"Symlinks":                text(0),
+		"FileDescriptorName":      text(227),
+		"TriggerLimitIntervalSec": timespan(230),
+		"TriggerLimitBurst":       number(230),
+		"PollLimitIntervalSec":    timespan(255),
+		"PollLimitBurst":          number(255),
+	},
+	"Timer": {
+		"OnActiveSec":         timespan(0),
+		"OnBootSec":           timespan(0),
+		"OnStartupSec":        timespan(0),
+		"OnUnitActiveSec":     timespan(0),
+		"OnUnitInactiveSec":   timespan(0),
+		"OnCalendar":          calendar(0),
+		"AccuracySec":         timespan(0),
+		"RandomizedDelaySec":  timespan(229),
+		"RandomizedOffsetSec": timespan(257),
+		"FixedRandomDelay":    boolean(247),
+		"DeferReactivation":   boolean(257),
+		"OnClockChange":       boolean(242),
+		"OnTimezoneChange":    boolean(242),
+		"Unit":                text(0),
+		"Persistent":          boolean(0),
+		"WakeSystem":          boolean(0),
+		"RemainAfterElapse":   boolean(229),
+	},
+}
+
+// execDirectives are the known settings shared by the `[Service]` and
+// `[Socket]` sections, configuring the execution environment and the resource
+// limits of the processes.
+//
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.exec.html
+// ref; https://www.freedesktop.org/software/systemd/man/latest/systemd.resource-control.html
+var execDirectives = map[string]directive{
+	"WorkingDirectory":              text(0),
+	"RootDirectory":                 text(0),
+	"RootImage":                     text(233),
+	"User":                          text(0),
+	"Group":                         text(0),
+	"DynamicUser":                   boolean(232),
+	"SupplementaryGroups":           text(0),
+	"Environment":                   text(0),
+	"EnvironmentFile":               text(0),
+	"PassEnvironment":               text(228),
+	"UnsetEnvironment":              text(235),
+	"StandardInput":                 text(0),
+	"StandardOutput":                text(0),
+	"StandardError":                 text(0),
+	"SyslogIdentifier":              text(0),
+	"LogLevelMax":                   text(236),
+	"LogExtraFields":                text(236),
+	"LogNamespace":                  text(245),
+	"UMask":                         text(0),
+	"Nice":                          number(0),
+	"OOMScoreAdjust":                text(0),
+	"LimitNOFILE":                   text(0),
+	"LimitNPROC":                    text(0),
+	"LimitCORE":                     text(0),
+	"LimitMEMLOCK":                  text(0),
+	"StateDirectory":                text(235),
+	"RuntimeDirectory":              text(211),
+	"CacheDirectory":                text(235),
+	"LogsDirectory":                 text(235),
+	"ConfigurationDirectory":        text(235),
+	"StateDirectoryMode":            text(235),
+	"RuntimeDirectoryMode":          text(211),
+	"RuntimeDirectoryPreserve":      enum(235, map[string]int{"no": 0, "yes": 0, "restart": 0}),
+	"LoadCredential":                text(247),
+	"LoadCredentialEncrypted":       text(250),
+	"SetCredential":                 text(247),
+	"SetCredentialEncrypted":        text(250),
+	"ImportCredential":              text(254),
+	"NoNewPrivileges":               boolean(0),
+	"ProtectSystem":                 enum(0, map[string]int{"yes": 0, "no": 0, "true": 0, "false": 0, "full": 0, "strict": 0}),
+	"ProtectHome":                   enum(0, map[string]int{"yes": 0, "no": 0, "true": 0, "false": 0, "read-only": 0, "tmpfs": 0}),
+	"ReadWritePaths":                text(231),
+	"ReadOnlyPaths":                 text(231),
+	"InaccessiblePaths":             text(231),
+	"ExecPaths":                     text(247),
+	"NoExecPaths":                   text(247),
+	"BindPaths":                     text(233),
+	"BindReadOnlyPaths":             text(233),
+	"TemporaryFileSystem":           text(238),
+	"PrivateTmp":                    boolean(0),
+	"PrivateDevices":                boolean(0),
+	"PrivateNetwork":                boolean(0),
+	"PrivateUsers":                  boolean(232),
+	"PrivateIPC":                    boolean(248),
+	"PrivateMounts":                 boolean(239),
+	"ProtectKernelTunables":         boolean(232),
+	"ProtectKernelModules":          boolean(232),
+	"ProtectKernelLogs":             boolean(244),
+	"ProtectControlGroups":          boolean(232),
+	"ProtectClock":                  boolean(245),
+	"ProtectHostname":               boolean(242),
+	"ProtectProc":                   enum(247, map[string]int{"noaccess": 0, "invisible": 0, "ptraceable": 0, "default": 0}),
+	"ProcSubset":                    enum(247, map[string]int{"all": 0, "pid": 0}),
+	"RestrictNamespaces":            text(233),
+	"RestrictRealtime":              boolean(231),
+	"RestrictSUIDSGID":              boolean(242),
+	"RestrictAddressFamilies":       text(211),
+	"RestrictFileSystems":           text(250),
+	"LockPersonality":               boolean(235),
+	"MemoryDenyWriteExecute":        boolean(231),
+	"RemoveIPC":                     boolean(232),
+	"KeyringMode":                   enum(235, map[string]int{"inherit": 0, "private": 0, "shared": 0}),
+	"CapabilityBoundingSet":         text(0),
+	"AmbientCapabilities":           text(229),
+	"SystemCallFilter":              text(0),
+	"SystemCallArchitectures":       text(0),
+	"SystemCallErrorNumber":         text(0),
+	"SystemCallLog":                 text(247),
+	"DevicePolicy":                  enum(0, map[string]int{"auto": 0, "closed": 0, "strict": 0}),
+	"DeviceAllow":                   text(0),
+	"IPAddressAllow":                text(235),
+	"IPAddressDeny":                 text(235),
+	"KillMode":                      enum(0, map[string]int{"control-group": 0, "mixed": 0, "process": 0, "none": 0}),
+	"KillSignal":                    text(0),
+	"SendSIGKILL":                   boolean(0),
+	"SendSIGHUP":                    boolean(0),
+	"Slice":                         text(0),
+	"Delegate":                      text(0),
+	"CPUAccounting":                 boolean(0),
+	"CPUWeight":                     text(231),
+	"StartupCPUWeight":              text(231),
+	"CPUQuota":                      directive{since: 213, check: checkPercent},
+	"CPUQuotaPeriodSec":             timespan(242),
+	"AllowedCPUs":                   text(244),
+	"MemoryAccounting":              boolean(0),
+	"MemoryMin":                     size(240),
+	"MemoryLow":                     size(233),
+	"MemoryHigh":                    size(231),
+	"MemoryMax":                     size(231),
+	"MemorySwapMax":                 size(232),
+	"MemoryZSwapMax":                size(253),
+	"MemoryLimit":                   size(0),
+	"TasksAccounting":               boolean(227),
+	"TasksMax":                      text(227),
+	"IOAccounting":                  boolean(230),
+	"IOWeight":                      text(230),
+	"IODeviceLatencyTargetSec":      text(240),
+	"IOReadBandwidthMax":            text(230),
+	"IOWriteBandwidthMax":           text(230),
+	"ManagedOOMSwap":                enum(247, map[string]int{"auto": 0, "kill": 0}),
+	"ManagedOOMMemoryPressure":      enum(247, map[string]int{"auto": 0, "kill": 0}),
+	"ManagedOOMMemoryPressureLimit": directive{since: 247, check: checkPercent},
+	"ManagedOOMPreference":          enum(248, map[string]int{"none": 0, "avoid": 0, "omit": 0}),
+	"MemoryPressureWatch":           enum(254, map[string]int{"auto": 0, "on": 0, "off": 0, "skip": 0}),
+	"MemoryPressureThresholdSec":    timespan(254),
+	"CoredumpFilter":                text(246),
+	"CoredumpReceive":               boolean(255),
+}
+
+func checkAny(string) error { return nil }
+
+func checkBool(s string) error {
+	if _, err := parseBool(s); err != nil {
+		return err
+	}
+	return nil
+}
+
+func checkTimespan(s string) error {
+	_, err := sdtime.ParseTimespan(s)
+	return err
+}
+
+func checkNumber(s string) error {
+	if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+		return errors.New("not a number")
+	}
+	return nil
+}
+
+func checkSize(s string) error {
+	if strings.HasSuffix(s, "%") {
+		return checkPercent(s)
+	}
+	_, err := parseSize(s)
+	return err
+}
+
+func checkPercent(s string) error {
+	p, ok := strings.CutSuffix(s, "%")
+	if !ok {
+		return errors.New("not a percentage")
+	}
+	if v, err := strconv.ParseFloat(p, 64); err != nil || v < 0 || math.IsInf(v, 0) {
+		return errors.New("not a percentage")
+	}
+	return nil
+}
+
+func checkCommand(s string) error {
+	cmds, err := SplitCommands(s)
+	if err != nil {
+		return err
+	}
+	for _, cmd := range cmds {
+		if strings.TrimLeft(cmd[0], "@-:+!|") == "" {
+			return errors.New("missing executable")
+		}
+	}
+	return nil
+}
+
+// checkCalendar checks a calendar event using [sdtime.ParseCalendar].
+func checkCalendar(s string) error {
+	_, err := sdtime.ParseCalendar(s)
+	return err
+}
+
+func checkEnum(values map[string]int) func(string) error {
+	return func(s string) error {
+		if _, ok := values[s]; !ok {
+			return fmt.Errorf("must be one of %s", strings.Join(slices.Sorted(maps.Keys(values)), ", "))
+		}
+		return nil
+	}
+}
+
+// isTrue reports whether s is a true boolean value.
+func isTrue(s string) bool {
+	b, err := parseBool(s)
+	return err == nil && b
+}
+
+// parseBool parses a boolean value, as done by systemd.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "1", "yes", "y", "true", "t", "on":
+		return true, nil
+	case "0", "no", "n", "false", "f", "off":
+		return false, nil
+	default:
+		return false, errors.New("not a boolean")
+	}
+}
+
+// parseSize parses a size in bytes with an optional binary suffix, such as
+// `512M`, as done by systemd. A size of `infinity` is returned as the maximum
+// size.
+func parseSize(s string) (uint64, error) {
+	if s == "infinity" {
+		return math.MaxUint64, nil
+	}
+	num := strings.TrimRight(s, "KMGTPEB")
+	var mult uint64 = 1
+	switch strings.TrimSuffix(s[len(num):], "B") {
+	case "":
+	case "K":
+		mult = 1 << 10
+	case "M":
+		mult = 1 << 20
+	case "G":
+		mult = 1 << 30
+	case "T":
+		mult = 1 << 40
+	case "P":
+		mult = 1 << 50
+	case "E":
+		mult = 1 << 60
+	default:
+		return 0, errors.New("not a size")
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 64)
+	if err != nil || n < 0 {
+		return 0, errors.New("not a size")
+	}
+	v := n * float64(mult)
+	if v >= math.MaxUint64 {
+		return 0, errors.New("size is too large")
+	}
+	return uint64(v), nil
+}
@@ -0,0 +1,198 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/matthewpi/sd/internal/metrics"
+	"github.com/matthewpi/sd/sdlisten"
+	"github.com/matthewpi/sd/sdnotify"
+)
+
+// Handler serves the connections of a listener, such as a [http.Server].
+type Handler interface {
+	// Serve accepts connections on the listener until it is closed or the
+	// handler is shut down.
+	Serve(l net.Listener) error
+	// Shutdown stops the handler, waiting for active connections to finish
+	// until the context is canceled.
+	Shutdown(ctx context.Context) error
+}
+
+// Server serves the listeners of an application with a handler per listener
+// name, notifying systemd once all of them are accepting connections.
+//
+// If any of the handlers fails, the failure is reported to systemd and all
+// the handlers are shut down, rather than continuing with some of the
+// listeners not being served.
+type Server struct {
+	// Handlers are the handlers of the listeners by name, such as the
+	// [FileDescriptorName=] of the `.socket` unit. Every listener must have a
+	// handler and every handler must have at least one listener.
+	//
+	// [FileDescriptorName=]: https://www.freedesktop.org/software/systemd/man/latest/systemd.socket.html#FileDescriptorName=
+	Handlers map[string]Handler
+
+	// Listeners are the sockets to listen on if they were not passed by
+	// systemd, the same as [App.Listeners].
+	Listeners []sdlisten.Socket
+
+	// ShutdownTimeout limits how long shutting down the handlers may take,
+	// zero leaves it to `TimeoutStopSec=` of the service.
+	ShutdownTimeout time.Duration
+}
+
+// acceptListener is a [net.Listener] that reports when it first accepts
+// connections.
+type acceptListener struct {
+	net.Listener
+	once      sync.Once
+	accepting *sync.WaitGroup
+}
+
+func (l *acceptListener) Accept() (net.Conn, error) {
+	l.once.Do(l.accepting.Done)
+	c, err := l.Listener.Accept()
+	if err != nil {
+		if !errors.Is(err, net.ErrClosed) {
+			metrics.ListenerErrors.Add(1)
+		}
+		return nil, err
+	}
+	metrics.ListenerAccepts.Add(1)
+	return c, nil
+}
+
+// serveResult is the result of serving a single listener.
+type serveResult struct {
+	name string
+	err  error
+}
+
+// Serve serves the listeners until the context is canceled or one of the
+// handlers fails, then shuts down all of the handlers.
+//
+// systemd is notified that the application is ready once all of the handlers
+// are accepting connections, and that it is stopping once the context is
+// canceled. The error of a failed handler is returned and reported to systemd
+// using [sdnotify.Error].
+func (s *Server) Serve(ctx context.Context) error {
+	listeners, err := acquireListeners(s.Listeners)
+	if err != nil {
+		_ = sdnotify.Error(err, errno(err))
+		return err
+	}
+	for name := range s.Handlers {
+		if len(listeners[name]) == 0 {
+			err = errors.Join(err, fmt.Errorf("sd: no listener for handler (%s)", name))
+		}
+	}
+	for name := range listeners {
+		if s.Handlers[name] == nil {
+			err = errors.Join(err, fmt.Errorf("sd: no handler for listener (%s)", name))
+		}
+	}
+	if err != nil {
+		closeListeners(listeners)
+		_ = sdnotify.Error(err, errno(err))
+		return err
+	}
+
+	var (
+		accepting sync.WaitGroup
+		serving   int
+		results   = make(chan serveResult)
+	)
+	for name, ls := range listeners {
+		h := s.Handlers[name]
+		for _, l := range ls {
+			al := &acceptListener{Listener: l.Listener, accepting: &accepting}
+			accepting.Add(1)
+			serving++
+			go func() {
+				err := h.Serve(al)
+				al.once.Do(accepting.Done)
+				results <- serveResult{name: name, err: err}
+			}()
+		}
+	}
+	ready := make(chan struct{})
+	go func() {
+		accepting.Wait()
+		close(ready)
+	}()
+
+run:
+	for {
+		select {
+		case <-ready:
+			ready = nil
+			_ = sdnotify.Ready()
+		case r := <-results:
+			serving--
+			if r.err == nil {
+				r.err = errors.New("stopped unexpectedly")
+			}
+			err = fmt.Errorf("sd: unable to serve listener (%s): %w", r.name, r.err)
+			break run
+		case <-ctx.Done():
+			break run
+		}
+	}
+	if err != nil {
+		_ = sdnotify.Error(err, errno(err))
+	} else {
+		_ = sdnotify.Stopping()
+	}
+
+	shutdownCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+	if s.ShutdownTimeout > 0 {
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, s.ShutdownTimeout)
+		defer cancel()
+	}
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = []error{err}
+	)
+	for name, h := range s.Handlers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := h.Shutdown(shutdownCtx); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("sd: unable to shut down handler (%s): %w", name, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	closeListeners(listeners)
+
+	for ; serving > 0; serving-- {
+		r := <-results
+		if r.err != nil && !errors.Is(r.err, http.ErrServerClosed) && !errors.Is(r.err, net.ErrClosed) {
+			errs = append(errs, fmt.Errorf("sd: unable to serve listener (%s): %w", r.name, r.err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// errno returns the errno of err to report to systemd, or 1 if it has none.
+func errno(err error) int {
+	var n syscall.Errno
+	if errors.As(err, &n) && n > 0 {
+		return int(n)
+	}
+	return 1
+}
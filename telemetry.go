@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: MIT
+// SPDX-FileCopyrightText: Copyright (c) 2025 Matthew Penner
+
+package sd
+
+import (
+	"context"
+
+	"github.com/matthewpi/sd/sddaemon"
+	"github.com/matthewpi/sd/sdid128"
+)
+
+// Transition is a change in the state of an application run by [Run], passed
+// to [App.OnTransition].
+type Transition string
+
+const (
+	// TransitionReady is when the application has started or reloaded.
+	TransitionReady Transition = "ready"
+	// TransitionReloading is when the application starts reloading.
+	TransitionReloading Transition = "reloading"
+	// TransitionReloadFailed is when the application failed to reload and
+	// keeps running as it was.
+	TransitionReloadFailed Transition = "reload-failed"
+	// TransitionStopping is when the application starts stopping.
+	TransitionStopping Transition = "stopping"
+)
+
+func (app *App) transition(ctx context.Context, t Transition) {
+	if app.OnTransition != nil {
+		app.OnTransition(ctx, t)
+	}
+}
+
+// ResourceAttributes returns attributes identifying this run of the service,
+// to be added to the resource of telemetry such as OpenTelemetry traces, so
+// the telemetry can be correlated with the journal of the service.
+//
+// The attributes follow the semantic conventions of OpenTelemetry where there
+// is one: `service.instance.id` is the invocation ID of the unit, which
+// changes each time the unit is started, and `host.id` is the machine ID.
+// `systemd.unit` and `systemd.invocation_id` are the unit and invocation ID
+// as matched by `journalctl _SYSTEMD_UNIT=` and `_SYSTEMD_INVOCATION_ID=`.
+// Attributes that are unknown, such as outside of systemd, are left out.
+func ResourceAttributes() map[string]string {
+	attrs := make(map[string]string)
+	if id, ok := sdid128.InvocationID(); ok {
+		attrs["service.instance.id"] = id.UUID()
+		attrs["systemd.invocation_id"] = id.String()
+	}
+	if id, err := sdid128.MachineID(); err == nil {
+		attrs["host.id"] = id.String()
+	}
+	if unit, _, err := sddaemon.CurrentUnit(); err == nil {
+		attrs["systemd.unit"] = unit
+	}
+	return attrs
+}